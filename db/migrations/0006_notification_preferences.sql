@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-user delivery preferences, one row per notification type and channel.
+-- Missing rows mean "enabled, immediate, no quiet hours".
+CREATE TABLE IF NOT EXISTS notification_preferences (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  notification_type TEXT NOT NULL,
+  channel TEXT NOT NULL,
+  enabled INTEGER NOT NULL DEFAULT 1,
+  quiet_hours_start TEXT,
+  quiet_hours_end TEXT,
+  delivery_mode TEXT NOT NULL DEFAULT 'IMMEDIATE',
+  digest_time TEXT NOT NULL DEFAULT '09:00',
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_preferences_unique
+  ON notification_preferences (user_id, notification_type, channel);
+
+-- Messages held back by quiet hours or digest delivery until deliver_after.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  patient_id TEXT NOT NULL,
+  notification_type TEXT NOT NULL,
+  channel TEXT NOT NULL,
+  destination TEXT NOT NULL,
+  message TEXT NOT NULL,
+  digest INTEGER NOT NULL DEFAULT 0,
+  deliver_after TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'QUEUED',
+  provider_message_id TEXT,
+  error_message TEXT,
+  sent_at TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due
+  ON notification_outbox (status, deliver_after);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_notification_outbox_due;
+DROP TABLE IF EXISTS notification_outbox;
+DROP INDEX IF EXISTS idx_notification_preferences_unique;
+DROP TABLE IF EXISTS notification_preferences;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Locale used to pick the message template bundle (en, fr, es).
+ALTER TABLE users ADD COLUMN locale TEXT NOT NULL DEFAULT 'en';
+ALTER TABLE patients ADD COLUMN locale TEXT NOT NULL DEFAULT 'en';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE patients DROP COLUMN locale;
+ALTER TABLE users DROP COLUMN locale;
+
+-- +goose StatementEnd
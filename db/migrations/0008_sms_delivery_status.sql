@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Carrier delivery status reported by Twilio status callbacks. status keeps
+-- the dispatch outcome (SENT, QUEUED, FAILED, ...); delivery_status follows
+-- the message after Twilio accepted it.
+ALTER TABLE notification_events ADD COLUMN delivery_status TEXT;
+ALTER TABLE notification_events ADD COLUMN error_code TEXT;
+ALTER TABLE notification_events ADD COLUMN delivery_updated_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_provider_message
+  ON notification_events (provider_message_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_notification_events_provider_message;
+ALTER TABLE notification_events DROP COLUMN delivery_updated_at;
+ALTER TABLE notification_events DROP COLUMN error_code;
+ALTER TABLE notification_events DROP COLUMN delivery_status;
+
+-- +goose StatementEnd
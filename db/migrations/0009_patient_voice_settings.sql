@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Voice used for a patient's spoken reminders. Missing rows, or NULL
+-- voice_name/language_code, fall back to the engine default for the
+-- patient's locale.
+CREATE TABLE IF NOT EXISTS patient_voice_settings (
+  patient_id TEXT PRIMARY KEY,
+  voice_name TEXT,
+  language_code TEXT,
+  speaking_rate REAL NOT NULL DEFAULT 1.0,
+  sample_rate_hz INTEGER NOT NULL DEFAULT 8000,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS patient_voice_settings;
+
+-- +goose StatementEnd
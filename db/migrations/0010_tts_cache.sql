@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Synthesized reminder audio, keyed by a hash of the text and voice
+-- settings. The WAV itself lives on disk at file_path; last_used_at drives
+-- LRU eviction.
+CREATE TABLE IF NOT EXISTS tts_cache (
+  cache_key TEXT PRIMARY KEY,
+  file_path TEXT NOT NULL,
+  size_bytes INTEGER NOT NULL,
+  hit_count INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  last_used_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tts_cache_last_used
+  ON tts_cache (last_used_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_tts_cache_last_used;
+DROP TABLE IF EXISTS tts_cache;
+
+-- +goose StatementEnd
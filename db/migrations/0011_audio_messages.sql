@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Spoken reminders queued for the dispenser's speaker. Rows are kept after
+-- acknowledgement so playback can be audited.
+CREATE TABLE IF NOT EXISTS audio_messages (
+  id TEXT PRIMARY KEY,
+  patient_id TEXT NOT NULL,
+  schedule_id TEXT NOT NULL,
+  due_at_iso TEXT NOT NULL,
+  file_path TEXT NOT NULL,
+  mime_type TEXT NOT NULL DEFAULT 'audio/wav',
+  size_bytes INTEGER NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  delivered_at TEXT,
+  acked_at TEXT,
+  expires_at TEXT NOT NULL,
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE,
+  FOREIGN KEY (schedule_id) REFERENCES schedules (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audio_messages_occurrence
+  ON audio_messages (patient_id, schedule_id, due_at_iso);
+
+CREATE INDEX IF NOT EXISTS idx_audio_messages_pending
+  ON audio_messages (patient_id, acked_at, expires_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_audio_messages_pending;
+DROP INDEX IF EXISTS idx_audio_messages_occurrence;
+DROP TABLE IF EXISTS audio_messages;
+
+-- +goose StatementEnd
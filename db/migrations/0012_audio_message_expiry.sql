@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- expired_at/expiry_reason record why a clip left the queue without being
+-- acknowledged (TAKEN, SKIPPED, MISSED, AGE or SUPERSEDED); purged_at is set
+-- once the janitor removed its WAV.
+ALTER TABLE audio_messages ADD COLUMN expired_at TEXT;
+ALTER TABLE audio_messages ADD COLUMN expiry_reason TEXT;
+ALTER TABLE audio_messages ADD COLUMN purged_at TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE audio_messages DROP COLUMN purged_at;
+ALTER TABLE audio_messages DROP COLUMN expiry_reason;
+ALTER TABLE audio_messages DROP COLUMN expired_at;
+
+-- +goose StatementEnd
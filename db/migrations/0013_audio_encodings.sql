@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Checksum and format of the primary WAV, reported in the next-audio
+-- manifest so the device can verify downloads.
+ALTER TABLE audio_messages ADD COLUMN sha256 TEXT;
+ALTER TABLE audio_messages ADD COLUMN duration_ms INTEGER;
+ALTER TABLE audio_messages ADD COLUMN sample_rate_hz INTEGER;
+
+-- Additional encodings (Opus, MP3) transcoded from the primary WAV.
+CREATE TABLE IF NOT EXISTS audio_message_encodings (
+  message_id TEXT NOT NULL,
+  mime_type TEXT NOT NULL,
+  file_path TEXT NOT NULL,
+  size_bytes INTEGER NOT NULL,
+  sha256 TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (message_id, mime_type),
+  FOREIGN KEY (message_id) REFERENCES audio_messages (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS audio_message_encodings;
+ALTER TABLE audio_messages DROP COLUMN sample_rate_hz;
+ALTER TABLE audio_messages DROP COLUMN duration_ms;
+ALTER TABLE audio_messages DROP COLUMN sha256;
+
+-- +goose StatementEnd
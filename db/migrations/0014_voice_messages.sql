@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Caregiver recordings played instead of the TTS reminder. due_at_iso NULL
+-- attaches the recording to every occurrence of the schedule; a recording
+-- for a specific occurrence takes precedence.
+CREATE TABLE IF NOT EXISTS voice_messages (
+  id TEXT PRIMARY KEY,
+  patient_id TEXT NOT NULL,
+  schedule_id TEXT NOT NULL,
+  due_at_iso TEXT,
+  file_path TEXT NOT NULL,
+  original_mime_type TEXT NOT NULL,
+  size_bytes INTEGER NOT NULL,
+  sha256 TEXT NOT NULL,
+  duration_ms INTEGER NOT NULL,
+  sample_rate_hz INTEGER NOT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE,
+  FOREIGN KEY (schedule_id) REFERENCES schedules (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_voice_messages_schedule
+  ON voice_messages (schedule_id, active);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_voice_messages_schedule;
+DROP TABLE IF EXISTS voice_messages;
+
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Every change to a medication's stock_count. quantity is signed (refills
+-- positive, dispenses and wastage negative) and balance_after is stock_count
+-- once the movement is applied, so summing quantity reproduces stock_count.
+-- created_at is RFC 3339 so stockHistory can filter it by range.
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id TEXT PRIMARY KEY,
+  medication_id TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('REFILL', 'DISPENSE', 'ADJUSTMENT', 'WASTAGE', 'CORRECTION')),
+  quantity INTEGER NOT NULL,
+  balance_after INTEGER NOT NULL,
+  actor TEXT,
+  dispense_event_id TEXT,
+  note TEXT,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  FOREIGN KEY (medication_id) REFERENCES medications (id) ON DELETE CASCADE,
+  FOREIGN KEY (dispense_event_id) REFERENCES dispense_events (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_medication
+  ON stock_movements (medication_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_dispense_event
+  ON stock_movements (dispense_event_id);
+
+-- Open the ledger with the stock each medication already has.
+INSERT INTO stock_movements (id, medication_id, kind, quantity, balance_after, actor, note, created_at)
+SELECT 'opening_' || id, id, 'CORRECTION', stock_count, stock_count, 'system', 'Opening balance', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM medications
+WHERE stock_count <> 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_stock_movements_dispense_event;
+DROP INDEX IF EXISTS idx_stock_movements_medication;
+DROP TABLE IF EXISTS stock_movements;
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Refills record the lot loaded into the silo.
+ALTER TABLE stock_movements ADD COLUMN lot_number TEXT;
+ALTER TABLE stock_movements ADD COLUMN expires_on TEXT;
+
+-- Set when the caregiver is alerted about low stock and cleared by a refill,
+-- so the alert fires once per refill cycle.
+ALTER TABLE medications ADD COLUMN low_stock_alerted_at TEXT;
+
+-- Medications already at or below their threshold were alerted on the way
+-- down.
+UPDATE medications
+SET low_stock_alerted_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE stock_count <= low_stock_threshold;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE medications DROP COLUMN low_stock_alerted_at;
+ALTER TABLE stock_movements DROP COLUMN expires_on;
+ALTER TABLE stock_movements DROP COLUMN lot_number;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Days the patient's pharmacy needs to fill a prescription; refill-by dates
+-- are this many days before the forecast run-out.
+ALTER TABLE patients ADD COLUMN pharmacy_lead_time_days INTEGER NOT NULL DEFAULT 3;
+
+-- Set when the caregiver is warned about a forecast run-out and cleared by a
+-- refill, like low_stock_alerted_at.
+ALTER TABLE medications ADD COLUMN runout_alerted_at TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE medications DROP COLUMN runout_alerted_at;
+ALTER TABLE patients DROP COLUMN pharmacy_lead_time_days;
+
+-- +goose StatementEnd
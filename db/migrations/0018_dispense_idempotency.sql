@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Keys devices send with recordDispenseAction so a retried request returns
+-- the original event instead of decrementing stock twice.
+CREATE TABLE IF NOT EXISTS dispense_idempotency_keys (
+  patient_id TEXT NOT NULL,
+  idempotency_key TEXT NOT NULL,
+  dispense_event_id TEXT NOT NULL,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  PRIMARY KEY (patient_id, idempotency_key),
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE,
+  FOREIGN KEY (dispense_event_id) REFERENCES dispense_events (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS dispense_idempotency_keys;
+
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Pills loaded into a silo, consumed oldest load first. The remaining
+-- quantities of a medication's lots add up to its stock_count; stock that
+-- predates lot tracking or was added by an adjustment has no lot_number.
+CREATE TABLE IF NOT EXISTS medication_lots (
+  id TEXT PRIMARY KEY,
+  medication_id TEXT NOT NULL,
+  lot_number TEXT,
+  quantity_loaded INTEGER NOT NULL,
+  quantity_remaining INTEGER NOT NULL,
+  -- YYYY-MM-DD
+  expires_on TEXT,
+  silo INTEGER,
+  loaded_at TEXT NOT NULL,
+  expiry_alerted_at TEXT,
+  FOREIGN KEY (medication_id) REFERENCES medications (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_medication_lots_medication
+  ON medication_lots (medication_id, loaded_at);
+
+-- How much of each lot a stock movement took or added.
+CREATE TABLE IF NOT EXISTS stock_movement_lots (
+  stock_movement_id TEXT NOT NULL,
+  lot_id TEXT NOT NULL,
+  quantity INTEGER NOT NULL,
+  PRIMARY KEY (stock_movement_id, lot_id),
+  FOREIGN KEY (stock_movement_id) REFERENCES stock_movements (id) ON DELETE CASCADE,
+  FOREIGN KEY (lot_id) REFERENCES medication_lots (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movement_lots_lot
+  ON stock_movement_lots (lot_id);
+
+-- Current stock becomes an untracked opening lot.
+INSERT INTO medication_lots (id, medication_id, quantity_loaded, quantity_remaining, silo, loaded_at)
+SELECT 'opening_' || id, id, stock_count, stock_count, cartridge_index, strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM medications
+WHERE stock_count > 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_stock_movement_lots_lot;
+DROP TABLE IF EXISTS stock_movement_lots;
+DROP INDEX IF EXISTS idx_medication_lots_medication;
+DROP TABLE IF EXISTS medication_lots;
+
+-- +goose StatementEnd
@@ -0,0 +1,101 @@
+-- +goose NO TRANSACTION
+
+-- Rebuilding medications needs foreign keys off, which SQLite ignores inside
+-- a transaction, so this migration manages its own.
+
+-- +goose Up
+-- +goose StatementBegin
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+-- Drop the 0-2 limit on cartridge_index; the patient's silo count bounds it
+-- now.
+CREATE TABLE medications_new (
+  id TEXT PRIMARY KEY,
+  patient_id TEXT NOT NULL,
+  label TEXT NOT NULL,
+  color TEXT,
+  stock_count INTEGER NOT NULL DEFAULT 0,
+  low_stock_threshold INTEGER NOT NULL DEFAULT 0,
+  cartridge_index INTEGER CHECK (cartridge_index >= 0),
+  max_daily_dose INTEGER NOT NULL DEFAULT 1,
+  created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+  low_stock_alerted_at TEXT,
+  runout_alerted_at TEXT,
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE
+);
+
+INSERT INTO medications_new (id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at)
+SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at
+FROM medications;
+
+DROP TABLE medications;
+ALTER TABLE medications_new RENAME TO medications;
+CREATE INDEX IF NOT EXISTS idx_medications_patient ON medications (patient_id);
+
+-- Only one medication per silo: the earliest one keeps a contested silo.
+UPDATE medications
+SET cartridge_index = NULL
+WHERE cartridge_index IS NOT NULL
+  AND rowid NOT IN (
+    SELECT MIN(rowid) FROM medications
+    WHERE cartridge_index IS NOT NULL
+    GROUP BY patient_id, cartridge_index
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_medications_patient_silo
+  ON medications (patient_id, cartridge_index)
+  WHERE cartridge_index IS NOT NULL;
+
+-- Number of silos on the patient's dispenser.
+ALTER TABLE patients ADD COLUMN silo_count INTEGER NOT NULL DEFAULT 3;
+
+-- One row per physical silo. The medication loaded in a silo is the one
+-- whose cartridge_index matches silo_index, and its stock_count is the fill.
+CREATE TABLE IF NOT EXISTS silos (
+  id TEXT PRIMARY KEY,
+  patient_id TEXT NOT NULL,
+  silo_index INTEGER NOT NULL CHECK (silo_index >= 0),
+  capacity INTEGER NOT NULL CHECK (capacity > 0),
+  pill_size_mm REAL,
+  -- When the device last collected a calibration request for the silo
+  calibrated_at TEXT,
+  updated_at TEXT NOT NULL,
+  UNIQUE (patient_id, silo_index),
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE
+);
+
+-- Existing patients get the three silos the original hardware had, large
+-- enough for what is already loaded.
+INSERT INTO silos (id, patient_id, silo_index, capacity, updated_at)
+SELECT
+  'silo_' || p.id || '_' || i.silo_index,
+  p.id,
+  i.silo_index,
+  MAX(60, COALESCE((
+    SELECT m.stock_count FROM medications m
+    WHERE m.patient_id = p.id AND m.cartridge_index = i.silo_index
+  ), 0)),
+  strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM patients p
+CROSS JOIN (SELECT 0 AS silo_index UNION ALL SELECT 1 UNION ALL SELECT 2) i;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- cartridge_index keeps its relaxed CHECK; restoring the 0-2 limit would
+-- fail for larger devices.
+DROP TABLE IF EXISTS silos;
+ALTER TABLE patients DROP COLUMN silo_count;
+DROP INDEX IF EXISTS idx_medications_patient_silo;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Reference drug data loaded from a local dataset such as an RxNorm extract;
+-- id is the dataset's own identifier (an RxCUI for RxNorm).
+CREATE TABLE IF NOT EXISTS medication_catalog (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  ingredient TEXT NOT NULL,
+  strength TEXT,
+  unit TEXT,
+  dosage_form TEXT,
+  updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_medication_catalog_name
+  ON medication_catalog (name COLLATE NOCASE);
+
+ALTER TABLE medications ADD COLUMN catalog_id TEXT REFERENCES medication_catalog (id) ON DELETE SET NULL;
+
+-- Off by default: reminders keep using the anonymized label unless the
+-- caregiver opts in to drug names.
+ALTER TABLE patients ADD COLUMN show_medication_names INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE patients DROP COLUMN show_medication_names;
+ALTER TABLE medications DROP COLUMN catalog_id;
+DROP INDEX IF EXISTS idx_medication_catalog_name;
+DROP TABLE IF EXISTS medication_catalog;
+
+-- +goose StatementEnd
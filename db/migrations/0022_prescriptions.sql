@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The prescription behind a medication. refills_remaining counts fills left
+-- after the current one and goes down with each refillMedication.
+CREATE TABLE IF NOT EXISTS prescriptions (
+  id TEXT PRIMARY KEY,
+  medication_id TEXT NOT NULL,
+  prescriber_name TEXT NOT NULL,
+  prescriber_phone TEXT,
+  pharmacy_name TEXT,
+  pharmacy_phone TEXT,
+  -- Directions as written, e.g. "Take 1 tablet by mouth twice daily"
+  sig TEXT,
+  quantity_per_fill INTEGER NOT NULL CHECK (quantity_per_fill > 0),
+  refills_remaining INTEGER NOT NULL CHECK (refills_remaining >= 0),
+  -- YYYY-MM-DD
+  written_on TEXT NOT NULL,
+  expires_on TEXT,
+  active INTEGER NOT NULL DEFAULT 1,
+  -- Set when the caregiver is warned; cleared when the prescription is edited
+  expiry_alerted_at TEXT,
+  no_refills_alerted_at TEXT,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  FOREIGN KEY (medication_id) REFERENCES medications (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_prescriptions_medication
+  ON prescriptions (medication_id, active);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_prescriptions_medication;
+DROP TABLE IF EXISTS prescriptions;
+
+-- +goose StatementEnd
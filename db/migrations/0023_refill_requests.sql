@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Pharmacies that refill requests are sent to. Every configured channel is
+-- used: email and fax_email go out through SMTP (fax_email is the pharmacy's
+-- fax-to-email gateway address), webhook_url receives a signed JSON POST.
+CREATE TABLE IF NOT EXISTS pharmacies (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  phone TEXT,
+  email TEXT,
+  fax_email TEXT,
+  webhook_url TEXT,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
+
+-- The pharmacy's own prescription number, quoted on refill requests.
+ALTER TABLE prescriptions ADD COLUMN rx_number TEXT;
+ALTER TABLE prescriptions ADD COLUMN pharmacy_id TEXT REFERENCES pharmacies (id) ON DELETE SET NULL;
+
+-- rx_number and pharmacy_id are copied from the prescription when the
+-- request is made so the record keeps what was actually sent.
+CREATE TABLE IF NOT EXISTS refill_requests (
+  id TEXT PRIMARY KEY,
+  patient_id TEXT NOT NULL,
+  medication_id TEXT NOT NULL,
+  prescription_id TEXT,
+  pharmacy_id TEXT,
+  rx_number TEXT,
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  status TEXT NOT NULL DEFAULT 'REQUESTED'
+    CHECK (status IN ('REQUESTED', 'READY', 'PICKED_UP', 'CANCELLED')),
+  -- MANUAL from requestRefill, FORECAST from the notification worker
+  source TEXT NOT NULL CHECK (source IN ('MANUAL', 'FORECAST')),
+  requested_at TEXT NOT NULL,
+  ready_at TEXT,
+  picked_up_at TEXT,
+  cancelled_at TEXT,
+  updated_at TEXT NOT NULL,
+  FOREIGN KEY (patient_id) REFERENCES patients (id) ON DELETE CASCADE,
+  FOREIGN KEY (medication_id) REFERENCES medications (id) ON DELETE CASCADE,
+  FOREIGN KEY (prescription_id) REFERENCES prescriptions (id) ON DELETE SET NULL,
+  FOREIGN KEY (pharmacy_id) REFERENCES pharmacies (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refill_requests_patient
+  ON refill_requests (patient_id, requested_at);
+
+-- At most one open request per medication.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refill_requests_open
+  ON refill_requests (medication_id)
+  WHERE status IN ('REQUESTED', 'READY');
+
+-- One row per channel a request was sent through.
+CREATE TABLE IF NOT EXISTS refill_request_deliveries (
+  id TEXT PRIMARY KEY,
+  refill_request_id TEXT NOT NULL,
+  channel TEXT NOT NULL CHECK (channel IN ('EMAIL', 'FAX', 'WEBHOOK')),
+  destination TEXT NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('SENT', 'FAILED')),
+  error TEXT,
+  attempted_at TEXT NOT NULL,
+  FOREIGN KEY (refill_request_id) REFERENCES refill_requests (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refill_request_deliveries_request
+  ON refill_request_deliveries (refill_request_id);
+
+-- Off by default: the worker only contacts the pharmacy for patients whose
+-- caregiver opted in.
+ALTER TABLE patients ADD COLUMN auto_refill_requests INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE patients DROP COLUMN auto_refill_requests;
+DROP INDEX IF EXISTS idx_refill_request_deliveries_request;
+DROP TABLE IF EXISTS refill_request_deliveries;
+DROP INDEX IF EXISTS idx_refill_requests_open;
+DROP INDEX IF EXISTS idx_refill_requests_patient;
+DROP TABLE IF EXISTS refill_requests;
+ALTER TABLE prescriptions DROP COLUMN pharmacy_id;
+ALTER TABLE prescriptions DROP COLUMN rx_number;
+DROP TABLE IF EXISTS pharmacies;
+
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Inbound SMS are matched to users by exact E.164 comparison, so bring
+-- existing numbers into that form: drop separators, then add the +1 country
+-- code to national numbers. Numbers that still aren't E.164 are left as they
+-- are and won't match until they are edited.
+UPDATE users
+SET phone = replace(replace(replace(replace(replace(trim(phone), ' ', ''), '-', ''), '.', ''), '(', ''), ')', '')
+WHERE phone IS NOT NULL;
+
+UPDATE users
+SET phone = '+' || substr(phone, 3)
+WHERE phone LIKE '00%';
+
+UPDATE users
+SET phone = '+1' || phone
+WHERE length(phone) = 10 AND phone NOT LIKE '+%';
+
+UPDATE users
+SET phone = '+' || phone
+WHERE length(phone) = 11 AND phone LIKE '1%';
+
+UPDATE users
+SET phone = NULL
+WHERE phone = '';
+
+CREATE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_users_phone;
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The request a key was first used with. A replay is compared against these
+-- rather than the event, which SMS replies and later updates may change.
+ALTER TABLE dispense_idempotency_keys ADD COLUMN schedule_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE dispense_idempotency_keys ADD COLUMN due_at_iso TEXT NOT NULL DEFAULT '';
+ALTER TABLE dispense_idempotency_keys ADD COLUMN status TEXT NOT NULL DEFAULT '';
+
+-- Existing keys only have the event to go on.
+UPDATE dispense_idempotency_keys
+SET
+  schedule_id = (SELECT de.schedule_id FROM dispense_events de WHERE de.id = dispense_event_id),
+  due_at_iso = (SELECT de.due_at_iso FROM dispense_events de WHERE de.id = dispense_event_id),
+  status = (SELECT de.status FROM dispense_events de WHERE de.id = dispense_event_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE dispense_idempotency_keys DROP COLUMN status;
+ALTER TABLE dispense_idempotency_keys DROP COLUMN due_at_iso;
+ALTER TABLE dispense_idempotency_keys DROP COLUMN schedule_id;
+
+-- +goose StatementEnd
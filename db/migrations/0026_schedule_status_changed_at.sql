@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- When the schedule last changed status. Adherence counts a paused or
+-- archived schedule's unanswered doses up to this point.
+ALTER TABLE schedules ADD COLUMN status_changed_at TEXT;
+
+UPDATE schedules
+SET status_changed_at = updated_at
+WHERE status <> 'ACTIVE';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE schedules DROP COLUMN status_changed_at;
+
+-- +goose StatementEnd
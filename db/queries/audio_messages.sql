@@ -0,0 +1,132 @@
+-- name: CreateAudioMessage :one
+INSERT INTO audio_messages (
+  id,
+  patient_id,
+  schedule_id,
+  due_at_iso,
+  file_path,
+  mime_type,
+  size_bytes,
+  expires_at,
+  sha256,
+  duration_ms,
+  sample_rate_hz
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAudioMessage :one
+SELECT * FROM audio_messages
+WHERE id = ?
+  AND patient_id = ?;
+
+-- name: GetOldestPendingAudioMessage :one
+SELECT * FROM audio_messages am
+WHERE am.patient_id = ?
+  AND am.acked_at IS NULL
+  AND am.expired_at IS NULL
+  AND am.expires_at > ?
+  AND NOT EXISTS (
+    SELECT 1 FROM dispense_events de
+    WHERE de.patient_id = am.patient_id
+      AND de.schedule_id = am.schedule_id
+      AND de.due_at_iso = am.due_at_iso
+      AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+  )
+ORDER BY am.due_at_iso ASC, am.created_at ASC
+LIMIT 1;
+
+-- name: GetLatestPendingAudioMessage :one
+SELECT * FROM audio_messages am
+WHERE am.patient_id = ?
+  AND am.acked_at IS NULL
+  AND am.expired_at IS NULL
+  AND am.expires_at > ?
+  AND NOT EXISTS (
+    SELECT 1 FROM dispense_events de
+    WHERE de.patient_id = am.patient_id
+      AND de.schedule_id = am.schedule_id
+      AND de.due_at_iso = am.due_at_iso
+      AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+  )
+ORDER BY am.due_at_iso DESC, am.created_at DESC
+LIMIT 1;
+
+-- name: MarkAudioMessageDelivered :exec
+UPDATE audio_messages
+SET delivered_at = COALESCE(delivered_at, ?)
+WHERE id = ?;
+
+-- name: MarkAudioMessageAcked :exec
+UPDATE audio_messages
+SET acked_at = ?
+WHERE id = ?;
+
+-- name: ExpireSupersededAudioMessages :exec
+UPDATE audio_messages
+SET expired_at = ?,
+    expiry_reason = 'SUPERSEDED'
+WHERE patient_id = ?
+  AND due_at_iso < ?
+  AND acked_at IS NULL
+  AND expired_at IS NULL;
+
+-- name: ExpireResolvedAudioMessages :execrows
+UPDATE audio_messages
+SET expired_at = sqlc.arg('now'),
+    expiry_reason = (
+      SELECT de.status FROM dispense_events de
+      WHERE de.patient_id = audio_messages.patient_id
+        AND de.schedule_id = audio_messages.schedule_id
+        AND de.due_at_iso = audio_messages.due_at_iso
+        AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+      LIMIT 1
+    )
+WHERE acked_at IS NULL
+  AND expired_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM dispense_events de
+    WHERE de.patient_id = audio_messages.patient_id
+      AND de.schedule_id = audio_messages.schedule_id
+      AND de.due_at_iso = audio_messages.due_at_iso
+      AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+  );
+
+-- name: ExpireStaleAudioMessages :execrows
+UPDATE audio_messages
+SET expired_at = sqlc.arg('now'),
+    expiry_reason = 'AGE'
+WHERE acked_at IS NULL
+  AND expired_at IS NULL
+  AND expires_at <= sqlc.arg('now');
+
+-- name: ListPurgeableAudioMessages :many
+SELECT * FROM audio_messages
+WHERE purged_at IS NULL
+  AND (acked_at IS NOT NULL OR expired_at IS NOT NULL);
+
+-- name: MarkAudioMessagePurged :exec
+UPDATE audio_messages
+SET purged_at = ?
+WHERE id = ?;
+
+-- name: ListLiveAudioMessagePaths :many
+SELECT file_path FROM audio_messages
+WHERE purged_at IS NULL
+UNION ALL
+SELECT ame.file_path FROM audio_message_encodings ame
+JOIN audio_messages am ON am.id = ame.message_id
+WHERE am.purged_at IS NULL;
+
+-- name: CreateAudioMessageEncoding :exec
+INSERT INTO audio_message_encodings (message_id, mime_type, file_path, size_bytes, sha256)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (message_id, mime_type) DO UPDATE SET
+  file_path = excluded.file_path,
+  size_bytes = excluded.size_bytes,
+  sha256 = excluded.sha256;
+
+-- name: ListAudioMessageEncodings :many
+SELECT * FROM audio_message_encodings
+WHERE message_id = ?
+ORDER BY created_at, mime_type;
@@ -27,3 +27,21 @@ SET
   action_source = ?
 WHERE id = ?
 RETURNING id, patient_id, schedule_id, due_at_iso, acted_at_iso, status, action_source, created_at;
+
+-- name: GetDispenseEventByOccurrence :one
+SELECT id, patient_id, schedule_id, due_at_iso, acted_at_iso, status, action_source, created_at
+FROM dispense_events
+WHERE patient_id = ?
+  AND schedule_id = ?
+  AND due_at_iso = ?
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetDispenseIdempotencyKey :one
+SELECT * FROM dispense_idempotency_keys
+WHERE patient_id = ?
+  AND idempotency_key = ?;
+
+-- name: CreateDispenseIdempotencyKey :exec
+INSERT INTO dispense_idempotency_keys (patient_id, idempotency_key, dispense_event_id, schedule_id, due_at_iso, status)
+VALUES (?, ?, ?, ?, ?, ?);
@@ -0,0 +1,28 @@
+-- name: UpsertCatalogEntry :exec
+INSERT INTO medication_catalog (id, name, ingredient, strength, unit, dosage_form, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+  name = excluded.name,
+  ingredient = excluded.ingredient,
+  strength = excluded.strength,
+  unit = excluded.unit,
+  dosage_form = excluded.dosage_form,
+  updated_at = excluded.updated_at;
+
+-- name: GetCatalogEntry :one
+SELECT * FROM medication_catalog
+WHERE id = ?;
+
+-- name: SearchCatalog :many
+SELECT * FROM medication_catalog
+WHERE instr(lower(name), lower(sqlc.arg('query'))) > 0
+   OR instr(lower(ingredient), lower(sqlc.arg('query'))) > 0
+ORDER BY name COLLATE NOCASE
+LIMIT sqlc.arg('limit');
+
+-- name: ListMedicationIngredientsByPatient :many
+SELECT sqlc.embed(m), mc.ingredient
+FROM medications m
+JOIN medication_catalog mc ON mc.id = m.catalog_id
+WHERE m.patient_id = ?
+ORDER BY m.cartridge_index;
@@ -0,0 +1,73 @@
+-- name: CreateMedicationLot :one
+INSERT INTO medication_lots (
+  id,
+  medication_id,
+  lot_number,
+  quantity_loaded,
+  quantity_remaining,
+  expires_on,
+  silo,
+  loaded_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetMedicationLot :one
+SELECT * FROM medication_lots
+WHERE id = ?;
+
+-- name: ListMedicationLots :many
+SELECT * FROM medication_lots
+WHERE medication_id = sqlc.arg('medication_id')
+  AND (CAST(sqlc.arg('include_depleted') AS BOOLEAN) OR quantity_remaining > 0)
+ORDER BY loaded_at, rowid;
+
+-- name: ListAvailableLotsFIFO :many
+SELECT * FROM medication_lots
+WHERE medication_id = ?
+  AND quantity_remaining > 0
+ORDER BY loaded_at, rowid;
+
+-- name: ConsumeMedicationLot :exec
+UPDATE medication_lots
+SET quantity_remaining = quantity_remaining - ?
+WHERE id = ?;
+
+-- name: CreateStockMovementLot :exec
+INSERT INTO stock_movement_lots (stock_movement_id, lot_id, quantity)
+VALUES (?, ?, ?);
+
+-- name: ListLotConsumptionsByDispenseEvent :many
+SELECT
+  sml.stock_movement_id,
+  sml.quantity,
+  sqlc.embed(l)
+FROM stock_movement_lots sml
+JOIN stock_movements sm ON sm.id = sml.stock_movement_id
+JOIN medication_lots l ON l.id = sml.lot_id
+WHERE sm.dispense_event_id = ?
+ORDER BY sm.created_at, l.loaded_at;
+
+-- name: ListDispenseEventsByLot :many
+SELECT DISTINCT de.id, de.patient_id, de.schedule_id, de.due_at_iso, de.acted_at_iso, de.status, de.action_source, de.created_at
+FROM stock_movement_lots sml
+JOIN stock_movements sm ON sm.id = sml.stock_movement_id
+JOIN dispense_events de ON de.id = sm.dispense_event_id
+WHERE sml.lot_id = ?
+ORDER BY de.due_at_iso DESC;
+
+-- name: ListExpiringLotsByPatient :many
+SELECT l.*
+FROM medication_lots l
+JOIN medications m ON m.id = l.medication_id
+WHERE m.patient_id = sqlc.arg('patient_id')
+  AND l.quantity_remaining > 0
+  AND l.expires_on IS NOT NULL
+  AND l.expires_on <= sqlc.arg('expires_before')
+  AND l.expiry_alerted_at IS NULL
+ORDER BY l.expires_on;
+
+-- name: MarkLotExpiryAlerted :exec
+UPDATE medication_lots
+SET expiry_alerted_at = ?
+WHERE id = ?;
@@ -28,3 +28,52 @@ RETURNING *;
 -- name: DeleteMedication :exec
 DELETE FROM medications
 WHERE id = ?;
+
+-- name: AdjustMedicationStock :one
+UPDATE medications
+SET
+  stock_count = stock_count + ?,
+  updated_at = datetime('now')
+WHERE id = ?
+RETURNING *;
+
+-- name: MarkLowStockAlerted :exec
+UPDATE medications
+SET low_stock_alerted_at = ?
+WHERE id = ?;
+
+-- name: ClearStockAlerts :exec
+UPDATE medications
+SET low_stock_alerted_at = NULL,
+    runout_alerted_at = NULL
+WHERE id = ?;
+
+-- name: MarkRunoutAlerted :exec
+UPDATE medications
+SET runout_alerted_at = ?
+WHERE id = ?;
+
+-- name: GetMedicationInSilo :one
+SELECT * FROM medications
+WHERE patient_id = ? AND cartridge_index = ?;
+
+-- name: ListMedicationsFromSilo :many
+SELECT * FROM medications
+WHERE patient_id = ? AND cartridge_index >= ?
+ORDER BY cartridge_index;
+
+-- name: SetMedicationCatalogEntry :one
+UPDATE medications
+SET
+  catalog_id = ?,
+  updated_at = datetime('now')
+WHERE id = ?
+RETURNING *;
+
+-- name: SetMedicationSilo :one
+UPDATE medications
+SET
+  cartridge_index = ?,
+  updated_at = datetime('now')
+WHERE id = ?
+RETURNING *;
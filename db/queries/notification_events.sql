@@ -10,9 +10,10 @@ INSERT INTO notification_events (
   message,
   status,
   provider_message_id,
-  error_message
+  error_message,
+  delivery_status
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING
   id,
   patient_id,
@@ -25,7 +26,10 @@ RETURNING
   status,
   provider_message_id,
   error_message,
-  created_at;
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at;
 
 -- name: GetNotificationEventByOccurrence :one
 SELECT
@@ -40,7 +44,10 @@ SELECT
   status,
   provider_message_id,
   error_message,
-  created_at
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
 FROM notification_events
 WHERE patient_id = ?
   AND schedule_id = ?
@@ -60,7 +67,98 @@ SELECT
   status,
   provider_message_id,
   error_message,
-  created_at
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
 FROM notification_events
 WHERE patient_id = ?
-ORDER BY created_at DESC;
\ No newline at end of file
+ORDER BY created_at DESC;
+
+-- name: ListSentRemindersByUserSince :many
+SELECT
+  id,
+  patient_id,
+  schedule_id,
+  user_id,
+  due_at_iso,
+  channel,
+  destination,
+  message,
+  status,
+  provider_message_id,
+  error_message,
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
+FROM notification_events
+WHERE user_id = ?
+  AND channel = 'SMS'
+  AND status = 'SENT'
+  AND due_at_iso >= ?
+ORDER BY due_at_iso DESC;
+
+-- name: GetNotificationEventByProviderMessageID :one
+SELECT
+  id,
+  patient_id,
+  schedule_id,
+  user_id,
+  due_at_iso,
+  channel,
+  destination,
+  message,
+  status,
+  provider_message_id,
+  error_message,
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
+FROM notification_events
+WHERE provider_message_id = ?
+LIMIT 1;
+
+-- name: UpdateNotificationDeliveryStatus :exec
+UPDATE notification_events
+SET delivery_status = ?,
+    error_code = ?,
+    error_message = COALESCE(?, error_message),
+    delivery_updated_at = ?
+WHERE id = ?;
+
+-- name: ListNotificationEventsPage :many
+SELECT
+  id,
+  patient_id,
+  schedule_id,
+  user_id,
+  due_at_iso,
+  channel,
+  destination,
+  message,
+  status,
+  provider_message_id,
+  error_message,
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
+FROM notification_events
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (CAST(sqlc.narg('start') AS TEXT) IS NULL OR due_at_iso >= sqlc.narg('start'))
+  AND (CAST(sqlc.narg('end') AS TEXT) IS NULL OR due_at_iso < sqlc.narg('end'))
+  AND (CAST(sqlc.narg('channel') AS TEXT) IS NULL OR channel = sqlc.narg('channel'))
+  AND (CAST(sqlc.narg('status') AS TEXT) IS NULL OR status = sqlc.narg('status'))
+ORDER BY due_at_iso DESC, created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountNotificationEvents :one
+SELECT COUNT(*)
+FROM notification_events
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (CAST(sqlc.narg('start') AS TEXT) IS NULL OR due_at_iso >= sqlc.narg('start'))
+  AND (CAST(sqlc.narg('end') AS TEXT) IS NULL OR due_at_iso < sqlc.narg('end'))
+  AND (CAST(sqlc.narg('channel') AS TEXT) IS NULL OR channel = sqlc.narg('channel'))
+  AND (CAST(sqlc.narg('status') AS TEXT) IS NULL OR status = sqlc.narg('status'));
@@ -0,0 +1,59 @@
+-- name: EnqueueNotification :one
+INSERT INTO notification_outbox (
+  id,
+  user_id,
+  patient_id,
+  notification_type,
+  channel,
+  destination,
+  message,
+  digest,
+  deliver_after
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListDueOutboxNotifications :many
+SELECT * FROM notification_outbox
+WHERE status = 'QUEUED'
+  AND deliver_after <= ?
+ORDER BY user_id, channel, destination, created_at;
+
+-- name: MarkOutboxNotificationSent :exec
+UPDATE notification_outbox
+SET
+  status = 'SENT',
+  provider_message_id = ?,
+  error_message = NULL,
+  sent_at = datetime('now')
+WHERE id = ?;
+
+-- name: MarkOutboxNotificationFailed :exec
+UPDATE notification_outbox
+SET
+  status = 'FAILED',
+  error_message = ?
+WHERE id = ?;
+
+-- name: CancelOutboxNotification :exec
+UPDATE notification_outbox
+SET status = 'CANCELLED'
+WHERE id = ?;
+
+-- name: CancelQueuedOutboxNotifications :exec
+UPDATE notification_outbox
+SET status = 'CANCELLED'
+WHERE user_id = ?
+  AND patient_id = ?
+  AND notification_type = ?
+  AND status = 'QUEUED';
+
+-- name: GetOutboxNotification :one
+SELECT * FROM notification_outbox
+WHERE id = ?;
+
+-- name: ClaimOutboxNotification :execrows
+UPDATE notification_outbox
+SET status = 'SENDING'
+WHERE id = ?
+  AND status = 'QUEUED';
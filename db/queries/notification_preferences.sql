@@ -0,0 +1,33 @@
+-- name: ListNotificationPreferencesByUser :many
+SELECT * FROM notification_preferences
+WHERE user_id = ?
+ORDER BY notification_type, channel;
+
+-- name: GetNotificationPreference :one
+SELECT * FROM notification_preferences
+WHERE user_id = ?
+  AND notification_type = ?
+  AND channel = ?;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (
+  id,
+  user_id,
+  notification_type,
+  channel,
+  enabled,
+  quiet_hours_start,
+  quiet_hours_end,
+  delivery_mode,
+  digest_time
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, notification_type, channel) DO UPDATE SET
+  enabled = excluded.enabled,
+  quiet_hours_start = excluded.quiet_hours_start,
+  quiet_hours_end = excluded.quiet_hours_end,
+  delivery_mode = excluded.delivery_mode,
+  digest_time = excluded.digest_time,
+  updated_at = datetime('now')
+RETURNING *;
+
@@ -0,0 +1,20 @@
+-- name: GetPatientVoiceSettings :one
+SELECT * FROM patient_voice_settings
+WHERE patient_id = ?;
+
+-- name: UpsertPatientVoiceSettings :one
+INSERT INTO patient_voice_settings (
+  patient_id,
+  voice_name,
+  language_code,
+  speaking_rate,
+  sample_rate_hz
+)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (patient_id) DO UPDATE SET
+  voice_name = excluded.voice_name,
+  language_code = excluded.language_code,
+  speaking_rate = excluded.speaking_rate,
+  sample_rate_hz = excluded.sample_rate_hz,
+  updated_at = datetime('now')
+RETURNING *;
@@ -1,23 +1,23 @@
 -- name: ListPatients :many
-SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at
+SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 FROM patients
 ORDER BY created_at DESC;
 
 -- name: ListPatientsByUser :many
-SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at
+SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 FROM patients
 WHERE user_id = ?
 ORDER BY created_at DESC;
 
 -- name: GetPatient :one
-SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at
+SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 FROM patients
 WHERE id = ?;
 
 -- name: CreatePatient :one
-INSERT INTO patients (id, user_id, first_name, last_name, timezone)
-VALUES (?, ?, ?, ?, ?)
-RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at;
+INSERT INTO patients (id, user_id, first_name, last_name, timezone, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests;
 
 -- name: UpdatePatient :one
 UPDATE patients
@@ -26,6 +26,11 @@ SET
   first_name = ?,
   last_name = ?,
   timezone = ?,
+  locale = ?,
+  pharmacy_lead_time_days = ?,
+  silo_count = ?,
+  show_medication_names = ?,
+  auto_refill_requests = ?,
   updated_at = datetime('now')
 WHERE id = ?
-RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at;
+RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests;
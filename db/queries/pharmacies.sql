@@ -0,0 +1,28 @@
+-- name: ListPharmacies :many
+SELECT * FROM pharmacies
+ORDER BY name COLLATE NOCASE;
+
+-- name: GetPharmacy :one
+SELECT * FROM pharmacies
+WHERE id = ?;
+
+-- name: CreatePharmacy :one
+INSERT INTO pharmacies (id, name, phone, email, fax_email, webhook_url, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdatePharmacy :one
+UPDATE pharmacies
+SET
+  name = ?,
+  phone = ?,
+  email = ?,
+  fax_email = ?,
+  webhook_url = ?,
+  updated_at = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: DeletePharmacy :exec
+DELETE FROM pharmacies
+WHERE id = ?;
@@ -0,0 +1,92 @@
+-- name: ListPrescriptionsByMedication :many
+SELECT * FROM prescriptions
+WHERE medication_id = ?
+ORDER BY active DESC, written_on DESC;
+
+-- name: GetPrescription :one
+SELECT * FROM prescriptions
+WHERE id = ?;
+
+-- name: CreatePrescription :one
+INSERT INTO prescriptions (
+  id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone,
+  sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, rx_number, pharmacy_id,
+  created_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdatePrescription :one
+-- The renewal alerts re-arm only when what they warned about changes: a new
+-- expiry date, or refills added to a prescription that had none left.
+UPDATE prescriptions
+SET
+  prescriber_name = sqlc.arg(prescriber_name),
+  prescriber_phone = sqlc.arg(prescriber_phone),
+  pharmacy_name = sqlc.arg(pharmacy_name),
+  pharmacy_phone = sqlc.arg(pharmacy_phone),
+  sig = sqlc.arg(sig),
+  quantity_per_fill = sqlc.arg(quantity_per_fill),
+  refills_remaining = sqlc.arg(refills_remaining),
+  written_on = sqlc.arg(written_on),
+  expires_on = sqlc.arg(expires_on),
+  active = sqlc.arg(active),
+  rx_number = sqlc.arg(rx_number),
+  pharmacy_id = sqlc.arg(pharmacy_id),
+  expiry_alerted_at = CASE
+    WHEN expires_on IS sqlc.arg(expires_on) THEN expiry_alerted_at
+    ELSE NULL
+  END,
+  no_refills_alerted_at = CASE
+    WHEN refills_remaining <= 0 AND sqlc.arg(refills_remaining) > 0 THEN NULL
+    ELSE no_refills_alerted_at
+  END,
+  updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeletePrescription :exec
+DELETE FROM prescriptions
+WHERE id = ?;
+
+-- name: GetRefillablePrescription :one
+-- The active, unexpired prescription with refills left that expires first.
+SELECT * FROM prescriptions
+WHERE medication_id = sqlc.arg('medication_id')
+  AND active = 1
+  AND refills_remaining > 0
+  AND (expires_on IS NULL OR expires_on >= sqlc.arg('today'))
+ORDER BY expires_on IS NULL, expires_on, written_on
+LIMIT 1;
+
+-- name: UsePrescriptionRefill :one
+UPDATE prescriptions
+SET
+  refills_remaining = refills_remaining - 1,
+  updated_at = ?
+WHERE id = ? AND refills_remaining > 0
+RETURNING *;
+
+-- name: ListPrescriptionsNeedingRenewal :many
+-- Active prescriptions of the patient's medications that expire by the
+-- cutoff or have no refills left, and haven't been alerted about yet.
+SELECT sqlc.embed(p), m.label AS medication_label, m.cartridge_index AS medication_cartridge_index
+FROM prescriptions p
+JOIN medications m ON m.id = p.medication_id
+WHERE m.patient_id = sqlc.arg('patient_id')
+  AND p.active = 1
+  AND (
+    (p.expiry_alerted_at IS NULL AND p.expires_on IS NOT NULL AND p.expires_on <= sqlc.arg('expires_before'))
+    OR (p.no_refills_alerted_at IS NULL AND p.refills_remaining = 0)
+  )
+ORDER BY p.expires_on;
+
+-- name: MarkPrescriptionExpiryAlerted :exec
+UPDATE prescriptions
+SET expiry_alerted_at = ?
+WHERE id = ?;
+
+-- name: MarkPrescriptionNoRefillsAlerted :exec
+UPDATE prescriptions
+SET no_refills_alerted_at = ?
+WHERE id = ?;
@@ -0,0 +1,43 @@
+-- name: ListRefillRequestsByPatient :many
+SELECT * FROM refill_requests
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (CAST(sqlc.narg('status') AS TEXT) IS NULL OR status = sqlc.narg('status'))
+ORDER BY requested_at DESC;
+
+-- name: GetRefillRequest :one
+SELECT * FROM refill_requests
+WHERE id = ?;
+
+-- name: GetOpenRefillRequest :one
+SELECT * FROM refill_requests
+WHERE medication_id = ? AND status IN ('REQUESTED', 'READY');
+
+-- name: CreateRefillRequest :one
+INSERT INTO refill_requests (
+  id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number,
+  quantity, status, source, requested_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, 'REQUESTED', ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateRefillRequestStatus :one
+-- Stamps the time of the status being entered; earlier stamps are kept.
+UPDATE refill_requests
+SET
+  status = sqlc.arg('status'),
+  ready_at = CASE WHEN sqlc.arg('status') = 'READY' THEN sqlc.arg('updated_at') ELSE ready_at END,
+  picked_up_at = CASE WHEN sqlc.arg('status') = 'PICKED_UP' THEN sqlc.arg('updated_at') ELSE picked_up_at END,
+  cancelled_at = CASE WHEN sqlc.arg('status') = 'CANCELLED' THEN sqlc.arg('updated_at') ELSE cancelled_at END,
+  updated_at = sqlc.arg('updated_at')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: CreateRefillRequestDelivery :one
+INSERT INTO refill_request_deliveries (id, refill_request_id, channel, destination, status, error, attempted_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListRefillRequestDeliveries :many
+SELECT * FROM refill_request_deliveries
+WHERE refill_request_id = ?
+ORDER BY attempted_at, channel;
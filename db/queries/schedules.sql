@@ -1,40 +1,42 @@
 -- name: ListSchedulesByPatient :many
-SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 FROM schedules
 WHERE patient_id = ?
 ORDER BY created_at DESC;
 
 -- name: GetSchedule :one
-SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 FROM schedules
 WHERE id = ?;
 
 -- name: CreateSchedule :one
 INSERT INTO schedules (id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status)
 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at;
+RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at;
 
 -- name: UpdateSchedule :one
 UPDATE schedules
 SET
-  title = ?,
-  timezone = ?,
-  rrule = ?,
-  start_date_iso = ?,
-  end_date_iso = ?,
-  lockout_minutes = ?,
-  status = ?,
+  title = sqlc.arg(title),
+  timezone = sqlc.arg(timezone),
+  rrule = sqlc.arg(rrule),
+  start_date_iso = sqlc.arg(start_date_iso),
+  end_date_iso = sqlc.arg(end_date_iso),
+  lockout_minutes = sqlc.arg(lockout_minutes),
+  status_changed_at = CASE WHEN status = sqlc.arg(status) THEN status_changed_at ELSE datetime('now') END,
+  status = sqlc.arg(status),
   updated_at = datetime('now')
-WHERE id = ?
-RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at;
+WHERE id = sqlc.arg(id)
+RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at;
 
 -- name: ArchiveSchedule :one
 UPDATE schedules
 SET
+  status_changed_at = CASE WHEN status = 'ARCHIVED' THEN status_changed_at ELSE datetime('now') END,
   status = 'ARCHIVED',
   updated_at = datetime('now')
 WHERE id = ?
-RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at;
+RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at;
 
 -- name: DeleteScheduleItemsBySchedule :exec
 DELETE FROM schedule_items
@@ -60,8 +62,11 @@ SELECT
   m.cartridge_index AS medication_cartridge_index,
   m.max_daily_dose AS medication_max_daily_dose,
   m.created_at AS medication_created_at,
-  m.updated_at AS medication_updated_at
+  m.updated_at AS medication_updated_at,
+  m.catalog_id AS medication_catalog_id,
+  mc.name AS medication_catalog_name
 FROM schedule_items si
 JOIN medications m ON m.id = si.medication_id
+LEFT JOIN medication_catalog mc ON mc.id = m.catalog_id
 WHERE si.schedule_id = ?
 ORDER BY m.cartridge_index;
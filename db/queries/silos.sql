@@ -0,0 +1,31 @@
+-- name: ListSilosByPatient :many
+SELECT * FROM silos
+WHERE patient_id = ?
+ORDER BY silo_index;
+
+-- name: GetSilo :one
+SELECT * FROM silos
+WHERE patient_id = ? AND silo_index = ?;
+
+-- name: CreateSilo :exec
+INSERT INTO silos (id, patient_id, silo_index, capacity, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (patient_id, silo_index) DO NOTHING;
+
+-- name: UpdateSilo :one
+UPDATE silos
+SET
+  capacity = ?,
+  pill_size_mm = ?,
+  updated_at = ?
+WHERE patient_id = ? AND silo_index = ?
+RETURNING *;
+
+-- name: DeleteSilosFrom :exec
+DELETE FROM silos
+WHERE patient_id = ? AND silo_index >= ?;
+
+-- name: MarkSiloCalibrated :exec
+UPDATE silos
+SET calibrated_at = ?
+WHERE patient_id = ? AND silo_index = ?;
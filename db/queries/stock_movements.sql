@@ -0,0 +1,46 @@
+-- name: CreateStockMovement :one
+INSERT INTO stock_movements (
+  id,
+  medication_id,
+  kind,
+  quantity,
+  balance_after,
+  actor,
+  dispense_event_id,
+  note,
+  created_at,
+  lot_number,
+  expires_on
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListStockMovementsByMedication :many
+SELECT * FROM stock_movements
+WHERE medication_id = sqlc.arg('medication_id')
+  AND (CAST(sqlc.narg('start') AS TEXT) IS NULL OR created_at >= sqlc.narg('start'))
+  AND (CAST(sqlc.narg('end') AS TEXT) IS NULL OR created_at < sqlc.narg('end'))
+ORDER BY created_at DESC, rowid DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SumStockMovements :one
+SELECT CAST(COALESCE(SUM(quantity), 0) AS INTEGER) AS total
+FROM stock_movements
+WHERE medication_id = ?;
+
+-- name: HasDispenseMovements :one
+SELECT EXISTS (
+  SELECT 1 FROM stock_movements
+  WHERE dispense_event_id = ? AND kind = 'DISPENSE'
+) AS dispensed;
+
+-- name: ListStockMovementsByPatient :many
+SELECT sqlc.embed(sm), m.label AS medication_label, mc.name AS medication_catalog_name
+FROM stock_movements sm
+JOIN medications m ON m.id = sm.medication_id
+LEFT JOIN medication_catalog mc ON mc.id = m.catalog_id
+WHERE m.patient_id = sqlc.arg('patient_id')
+  AND sm.kind = sqlc.arg('kind')
+  AND sm.created_at >= sqlc.arg('start')
+  AND sm.created_at < sqlc.arg('end')
+ORDER BY sm.created_at, sm.rowid;
@@ -0,0 +1,29 @@
+-- name: GetTTSCacheEntry :one
+SELECT * FROM tts_cache
+WHERE cache_key = ?;
+
+-- name: UpsertTTSCacheEntry :exec
+INSERT INTO tts_cache (cache_key, file_path, size_bytes, last_used_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (cache_key) DO UPDATE SET
+  file_path = excluded.file_path,
+  size_bytes = excluded.size_bytes,
+  last_used_at = excluded.last_used_at;
+
+-- name: TouchTTSCacheEntry :exec
+UPDATE tts_cache
+SET hit_count = hit_count + 1,
+    last_used_at = ?
+WHERE cache_key = ?;
+
+-- name: GetTTSCacheSize :one
+SELECT CAST(COALESCE(SUM(size_bytes), 0) AS INTEGER) AS total_bytes
+FROM tts_cache;
+
+-- name: ListTTSCacheEntriesByLastUsed :many
+SELECT * FROM tts_cache
+ORDER BY last_used_at ASC;
+
+-- name: DeleteTTSCacheEntry :exec
+DELETE FROM tts_cache
+WHERE cache_key = ?;
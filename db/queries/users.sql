@@ -6,7 +6,8 @@ SELECT
   phone,
   timezone,
   created_at,
-  updated_at
+  updated_at,
+  locale
 FROM users
 ORDER BY created_at DESC;
 
@@ -18,7 +19,8 @@ SELECT
   phone,
   timezone,
   created_at,
-  updated_at
+  updated_at,
+  locale
 FROM users
 WHERE id = ?;
 
@@ -31,7 +33,8 @@ SELECT
   timezone,
   password_hash,
   created_at,
-  updated_at
+  updated_at,
+  locale
 FROM users
 WHERE email = ?;
 
@@ -42,9 +45,10 @@ INSERT INTO users (
   full_name,
   phone,
   timezone,
-  password_hash
+  password_hash,
+  locale
 )
-VALUES (?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 RETURNING
   id,
   email,
@@ -53,7 +57,8 @@ RETURNING
   timezone,
   password_hash,
   created_at,
-  updated_at;
+  updated_at,
+  locale;
 
 -- name: UpdateUser :one
 UPDATE users
@@ -63,6 +68,7 @@ SET
   phone = ?,
   timezone = ?,
   password_hash = ?,
+  locale = ?,
   updated_at = datetime('now')
 WHERE id = ?
 RETURNING
@@ -73,5 +79,21 @@ RETURNING
   timezone,
   password_hash,
   created_at,
-  updated_at;
+  updated_at,
+  locale;
+
 
+-- name: GetUserByPhone :one
+SELECT
+  id,
+  email,
+  full_name,
+  phone,
+  timezone,
+  created_at,
+  updated_at,
+  locale
+FROM users
+WHERE phone = ?
+ORDER BY created_at DESC
+LIMIT 1;
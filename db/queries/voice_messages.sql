@@ -0,0 +1,40 @@
+-- name: CreateVoiceMessage :one
+INSERT INTO voice_messages (
+  id,
+  patient_id,
+  schedule_id,
+  due_at_iso,
+  file_path,
+  original_mime_type,
+  size_bytes,
+  sha256,
+  duration_ms,
+  sample_rate_hz
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetVoiceMessage :one
+SELECT * FROM voice_messages
+WHERE id = ?;
+
+-- name: ListVoiceMessagesByPatient :many
+SELECT * FROM voice_messages
+WHERE patient_id = ?
+  AND active = 1
+ORDER BY created_at DESC;
+
+-- name: GetVoiceMessageForOccurrence :one
+SELECT * FROM voice_messages
+WHERE patient_id = ?
+  AND schedule_id = ?
+  AND active = 1
+  AND (due_at_iso = sqlc.arg('due_at_iso') OR due_at_iso IS NULL)
+ORDER BY due_at_iso IS NULL, created_at DESC
+LIMIT 1;
+
+-- name: DeactivateVoiceMessage :exec
+UPDATE voice_messages
+SET active = 0,
+    updated_at = datetime('now')
+WHERE id = ?;
@@ -2,8 +2,13 @@
 
 import urequests
 import json
+import os
+from time import sleep
 from config import BACKEND_URL, PATIENT_ID
 
+RECORD_DISPENSE_ATTEMPTS = 3
+RECORD_DISPENSE_RETRY_SECONDS = 5
+
 # GRAPHQL QUERIES
 
 DUE_NOW_QUERY = """
@@ -99,8 +104,17 @@ def get_due_medications(window_minutes=1):
     return []
 
 
+def new_idempotency_key():
+    """Random UUID (version 4) identifying one dispense report."""
+    b = bytearray(os.urandom(16))
+    b[6] = (b[6] & 0x0F) | 0x40
+    b[8] = (b[8] & 0x3F) | 0x80
+    h = "".join("{:02x}".format(x) for x in b)
+    return "-".join((h[0:8], h[8:12], h[12:16], h[16:20], h[20:32]))
+
+
 def record_dispense(schedule_id, due_at_iso, acted_at_iso, status):
-    """Report dispense result to backend."""
+    """Report dispense result to backend, retrying if the request fails."""
     variables = {
         "input": {
             "patientId": PATIENT_ID,
@@ -108,14 +122,23 @@ def record_dispense(schedule_id, due_at_iso, acted_at_iso, status):
             "dueAtISO": due_at_iso,
             "actedAtISO": acted_at_iso,
             "status": status,
-            "actionSource": "device"
+            "actionSource": "device",
+            # New key for every report, reused only by its retries, so the
+            # backend records each report once even if a response is lost
+            "idempotencyKey": new_idempotency_key()
         }
     }
 
-    result = graphql_request(RECORD_DISPENSE_MUTATION, variables)
-    if result:
-        print(f"Recorded dispense: {status}")
-    return result
+    for attempt in range(RECORD_DISPENSE_ATTEMPTS):
+        result = graphql_request(RECORD_DISPENSE_MUTATION, variables)
+        if result:
+            print(f"Recorded dispense: {status}")
+            return result
+        if attempt + 1 < RECORD_DISPENSE_ATTEMPTS:
+            sleep(RECORD_DISPENSE_RETRY_SECONDS)
+
+    print(f"Failed to record dispense: {status}")
+    return None
 
 
 def get_pending_dispense():
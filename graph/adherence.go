@@ -0,0 +1,456 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/notifications"
+)
+
+const (
+	defaultOnTimeMinutes = 30
+	maxAdherenceRange    = 366 * 24 * time.Hour
+)
+
+// doseOutcome is one scheduled dose and what became of it. Status is TAKEN,
+// SKIPPED, MISSED or PENDING; Event is the dispense event it was matched to,
+// if any, and keeps the original status for device faults.
+type doseOutcome struct {
+	Schedule db.Schedule
+	Items    []db.ListScheduleItemsByScheduleRow
+	DueAt    time.Time
+	Status   model.DispenseStatus
+	ActedAt  *time.Time
+	Event    *db.DispenseEvent
+}
+
+// collectDoseOutcomes expands the patient's schedules over [start, end) up to
+// now and matches each due dose with its dispense event. Events are matched
+// to the nearest occurrence of their schedule within the lockout window, so
+// a dose recorded a little off its exact due time still counts. Doses come
+// back in due order.
+func (r *Resolver) collectDoseOutcomes(ctx context.Context, patient db.Patient, start, end, now time.Time) ([]doseOutcome, error) {
+	loc := notifications.PatientLocation(patient.Timezone)
+	until := end
+	if now.Before(until) {
+		until = now
+	}
+
+	schedules, err := r.Queries.ListSchedulesByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+
+	maxTolerance := time.Minute
+	for _, schedule := range schedules {
+		maxTolerance = max(maxTolerance, lockoutTolerance(schedule))
+	}
+	events, err := r.Queries.ListDispenseEventsByPatient(ctx, db.ListDispenseEventsByPatientParams{
+		PatientID:  patient.ID,
+		DueAtIso:   formatDBTime(start.Add(-maxTolerance)),
+		DueAtIso_2: formatDBTime(end.Add(maxTolerance)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list dispense events: %w", err)
+	}
+	eventsBySchedule := make(map[string][]db.DispenseEvent)
+	for _, event := range events {
+		eventsBySchedule[event.ScheduleID] = append(eventsBySchedule[event.ScheduleID], event)
+	}
+
+	var doses []doseOutcome
+	for _, schedule := range schedules {
+		if !start.Before(until) {
+			break
+		}
+		occurrences, err := notifications.ScheduleOccurrences(schedule, start, until, loc)
+		if err != nil {
+			return nil, fmt.Errorf("expand schedule %s: %w", schedule.ID, err)
+		}
+		// Between is inclusive; the range end is not.
+		for len(occurrences) > 0 && !occurrences[len(occurrences)-1].Before(end) {
+			occurrences = occurrences[:len(occurrences)-1]
+		}
+		if len(occurrences) == 0 {
+			continue
+		}
+
+		matched, err := matchDispenseEvents(occurrences, eventsBySchedule[schedule.ID], lockoutTolerance(schedule))
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := r.Queries.ListScheduleItemsBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list schedule items for %s: %w", schedule.ID, err)
+		}
+
+		// A paused or archived schedule stopped reminding when its status
+		// changed; unanswered doses after that are not misses.
+		inactiveSince, err := scheduleInactiveSince(schedule)
+		if err != nil {
+			return nil, err
+		}
+		for i, at := range occurrences {
+			event := matched[i]
+			if event == nil && inactiveSince != nil && !at.Before(*inactiveSince) {
+				continue
+			}
+			dose := doseOutcome{
+				Schedule: schedule,
+				Items:    items,
+				DueAt:    at.In(loc),
+				Event:    event,
+			}
+			if err := classifyDose(&dose, now, lockoutTolerance(schedule)); err != nil {
+				return nil, err
+			}
+			doses = append(doses, dose)
+		}
+	}
+
+	sort.SliceStable(doses, func(i, j int) bool { return doses[i].DueAt.Before(doses[j].DueAt) })
+	return doses, nil
+}
+
+// scheduleInactiveSince returns when a paused or archived schedule left the
+// ACTIVE status, or nil for an active schedule. Schedules that predate
+// status_changed_at fall back to their last update.
+func scheduleInactiveSince(schedule db.Schedule) (*time.Time, error) {
+	if schedule.Status == string(model.ScheduleStatusActive) {
+		return nil, nil
+	}
+	changed := schedule.StatusChangedAt
+	if !changed.Valid {
+		changed = sql.NullString{String: schedule.UpdatedAt, Valid: true}
+	}
+	at, err := parseDBTime(changed.String)
+	if err != nil {
+		return nil, fmt.Errorf("parse status change of schedule %s: %w", schedule.ID, err)
+	}
+	return &at, nil
+}
+
+func lockoutTolerance(schedule db.Schedule) time.Duration {
+	return time.Duration(max(schedule.LockoutMinutes, 1)) * time.Minute
+}
+
+// matchDispenseEvents pairs events with the nearest occurrence within
+// tolerance. When several events land on one occurrence the most recently
+// created wins, like GetDispenseEventByOccurrence.
+func matchDispenseEvents(occurrences []time.Time, events []db.DispenseEvent, tolerance time.Duration) ([]*db.DispenseEvent, error) {
+	matched := make([]*db.DispenseEvent, len(occurrences))
+	for i := range events {
+		event := &events[i]
+		due, err := parseDBTime(event.DueAtIso)
+		if err != nil {
+			return nil, fmt.Errorf("parse due time of dispense event %s: %w", event.ID, err)
+		}
+
+		idx := sort.Search(len(occurrences), func(i int) bool { return !occurrences[i].Before(due) })
+		best := -1
+		for _, candidate := range []int{idx - 1, idx} {
+			if candidate < 0 || candidate >= len(occurrences) {
+				continue
+			}
+			if best == -1 || absDuration(occurrences[candidate].Sub(due)) < absDuration(occurrences[best].Sub(due)) {
+				best = candidate
+			}
+		}
+		if best == -1 || absDuration(occurrences[best].Sub(due)) > tolerance {
+			continue
+		}
+		if current := matched[best]; current == nil || event.CreatedAt > current.CreatedAt {
+			matched[best] = event
+		}
+	}
+	return matched, nil
+}
+
+// classifyDose sets the dose's status from its event. Without a final
+// outcome a dose is PENDING until its lockout window closes.
+func classifyDose(dose *doseOutcome, now time.Time, tolerance time.Duration) error {
+	status := model.DispenseStatusPending
+	if dose.Event != nil {
+		status = model.DispenseStatus(dose.Event.Status)
+	}
+
+	switch status {
+	case model.DispenseStatusTaken:
+		dose.Status = model.DispenseStatusTaken
+		acted, err := parseNullableDBTime(dose.Event.ActedAtIso)
+		if err != nil {
+			return fmt.Errorf("parse acted time of dispense event %s: %w", dose.Event.ID, err)
+		}
+		dose.ActedAt = acted
+	case model.DispenseStatusSkipped:
+		dose.Status = model.DispenseStatusSkipped
+	case model.DispenseStatusPending:
+		dose.Status = model.DispenseStatusMissed
+		if now.Before(dose.DueAt.Add(tolerance)) {
+			dose.Status = model.DispenseStatusPending
+		}
+	default:
+		dose.Status = model.DispenseStatusMissed
+	}
+	return nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// adherenceCounter accumulates doses, which must be added in due order for
+// streaks to be right.
+type adherenceCounter struct {
+	stats      model.AdherenceStats
+	onTime     time.Duration
+	delaySum   float64
+	delayCount int
+	streak     int
+}
+
+func newAdherenceCounter(onTime time.Duration) *adherenceCounter {
+	return &adherenceCounter{onTime: onTime}
+}
+
+func (c *adherenceCounter) add(dose doseOutcome) {
+	c.stats.Scheduled++
+	switch dose.Status {
+	case model.DispenseStatusTaken:
+		c.stats.Taken++
+		c.streak++
+		c.stats.LongestStreak = max(c.stats.LongestStreak, c.streak)
+		if dose.ActedAt != nil {
+			delay := dose.ActedAt.Sub(dose.DueAt)
+			if absDuration(delay) <= c.onTime {
+				c.stats.OnTime++
+			}
+			c.delaySum += delay.Minutes()
+			c.delayCount++
+		}
+	case model.DispenseStatusSkipped:
+		c.stats.Skipped++
+		c.streak = 0
+	case model.DispenseStatusPending:
+		c.stats.Pending++
+	default:
+		c.stats.Missed++
+		c.streak = 0
+	}
+}
+
+func (c *adherenceCounter) result() *model.AdherenceStats {
+	stats := c.stats
+	if decided := stats.Scheduled - stats.Pending; decided > 0 {
+		rate := float64(stats.Taken) / float64(decided)
+		stats.AdherenceRate = &rate
+	}
+	if stats.Taken > 0 {
+		rate := float64(stats.OnTime) / float64(stats.Taken)
+		stats.OnTimeRate = &rate
+	}
+	if c.delayCount > 0 {
+		mean := c.delaySum / float64(c.delayCount)
+		stats.MeanDelayMinutes = &mean
+	}
+	return &stats
+}
+
+// periodStart truncates t to the start of its day, Monday-based week or
+// month in t's location.
+func periodStart(t time.Time, groupBy model.AdherenceGroupBy) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch groupBy {
+	case model.AdherenceGroupByWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case model.AdherenceGroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+func nextPeriod(start time.Time, groupBy model.AdherenceGroupBy) time.Time {
+	switch groupBy {
+	case model.AdherenceGroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case model.AdherenceGroupByMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func validateAdherenceRange(rangeArg model.DateRangeInput) error {
+	if !rangeArg.Start.Before(rangeArg.End) {
+		return fmt.Errorf("range end must be after its start")
+	}
+	if rangeArg.End.Sub(rangeArg.Start) > maxAdherenceRange {
+		return fmt.Errorf("range cannot be longer than 366 days")
+	}
+	return nil
+}
+
+func (r *Resolver) adherenceReport(ctx context.Context, patientID string, rangeArg model.DateRangeInput, groupByArg *model.AdherenceGroupBy, onTimeMinutes *int) (*model.AdherenceReport, error) {
+	if err := validateAdherenceRange(rangeArg); err != nil {
+		return nil, err
+	}
+	groupBy := model.AdherenceGroupByDay
+	if groupByArg != nil {
+		groupBy = *groupByArg
+	}
+	onTime := defaultOnTimeMinutes
+	if onTimeMinutes != nil {
+		if *onTimeMinutes < 0 {
+			return nil, fmt.Errorf("onTimeMinutes cannot be negative")
+		}
+		onTime = *onTimeMinutes
+	}
+
+	patient, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s: %w", patientID, err)
+	}
+	doses, err := r.collectDoseOutcomes(ctx, patient, rangeArg.Start, rangeArg.End, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return r.summarizeAdherence(ctx, patient, doses, rangeArg, groupBy, onTime)
+}
+
+// summarizeAdherence builds the report from collectDoseOutcomes' doses.
+func (r *Resolver) summarizeAdherence(ctx context.Context, patient db.Patient, doses []doseOutcome, rangeArg model.DateRangeInput, groupBy model.AdherenceGroupBy, onTime int) (*model.AdherenceReport, error) {
+	loc := notifications.PatientLocation(patient.Timezone)
+	onTimeWindow := time.Duration(onTime) * time.Minute
+
+	type period struct {
+		start, end time.Time
+		counter    *adherenceCounter
+	}
+	var periods []*period
+	for at := periodStart(rangeArg.Start.In(loc), groupBy); at.Before(rangeArg.End); at = nextPeriod(at, groupBy) {
+		periods = append(periods, &period{start: at, end: nextPeriod(at, groupBy), counter: newAdherenceCounter(onTimeWindow)})
+	}
+
+	overall := newAdherenceCounter(onTimeWindow)
+	bySchedule := make(map[string]*adherenceCounter)
+	byMedication := make(map[string]*adherenceCounter)
+	var scheduleOrder, medicationOrder []string
+	var hourScheduled, hourMissed [24]int
+
+	for _, dose := range doses {
+		overall.add(dose)
+
+		idx := sort.Search(len(periods), func(i int) bool { return dose.DueAt.Before(periods[i].end) })
+		if idx < len(periods) {
+			periods[idx].counter.add(dose)
+		}
+
+		counter, ok := bySchedule[dose.Schedule.ID]
+		if !ok {
+			counter = newAdherenceCounter(onTimeWindow)
+			bySchedule[dose.Schedule.ID] = counter
+			scheduleOrder = append(scheduleOrder, dose.Schedule.ID)
+		}
+		counter.add(dose)
+
+		seen := make(map[string]bool, len(dose.Items))
+		for _, item := range dose.Items {
+			if seen[item.MedicationID] {
+				continue
+			}
+			seen[item.MedicationID] = true
+			counter, ok := byMedication[item.MedicationID]
+			if !ok {
+				counter = newAdherenceCounter(onTimeWindow)
+				byMedication[item.MedicationID] = counter
+				medicationOrder = append(medicationOrder, item.MedicationID)
+			}
+			counter.add(dose)
+		}
+
+		hour := dose.DueAt.Hour()
+		hourScheduled[hour]++
+		if dose.Status == model.DispenseStatusMissed {
+			hourMissed[hour]++
+		}
+	}
+
+	report := &model.AdherenceReport{
+		PatientID:     patient.ID,
+		Start:         rangeArg.Start,
+		End:           rangeArg.End,
+		Timezone:      loc.String(),
+		GroupBy:       groupBy,
+		OnTimeMinutes: onTime,
+		Overall:       overall.result(),
+		Periods:       make([]*model.AdherencePeriod, 0, len(periods)),
+		Schedules:     make([]*model.ScheduleAdherence, 0, len(scheduleOrder)),
+		Medications:   make([]*model.MedicationAdherence, 0, len(medicationOrder)),
+		MissesByHour:  make([]*model.HourlyMisses, 0),
+	}
+	// The first and last periods are cut to the requested range.
+	for _, p := range periods {
+		from, to := p.start, p.end
+		if from.Before(rangeArg.Start) {
+			from = rangeArg.Start.In(loc)
+		}
+		if to.After(rangeArg.End) {
+			to = rangeArg.End.In(loc)
+		}
+		report.Periods = append(report.Periods, &model.AdherencePeriod{
+			Start: from,
+			End:   to,
+			Stats: p.counter.result(),
+		})
+	}
+	for _, scheduleID := range scheduleOrder {
+		row, err := r.Queries.GetSchedule(ctx, scheduleID)
+		if err != nil {
+			return nil, fmt.Errorf("load schedule %s: %w", scheduleID, err)
+		}
+		schedule, err := r.buildScheduleModel(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		report.Schedules = append(report.Schedules, &model.ScheduleAdherence{
+			Schedule: schedule,
+			Stats:    bySchedule[scheduleID].result(),
+		})
+	}
+	for _, medicationID := range medicationOrder {
+		row, err := r.Queries.GetMedication(ctx, medicationID)
+		if err != nil {
+			return nil, fmt.Errorf("load medication %s: %w", medicationID, err)
+		}
+		medication, err := buildMedicationModel(row)
+		if err != nil {
+			return nil, err
+		}
+		report.Medications = append(report.Medications, &model.MedicationAdherence{
+			Medication: medication,
+			Stats:      byMedication[medicationID].result(),
+		})
+	}
+	for hour := range hourScheduled {
+		if hourScheduled[hour] == 0 {
+			continue
+		}
+		report.MissesByHour = append(report.MissesByHour, &model.HourlyMisses{
+			Hour:      hour,
+			Scheduled: hourScheduled[hour],
+			Missed:    hourMissed[hour],
+		})
+	}
+	return report, nil
+}
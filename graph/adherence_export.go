@@ -0,0 +1,342 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/notifications"
+	"pillbox/internal/report"
+)
+
+// renderAdherenceReport builds and renders the printable adherence report,
+// returning the file and its download name.
+func (r *Resolver) renderAdherenceReport(ctx context.Context, patientID string, rangeArg model.DateRangeInput, format report.Format) ([]byte, string, error) {
+	if err := validateAdherenceRange(rangeArg); err != nil {
+		return nil, "", err
+	}
+	data, err := r.loadAdherenceExport(ctx, patientID, rangeArg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, format, data); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), data.Filename(format), nil
+}
+
+func (r *Resolver) exportAdherenceReport(ctx context.Context, patientID string, rangeArg model.DateRangeInput, formatArg model.ReportFormat) (*model.ReportFile, error) {
+	format, err := report.ParseFormat(string(formatArg))
+	if err != nil {
+		return nil, err
+	}
+	content, filename, err := r.renderAdherenceReport(ctx, patientID, rangeArg, format)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ReportFile{
+		Filename:    filename,
+		ContentType: format.ContentType(),
+		Content:     base64.StdEncoding.EncodeToString(content),
+		Size:        len(content),
+	}, nil
+}
+
+func (r *Resolver) loadAdherenceExport(ctx context.Context, patientID string, rangeArg model.DateRangeInput) (report.Adherence, error) {
+	patient, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return report.Adherence{}, fmt.Errorf("load patient %s: %w", patientID, err)
+	}
+	loc := notifications.PatientLocation(patient.Timezone)
+	now := time.Now()
+
+	doses, err := r.collectDoseOutcomes(ctx, patient, rangeArg.Start, rangeArg.End, now)
+	if err != nil {
+		return report.Adherence{}, err
+	}
+	summary, err := r.summarizeAdherence(ctx, patient, doses, rangeArg, model.AdherenceGroupByDay, defaultOnTimeMinutes)
+	if err != nil {
+		return report.Adherence{}, err
+	}
+
+	data := report.Adherence{
+		PatientName: strings.TrimSpace(patient.FirstName + " " + patient.LastName),
+		Timezone:    loc.String(),
+		Start:       rangeArg.Start.In(loc),
+		End:         rangeArg.End.In(loc),
+		GeneratedAt: now.In(loc),
+		Overall:     reportStats(summary.Overall),
+	}
+
+	// The regimen lists every schedule with doses in the range, then any
+	// active schedule that had none, so the current regimen is complete.
+	schedules, err := r.Queries.ListSchedulesByPatient(ctx, patient.ID)
+	if err != nil {
+		return report.Adherence{}, fmt.Errorf("list schedules: %w", err)
+	}
+	scheduleRows := make(map[string]db.Schedule, len(schedules))
+	for _, schedule := range schedules {
+		scheduleRows[schedule.ID] = schedule
+	}
+	regimenIndex := make(map[string]int)
+	addRegimen := func(schedule db.Schedule, stats *model.AdherenceStats) error {
+		items, err := r.Queries.ListScheduleItemsBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return fmt.Errorf("list schedule items for %s: %w", schedule.ID, err)
+		}
+		since, err := parseDBTime(schedule.StartDateIso)
+		if err != nil {
+			return err
+		}
+		entry := report.RegimenEntry{
+			Title:       schedule.Title,
+			Status:      schedule.Status,
+			Rule:        strings.TrimPrefix(schedule.Rrule, "RRULE:"),
+			Since:       since.In(loc),
+			Medications: make([]string, 0, len(items)),
+		}
+		if stats != nil {
+			entry.Stats = reportStats(stats)
+		}
+		for _, item := range items {
+			entry.Medications = append(entry.Medications, fmt.Sprintf("%d x %s", item.Qty, medicationReportName(item.MedicationLabel, item.MedicationCatalogName)))
+		}
+		regimenIndex[schedule.ID] = len(data.Regimen)
+		data.Regimen = append(data.Regimen, entry)
+		return nil
+	}
+	for _, item := range summary.Schedules {
+		if err := addRegimen(scheduleRows[item.Schedule.ID], item.Stats); err != nil {
+			return report.Adherence{}, err
+		}
+	}
+	for _, schedule := range schedules {
+		if _, ok := regimenIndex[schedule.ID]; ok || schedule.Status != string(model.ScheduleStatusActive) {
+			continue
+		}
+		if err := addRegimen(schedule, nil); err != nil {
+			return report.Adherence{}, err
+		}
+	}
+
+	for _, dose := range doses {
+		item := report.Dose{
+			Schedule: regimenIndex[dose.Schedule.ID],
+			DueAt:    dose.DueAt,
+			Status:   string(dose.Status),
+		}
+		if dose.ActedAt != nil {
+			acted := dose.ActedAt.In(loc)
+			item.ActedAt = &acted
+		}
+		if dose.Status == model.DispenseStatusMissed {
+			item.Reason = "No response"
+			if dose.Event != nil && dose.Event.Status != string(model.DispenseStatusPending) {
+				item.Reason = dose.Event.Status
+			}
+		}
+		data.Doses = append(data.Doses, item)
+	}
+
+	events, err := r.Queries.ListDispenseEventsByPatient(ctx, db.ListDispenseEventsByPatientParams{
+		PatientID:  patient.ID,
+		DueAtIso:   formatDBTime(rangeArg.Start),
+		DueAtIso_2: formatDBTime(rangeArg.End),
+	})
+	if err != nil {
+		return report.Adherence{}, fmt.Errorf("list dispense events: %w", err)
+	}
+	// Oldest first, like the rest of the report.
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Status != string(model.DispenseStatusCupAbsent) && event.Status != string(model.DispenseStatusEmptySilo) {
+			continue
+		}
+		due, err := parseDBTime(event.DueAtIso)
+		if err != nil {
+			return report.Adherence{}, err
+		}
+		if !due.Before(rangeArg.End) {
+			continue
+		}
+		acted, err := parseNullableDBTime(event.ActedAtIso)
+		if err != nil {
+			return report.Adherence{}, err
+		}
+		if acted != nil {
+			local := acted.In(loc)
+			acted = &local
+		}
+		data.Faults = append(data.Faults, report.Fault{
+			DueAt:    due.In(loc),
+			ActedAt:  acted,
+			Schedule: scheduleRows[event.ScheduleID].Title,
+			Kind:     event.Status,
+			Source:   event.ActionSource.String,
+		})
+	}
+
+	refills, err := r.Queries.ListStockMovementsByPatient(ctx, db.ListStockMovementsByPatientParams{
+		PatientID: patient.ID,
+		Kind:      string(model.StockMovementKindRefill),
+		Start:     formatDBTime(rangeArg.Start),
+		End:       formatDBTime(rangeArg.End),
+	})
+	if err != nil {
+		return report.Adherence{}, fmt.Errorf("list refills: %w", err)
+	}
+	for _, row := range refills {
+		at, err := parseDBTime(row.StockMovement.CreatedAt)
+		if err != nil {
+			return report.Adherence{}, err
+		}
+		data.Refills = append(data.Refills, report.Refill{
+			At:           at.In(loc),
+			Medication:   medicationReportName(row.MedicationLabel, row.MedicationCatalogName),
+			Quantity:     row.StockMovement.Quantity,
+			BalanceAfter: row.StockMovement.BalanceAfter,
+			LotNumber:    row.StockMovement.LotNumber.String,
+			ExpiresOn:    row.StockMovement.ExpiresOn.String,
+			Actor:        row.StockMovement.Actor.String,
+		})
+	}
+	return data, nil
+}
+
+// medicationReportName shows the drug name next to the silo label. Reports
+// go to the care team, so names appear whatever the patient's reminder
+// privacy setting.
+func medicationReportName(label string, catalogName sql.NullString) string {
+	label = strings.TrimSpace(label)
+	if !catalogName.Valid || strings.TrimSpace(catalogName.String) == "" {
+		return label
+	}
+	if label == "" {
+		return catalogName.String
+	}
+	return fmt.Sprintf("%s (%s)", catalogName.String, label)
+}
+
+func reportStats(stats *model.AdherenceStats) report.Stats {
+	return report.Stats{
+		Scheduled:        stats.Scheduled,
+		Taken:            stats.Taken,
+		Missed:           stats.Missed,
+		Skipped:          stats.Skipped,
+		Pending:          stats.Pending,
+		AdherenceRate:    stats.AdherenceRate,
+		OnTimeRate:       stats.OnTimeRate,
+		MeanDelayMinutes: stats.MeanDelayMinutes,
+		LongestStreak:    stats.LongestStreak,
+	}
+}
+
+// AdherenceReportHandler serves GET
+// /patients/{patientID}/adherence-report?format=pdf&start=...&end=...
+// start and end are YYYY-MM-DD days in the patient's timezone, end
+// included, or RFC3339 times with end excluded.
+type AdherenceReportHandler struct {
+	resolver *Resolver
+}
+
+func NewAdherenceReportHandler(resolver *Resolver) *AdherenceReportHandler {
+	return &AdherenceReportHandler{resolver: resolver}
+}
+
+func (h *AdherenceReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "patients" || parts[2] != "adherence-report" {
+		http.NotFound(w, r)
+		return
+	}
+	patientID := parts[1]
+
+	query := r.URL.Query()
+	formatName := query.Get("format")
+	if formatName == "" {
+		formatName = string(report.FormatPDF)
+	}
+	format, err := report.ParseFormat(formatName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	patient, err := h.resolver.Queries.GetPatient(r.Context(), patientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("adherence report for patient %s: load patient: %v", patientID, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	loc := notifications.PatientLocation(patient.Timezone)
+	start, err := parseReportBound(query.Get("start"), loc, false)
+	if err != nil {
+		http.Error(w, "start: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	end, err := parseReportBound(query.Get("end"), loc, true)
+	if err != nil {
+		http.Error(w, "end: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rangeArg := model.DateRangeInput{Start: start, End: end}
+	if err := validateAdherenceRange(rangeArg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	content, filename, err := h.resolver.renderAdherenceReport(r.Context(), patientID, rangeArg, format)
+	if err != nil {
+		log.Printf("adherence report for patient %s: %v", patientID, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, _ = w.Write(content)
+}
+
+// parseReportBound reads a range bound. A bare date is midnight in loc; as
+// an end bound it means the end of that day.
+func parseReportBound(value string, loc *time.Location, end bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("is required")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be YYYY-MM-DD or RFC3339")
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdherenceReportHandlerStatus(t *testing.T) {
+	const path = "/patients/patient_demo_002/adherence-report"
+
+	tests := []struct {
+		name     string
+		target   string
+		setup    func(t *testing.T, r *Resolver)
+		want     int
+		wantBody string
+	}{
+		{name: "report", target: path + "?format=csv&start=2026-06-01&end=2026-06-07", want: http.StatusOK},
+		{name: "unknown format", target: path + "?format=docx&start=2026-06-01&end=2026-06-07", want: http.StatusBadRequest},
+		{name: "unknown patient", target: "/patients/nobody/adherence-report?format=csv&start=2026-06-01&end=2026-06-07", want: http.StatusNotFound},
+		{name: "missing start", target: path + "?format=csv&end=2026-06-07", want: http.StatusBadRequest},
+		{name: "end before start", target: path + "?format=csv&start=2026-06-07&end=2026-06-01", want: http.StatusBadRequest, wantBody: "range end must be after its start"},
+		{name: "range too long", target: path + "?format=csv&start=2025-01-01&end=2026-06-01", want: http.StatusBadRequest, wantBody: "range cannot be longer than 366 days"},
+		{
+			name:   "report fails",
+			target: path + "?format=csv&start=2026-06-01&end=2026-06-07",
+			setup: func(t *testing.T, r *Resolver) {
+				if _, err := r.DB.Exec(`UPDATE schedules SET rrule = 'RRULE:FREQ=SOMETIMES' WHERE id = 'sched_demo_central'`); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want:     http.StatusInternalServerError,
+			wantBody: "internal error",
+		},
+		{
+			name:   "patient lookup fails",
+			target: path + "?format=csv&start=2026-06-01&end=2026-06-07",
+			setup: func(t *testing.T, r *Resolver) {
+				r.DB.Close()
+			},
+			want:     http.StatusInternalServerError,
+			wantBody: "internal error",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestResolver(t)
+			if tc.setup != nil {
+				tc.setup(t, r)
+			}
+			rec := httptest.NewRecorder()
+			NewAdherenceReportHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+			}
+			if tc.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tc.wantBody {
+				t.Fatalf("body = %q, want %q", rec.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
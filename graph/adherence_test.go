@@ -0,0 +1,155 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+)
+
+func TestCollectDoseOutcomesInactiveSchedules(t *testing.T) {
+	ctx := context.Background()
+
+	// A daily 07:00 Chicago (12:00Z) dose for patient_demo_002, taken on
+	// June 2 and, after the schedule stopped on June 6, on June 8.
+	setup := func(t *testing.T, status, statusChangedAt, updatedAt string) *Resolver {
+		t.Helper()
+		r := newTestResolver(t)
+		if _, err := r.DB.Exec(`INSERT INTO schedules (id, patient_id, title, timezone, rrule, start_date_iso, lockout_minutes, status, updated_at, status_changed_at)
+			VALUES ('sched_test', 'patient_demo_002', 'Test', 'America/Chicago', 'RRULE:FREQ=DAILY', '2026-06-01T12:00:00Z', 60, ?, ?, NULLIF(?, ''))`,
+			status, updatedAt, statusChangedAt); err != nil {
+			t.Fatal(err)
+		}
+		for i, due := range []string{"2026-06-02T12:00:00Z", "2026-06-08T12:00:00Z"} {
+			if _, err := r.DB.Exec(`INSERT INTO dispense_events (id, patient_id, schedule_id, due_at_iso, acted_at_iso, status)
+				VALUES (?, 'patient_demo_002', 'sched_test', ?, ?, 'TAKEN')`, fmt.Sprintf("event_test_%d", i), due, due); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return r
+	}
+
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 6, 11, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 6, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		status          string
+		statusChangedAt string
+		updatedAt       string
+		wantDays        []int
+		wantMissed      int
+	}{
+		{
+			name:       "active",
+			status:     "ACTIVE",
+			updatedAt:  "2026-06-06 00:00:00",
+			wantDays:   []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			wantMissed: 8,
+		},
+		{
+			name:            "archived",
+			status:          "ARCHIVED",
+			statusChangedAt: "2026-06-06 00:00:00",
+			updatedAt:       "2026-06-09 00:00:00",
+			wantDays:        []int{1, 2, 3, 4, 5, 8},
+			wantMissed:      4,
+		},
+		{
+			name:       "paused before status changes were recorded",
+			status:     "PAUSED",
+			updatedAt:  "2026-06-06 00:00:00",
+			wantDays:   []int{1, 2, 3, 4, 5, 8},
+			wantMissed: 4,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := setup(t, tc.status, tc.statusChangedAt, tc.updatedAt)
+			patient, err := r.Queries.GetPatient(ctx, "patient_demo_002")
+			if err != nil {
+				t.Fatal(err)
+			}
+			doses, err := r.collectDoseOutcomes(ctx, db.Patient(patient), start, end, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var days []int
+			missed := 0
+			for _, dose := range doses {
+				if dose.Schedule.ID != "sched_test" {
+					continue
+				}
+				days = append(days, dose.DueAt.UTC().Day())
+				if dose.Status == model.DispenseStatusMissed {
+					missed++
+				}
+			}
+			if len(days) != len(tc.wantDays) || missed != tc.wantMissed {
+				t.Fatalf("doses on %v with %d missed, want %v with %d missed", days, missed, tc.wantDays, tc.wantMissed)
+			}
+			for i := range days {
+				if days[i] != tc.wantDays[i] {
+					t.Fatalf("doses on %v, want %v", days, tc.wantDays)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleStatusChangedAt(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+	m := &mutationResolver{r}
+
+	get := func() db.Schedule {
+		t.Helper()
+		schedule, err := r.Queries.GetSchedule(ctx, "sched_demo_morning")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schedule
+	}
+	if get().StatusChangedAt.Valid {
+		t.Fatal("an active schedule that never changed status has a status change time")
+	}
+
+	if _, err := m.ArchiveSchedule(ctx, "sched_demo_morning"); err != nil {
+		t.Fatal(err)
+	}
+	archived := get()
+	if !archived.StatusChangedAt.Valid {
+		t.Fatal("archiving did not record the status change")
+	}
+
+	// Renaming the archived schedule keeps the time it stopped.
+	if _, err := r.DB.Exec(`UPDATE schedules SET status_changed_at = '2026-06-06 00:00:00' WHERE id = 'sched_demo_morning'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Queries.UpdateSchedule(ctx, db.UpdateScheduleParams{
+		Title:          "Renamed",
+		Timezone:       archived.Timezone,
+		Rrule:          archived.Rrule,
+		StartDateIso:   archived.StartDateIso,
+		EndDateIso:     archived.EndDateIso,
+		LockoutMinutes: archived.LockoutMinutes,
+		Status:         archived.Status,
+		ID:             archived.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := get().StatusChangedAt.String; got != "2026-06-06 00:00:00" {
+		t.Fatalf("status change time after rename = %q, want it unchanged", got)
+	}
+	if _, err := m.ArchiveSchedule(ctx, "sched_demo_morning"); err != nil {
+		t.Fatal(err)
+	}
+	if got := get().StatusChangedAt.String; got != "2026-06-06 00:00:00" {
+		t.Fatalf("status change time after archiving again = %q, want it unchanged", got)
+	}
+}
@@ -8,9 +8,16 @@ import (
 
 	"pillbox/graph/model"
 	"pillbox/internal/db"
+	"pillbox/internal/notifications"
 )
 
-func (r *Resolver) buildUserModel(ctx context.Context, userID, email, fullName string, phone sql.NullString, timezone, createdAt, updatedAt string) (*model.User, error) {
+const (
+	// recentNotificationsLimit caps Patient.notifications.
+	recentNotificationsLimit = 20
+	maxNotificationPageSize  = 200
+)
+
+func (r *Resolver) buildUserModel(ctx context.Context, userID, email, fullName string, phone sql.NullString, timezone, locale, createdAt, updatedAt string) (*model.User, error) {
 	created, err := parseDBTime(createdAt)
 	if err != nil {
 		return nil, err
@@ -34,15 +41,63 @@ func (r *Resolver) buildUserModel(ctx context.Context, userID, email, fullName s
 		patients = append(patients, mp)
 	}
 
+	preferences, err := r.loadNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.User{
-		ID:        userID,
-		Email:     email,
-		FullName:  fullName,
-		Phone:     ptrFromNullString(phone),
-		Timezone:  timezone,
-		CreatedAt: created,
-		UpdatedAt: updated,
-		Patients:  patients,
+		ID:                      userID,
+		Email:                   email,
+		FullName:                fullName,
+		Phone:                   ptrFromNullString(phone),
+		Timezone:                timezone,
+		Locale:                  locale,
+		CreatedAt:               created,
+		UpdatedAt:               updated,
+		Patients:                patients,
+		NotificationPreferences: preferences,
+	}, nil
+}
+
+func (r *Resolver) loadNotificationPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	rows, err := r.Queries.ListNotificationPreferencesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list notification preferences: %w", err)
+	}
+	result := make([]*model.NotificationPreference, 0, len(rows))
+	for _, row := range rows {
+		pref, err := buildNotificationPreferenceModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pref)
+	}
+	return result, nil
+}
+
+func buildNotificationPreferenceModel(row db.NotificationPreference) (*model.NotificationPreference, error) {
+	createdAt, err := parseDBTime(row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := parseDBTime(row.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationPreference{
+		ID:              row.ID,
+		UserID:          row.UserID,
+		Type:            model.NotificationType(row.NotificationType),
+		Channel:         model.NotificationChannel(row.Channel),
+		Enabled:         row.Enabled != 0,
+		QuietHoursStart: ptrFromNullString(row.QuietHoursStart),
+		QuietHoursEnd:   ptrFromNullString(row.QuietHoursEnd),
+		DeliveryMode:    model.NotificationDeliveryMode(row.DeliveryMode),
+		DigestTime:      row.DigestTime,
+		CreatedAt:       createdAt,
+		UpdatedAt:       updatedAt,
 	}, nil
 }
 
@@ -71,17 +126,102 @@ func (r *Resolver) buildPatientModel(ctx context.Context, record db.Patient) (*m
 		return nil, err
 	}
 
+	notificationEvents, err := r.loadNotificationEvents(ctx, db.ListNotificationEventsPageParams{
+		PatientID: record.ID,
+		Limit:     recentNotificationsLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	voice, err := r.loadVoiceSettings(ctx, record.ID, record.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	voiceMessages, err := r.loadVoiceMessages(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	silos, err := r.loadSilos(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.Patient{
 		ID:                     record.ID,
 		UserID:                 ptrFromNullString(record.UserID),
 		FirstName:              record.FirstName,
 		LastName:               record.LastName,
 		Timezone:               record.Timezone,
+		Locale:                 record.Locale,
 		CreatedAt:              createdAt,
 		UpdatedAt:              updatedAt,
 		Medications:            meds,
 		Schedules:              schedules,
 		UpcomingDispenseEvents: upcoming,
+		Notifications:          notificationEvents,
+		VoiceSettings:          voice,
+		VoiceMessages:          voiceMessages,
+		PharmacyLeadTimeDays:   int(record.PharmacyLeadTimeDays),
+		SiloCount:              int(record.SiloCount),
+		Silos:                  silos,
+		ShowMedicationNames:    record.ShowMedicationNames != 0,
+		AutoRefillRequests:     record.AutoRefillRequests != 0,
+	}, nil
+}
+
+func (r *Resolver) loadVoiceSettings(ctx context.Context, patientID, locale string) (*model.VoiceSettings, error) {
+	settings, err := notifications.LoadVoiceSettings(ctx, r.Queries, patientID, locale)
+	if err != nil {
+		return nil, fmt.Errorf("load voice settings: %w", err)
+	}
+
+	return &model.VoiceSettings{
+		PatientID:       patientID,
+		VoiceName:       ptrString(settings.VoiceName),
+		LanguageCode:    settings.LanguageCode,
+		SpeakingRate:    settings.SpeakingRate,
+		SampleRateHertz: settings.SampleRateHz,
+	}, nil
+}
+
+func (r *Resolver) loadVoiceMessages(ctx context.Context, patientID string) ([]*model.VoiceMessage, error) {
+	rows, err := r.Queries.ListVoiceMessagesByPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("list voice messages: %w", err)
+	}
+	result := make([]*model.VoiceMessage, 0, len(rows))
+	for _, row := range rows {
+		message, err := buildVoiceMessageModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, message)
+	}
+	return result, nil
+}
+
+func buildVoiceMessageModel(record db.VoiceMessage) (*model.VoiceMessage, error) {
+	dueAt, err := parseNullableDBTime(record.DueAtIso)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := parseDBTime(record.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.VoiceMessage{
+		ID:               record.ID,
+		PatientID:        record.PatientID,
+		ScheduleID:       record.ScheduleID,
+		DueAtIso:         dueAt,
+		OriginalMimeType: record.OriginalMimeType,
+		DurationMs:       int(record.DurationMs),
+		SampleRateHertz:  int(record.SampleRateHz),
+		CreatedAt:        createdAt,
 	}, nil
 }
 
@@ -101,6 +241,156 @@ func (r *Resolver) loadMedications(ctx context.Context, patientID string) ([]*mo
 	return result, nil
 }
 
+func buildSiloModel(row db.Silo, medication *db.Medication) (*model.Silo, error) {
+	updatedAt, err := parseDBTime(row.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	calibratedAt, err := parseNullableDBTime(row.CalibratedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	silo := &model.Silo{
+		ID:           row.ID,
+		PatientID:    row.PatientID,
+		Index:        int(row.SiloIndex),
+		Capacity:     int(row.Capacity),
+		CalibratedAt: calibratedAt,
+		UpdatedAt:    updatedAt,
+	}
+	if row.PillSizeMm.Valid {
+		silo.PillSizeMm = &row.PillSizeMm.Float64
+	}
+	if medication != nil {
+		silo.Medication, err = buildMedicationModel(*medication)
+		if err != nil {
+			return nil, err
+		}
+		silo.Fill = int(medication.StockCount)
+	}
+	return silo, nil
+}
+
+func buildPrescriptionModel(row db.Prescription) (*model.Prescription, error) {
+	createdAt, err := parseDBTime(row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := parseDBTime(row.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Prescription{
+		ID:               row.ID,
+		MedicationID:     row.MedicationID,
+		PrescriberName:   row.PrescriberName,
+		PrescriberPhone:  ptrFromNullString(row.PrescriberPhone),
+		PharmacyName:     ptrFromNullString(row.PharmacyName),
+		PharmacyPhone:    ptrFromNullString(row.PharmacyPhone),
+		Sig:              ptrFromNullString(row.Sig),
+		QuantityPerFill:  int(row.QuantityPerFill),
+		RefillsRemaining: int(row.RefillsRemaining),
+		WrittenOn:        row.WrittenOn,
+		ExpiresOn:        ptrFromNullString(row.ExpiresOn),
+		Active:           row.Active != 0,
+		RxNumber:         ptrFromNullString(row.RxNumber),
+		PharmacyID:       ptrFromNullString(row.PharmacyID),
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
+	}, nil
+}
+
+func buildPharmacyModel(row db.Pharmacy) (*model.Pharmacy, error) {
+	createdAt, err := parseDBTime(row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := parseDBTime(row.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Pharmacy{
+		ID:         row.ID,
+		Name:       row.Name,
+		Phone:      ptrFromNullString(row.Phone),
+		Email:      ptrFromNullString(row.Email),
+		FaxEmail:   ptrFromNullString(row.FaxEmail),
+		WebhookURL: ptrFromNullString(row.WebhookUrl),
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+	}, nil
+}
+
+func buildRefillRequestModel(row db.RefillRequest) (*model.RefillRequest, error) {
+	requestedAt, err := parseDBTime(row.RequestedAt)
+	if err != nil {
+		return nil, err
+	}
+	readyAt, err := parseNullableDBTime(row.ReadyAt)
+	if err != nil {
+		return nil, err
+	}
+	pickedUpAt, err := parseNullableDBTime(row.PickedUpAt)
+	if err != nil {
+		return nil, err
+	}
+	cancelledAt, err := parseNullableDBTime(row.CancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := parseDBTime(row.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RefillRequest{
+		ID:             row.ID,
+		PatientID:      row.PatientID,
+		MedicationID:   row.MedicationID,
+		PrescriptionID: ptrFromNullString(row.PrescriptionID),
+		PharmacyID:     ptrFromNullString(row.PharmacyID),
+		RxNumber:       ptrFromNullString(row.RxNumber),
+		Quantity:       int(row.Quantity),
+		Status:         model.RefillRequestStatus(row.Status),
+		Source:         model.RefillRequestSource(row.Source),
+		RequestedAt:    requestedAt,
+		ReadyAt:        readyAt,
+		PickedUpAt:     pickedUpAt,
+		CancelledAt:    cancelledAt,
+		UpdatedAt:      updatedAt,
+	}, nil
+}
+
+func buildRefillRequestDeliveryModel(row db.RefillRequestDelivery) (*model.RefillRequestDelivery, error) {
+	attemptedAt, err := parseDBTime(row.AttemptedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RefillRequestDelivery{
+		ID:          row.ID,
+		Channel:     model.RefillDeliveryChannel(row.Channel),
+		Destination: row.Destination,
+		Status:      model.RefillDeliveryStatus(row.Status),
+		Error:       ptrFromNullString(row.Error),
+		AttemptedAt: attemptedAt,
+	}, nil
+}
+
+func buildCatalogEntryModel(row db.MedicationCatalog) *model.CatalogEntry {
+	return &model.CatalogEntry{
+		ID:         row.ID,
+		Name:       row.Name,
+		Ingredient: row.Ingredient,
+		Strength:   ptrFromNullString(row.Strength),
+		Unit:       ptrFromNullString(row.Unit),
+		DosageForm: ptrFromNullString(row.DosageForm),
+	}
+}
+
 func buildMedicationModel(row db.Medication) (*model.Medication, error) {
 	createdAt, err := parseDBTime(row.CreatedAt)
 	if err != nil {
@@ -120,11 +410,70 @@ func buildMedicationModel(row db.Medication) (*model.Medication, error) {
 		LowStockThreshold: int(row.LowStockThreshold),
 		CartridgeIndex:    ptrFromNullInt(row.CartridgeIndex),
 		MaxDailyDose:      int(row.MaxDailyDose),
+		CatalogID:         ptrFromNullString(row.CatalogID),
 		CreatedAt:         createdAt,
 		UpdatedAt:         updatedAt,
 	}, nil
 }
 
+func buildMedicationForecastModel(forecast notifications.MedicationForecast) (*model.MedicationForecast, error) {
+	medication, err := buildMedicationModel(forecast.Medication)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.MedicationForecast{
+		Medication:       medication,
+		DailyConsumption: forecast.DailyConsumption,
+		RunOutAt:         forecast.RunOutAt,
+		RefillBy:         forecast.RefillBy,
+	}
+	if forecast.DailyConsumption > 0 {
+		days := float64(forecast.Medication.StockCount) / forecast.DailyConsumption
+		result.DaysOfSupply = &days
+	}
+	return result, nil
+}
+
+func buildStockMovementModel(row db.StockMovement) (*model.StockMovement, error) {
+	createdAt, err := parseDBTime(row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StockMovement{
+		ID:              row.ID,
+		MedicationID:    row.MedicationID,
+		Kind:            model.StockMovementKind(row.Kind),
+		Quantity:        int(row.Quantity),
+		BalanceAfter:    int(row.BalanceAfter),
+		Actor:           ptrFromNullString(row.Actor),
+		DispenseEventID: ptrFromNullString(row.DispenseEventID),
+		Note:            ptrFromNullString(row.Note),
+		LotNumber:       ptrFromNullString(row.LotNumber),
+		ExpiresOn:       ptrFromNullString(row.ExpiresOn),
+		CreatedAt:       createdAt,
+	}, nil
+}
+
+func buildMedicationLotModel(row db.MedicationLot) (*model.MedicationLot, error) {
+	loadedAt, err := parseDBTime(row.LoadedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MedicationLot{
+		ID:                row.ID,
+		MedicationID:      row.MedicationID,
+		LotNumber:         ptrFromNullString(row.LotNumber),
+		QuantityLoaded:    int(row.QuantityLoaded),
+		QuantityRemaining: int(row.QuantityRemaining),
+		ExpiresOn:         ptrFromNullString(row.ExpiresOn),
+		Silo:              ptrFromNullInt(row.Silo),
+		LoadedAt:          loadedAt,
+	}, nil
+}
+
 func (r *Resolver) loadSchedules(ctx context.Context, patientID string) ([]*model.Schedule, error) {
 	rows, err := r.Queries.ListSchedulesByPatient(ctx, patientID)
 	if err != nil {
@@ -176,6 +525,7 @@ func (r *Resolver) buildScheduleModel(ctx context.Context, record db.Schedule) (
 			LowStockThreshold: row.MedicationLowStockThreshold,
 			CartridgeIndex:    row.MedicationCartridgeIndex,
 			MaxDailyDose:      row.MedicationMaxDailyDose,
+			CatalogID:         row.MedicationCatalogID,
 			CreatedAt:         row.MedicationCreatedAt,
 			UpdatedAt:         row.MedicationUpdatedAt,
 		})
@@ -269,3 +619,58 @@ func (r *Resolver) fetchEvents(ctx context.Context, patientID string, start, end
 	}
 	return result, nil
 }
+
+func (r *Resolver) loadNotificationEvents(ctx context.Context, filter db.ListNotificationEventsPageParams) ([]*model.NotificationEvent, error) {
+	rows, err := r.Queries.ListNotificationEventsPage(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list notification events: %w", err)
+	}
+	result := make([]*model.NotificationEvent, 0, len(rows))
+	for _, row := range rows {
+		ev, err := buildNotificationEventModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ev)
+	}
+	return result, nil
+}
+
+func buildNotificationEventModel(row db.NotificationEvent) (*model.NotificationEvent, error) {
+	due, err := parseDBTime(row.DueAtIso)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := parseDBTime(row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	deliveryUpdatedAt, err := parseNullableDBTime(row.DeliveryUpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveryStatus *model.NotificationDeliveryStatus
+	if row.DeliveryStatus.Valid {
+		status := model.NotificationDeliveryStatus(row.DeliveryStatus.String)
+		deliveryStatus = &status
+	}
+
+	return &model.NotificationEvent{
+		ID:                row.ID,
+		PatientID:         row.PatientID,
+		ScheduleID:        row.ScheduleID,
+		UserID:            ptrFromNullString(row.UserID),
+		DueAtIso:          due,
+		Channel:           model.NotificationChannel(row.Channel),
+		Destination:       row.Destination,
+		Message:           row.Message,
+		Status:            model.NotificationStatus(row.Status),
+		ProviderMessageID: ptrFromNullString(row.ProviderMessageID),
+		DeliveryStatus:    deliveryStatus,
+		ErrorCode:         ptrFromNullString(row.ErrorCode),
+		ErrorMessage:      ptrFromNullString(row.ErrorMessage),
+		DeliveryUpdatedAt: deliveryUpdatedAt,
+		CreatedAt:         createdAt,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+)
+
+const (
+	defaultCatalogSearchLimit = 20
+	maxCatalogSearchLimit     = 100
+)
+
+func (r *Resolver) searchMedicationCatalog(ctx context.Context, query string, limit *int) ([]*model.CatalogEntry, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	n := defaultCatalogSearchLimit
+	if limit != nil {
+		if *limit < 1 || *limit > maxCatalogSearchLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxCatalogSearchLimit)
+		}
+		n = *limit
+	}
+
+	rows, err := r.Queries.SearchCatalog(ctx, db.SearchCatalogParams{
+		Query: query,
+		Limit: int64(n),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search medication catalog: %w", err)
+	}
+	result := make([]*model.CatalogEntry, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, buildCatalogEntryModel(row))
+	}
+	return result, nil
+}
+
+func (r *Resolver) loadCatalogEntry(ctx context.Context, id string) (*model.CatalogEntry, error) {
+	row, err := r.Queries.GetCatalogEntry(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load catalog entry %s: %w", id, err)
+	}
+	return buildCatalogEntryModel(row), nil
+}
+
+// linkCatalogEntry points the medication at a catalog entry, or unlinks it
+// when catalogID is blank. Run it inside withTx.
+func linkCatalogEntry(ctx context.Context, q *db.Queries, medicationID, catalogID string) (db.Medication, error) {
+	link := nullTrimmedStringFromPtr(&catalogID)
+	if link.Valid {
+		if _, err := q.GetCatalogEntry(ctx, link.String); errors.Is(err, sql.ErrNoRows) {
+			return db.Medication{}, fmt.Errorf("catalog entry %s not found", link.String)
+		} else if err != nil {
+			return db.Medication{}, fmt.Errorf("load catalog entry %s: %w", link.String, err)
+		}
+	}
+
+	record, err := q.SetMedicationCatalogEntry(ctx, db.SetMedicationCatalogEntryParams{
+		CatalogID: link,
+		ID:        medicationID,
+	})
+	if err != nil {
+		return db.Medication{}, fmt.Errorf("link medication to catalog: %w", err)
+	}
+	return record, nil
+}
@@ -0,0 +1,343 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/notifications"
+)
+
+// recordDispenseAction stores a dispense outcome, decrements stock for taken
+// doses and notifies the caregiver about missed doses, device faults and low
+// stock. It backs both the recordDispenseAction mutation and SMS replies.
+//
+// The event, stock movements and caregiver notifications are written in one
+// transaction; notifications go through the outbox and are sent after it
+// commits. A repeated idempotency key returns the event it recorded without
+// changing anything, and stock is only ever decremented once per event.
+func (r *Resolver) recordDispenseAction(ctx context.Context, input model.DispenseActionInput) (*model.DispenseEvent, error) {
+	var (
+		record   db.DispenseEvent
+		outboxID []string
+	)
+
+	idempotencyKey := ""
+	if input.IdempotencyKey != nil {
+		idempotencyKey = strings.TrimSpace(*input.IdempotencyKey)
+	}
+
+	err := r.withTx(ctx, func(qtx *db.Queries) error {
+		if idempotencyKey != "" {
+			existing, found, err := replayedDispense(ctx, qtx, input, idempotencyKey)
+			if err != nil {
+				return err
+			}
+			if found {
+				record = existing
+				return nil
+			}
+		}
+
+		var (
+			err                  error
+			shouldDecrementStock bool
+		)
+		record, shouldDecrementStock, err = saveDispenseEvent(ctx, qtx, input)
+		if err != nil {
+			return err
+		}
+
+		if idempotencyKey != "" {
+			if err := qtx.CreateDispenseIdempotencyKey(ctx, db.CreateDispenseIdempotencyKeyParams{
+				PatientID:       input.PatientID,
+				IdempotencyKey:  idempotencyKey,
+				DispenseEventID: record.ID,
+				ScheduleID:      input.ScheduleID,
+				DueAtIso:        formatDBTime(input.DueAtIso),
+				Status:          string(input.Status),
+			}); err != nil {
+				return fmt.Errorf("record idempotency key: %w", err)
+			}
+		}
+
+		dispatcher := notifications.NewDispatcher(qtx, nil)
+
+		statusOutbox, err := queueStatusNotification(ctx, qtx, dispatcher, input)
+		if err != nil {
+			return err
+		}
+		outboxID = append(outboxID, statusOutbox...)
+
+		if shouldDecrementStock {
+			stockOutbox, err := decrementDispensedStock(ctx, qtx, dispatcher, input, record)
+			if err != nil {
+				return err
+			}
+			outboxID = append(outboxID, stockOutbox...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Delivery failures are recorded on the outbox entries; the dispense
+	// itself has already been saved.
+	if err := notifications.NewDispatcher(r.Queries, nil).SendQueued(ctx, outboxID); err != nil {
+		log.Printf("record dispense: send notifications: %v", err)
+	}
+
+	return buildDispenseEvent(record)
+}
+
+// replayedDispense returns the event an earlier request with the same
+// idempotency key recorded. The replay must match the request the key was
+// first used with; the event itself may have changed since.
+func replayedDispense(ctx context.Context, qtx *db.Queries, input model.DispenseActionInput, key string) (db.DispenseEvent, bool, error) {
+	existing, err := qtx.GetDispenseIdempotencyKey(ctx, db.GetDispenseIdempotencyKeyParams{
+		PatientID:      input.PatientID,
+		IdempotencyKey: key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.DispenseEvent{}, false, nil
+	}
+	if err != nil {
+		return db.DispenseEvent{}, false, fmt.Errorf("load idempotency key: %w", err)
+	}
+
+	if existing.ScheduleID != input.ScheduleID || existing.DueAtIso != formatDBTime(input.DueAtIso) || existing.Status != string(input.Status) {
+		return db.DispenseEvent{}, false, fmt.Errorf("idempotency key %q was already used for a different dispense action", key)
+	}
+
+	record, err := qtx.GetDispenseEvent(ctx, existing.DispenseEventID)
+	if err != nil {
+		return db.DispenseEvent{}, false, fmt.Errorf("load dispense event %s: %w", existing.DispenseEventID, err)
+	}
+	return record, true, nil
+}
+
+// saveDispenseEvent creates or updates the event and reports whether its
+// pills should come out of stock: the dose is TAKEN and no earlier update of
+// the event already took them, so TAKEN, SKIPPED, TAKEN counts once.
+func saveDispenseEvent(ctx context.Context, qtx *db.Queries, input model.DispenseActionInput) (db.DispenseEvent, bool, error) {
+	if input.EventID != nil && *input.EventID != "" {
+		existing, err := qtx.GetDispenseEvent(ctx, *input.EventID)
+		if err != nil {
+			return db.DispenseEvent{}, false, fmt.Errorf("load dispense event %s: %w", *input.EventID, err)
+		}
+		record, err := qtx.UpdateDispenseEvent(ctx, db.UpdateDispenseEventParams{
+			PatientID:    input.PatientID,
+			ScheduleID:   input.ScheduleID,
+			DueAtIso:     formatDBTime(input.DueAtIso),
+			ActedAtIso:   formatNullableTimePtr(input.ActedAtIso),
+			Status:       string(input.Status),
+			ActionSource: nullStringFromPtr(input.ActionSource),
+			ID:           *input.EventID,
+		})
+		if err != nil {
+			return db.DispenseEvent{}, false, fmt.Errorf("record dispense event: %w", err)
+		}
+		if input.Status != model.DispenseStatusTaken {
+			return record, false, nil
+		}
+		dispensed, err := qtx.HasDispenseMovements(ctx, nullableID(existing.ID))
+		if err != nil {
+			return db.DispenseEvent{}, false, fmt.Errorf("check dispensed stock for %s: %w", existing.ID, err)
+		}
+		return record, dispensed == 0, nil
+	}
+
+	record, err := qtx.CreateDispenseEvent(ctx, db.CreateDispenseEventParams{
+		ID:           uuid.NewString(),
+		PatientID:    input.PatientID,
+		ScheduleID:   input.ScheduleID,
+		DueAtIso:     formatDBTime(input.DueAtIso),
+		ActedAtIso:   formatNullableTimePtr(input.ActedAtIso),
+		Status:       string(input.Status),
+		ActionSource: nullStringFromPtr(input.ActionSource),
+	})
+	if err != nil {
+		return db.DispenseEvent{}, false, fmt.Errorf("record dispense event: %w", err)
+	}
+	return record, input.Status == model.DispenseStatusTaken, nil
+}
+
+// loadRecipient returns the patient's caregiver when they can receive SMS.
+func loadRecipient(ctx context.Context, qtx *db.Queries, patient db.Patient) (*db.GetUserRow, error) {
+	if !patient.UserID.Valid {
+		return nil, nil
+	}
+	user, err := qtx.GetUser(ctx, patient.UserID.String)
+	if err != nil {
+		return nil, fmt.Errorf("load user %s: %w", patient.UserID.String, err)
+	}
+	if !user.Phone.Valid || user.Phone.String == "" {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+// queueStatusNotification tells the caregiver about missed doses and device
+// faults.
+func queueStatusNotification(ctx context.Context, qtx *db.Queries, dispatcher *notifications.Dispatcher, input model.DispenseActionInput) ([]string, error) {
+	if input.Status != model.DispenseStatusMissed &&
+		input.Status != model.DispenseStatusCupAbsent &&
+		input.Status != model.DispenseStatusEmptySilo {
+		return nil, nil
+	}
+
+	patient, err := qtx.GetPatient(ctx, input.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s for status notification: %w", input.PatientID, err)
+	}
+	user, err := loadRecipient(ctx, qtx, patient)
+	if err != nil {
+		return nil, fmt.Errorf("load status notification recipient: %w", err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	var (
+		notificationType string
+		data             = notifications.MessageData{FirstName: patient.FirstName}
+	)
+
+	switch input.Status {
+	case model.DispenseStatusMissed:
+		notificationType = notifications.TypeMissedDose
+
+	case model.DispenseStatusCupAbsent:
+		notificationType = notifications.TypeCupAbsent
+
+	case model.DispenseStatusEmptySilo:
+		silo := int64(0)
+
+		items, err := qtx.ListScheduleItemsBySchedule(ctx, input.ScheduleID)
+		if err == nil && len(items) > 0 {
+			medication, err := qtx.GetMedication(ctx, items[0].MedicationID)
+			if err == nil && medication.CartridgeIndex.Valid {
+				silo = medication.CartridgeIndex.Int64
+			}
+		}
+
+		data.Silo = silo + 1
+		notificationType = notifications.TypeEmptySilo
+	}
+
+	message, err := notifications.RenderMessage(user.Locale, notificationType, notifications.ChannelSMS, data)
+	if err != nil {
+		return nil, fmt.Errorf("render status notification: %w", err)
+	}
+
+	result, err := dispatcher.Enqueue(ctx, notifications.Notification{
+		UserID:      user.ID,
+		PatientID:   patient.ID,
+		Type:        notificationType,
+		Destination: user.Phone.String,
+		Message:     message,
+		Timezone:    user.Timezone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queue status notification: %w", err)
+	}
+	return outboxIDs(result), nil
+}
+
+// decrementDispensedStock records a DISPENSE movement for every item of the
+// schedule and warns the caregiver when stock runs low.
+func decrementDispensedStock(ctx context.Context, qtx *db.Queries, dispatcher *notifications.Dispatcher, input model.DispenseActionInput, record db.DispenseEvent) ([]string, error) {
+	items, err := qtx.ListScheduleItemsBySchedule(ctx, input.ScheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("load schedule items for stock update: %w", err)
+	}
+
+	patient, err := qtx.GetPatient(ctx, input.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s for refill notification: %w", input.PatientID, err)
+	}
+	recipient, err := loadRecipient(ctx, qtx, patient)
+	if err != nil {
+		return nil, fmt.Errorf("load refill notification recipient: %w", err)
+	}
+
+	var queued []string
+	for _, item := range items {
+		medication, updated, _, err := applyStockMovement(ctx, qtx, stockChange{
+			MedicationID:    item.MedicationID,
+			Kind:            model.StockMovementKindDispense,
+			Quantity:        -item.Qty,
+			Actor:           input.ActionSource,
+			DispenseEventID: record.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		oldStock := medication.StockCount
+		newStock := updated.StockCount
+
+		// Low stock alerts once until the next refill; running out
+		// alerts again regardless.
+		threshold := medication.LowStockThreshold
+		newlyLow := newStock <= threshold && !medication.LowStockAlertedAt.Valid
+		justEmptied := oldStock > 0 && newStock == 0
+
+		if !newlyLow && !justEmptied {
+			continue
+		}
+		if err := qtx.MarkLowStockAlerted(ctx, db.MarkLowStockAlertedParams{
+			LowStockAlertedAt: sql.NullString{String: formatDBTime(time.Now()), Valid: true},
+			ID:                medication.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("mark low stock alerted %s: %w", medication.ID, err)
+		}
+		if recipient == nil {
+			continue
+		}
+
+		silo := int64(0)
+		if medication.CartridgeIndex.Valid {
+			silo = medication.CartridgeIndex.Int64
+		}
+
+		message, err := notifications.RenderMessage(recipient.Locale, notifications.TypeLowStock, notifications.ChannelSMS, notifications.MessageData{
+			FirstName: patient.FirstName,
+			Silo:      silo + 1,
+			Stock:     newStock,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("render refill notification: %w", err)
+		}
+
+		result, err := dispatcher.Enqueue(ctx, notifications.Notification{
+			UserID:      recipient.ID,
+			PatientID:   patient.ID,
+			Type:        notifications.TypeLowStock,
+			Destination: recipient.Phone.String,
+			Message:     message,
+			Timezone:    recipient.Timezone,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("queue refill notification: %w", err)
+		}
+		queued = append(queued, outboxIDs(result)...)
+	}
+	return queued, nil
+}
+
+func outboxIDs(result notifications.DispatchResult) []string {
+	if result.OutboxID == "" {
+		return nil
+	}
+	return []string{result.OutboxID}
+}
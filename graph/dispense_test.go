@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"pillbox/graph/model"
+	"pillbox/internal/notifications"
+)
+
+// sched_demo_morning doses one metformin (stock 60) and one atorvastatin
+// (stock 90).
+func dispenseInput(status model.DispenseStatus, dueAt time.Time, key string) model.DispenseActionInput {
+	input := model.DispenseActionInput{
+		PatientID:    "patient_demo_001",
+		ScheduleID:   "sched_demo_morning",
+		DueAtIso:     dueAt,
+		Status:       status,
+		ActionSource: ptrString("device"),
+	}
+	if key != "" {
+		input.IdempotencyKey = &key
+	}
+	return input
+}
+
+func stockCounts(t *testing.T, r *Resolver) (metformin, atorvastatin int64) {
+	t.Helper()
+	ctx := context.Background()
+	m, err := r.Queries.GetMedication(ctx, "med_demo_metformin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := r.Queries.GetMedication(ctx, "med_demo_atorvastatin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.StockCount, a.StockCount
+}
+
+func wantStock(t *testing.T, r *Resolver, metformin, atorvastatin int64) {
+	t.Helper()
+	gotM, gotA := stockCounts(t, r)
+	if gotM != metformin || gotA != atorvastatin {
+		t.Fatalf("stock = metformin %d, atorvastatin %d; want %d, %d", gotM, gotA, metformin, atorvastatin)
+	}
+}
+
+var testDueAt = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRecordDispenseReplayDecrementsOnce(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+
+	first, err := r.recordDispenseAction(ctx, dispenseInput(model.DispenseStatusTaken, testDueAt, "key-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := r.recordDispenseAction(ctx, dispenseInput(model.DispenseStatusTaken, testDueAt, "key-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.ID != first.ID {
+		t.Fatalf("replay returned event %s, want %s", replay.ID, first.ID)
+	}
+	wantStock(t, r, 59, 89)
+}
+
+func TestRecordDispenseRejectsConflictingReplay(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+
+	if _, err := r.recordDispenseAction(ctx, dispenseInput(model.DispenseStatusTaken, testDueAt, "key-1")); err != nil {
+		t.Fatal(err)
+	}
+	for name, input := range map[string]model.DispenseActionInput{
+		"status":   dispenseInput(model.DispenseStatusSkipped, testDueAt, "key-1"),
+		"due time": dispenseInput(model.DispenseStatusTaken, testDueAt.Add(24*time.Hour), "key-1"),
+	} {
+		_, err := r.recordDispenseAction(ctx, input)
+		if err == nil || !strings.Contains(err.Error(), "already used") {
+			t.Errorf("replay with a different %s: err = %v, want a conflict", name, err)
+		}
+	}
+	wantStock(t, r, 59, 89)
+}
+
+func TestRecordDispenseReplayAfterEventUpdate(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+
+	first, err := r.recordDispenseAction(ctx, dispenseInput(model.DispenseStatusCupAbsent, testDueAt, "key-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An SMS reply marks the dose taken without a key.
+	taken := dispenseInput(model.DispenseStatusTaken, testDueAt, "")
+	taken.EventID = &first.ID
+	taken.ActionSource = ptrString("SMS")
+	if _, err := r.recordDispenseAction(ctx, taken); err != nil {
+		t.Fatal(err)
+	}
+
+	// The device retries its original report after the update.
+	replay, err := r.recordDispenseAction(ctx, dispenseInput(model.DispenseStatusCupAbsent, testDueAt, "key-1"))
+	if err != nil {
+		t.Fatalf("replay after the event changed: %v", err)
+	}
+	if replay.ID != first.ID || replay.Status != model.DispenseStatusTaken {
+		t.Fatalf("replay = %s %s, want %s TAKEN untouched", replay.ID, replay.Status, first.ID)
+	}
+
+	// The same status reported again by a new attempt is a new action.
+	again := dispenseInput(model.DispenseStatusCupAbsent, testDueAt, "key-2")
+	again.EventID = &first.ID
+	updated, err := r.recordDispenseAction(ctx, again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != model.DispenseStatusCupAbsent {
+		t.Fatalf("status = %s, want CUP_ABSENT", updated.Status)
+	}
+	wantStock(t, r, 59, 89)
+}
+
+func TestRecordDispenseTakenSkippedTakenDecrementsOnce(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+
+	event, err := r.recordDispenseAction(ctx, dispenseInput(model.DispenseStatusTaken, testDueAt, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []model.DispenseStatus{model.DispenseStatusSkipped, model.DispenseStatusTaken, model.DispenseStatusTaken} {
+		input := dispenseInput(status, testDueAt, "")
+		input.EventID = &event.ID
+		if _, err := r.recordDispenseAction(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantStock(t, r, 59, 89)
+
+	lots, err := r.loadDispenseLots(ctx, event.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lots) != 2 {
+		t.Fatalf("event drew from %d lots, want one per medication", len(lots))
+	}
+}
+
+func TestRecordDispenseQueuesLowStockOnce(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+
+	// Metformin's threshold is 10.
+	if _, err := r.DB.Exec(`UPDATE medications SET stock_count = 11 WHERE id = 'med_demo_metformin'`); err != nil {
+		t.Fatal(err)
+	}
+	for day := 0; day < 3; day++ {
+		key := fmt.Sprintf("key-%d", day)
+		input := dispenseInput(model.DispenseStatusTaken, testDueAt.AddDate(0, 0, day), key)
+		if _, err := r.recordDispenseAction(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+		// A retry of the same report changes nothing.
+		if _, err := r.recordDispenseAction(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var queued int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM notification_outbox WHERE notification_type = ?`, notifications.TypeLowStock).Scan(&queued); err != nil {
+		t.Fatal(err)
+	}
+	if queued != 1 {
+		t.Fatalf("queued %d low-stock notifications, want 1", queued)
+	}
+	wantStock(t, r, 8, 87)
+}
@@ -3,10 +3,13 @@ package graph
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+
+	"pillbox/internal/notifications"
 )
 
 var timeLayouts = []string{
@@ -91,6 +94,18 @@ func ptrFromNullInt(ns sql.NullInt64) *int {
 	return nil
 }
 
+// nullClockFromPtr validates an optional HH:MM value, treating blank as unset.
+func nullClockFromPtr(val *string) (sql.NullString, error) {
+	if val == nil || strings.TrimSpace(*val) == "" {
+		return sql.NullString{}, nil
+	}
+	clock := strings.TrimSpace(*val)
+	if _, _, err := notifications.ParseClock(clock); err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: clock, Valid: true}, nil
+}
+
 func hashPassword(password string) (string, error) {
 	if password == "" {
 		return "", nil
@@ -109,44 +124,58 @@ func verifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
-func buildRefillMessage(firstName string, silo int64, stock int64) string {
-	siloNumber := silo + 1
-
-	if stock <= 0 {
-		return fmt.Sprintf(
-			"Hi %s, Silo #%d has run out of pills. Please refill at your earliest convenience.",
-			firstName,
-			siloNumber,
-		)
+// localeFromPtr validates an optional locale input, returning fallback when unset.
+func localeFromPtr(val *string, fallback string) (string, error) {
+	if val == nil || strings.TrimSpace(*val) == "" {
+		return fallback, nil
+	}
+	if !notifications.IsSupportedLocale(*val) {
+		return "", fmt.Errorf("unsupported locale %q (supported: %s)", *val, strings.Join(notifications.SupportedLocales(), ", "))
 	}
+	return notifications.NormalizeLocale(*val), nil
+}
 
-	return fmt.Sprintf(
-		"Hi %s, Silo #%d is running low on pills with %d remaining. Please refill soon.",
-		firstName,
-		siloNumber,
-		stock,
-	)
+// phoneFromPtr stores phone numbers in E.164 so inbound SMS can be matched
+// to users by exact comparison.
+func phoneFromPtr(val *string) (sql.NullString, error) {
+	if val == nil || strings.TrimSpace(*val) == "" {
+		return sql.NullString{}, nil
+	}
+	phone, err := notifications.NormalizePhone(*val)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: phone, Valid: true}, nil
 }
 
-func buildCupAbsentMessage(firstName string) string {
-	return fmt.Sprintf(
-		"Hi %s, DoseDock could not dispense medication because the cup was not in place. Please check the device.",
-		firstName,
-	)
+func leadTimeFromPtr(val *int, fallback int64) (int64, error) {
+	if val == nil {
+		return fallback, nil
+	}
+	if *val < 0 || *val > notifications.MaxPharmacyLeadTimeDays {
+		return 0, fmt.Errorf("pharmacy lead time must be between 0 and %d days", notifications.MaxPharmacyLeadTimeDays)
+	}
+	return int64(*val), nil
 }
 
-func buildMissedMedicationMessage(firstName string) string {
-	return fmt.Sprintf(
-		"Hi %s, a scheduled medication dose was missed. Please check on the patient.",
-		firstName,
-	)
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// languageCodeFromPtr validates an optional BCP-47 language code such as
+// "fr-CA"; empty values mean "follow the patient's locale".
+func languageCodeFromPtr(val *string) (sql.NullString, error) {
+	ns := nullTrimmedStringFromPtr(val)
+	if !ns.Valid {
+		return ns, nil
+	}
+	if !languageCodePattern.MatchString(ns.String) {
+		return sql.NullString{}, fmt.Errorf("invalid language code %q", ns.String)
+	}
+	return ns, nil
 }
 
-func buildEmptySiloMessage(firstName string, silo int64) string {
-	siloNumber := silo + 1
-	return fmt.Sprintf(
-		"Hi %s, Silo #%d could not dispense medication. Please check the device, the silo could be empty.",
-		firstName,
-		siloNumber,
-	)
-}
\ No newline at end of file
+func nullTrimmedStringFromPtr(val *string) sql.NullString {
+	if val == nil || strings.TrimSpace(*val) == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strings.TrimSpace(*val), Valid: true}
+}
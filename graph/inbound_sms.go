@@ -0,0 +1,248 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/notifications"
+)
+
+const (
+	// smsReplyWindow bounds how old a reminder can be and still be answered.
+	smsReplyWindow       = 12 * time.Hour
+	defaultSnoozeMinutes = 10
+	maxSnoozeMinutes     = 240
+)
+
+const (
+	smsCommandTaken  = "TAKEN"
+	smsCommandSkip   = "SKIP"
+	smsCommandSnooze = "SNOOZE"
+)
+
+// InboundSMSHandler handles Twilio inbound message webhooks so caregivers can
+// answer a reminder with TAKEN, SKIP or SNOOZE <minutes>.
+type InboundSMSHandler struct {
+	resolver  *Resolver
+	validator *notifications.TwilioRequestValidator
+}
+
+func NewInboundSMSHandler(resolver *Resolver, validator *notifications.TwilioRequestValidator) *InboundSMSHandler {
+	return &InboundSMSHandler{
+		resolver:  resolver,
+		validator: validator,
+	}
+}
+
+func (h *InboundSMSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.validator.Validate(r) {
+		http.Error(w, "invalid twilio signature", http.StatusForbidden)
+		return
+	}
+
+	reply, err := h.handleMessage(r.Context(), r.PostForm.Get("From"), r.PostForm.Get("Body"))
+	if err != nil {
+		log.Printf("inbound sms: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	notifications.WriteTwiML(w, reply)
+}
+
+func (h *InboundSMSHandler) handleMessage(ctx context.Context, from, body string) (string, error) {
+	r := h.resolver
+
+	user, found, err := h.findUserByPhone(ctx, from)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		// Unknown senders get no reply.
+		return "", nil
+	}
+
+	command, minutes, ok := parseSMSCommand(body)
+	if !ok {
+		return notifications.RenderMessage(user.Locale, notifications.ReplyHelp, notifications.ChannelSMS, notifications.MessageData{})
+	}
+
+	reminder, found, err := h.latestPendingReminder(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return notifications.RenderMessage(user.Locale, notifications.ReplyNoPending, notifications.ChannelSMS, notifications.MessageData{})
+	}
+
+	patient, err := r.Queries.GetPatient(ctx, reminder.PatientID)
+	if err != nil {
+		return "", fmt.Errorf("load patient %s: %w", reminder.PatientID, err)
+	}
+	dueAt, err := parseDBTime(reminder.DueAtIso)
+	if err != nil {
+		return "", err
+	}
+	loc, err := time.LoadLocation(patient.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	data := notifications.MessageData{
+		FirstName: patient.FirstName,
+		DueAt:     dueAt.In(loc),
+		Minutes:   minutes,
+	}
+	now := time.Now().UTC()
+
+	var replyType string
+	switch command {
+	case smsCommandTaken, smsCommandSkip:
+		status := model.DispenseStatusTaken
+		replyType = notifications.ReplyTaken
+		if command == smsCommandSkip {
+			status = model.DispenseStatusSkipped
+			replyType = notifications.ReplySkipped
+		}
+
+		input := model.DispenseActionInput{
+			PatientID:    reminder.PatientID,
+			ScheduleID:   reminder.ScheduleID,
+			DueAtIso:     dueAt,
+			ActedAtIso:   &now,
+			Status:       status,
+			ActionSource: ptrString("SMS"),
+		}
+		existing, err := r.Queries.GetDispenseEventByOccurrence(ctx, db.GetDispenseEventByOccurrenceParams{
+			PatientID:  reminder.PatientID,
+			ScheduleID: reminder.ScheduleID,
+			DueAtIso:   reminder.DueAtIso,
+		})
+		if err == nil {
+			input.EventID = &existing.ID
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("load dispense event: %w", err)
+		}
+
+		if _, err := r.recordDispenseAction(ctx, input); err != nil {
+			return "", fmt.Errorf("record sms reply: %w", err)
+		}
+
+		// Drop any snoozed copy of the reminder now that it has been answered.
+		if err := r.Queries.CancelQueuedOutboxNotifications(ctx, db.CancelQueuedOutboxNotificationsParams{
+			UserID:           user.ID,
+			PatientID:        reminder.PatientID,
+			NotificationType: notifications.TypeDoseReminder,
+		}); err != nil {
+			return "", fmt.Errorf("cancel snoozed reminders: %w", err)
+		}
+
+	case smsCommandSnooze:
+		replyType = notifications.ReplySnoozed
+		err := notifications.NewDispatcher(r.Queries, nil).Schedule(ctx, notifications.Notification{
+			UserID:      user.ID,
+			PatientID:   reminder.PatientID,
+			Type:        notifications.TypeDoseReminder,
+			Destination: reminder.Destination,
+			Message:     reminder.Message,
+			Timezone:    user.Timezone,
+		}, now.Add(time.Duration(minutes)*time.Minute))
+		if err != nil {
+			return "", fmt.Errorf("snooze reminder: %w", err)
+		}
+	}
+
+	return notifications.RenderMessage(user.Locale, replyType, notifications.ChannelSMS, data)
+}
+
+// findUserByPhone looks up the user whose stored E.164 number sent the
+// message. Twilio reports From in E.164 already; normalizing it again keeps
+// the lookup exact either way.
+func (h *InboundSMSHandler) findUserByPhone(ctx context.Context, phone string) (db.GetUserByPhoneRow, bool, error) {
+	normalized, err := notifications.NormalizePhone(phone)
+	if err != nil {
+		return db.GetUserByPhoneRow{}, false, nil
+	}
+
+	user, err := h.resolver.Queries.GetUserByPhone(ctx, sql.NullString{String: normalized, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.GetUserByPhoneRow{}, false, nil
+	}
+	if err != nil {
+		return db.GetUserByPhoneRow{}, false, fmt.Errorf("find user by phone: %w", err)
+	}
+	return user, true, nil
+}
+
+// latestPendingReminder returns the most recent reminder sent to the user
+// whose occurrence has not been resolved yet.
+func (h *InboundSMSHandler) latestPendingReminder(ctx context.Context, userID string) (db.NotificationEvent, bool, error) {
+	q := h.resolver.Queries
+
+	reminders, err := q.ListSentRemindersByUserSince(ctx, db.ListSentRemindersByUserSinceParams{
+		UserID:   sql.NullString{String: userID, Valid: true},
+		DueAtIso: formatDBTime(time.Now().Add(-smsReplyWindow)),
+	})
+	if err != nil {
+		return db.NotificationEvent{}, false, fmt.Errorf("list reminders: %w", err)
+	}
+
+	for _, reminder := range reminders {
+		event, err := q.GetDispenseEventByOccurrence(ctx, db.GetDispenseEventByOccurrenceParams{
+			PatientID:  reminder.PatientID,
+			ScheduleID: reminder.ScheduleID,
+			DueAtIso:   reminder.DueAtIso,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return reminder, true, nil
+		}
+		if err != nil {
+			return db.NotificationEvent{}, false, fmt.Errorf("load dispense event: %w", err)
+		}
+		if event.Status == string(model.DispenseStatusPending) {
+			return reminder, true, nil
+		}
+	}
+	return db.NotificationEvent{}, false, nil
+}
+
+// parseSMSCommand understands TAKEN, SKIP and SNOOZE [minutes] along with a
+// few common synonyms, ignoring case and punctuation.
+func parseSMSCommand(body string) (command string, minutes int, ok bool) {
+	fields := strings.Fields(strings.ToUpper(strings.Trim(strings.TrimSpace(body), ".!")))
+	if len(fields) == 0 {
+		return "", 0, false
+	}
+
+	switch fields[0] {
+	case "TAKEN", "TAKE", "TOOK", "DONE", "YES", "Y":
+		return smsCommandTaken, 0, true
+	case "SKIP", "SKIPPED", "NO", "N":
+		return smsCommandSkip, 0, true
+	case "SNOOZE", "LATER":
+		minutes = defaultSnoozeMinutes
+		if len(fields) > 1 {
+			parsed, err := strconv.Atoi(strings.TrimSuffix(fields[1], "M"))
+			if err != nil || parsed <= 0 {
+				return "", 0, false
+			}
+			minutes = min(parsed, maxSnoozeMinutes)
+		}
+		return smsCommandSnooze, minutes, true
+	default:
+		return "", 0, false
+	}
+}
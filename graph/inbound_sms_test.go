@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"pillbox/graph/model"
+)
+
+func TestParseSMSCommand(t *testing.T) {
+	tests := []struct {
+		body    string
+		command string
+		minutes int
+		ok      bool
+	}{
+		{body: "TAKEN", command: smsCommandTaken, ok: true},
+		{body: "  taken. ", command: smsCommandTaken, ok: true},
+		{body: "Yes!", command: smsCommandTaken, ok: true},
+		{body: "y", command: smsCommandTaken, ok: true},
+		{body: "took it", command: smsCommandTaken, ok: true},
+		{body: "skip", command: smsCommandSkip, ok: true},
+		{body: "No", command: smsCommandSkip, ok: true},
+		{body: "snooze", command: smsCommandSnooze, minutes: defaultSnoozeMinutes, ok: true},
+		{body: "SNOOZE 30", command: smsCommandSnooze, minutes: 30, ok: true},
+		{body: "later 15m", command: smsCommandSnooze, minutes: 15, ok: true},
+		{body: "snooze 1000", command: smsCommandSnooze, minutes: maxSnoozeMinutes, ok: true},
+		{body: "snooze 0"},
+		{body: "snooze -5"},
+		{body: "snooze soon"},
+		{body: ""},
+		{body: "   "},
+		{body: "hello"},
+		{body: "maybe taken"},
+	}
+	for _, tc := range tests {
+		command, minutes, ok := parseSMSCommand(tc.body)
+		if command != tc.command || minutes != tc.minutes || ok != tc.ok {
+			t.Errorf("parseSMSCommand(%q) = %q, %d, %v, want %q, %d, %v", tc.body, command, minutes, ok, tc.command, tc.minutes, tc.ok)
+		}
+	}
+}
+
+func TestFindUserByPhone(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+	h := NewInboundSMSHandler(r, nil)
+
+	phone := "(555) 123-4567"
+	created, err := (&mutationResolver{r}).UpsertUser(ctx, model.UserInput{
+		Email:    "phone@example.com",
+		FullName: "Phone Test",
+		Phone:    &phone,
+		Timezone: "America/New_York",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Phone == nil || *created.Phone != "+15551234567" {
+		t.Fatalf("stored phone = %v, want +15551234567", created.Phone)
+	}
+
+	tests := []struct {
+		from string
+		want string
+	}{
+		{from: "+15551234567", want: created.ID},
+		{from: "5551234567", want: created.ID},
+		{from: "+1 555 123 4567", want: created.ID},
+		// The seed stored "+1-555-1000"; the migration normalized it.
+		{from: "+15551000", want: "user_demo_caregiver"},
+		{from: "+15559999999"},
+		{from: "unknown"},
+		{from: ""},
+	}
+	for _, tc := range tests {
+		user, found, err := h.findUserByPhone(ctx, tc.from)
+		if err != nil {
+			t.Fatalf("findUserByPhone(%q): %v", tc.from, err)
+		}
+		if found != (tc.want != "") || user.ID != tc.want {
+			t.Errorf("findUserByPhone(%q) = %q, %v, want %q", tc.from, user.ID, found, tc.want)
+		}
+	}
+
+	bad := "555-1234"
+	if _, err := (&mutationResolver{r}).UpsertUser(ctx, model.UserInput{
+		Email:    "bad@example.com",
+		FullName: "Bad Phone",
+		Phone:    &bad,
+		Timezone: "America/New_York",
+	}); err == nil {
+		t.Fatal("UpsertUser accepted a phone number without a country code")
+	}
+}
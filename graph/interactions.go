@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"pillbox/graph/model"
+	"pillbox/internal/catalog"
+	"pillbox/internal/db"
+)
+
+// loadInteractionWarnings checks the patient's catalog-linked medications
+// and keeps the warnings involving at least one medication accepted by
+// include; a nil include keeps them all.
+func (r *Resolver) loadInteractionWarnings(ctx context.Context, patientID string, include func(medicationID string) bool) ([]*model.InteractionWarning, error) {
+	rows, err := r.Queries.ListMedicationIngredientsByPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("list medication ingredients: %w", err)
+	}
+
+	byID := make(map[string]db.Medication, len(rows))
+	checked := make([]catalog.Medication, 0, len(rows))
+	for _, row := range rows {
+		byID[row.Medication.ID] = row.Medication
+		checked = append(checked, catalog.Medication{
+			ID:         row.Medication.ID,
+			Ingredient: row.Ingredient,
+		})
+	}
+
+	warnings, err := catalog.CheckInteractions(checked)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.InteractionWarning, 0, len(warnings))
+	for _, warning := range warnings {
+		if include != nil && !include(warning.Medications[0]) && !include(warning.Medications[1]) {
+			continue
+		}
+		item, err := buildInteractionWarningModel(warning, byID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (r *Resolver) medicationInteractionWarnings(ctx context.Context, medication *model.Medication) ([]*model.InteractionWarning, error) {
+	if medication.CatalogID == nil {
+		return []*model.InteractionWarning{}, nil
+	}
+	return r.loadInteractionWarnings(ctx, medication.PatientID, func(id string) bool {
+		return id == medication.ID
+	})
+}
+
+func (r *Resolver) scheduleInteractionWarnings(ctx context.Context, schedule *model.Schedule) ([]*model.InteractionWarning, error) {
+	inSchedule := make(map[string]bool, len(schedule.Items))
+	for _, item := range schedule.Items {
+		if item.Medication != nil && item.Medication.CatalogID != nil {
+			inSchedule[item.Medication.ID] = true
+		}
+	}
+	if len(inSchedule) == 0 {
+		return []*model.InteractionWarning{}, nil
+	}
+	return r.loadInteractionWarnings(ctx, schedule.PatientID, func(id string) bool {
+		return inSchedule[id]
+	})
+}
+
+func buildInteractionWarningModel(warning catalog.Warning, medications map[string]db.Medication) (*model.InteractionWarning, error) {
+	meds := make([]*model.Medication, 0, len(warning.Medications))
+	for _, id := range warning.Medications {
+		med, err := buildMedicationModel(medications[id])
+		if err != nil {
+			return nil, err
+		}
+		meds = append(meds, med)
+	}
+
+	return &model.InteractionWarning{
+		Kind:        model.InteractionKind(warning.Kind),
+		Severity:    model.InteractionSeverity(warning.Severity),
+		Medications: meds,
+		Ingredients: warning.Ingredients[:],
+		Description: warning.Description,
+	}, nil
+}
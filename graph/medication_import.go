@@ -0,0 +1,569 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/fhir"
+	"pillbox/internal/notifications"
+)
+
+const (
+	// Prescriptions say nothing about how long a cup may wait, so imported
+	// schedules get the usual hour.
+	importLockoutMinutes = 60
+	// An imported schedule matches an existing one when both dispense the
+	// same items at the same times over this many days.
+	importMatchDays = 14
+)
+
+// importPlan is what an import would do to the patient's regimen.
+type importPlan struct {
+	patient     db.Patient
+	loc         *time.Location
+	medications []*importMedication
+	schedules   []*importSchedule
+	skipped     []*model.ImportIssue
+	warnings    []*model.ImportIssue
+}
+
+type importMedication struct {
+	name      string
+	label     string
+	catalogID string
+	// Set when the patient already has the medication, and once created.
+	id       string
+	existing bool
+	// Most units a day across the imported schedules, for maxDailyDose.
+	maxDaily int
+}
+
+type importSchedule struct {
+	rule    fhir.ImportedRule
+	status  model.ScheduleStatus
+	title   string
+	items   []importItem
+	sources []string
+	// Set when the patient already has the schedule, and once created.
+	id       string
+	existing bool
+}
+
+type importItem struct {
+	medication *importMedication
+	qty        int
+}
+
+func (r *Resolver) importMedicationRequests(ctx context.Context, patientID, bundle string, confirm *string) (*model.MedicationImport, error) {
+	now := time.Now()
+	plan, err := r.planMedicationImport(ctx, patientID, bundle, now)
+	if err != nil {
+		return nil, err
+	}
+	token := plan.token()
+	if confirm == nil || strings.TrimSpace(*confirm) == "" {
+		return plan.model(true, token), nil
+	}
+	if strings.TrimSpace(*confirm) != token {
+		return nil, fmt.Errorf("the import changed since it was previewed; preview it again and confirm the new plan")
+	}
+	if len(plan.schedules) == 0 {
+		return nil, fmt.Errorf("the bundle has nothing to import")
+	}
+
+	err = r.withTx(ctx, func(qtx *db.Queries) error {
+		for _, med := range plan.medications {
+			if med.existing {
+				continue
+			}
+			created, err := qtx.CreateMedication(ctx, db.CreateMedicationParams{
+				ID:                uuid.NewString(),
+				PatientID:         plan.patient.ID,
+				Label:             med.label,
+				MaxDailyDose:      int64(max(med.maxDaily, 1)),
+				LowStockThreshold: 0,
+			})
+			if err != nil {
+				return fmt.Errorf("create medication %s: %w", med.name, err)
+			}
+			if med.catalogID != "" {
+				if _, err := linkCatalogEntry(ctx, qtx, created.ID, med.catalogID); err != nil {
+					return err
+				}
+			}
+			med.id = created.ID
+		}
+
+		for _, sched := range plan.schedules {
+			if sched.existing {
+				continue
+			}
+			created, err := qtx.CreateSchedule(ctx, db.CreateScheduleParams{
+				ID:             uuid.NewString(),
+				PatientID:      plan.patient.ID,
+				Title:          sched.title,
+				Timezone:       plan.patient.Timezone,
+				Rrule:          sched.rule.RRule,
+				StartDateIso:   formatDBTime(sched.rule.Start),
+				EndDateIso:     formatNullableTimePtr(sched.rule.End),
+				LockoutMinutes: importLockoutMinutes,
+				Status:         string(sched.status),
+			})
+			if err != nil {
+				return fmt.Errorf("create schedule %s: %w", sched.title, err)
+			}
+			for _, item := range sched.items {
+				if _, err := qtx.CreateScheduleItem(ctx, db.CreateScheduleItemParams{
+					ID:           uuid.NewString(),
+					ScheduleID:   created.ID,
+					MedicationID: item.medication.id,
+					Qty:          int64(item.qty),
+				}); err != nil {
+					return fmt.Errorf("create schedule item: %w", err)
+				}
+			}
+			sched.id = created.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import medication requests: %w", err)
+	}
+	return plan.model(false, token), nil
+}
+
+// planMedicationImport translates the bundle and matches it against the
+// patient's medications and schedules without writing anything.
+func (r *Resolver) planMedicationImport(ctx context.Context, patientID, bundle string, now time.Time) (*importPlan, error) {
+	patient, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s: %w", patientID, err)
+	}
+	plan := &importPlan{patient: patient, loc: notifications.PatientLocation(patient.Timezone)}
+
+	requests, skipped, err := fhir.ParseMedicationRequestBundle([]byte(bundle), plan.loc, now)
+	if err != nil {
+		return nil, fmt.Errorf("read bundle: %w", err)
+	}
+	for _, issue := range skipped {
+		plan.skipped = append(plan.skipped, &model.ImportIssue{Source: issue.Source, Message: issue.Message})
+	}
+
+	existingMeds, err := r.Queries.ListMedicationsByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list medications: %w", err)
+	}
+	codeSystem := fhir.MedicationCodeSystemFromEnv()
+	medications := make(map[string]*importMedication)
+	schedules := make(map[string]*importSchedule)
+
+	for _, request := range requests {
+		for _, warning := range request.Warnings {
+			plan.warnings = append(plan.warnings, &model.ImportIssue{Source: request.Source, Message: warning})
+		}
+
+		catalogID, err := r.importCatalogID(ctx, request, codeSystem, plan)
+		if err != nil {
+			return nil, err
+		}
+		key := "name:" + strings.ToLower(request.Name)
+		if catalogID != "" {
+			key = "catalog:" + catalogID
+		}
+		med, ok := medications[key]
+		if !ok {
+			med = &importMedication{name: request.Name, catalogID: catalogID}
+			matchExistingMedication(med, existingMeds)
+			medications[key] = med
+			plan.medications = append(plan.medications, med)
+		}
+
+		status := model.ScheduleStatusActive
+		if request.OnHold {
+			status = model.ScheduleStatusPaused
+		}
+		for _, dosage := range request.Dosages {
+			for _, rule := range dosage.Rules {
+				key := strings.Join([]string{rule.RRule, rule.Start.Format(time.RFC3339), formatNullableTimePtr(rule.End).String, string(status)}, "|")
+				sched, ok := schedules[key]
+				if !ok {
+					sched = &importSchedule{rule: rule, status: status}
+					schedules[key] = sched
+					plan.schedules = append(plan.schedules, sched)
+				}
+				sched.add(med, dosage.Qty, request.Source)
+			}
+		}
+	}
+
+	labels := make(map[string]bool)
+	for _, med := range existingMeds {
+		labels[strings.ToLower(med.Label)] = true
+	}
+	for _, med := range plan.medications {
+		if med.existing {
+			continue
+		}
+		med.label = importLabel(med.name, patient.ShowMedicationNames != 0, labels)
+		if med.catalogID == "" && med.label != med.name {
+			plan.warnings = append(plan.warnings, &model.ImportIssue{
+				Source:  med.name,
+				Message: fmt.Sprintf("not in the medication catalog and names are hidden, so it is labelled %s; relabel it, as a later import cannot recognise it", med.label),
+			})
+		}
+	}
+
+	for _, sched := range plan.schedules {
+		sched.title = importScheduleTitle(sched.rule.RRule)
+	}
+	if err := plan.dailyDoses(now); err != nil {
+		return nil, err
+	}
+	if err := r.matchExistingSchedules(ctx, plan, now); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// importCatalogID finds the catalog entry for the request's coding in the
+// configured medication code system, if the catalog has it.
+func (r *Resolver) importCatalogID(ctx context.Context, request fhir.ImportedRequest, codeSystem string, plan *importPlan) (string, error) {
+	if codeSystem == "" {
+		return "", nil
+	}
+	for _, coding := range request.Codings {
+		if coding.System != codeSystem || strings.TrimSpace(coding.Code) == "" {
+			continue
+		}
+		entry, err := r.Queries.GetCatalogEntry(ctx, strings.TrimSpace(coding.Code))
+		if errors.Is(err, sql.ErrNoRows) {
+			plan.warnings = append(plan.warnings, &model.ImportIssue{
+				Source:  request.Source,
+				Message: fmt.Sprintf("code %s is not in the medication catalog; the medication is not linked", coding.Code),
+			})
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("load catalog entry %s: %w", coding.Code, err)
+		}
+		return entry.ID, nil
+	}
+	return "", nil
+}
+
+// matchExistingMedication marks med as one of the patient's medications,
+// found by catalog entry or else by a label equal to its name.
+func matchExistingMedication(med *importMedication, existing []db.Medication) {
+	if med.catalogID != "" {
+		for _, candidate := range existing {
+			if candidate.CatalogID.Valid && candidate.CatalogID.String == med.catalogID {
+				med.id, med.label, med.existing = candidate.ID, candidate.Label, true
+				return
+			}
+		}
+	}
+	for _, candidate := range existing {
+		if strings.EqualFold(strings.TrimSpace(candidate.Label), med.name) {
+			med.id, med.label, med.existing = candidate.ID, candidate.Label, true
+			return
+		}
+	}
+}
+
+// importLabel names a new medication on the dispenser: the drug name when
+// the patient shows names, otherwise "Imported N" like the anonymised silo
+// labels. taken collects lowercased labels already in use.
+func importLabel(name string, showNames bool, taken map[string]bool) string {
+	label := name
+	if !showNames || taken[strings.ToLower(label)] {
+		for n := 1; ; n++ {
+			if showNames {
+				label = fmt.Sprintf("%s (%d)", name, n+1)
+			} else {
+				label = fmt.Sprintf("Imported %d", n)
+			}
+			if !taken[strings.ToLower(label)] {
+				break
+			}
+		}
+	}
+	taken[strings.ToLower(label)] = true
+	return label
+}
+
+func (s *importSchedule) add(med *importMedication, qty int, source string) {
+	found := false
+	for i := range s.items {
+		if s.items[i].medication == med {
+			s.items[i].qty += qty
+			found = true
+		}
+	}
+	if !found {
+		s.items = append(s.items, importItem{medication: med, qty: qty})
+	}
+	for _, existing := range s.sources {
+		if existing == source {
+			return
+		}
+	}
+	s.sources = append(s.sources, source)
+}
+
+// dailyDoses sets each medication's maxDaily to its busiest day across the
+// imported schedules over the coming weeks.
+func (p *importPlan) dailyDoses(now time.Time) error {
+	perDay := make(map[*importMedication]map[string]int)
+	for _, sched := range p.schedules {
+		occurrences, err := notifications.ScheduleOccurrences(sched.dbSchedule(p.patient), now, now.AddDate(0, 0, importMatchDays), p.loc)
+		if err != nil {
+			return fmt.Errorf("expand %s: %w", sched.rule.RRule, err)
+		}
+		for _, at := range occurrences {
+			day := at.In(p.loc).Format(time.DateOnly)
+			for _, item := range sched.items {
+				if perDay[item.medication] == nil {
+					perDay[item.medication] = make(map[string]int)
+				}
+				perDay[item.medication][day] += item.qty
+				item.medication.maxDaily = max(item.medication.maxDaily, perDay[item.medication][day])
+			}
+		}
+	}
+	return nil
+}
+
+func (s *importSchedule) dbSchedule(patient db.Patient) db.Schedule {
+	return db.Schedule{
+		PatientID:    patient.ID,
+		Timezone:     patient.Timezone,
+		Rrule:        s.rule.RRule,
+		StartDateIso: formatDBTime(s.rule.Start),
+		EndDateIso:   formatNullableTimePtr(s.rule.End),
+		Status:       string(s.status),
+	}
+}
+
+// matchExistingSchedules marks planned schedules the patient already has:
+// an unarchived schedule with the same status and items whose doses fall at
+// the same times, so importing the same bundle twice adds nothing.
+func (r *Resolver) matchExistingSchedules(ctx context.Context, plan *importPlan, now time.Time) error {
+	existing, err := r.Queries.ListSchedulesByPatient(ctx, plan.patient.ID)
+	if err != nil {
+		return fmt.Errorf("list schedules: %w", err)
+	}
+	horizon := now.AddDate(0, 0, importMatchDays)
+	used := make(map[string]bool)
+
+	for _, sched := range plan.schedules {
+		wantItems := make(map[string]int)
+		for _, item := range sched.items {
+			if !item.medication.existing {
+				wantItems = nil
+				break
+			}
+			wantItems[item.medication.id] += item.qty
+		}
+		if wantItems == nil {
+			continue
+		}
+		want, err := notifications.ScheduleOccurrences(sched.dbSchedule(plan.patient), now, horizon, plan.loc)
+		if err != nil {
+			return fmt.Errorf("expand %s: %w", sched.rule.RRule, err)
+		}
+
+		for _, candidate := range existing {
+			if used[candidate.ID] || candidate.Status != string(sched.status) {
+				continue
+			}
+			items, err := r.Queries.ListScheduleItemsBySchedule(ctx, candidate.ID)
+			if err != nil {
+				return fmt.Errorf("list schedule items for %s: %w", candidate.ID, err)
+			}
+			haveItems := make(map[string]int)
+			for _, item := range items {
+				haveItems[item.MedicationID] += int(item.Qty)
+			}
+			if !sameQuantities(wantItems, haveItems) {
+				continue
+			}
+			have, err := notifications.ScheduleOccurrences(candidate, now, horizon, notifications.PatientLocation(candidate.Timezone))
+			if err != nil || !sameTimes(want, have) {
+				continue
+			}
+			sched.id, sched.existing = candidate.ID, true
+			used[candidate.ID] = true
+			break
+		}
+	}
+	return nil
+}
+
+func sameQuantities(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, qty := range a {
+		if b[id] != qty {
+			return false
+		}
+	}
+	return true
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// importScheduleTitle describes a translated rule, e.g. "Doses at 08:00,
+// 20:00" or "Weekly at 09:30 (SU)".
+func importScheduleTitle(rule string) string {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(strings.TrimPrefix(rule, "RRULE:"), ";") {
+		name, value, _ := strings.Cut(part, "=")
+		parts[name] = value
+	}
+	if parts["FREQ"] == "HOURLY" {
+		return fmt.Sprintf("Every %s hours", parts["INTERVAL"])
+	}
+
+	var times []string
+	minute, _ := strconv.Atoi(parts["BYMINUTE"])
+	for _, hour := range strings.Split(parts["BYHOUR"], ",") {
+		h, err := strconv.Atoi(hour)
+		if err != nil {
+			continue
+		}
+		times = append(times, fmt.Sprintf("%02d:%02d", h, minute))
+	}
+	sort.Strings(times)
+
+	var title string
+	switch {
+	case len(times) == 0:
+		title = map[string]string{"DAILY": "Daily", "WEEKLY": "Weekly", "MONTHLY": "Monthly", "YEARLY": "Yearly"}[parts["FREQ"]]
+		if title == "" {
+			title = "Imported schedule"
+		}
+	case parts["FREQ"] == "WEEKLY":
+		title = "Weekly at " + strings.Join(times, ", ")
+	case parts["FREQ"] == "MONTHLY":
+		title = "Monthly at " + strings.Join(times, ", ")
+	case parts["FREQ"] == "YEARLY":
+		title = "Yearly at " + strings.Join(times, ", ")
+	default:
+		title = "Doses at " + strings.Join(times, ", ")
+		if len(times) == 1 {
+			title = "Dose at " + times[0]
+		}
+	}
+	if interval := parts["INTERVAL"]; interval != "" {
+		title += fmt.Sprintf(" every %s %s", interval, map[string]string{
+			"DAILY": "days", "WEEKLY": "weeks", "MONTHLY": "months", "YEARLY": "years",
+		}[parts["FREQ"]])
+	}
+	if days := parts["BYDAY"]; days != "" {
+		title += " (" + days + ")"
+	}
+	return title
+}
+
+// token fingerprints the plan so a confirmation applies exactly what was
+// previewed. Starts count to the day: a schedule that starts "now" moves
+// between preview and confirm, but a preview from yesterday is stale.
+func (p *importPlan) token() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", p.patient.ID)
+	for _, med := range p.medications {
+		fmt.Fprintf(&b, "med|%s|%s|%s|%s|%t\n", med.name, med.label, med.catalogID, med.id, med.existing)
+	}
+	for _, sched := range p.schedules {
+		end := ""
+		if sched.rule.End != nil {
+			end = sched.rule.End.Format(time.RFC3339)
+		}
+		fmt.Fprintf(&b, "sched|%s|%s|%s|%s|%s|%t\n", sched.rule.RRule, sched.rule.Start.Format(time.DateOnly), end, sched.status, sched.id, sched.existing)
+		for _, item := range sched.items {
+			fmt.Fprintf(&b, "item|%s|%d\n", item.medication.name, item.qty)
+		}
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (p *importPlan) model(dryRun bool, token string) *model.MedicationImport {
+	result := &model.MedicationImport{
+		PatientID:         p.patient.ID,
+		DryRun:            dryRun,
+		ConfirmationToken: token,
+		Medications:       []*model.ImportedMedication{},
+		Schedules:         []*model.ImportedSchedule{},
+		Skipped:           p.skipped,
+		Warnings:          p.warnings,
+	}
+	if result.Skipped == nil {
+		result.Skipped = []*model.ImportIssue{}
+	}
+	if result.Warnings == nil {
+		result.Warnings = []*model.ImportIssue{}
+	}
+
+	action := func(existing bool) model.ImportAction {
+		if existing {
+			return model.ImportActionExisting
+		}
+		return model.ImportActionCreate
+	}
+	for _, med := range p.medications {
+		result.Medications = append(result.Medications, &model.ImportedMedication{
+			Action:       action(med.existing),
+			MedicationID: ptrString(med.id),
+			Name:         med.name,
+			Label:        med.label,
+			CatalogID:    ptrString(med.catalogID),
+		})
+	}
+	for _, sched := range p.schedules {
+		items := make([]*model.ImportedScheduleItem, 0, len(sched.items))
+		for _, item := range sched.items {
+			items = append(items, &model.ImportedScheduleItem{Name: item.medication.name, Qty: item.qty})
+		}
+		var end *time.Time
+		if sched.rule.End != nil {
+			t := sched.rule.End.UTC()
+			end = &t
+		}
+		result.Schedules = append(result.Schedules, &model.ImportedSchedule{
+			Action:       action(sched.existing),
+			ScheduleID:   ptrString(sched.id),
+			Title:        sched.title,
+			Rrule:        sched.rule.RRule,
+			StartDateIso: sched.rule.Start.UTC(),
+			EndDateIso:   end,
+			Status:       sched.status,
+			Items:        items,
+			Sources:      sched.sources,
+		})
+	}
+	return result
+}
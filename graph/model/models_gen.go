@@ -8,21 +8,66 @@ import (
 	"io"
 	"strconv"
 	"time"
+
+	"github.com/99designs/gqlgen/graphql"
 )
 
+type AdherencePeriod struct {
+	Start time.Time       `json:"start"`
+	End   time.Time       `json:"end"`
+	Stats *AdherenceStats `json:"stats"`
+}
+
+type AdherenceReport struct {
+	PatientID     string                 `json:"patientId"`
+	Start         time.Time              `json:"start"`
+	End           time.Time              `json:"end"`
+	Timezone      string                 `json:"timezone"`
+	GroupBy       AdherenceGroupBy       `json:"groupBy"`
+	OnTimeMinutes int                    `json:"onTimeMinutes"`
+	Overall       *AdherenceStats        `json:"overall"`
+	Periods       []*AdherencePeriod     `json:"periods"`
+	Schedules     []*ScheduleAdherence   `json:"schedules"`
+	Medications   []*MedicationAdherence `json:"medications"`
+	MissesByHour  []*HourlyMisses        `json:"missesByHour"`
+}
+
+type AdherenceStats struct {
+	Scheduled        int      `json:"scheduled"`
+	Taken            int      `json:"taken"`
+	Missed           int      `json:"missed"`
+	Skipped          int      `json:"skipped"`
+	Pending          int      `json:"pending"`
+	AdherenceRate    *float64 `json:"adherenceRate,omitempty"`
+	OnTime           int      `json:"onTime"`
+	OnTimeRate       *float64 `json:"onTimeRate,omitempty"`
+	MeanDelayMinutes *float64 `json:"meanDelayMinutes,omitempty"`
+	LongestStreak    int      `json:"longestStreak"`
+}
+
+type CatalogEntry struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Ingredient string  `json:"ingredient"`
+	Strength   *string `json:"strength,omitempty"`
+	Unit       *string `json:"unit,omitempty"`
+	DosageForm *string `json:"dosageForm,omitempty"`
+}
+
 type DateRangeInput struct {
 	Start time.Time `json:"start"`
 	End   time.Time `json:"end"`
 }
 
 type DispenseActionInput struct {
-	EventID      *string        `json:"eventId,omitempty"`
-	PatientID    string         `json:"patientId"`
-	ScheduleID   string         `json:"scheduleId"`
-	DueAtIso     time.Time      `json:"dueAtISO"`
-	ActedAtIso   *time.Time     `json:"actedAtISO,omitempty"`
-	Status       DispenseStatus `json:"status"`
-	ActionSource *string        `json:"actionSource,omitempty"`
+	EventID        *string        `json:"eventId,omitempty"`
+	PatientID      string         `json:"patientId"`
+	ScheduleID     string         `json:"scheduleId"`
+	DueAtIso       time.Time      `json:"dueAtISO"`
+	ActedAtIso     *time.Time     `json:"actedAtISO,omitempty"`
+	Status         DispenseStatus `json:"status"`
+	ActionSource   *string        `json:"actionSource,omitempty"`
+	IdempotencyKey *string        `json:"idempotencyKey,omitempty"`
 }
 
 type DispenseEvent struct {
@@ -62,22 +107,98 @@ type DueSchedule struct {
 	Medications []*DueMedication `json:"medications"`
 }
 
+type HourlyMisses struct {
+	Hour      int `json:"hour"`
+	Scheduled int `json:"scheduled"`
+	Missed    int `json:"missed"`
+}
+
+type ImportIssue struct {
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+type ImportedMedication struct {
+	Action       ImportAction `json:"action"`
+	MedicationID *string      `json:"medicationId,omitempty"`
+	Name         string       `json:"name"`
+	Label        string       `json:"label"`
+	CatalogID    *string      `json:"catalogId,omitempty"`
+}
+
+type ImportedSchedule struct {
+	Action       ImportAction            `json:"action"`
+	ScheduleID   *string                 `json:"scheduleId,omitempty"`
+	Title        string                  `json:"title"`
+	Rrule        string                  `json:"rrule"`
+	StartDateIso time.Time               `json:"startDateISO"`
+	EndDateIso   *time.Time              `json:"endDateISO,omitempty"`
+	Status       ScheduleStatus          `json:"status"`
+	Items        []*ImportedScheduleItem `json:"items"`
+	Sources      []string                `json:"sources"`
+}
+
+type ImportedScheduleItem struct {
+	Name string `json:"name"`
+	Qty  int    `json:"qty"`
+}
+
+type InteractionWarning struct {
+	Kind        InteractionKind     `json:"kind"`
+	Severity    InteractionSeverity `json:"severity"`
+	Medications []*Medication       `json:"medications"`
+	Ingredients []string            `json:"ingredients"`
+	Description string              `json:"description"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type LotConsumption struct {
+	Lot             *MedicationLot `json:"lot"`
+	StockMovementID string         `json:"stockMovementId"`
+	Quantity        int            `json:"quantity"`
+}
+
 type Medication struct {
-	ID                string    `json:"id"`
-	PatientID         string    `json:"patientId"`
-	Label             *string   `json:"label,omitempty"`
-	Color             *string   `json:"color,omitempty"`
-	StockCount        int       `json:"stockCount"`
-	LowStockThreshold int       `json:"lowStockThreshold"`
-	CartridgeIndex    *int      `json:"cartridgeIndex,omitempty"`
-	MaxDailyDose      int       `json:"maxDailyDose"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	ID                  string                `json:"id"`
+	PatientID           string                `json:"patientId"`
+	Label               *string               `json:"label,omitempty"`
+	Color               *string               `json:"color,omitempty"`
+	StockCount          int                   `json:"stockCount"`
+	LowStockThreshold   int                   `json:"lowStockThreshold"`
+	CartridgeIndex      *int                  `json:"cartridgeIndex,omitempty"`
+	MaxDailyDose        int                   `json:"maxDailyDose"`
+	CatalogID           *string               `json:"catalogId,omitempty"`
+	InteractionWarnings []*InteractionWarning `json:"interactionWarnings"`
+	Prescriptions       []*Prescription       `json:"prescriptions"`
+	CreatedAt           time.Time             `json:"createdAt"`
+	UpdatedAt           time.Time             `json:"updatedAt"`
+}
+
+type MedicationAdherence struct {
+	Medication *Medication     `json:"medication"`
+	Stats      *AdherenceStats `json:"stats"`
+}
+
+type MedicationForecast struct {
+	Medication       *Medication `json:"medication"`
+	DailyConsumption float64     `json:"dailyConsumption"`
+	DaysOfSupply     *float64    `json:"daysOfSupply,omitempty"`
+	RunOutAt         *time.Time  `json:"runOutAt,omitempty"`
+	RefillBy         *time.Time  `json:"refillBy,omitempty"`
+}
+
+type MedicationImport struct {
+	PatientID         string                `json:"patientId"`
+	DryRun            bool                  `json:"dryRun"`
+	ConfirmationToken string                `json:"confirmationToken"`
+	Medications       []*ImportedMedication `json:"medications"`
+	Schedules         []*ImportedSchedule   `json:"schedules"`
+	Skipped           []*ImportIssue        `json:"skipped"`
+	Warnings          []*ImportIssue        `json:"warnings"`
 }
 
 type MedicationInput struct {
@@ -89,47 +210,241 @@ type MedicationInput struct {
 	LowStockThreshold *int    `json:"lowStockThreshold,omitempty"`
 	CartridgeIndex    *int    `json:"cartridgeIndex,omitempty"`
 	MaxDailyDose      *int    `json:"maxDailyDose,omitempty"`
+	CatalogID         *string `json:"catalogId,omitempty"`
+}
+
+type MedicationLot struct {
+	ID                string    `json:"id"`
+	MedicationID      string    `json:"medicationId"`
+	LotNumber         *string   `json:"lotNumber,omitempty"`
+	QuantityLoaded    int       `json:"quantityLoaded"`
+	QuantityRemaining int       `json:"quantityRemaining"`
+	ExpiresOn         *string   `json:"expiresOn,omitempty"`
+	Silo              *int      `json:"silo,omitempty"`
+	LoadedAt          time.Time `json:"loadedAt"`
 }
 
 type Mutation struct {
 }
 
+type NotificationEvent struct {
+	ID                string                      `json:"id"`
+	PatientID         string                      `json:"patientId"`
+	ScheduleID        string                      `json:"scheduleId"`
+	UserID            *string                     `json:"userId,omitempty"`
+	DueAtIso          time.Time                   `json:"dueAtISO"`
+	Channel           NotificationChannel         `json:"channel"`
+	Destination       string                      `json:"destination"`
+	Message           string                      `json:"message"`
+	Status            NotificationStatus          `json:"status"`
+	ProviderMessageID *string                     `json:"providerMessageId,omitempty"`
+	DeliveryStatus    *NotificationDeliveryStatus `json:"deliveryStatus,omitempty"`
+	ErrorCode         *string                     `json:"errorCode,omitempty"`
+	ErrorMessage      *string                     `json:"errorMessage,omitempty"`
+	DeliveryUpdatedAt *time.Time                  `json:"deliveryUpdatedAt,omitempty"`
+	CreatedAt         time.Time                   `json:"createdAt"`
+}
+
+type NotificationEventPage struct {
+	Items      []*NotificationEvent `json:"items"`
+	TotalCount int                  `json:"totalCount"`
+	HasMore    bool                 `json:"hasMore"`
+}
+
+type NotificationPreference struct {
+	ID              string                   `json:"id"`
+	UserID          string                   `json:"userId"`
+	Type            NotificationType         `json:"type"`
+	Channel         NotificationChannel      `json:"channel"`
+	Enabled         bool                     `json:"enabled"`
+	QuietHoursStart *string                  `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd   *string                  `json:"quietHoursEnd,omitempty"`
+	DeliveryMode    NotificationDeliveryMode `json:"deliveryMode"`
+	DigestTime      string                   `json:"digestTime"`
+	CreatedAt       time.Time                `json:"createdAt"`
+	UpdatedAt       time.Time                `json:"updatedAt"`
+}
+
+type NotificationPreferenceInput struct {
+	UserID          string                    `json:"userId"`
+	Type            NotificationType          `json:"type"`
+	Channel         *NotificationChannel      `json:"channel,omitempty"`
+	Enabled         bool                      `json:"enabled"`
+	QuietHoursStart *string                   `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd   *string                   `json:"quietHoursEnd,omitempty"`
+	DeliveryMode    *NotificationDeliveryMode `json:"deliveryMode,omitempty"`
+	DigestTime      *string                   `json:"digestTime,omitempty"`
+}
+
+type NotificationPreview struct {
+	Type    NotificationType    `json:"type"`
+	Channel NotificationChannel `json:"channel"`
+	Locale  string              `json:"locale"`
+	Message string              `json:"message"`
+}
+
 type Patient struct {
-	ID                     string           `json:"id"`
-	UserID                 *string          `json:"userId,omitempty"`
-	FirstName              string           `json:"firstName"`
-	LastName               string           `json:"lastName"`
-	Timezone               string           `json:"timezone"`
-	CreatedAt              time.Time        `json:"createdAt"`
-	UpdatedAt              time.Time        `json:"updatedAt"`
-	Medications            []*Medication    `json:"medications"`
-	Schedules              []*Schedule      `json:"schedules"`
-	UpcomingDispenseEvents []*DispenseEvent `json:"upcomingDispenseEvents"`
+	ID                     string               `json:"id"`
+	UserID                 *string              `json:"userId,omitempty"`
+	FirstName              string               `json:"firstName"`
+	LastName               string               `json:"lastName"`
+	Timezone               string               `json:"timezone"`
+	Locale                 string               `json:"locale"`
+	CreatedAt              time.Time            `json:"createdAt"`
+	UpdatedAt              time.Time            `json:"updatedAt"`
+	Medications            []*Medication        `json:"medications"`
+	Schedules              []*Schedule          `json:"schedules"`
+	UpcomingDispenseEvents []*DispenseEvent     `json:"upcomingDispenseEvents"`
+	Notifications          []*NotificationEvent `json:"notifications"`
+	VoiceSettings          *VoiceSettings       `json:"voiceSettings"`
+	PharmacyLeadTimeDays   int                  `json:"pharmacyLeadTimeDays"`
+	VoiceMessages          []*VoiceMessage      `json:"voiceMessages"`
+	SiloCount              int                  `json:"siloCount"`
+	Silos                  []*Silo              `json:"silos"`
+	ShowMedicationNames    bool                 `json:"showMedicationNames"`
+	AutoRefillRequests     bool                 `json:"autoRefillRequests"`
 }
 
 type PatientInput struct {
-	UserID    *string `json:"userId,omitempty"`
-	FirstName string  `json:"firstName"`
-	LastName  string  `json:"lastName"`
-	Timezone  string  `json:"timezone"`
+	UserID               *string `json:"userId,omitempty"`
+	FirstName            string  `json:"firstName"`
+	LastName             string  `json:"lastName"`
+	Timezone             string  `json:"timezone"`
+	Locale               *string `json:"locale,omitempty"`
+	PharmacyLeadTimeDays *int    `json:"pharmacyLeadTimeDays,omitempty"`
+	SiloCount            *int    `json:"siloCount,omitempty"`
+	ShowMedicationNames  *bool   `json:"showMedicationNames,omitempty"`
+	AutoRefillRequests   *bool   `json:"autoRefillRequests,omitempty"`
+}
+
+type Pharmacy struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Phone      *string   `json:"phone,omitempty"`
+	Email      *string   `json:"email,omitempty"`
+	FaxEmail   *string   `json:"faxEmail,omitempty"`
+	WebhookURL *string   `json:"webhookUrl,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type PharmacyInput struct {
+	ID         *string `json:"id,omitempty"`
+	Name       string  `json:"name"`
+	Phone      *string `json:"phone,omitempty"`
+	Email      *string `json:"email,omitempty"`
+	FaxEmail   *string `json:"faxEmail,omitempty"`
+	WebhookURL *string `json:"webhookUrl,omitempty"`
+}
+
+type Prescription struct {
+	ID               string    `json:"id"`
+	MedicationID     string    `json:"medicationId"`
+	PrescriberName   string    `json:"prescriberName"`
+	PrescriberPhone  *string   `json:"prescriberPhone,omitempty"`
+	PharmacyName     *string   `json:"pharmacyName,omitempty"`
+	PharmacyPhone    *string   `json:"pharmacyPhone,omitempty"`
+	Sig              *string   `json:"sig,omitempty"`
+	QuantityPerFill  int       `json:"quantityPerFill"`
+	RefillsRemaining int       `json:"refillsRemaining"`
+	WrittenOn        string    `json:"writtenOn"`
+	ExpiresOn        *string   `json:"expiresOn,omitempty"`
+	Active           bool      `json:"active"`
+	RxNumber         *string   `json:"rxNumber,omitempty"`
+	PharmacyID       *string   `json:"pharmacyId,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type PrescriptionInput struct {
+	ID               *string `json:"id,omitempty"`
+	MedicationID     string  `json:"medicationId"`
+	PrescriberName   string  `json:"prescriberName"`
+	PrescriberPhone  *string `json:"prescriberPhone,omitempty"`
+	PharmacyName     *string `json:"pharmacyName,omitempty"`
+	PharmacyPhone    *string `json:"pharmacyPhone,omitempty"`
+	Sig              *string `json:"sig,omitempty"`
+	QuantityPerFill  int     `json:"quantityPerFill"`
+	RefillsRemaining int     `json:"refillsRemaining"`
+	WrittenOn        string  `json:"writtenOn"`
+	ExpiresOn        *string `json:"expiresOn,omitempty"`
+	Active           *bool   `json:"active,omitempty"`
+	RxNumber         *string `json:"rxNumber,omitempty"`
+	PharmacyID       *string `json:"pharmacyId,omitempty"`
 }
 
 type Query struct {
 }
 
+type RefillRequest struct {
+	ID             string                   `json:"id"`
+	PatientID      string                   `json:"patientId"`
+	MedicationID   string                   `json:"medicationId"`
+	Medication     *Medication              `json:"medication"`
+	PrescriptionID *string                  `json:"prescriptionId,omitempty"`
+	PharmacyID     *string                  `json:"pharmacyId,omitempty"`
+	Pharmacy       *Pharmacy                `json:"pharmacy,omitempty"`
+	RxNumber       *string                  `json:"rxNumber,omitempty"`
+	Quantity       int                      `json:"quantity"`
+	Status         RefillRequestStatus      `json:"status"`
+	Source         RefillRequestSource      `json:"source"`
+	RequestedAt    time.Time                `json:"requestedAt"`
+	ReadyAt        *time.Time               `json:"readyAt,omitempty"`
+	PickedUpAt     *time.Time               `json:"pickedUpAt,omitempty"`
+	CancelledAt    *time.Time               `json:"cancelledAt,omitempty"`
+	UpdatedAt      time.Time                `json:"updatedAt"`
+	Deliveries     []*RefillRequestDelivery `json:"deliveries"`
+}
+
+type RefillRequestDelivery struct {
+	ID          string                `json:"id"`
+	Channel     RefillDeliveryChannel `json:"channel"`
+	Destination string                `json:"destination"`
+	Status      RefillDeliveryStatus  `json:"status"`
+	Error       *string               `json:"error,omitempty"`
+	AttemptedAt time.Time             `json:"attemptedAt"`
+}
+
+type RefillRequestInput struct {
+	MedicationID   string  `json:"medicationId"`
+	PrescriptionID *string `json:"prescriptionId,omitempty"`
+	PharmacyID     *string `json:"pharmacyId,omitempty"`
+	Quantity       *int    `json:"quantity,omitempty"`
+}
+
+type RefillResult struct {
+	Medication   *Medication             `json:"medication"`
+	Movement     *StockMovement          `json:"movement"`
+	Calibration  *SiloCalibrationRequest `json:"calibration,omitempty"`
+	Prescription *Prescription           `json:"prescription,omitempty"`
+}
+
+type ReportFile struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+	Size        int    `json:"size"`
+}
+
 type Schedule struct {
-	ID             string          `json:"id"`
-	PatientID      string          `json:"patientId"`
-	Title          string          `json:"title"`
-	Timezone       string          `json:"timezone"`
-	Rrule          string          `json:"rrule"`
-	StartDateIso   time.Time       `json:"startDateISO"`
-	EndDateIso     *time.Time      `json:"endDateISO,omitempty"`
-	LockoutMinutes int             `json:"lockoutMinutes"`
-	Status         ScheduleStatus  `json:"status"`
-	Items          []*ScheduleItem `json:"items"`
-	CreatedAt      time.Time       `json:"createdAt"`
-	UpdatedAt      time.Time       `json:"updatedAt"`
+	ID                  string                `json:"id"`
+	PatientID           string                `json:"patientId"`
+	Title               string                `json:"title"`
+	Timezone            string                `json:"timezone"`
+	Rrule               string                `json:"rrule"`
+	StartDateIso        time.Time             `json:"startDateISO"`
+	EndDateIso          *time.Time            `json:"endDateISO,omitempty"`
+	LockoutMinutes      int                   `json:"lockoutMinutes"`
+	Status              ScheduleStatus        `json:"status"`
+	Items               []*ScheduleItem       `json:"items"`
+	InteractionWarnings []*InteractionWarning `json:"interactionWarnings"`
+	CreatedAt           time.Time             `json:"createdAt"`
+	UpdatedAt           time.Time             `json:"updatedAt"`
+}
+
+type ScheduleAdherence struct {
+	Schedule *Schedule       `json:"schedule"`
+	Stats    *AdherenceStats `json:"stats"`
 }
 
 type ScheduleInput struct {
@@ -157,15 +472,73 @@ type ScheduleItemInput struct {
 	Qty          int    `json:"qty"`
 }
 
+type Silo struct {
+	ID           string      `json:"id"`
+	PatientID    string      `json:"patientId"`
+	Index        int         `json:"index"`
+	Capacity     int         `json:"capacity"`
+	PillSizeMm   *float64    `json:"pillSizeMm,omitempty"`
+	CalibratedAt *time.Time  `json:"calibratedAt,omitempty"`
+	Medication   *Medication `json:"medication,omitempty"`
+	Fill         int         `json:"fill"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+}
+
+type SiloCalibrationRequest struct {
+	ID           string    `json:"id"`
+	PatientID    string    `json:"patientId"`
+	Silo         int       `json:"silo"`
+	MedicationID string    `json:"medicationId"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type SiloInput struct {
+	PatientID  string   `json:"patientId"`
+	Index      int      `json:"index"`
+	Capacity   int      `json:"capacity"`
+	PillSizeMm *float64 `json:"pillSizeMm,omitempty"`
+}
+
+type StockAdjustmentInput struct {
+	MedicationID string            `json:"medicationId"`
+	Kind         StockMovementKind `json:"kind"`
+	Quantity     int               `json:"quantity"`
+	Actor        *string           `json:"actor,omitempty"`
+	Note         *string           `json:"note,omitempty"`
+}
+
+type StockHistory struct {
+	MedicationID  string           `json:"medicationId"`
+	StockCount    int              `json:"stockCount"`
+	LedgerBalance int              `json:"ledgerBalance"`
+	Movements     []*StockMovement `json:"movements"`
+}
+
+type StockMovement struct {
+	ID              string            `json:"id"`
+	MedicationID    string            `json:"medicationId"`
+	Kind            StockMovementKind `json:"kind"`
+	Quantity        int               `json:"quantity"`
+	BalanceAfter    int               `json:"balanceAfter"`
+	Actor           *string           `json:"actor,omitempty"`
+	DispenseEventID *string           `json:"dispenseEventId,omitempty"`
+	Note            *string           `json:"note,omitempty"`
+	LotNumber       *string           `json:"lotNumber,omitempty"`
+	ExpiresOn       *string           `json:"expiresOn,omitempty"`
+	CreatedAt       time.Time         `json:"createdAt"`
+}
+
 type User struct {
-	ID        string     `json:"id"`
-	Email     string     `json:"email"`
-	FullName  string     `json:"fullName"`
-	Phone     *string    `json:"phone,omitempty"`
-	Timezone  string     `json:"timezone"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt"`
-	Patients  []*Patient `json:"patients"`
+	ID                      string                    `json:"id"`
+	Email                   string                    `json:"email"`
+	FullName                string                    `json:"fullName"`
+	Phone                   *string                   `json:"phone,omitempty"`
+	Timezone                string                    `json:"timezone"`
+	Locale                  string                    `json:"locale"`
+	CreatedAt               time.Time                 `json:"createdAt"`
+	UpdatedAt               time.Time                 `json:"updatedAt"`
+	Patients                []*Patient                `json:"patients"`
+	NotificationPreferences []*NotificationPreference `json:"notificationPreferences"`
 }
 
 type UserInput struct {
@@ -174,9 +547,100 @@ type UserInput struct {
 	FullName string  `json:"fullName"`
 	Phone    *string `json:"phone,omitempty"`
 	Timezone string  `json:"timezone"`
+	Locale   *string `json:"locale,omitempty"`
 	Password *string `json:"password,omitempty"`
 }
 
+type VoiceMessage struct {
+	ID               string     `json:"id"`
+	PatientID        string     `json:"patientId"`
+	ScheduleID       string     `json:"scheduleId"`
+	DueAtIso         *time.Time `json:"dueAtISO,omitempty"`
+	OriginalMimeType string     `json:"originalMimeType"`
+	DurationMs       int        `json:"durationMs"`
+	SampleRateHertz  int        `json:"sampleRateHertz"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+type VoiceMessageInput struct {
+	PatientID  string         `json:"patientId"`
+	ScheduleID string         `json:"scheduleId"`
+	DueAtIso   *time.Time     `json:"dueAtISO,omitempty"`
+	File       graphql.Upload `json:"file"`
+}
+
+type VoiceSettings struct {
+	PatientID       string  `json:"patientId"`
+	VoiceName       *string `json:"voiceName,omitempty"`
+	LanguageCode    string  `json:"languageCode"`
+	SpeakingRate    float64 `json:"speakingRate"`
+	SampleRateHertz int     `json:"sampleRateHertz"`
+}
+
+type VoiceSettingsInput struct {
+	VoiceName       *string  `json:"voiceName,omitempty"`
+	LanguageCode    *string  `json:"languageCode,omitempty"`
+	SpeakingRate    *float64 `json:"speakingRate,omitempty"`
+	SampleRateHertz *int     `json:"sampleRateHertz,omitempty"`
+}
+
+type AdherenceGroupBy string
+
+const (
+	AdherenceGroupByDay   AdherenceGroupBy = "DAY"
+	AdherenceGroupByWeek  AdherenceGroupBy = "WEEK"
+	AdherenceGroupByMonth AdherenceGroupBy = "MONTH"
+)
+
+var AllAdherenceGroupBy = []AdherenceGroupBy{
+	AdherenceGroupByDay,
+	AdherenceGroupByWeek,
+	AdherenceGroupByMonth,
+}
+
+func (e AdherenceGroupBy) IsValid() bool {
+	switch e {
+	case AdherenceGroupByDay, AdherenceGroupByWeek, AdherenceGroupByMonth:
+		return true
+	}
+	return false
+}
+
+func (e AdherenceGroupBy) String() string {
+	return string(e)
+}
+
+func (e *AdherenceGroupBy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AdherenceGroupBy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AdherenceGroupBy", str)
+	}
+	return nil
+}
+
+func (e AdherenceGroupBy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AdherenceGroupBy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AdherenceGroupBy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type DispenseStatus string
 
 const (
@@ -242,50 +706,48 @@ func (e DispenseStatus) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-type ScheduleStatus string
+type ImportAction string
 
 const (
-	ScheduleStatusActive   ScheduleStatus = "ACTIVE"
-	ScheduleStatusPaused   ScheduleStatus = "PAUSED"
-	ScheduleStatusArchived ScheduleStatus = "ARCHIVED"
+	ImportActionCreate   ImportAction = "CREATE"
+	ImportActionExisting ImportAction = "EXISTING"
 )
 
-var AllScheduleStatus = []ScheduleStatus{
-	ScheduleStatusActive,
-	ScheduleStatusPaused,
-	ScheduleStatusArchived,
+var AllImportAction = []ImportAction{
+	ImportActionCreate,
+	ImportActionExisting,
 }
 
-func (e ScheduleStatus) IsValid() bool {
+func (e ImportAction) IsValid() bool {
 	switch e {
-	case ScheduleStatusActive, ScheduleStatusPaused, ScheduleStatusArchived:
+	case ImportActionCreate, ImportActionExisting:
 		return true
 	}
 	return false
 }
 
-func (e ScheduleStatus) String() string {
+func (e ImportAction) String() string {
 	return string(e)
 }
 
-func (e *ScheduleStatus) UnmarshalGQL(v any) error {
+func (e *ImportAction) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = ScheduleStatus(str)
+	*e = ImportAction(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid ScheduleStatus", str)
+		return fmt.Errorf("%s is not a valid ImportAction", str)
 	}
 	return nil
 }
 
-func (e ScheduleStatus) MarshalGQL(w io.Writer) {
+func (e ImportAction) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *ScheduleStatus) UnmarshalJSON(b []byte) error {
+func (e *ImportAction) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -293,7 +755,819 @@ func (e *ScheduleStatus) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e ScheduleStatus) MarshalJSON() ([]byte, error) {
+func (e ImportAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type InteractionKind string
+
+const (
+	InteractionKindInteraction      InteractionKind = "INTERACTION"
+	InteractionKindDuplicateTherapy InteractionKind = "DUPLICATE_THERAPY"
+)
+
+var AllInteractionKind = []InteractionKind{
+	InteractionKindInteraction,
+	InteractionKindDuplicateTherapy,
+}
+
+func (e InteractionKind) IsValid() bool {
+	switch e {
+	case InteractionKindInteraction, InteractionKindDuplicateTherapy:
+		return true
+	}
+	return false
+}
+
+func (e InteractionKind) String() string {
+	return string(e)
+}
+
+func (e *InteractionKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InteractionKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InteractionKind", str)
+	}
+	return nil
+}
+
+func (e InteractionKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *InteractionKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e InteractionKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type InteractionSeverity string
+
+const (
+	InteractionSeverityContraindicated InteractionSeverity = "CONTRAINDICATED"
+	InteractionSeverityMajor           InteractionSeverity = "MAJOR"
+	InteractionSeverityModerate        InteractionSeverity = "MODERATE"
+	InteractionSeverityMinor           InteractionSeverity = "MINOR"
+)
+
+var AllInteractionSeverity = []InteractionSeverity{
+	InteractionSeverityContraindicated,
+	InteractionSeverityMajor,
+	InteractionSeverityModerate,
+	InteractionSeverityMinor,
+}
+
+func (e InteractionSeverity) IsValid() bool {
+	switch e {
+	case InteractionSeverityContraindicated, InteractionSeverityMajor, InteractionSeverityModerate, InteractionSeverityMinor:
+		return true
+	}
+	return false
+}
+
+func (e InteractionSeverity) String() string {
+	return string(e)
+}
+
+func (e *InteractionSeverity) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InteractionSeverity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InteractionSeverity", str)
+	}
+	return nil
+}
+
+func (e InteractionSeverity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *InteractionSeverity) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e InteractionSeverity) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationChannel string
+
+const (
+	NotificationChannelSms   NotificationChannel = "SMS"
+	NotificationChannelAudio NotificationChannel = "AUDIO"
+)
+
+var AllNotificationChannel = []NotificationChannel{
+	NotificationChannelSms,
+	NotificationChannelAudio,
+}
+
+func (e NotificationChannel) IsValid() bool {
+	switch e {
+	case NotificationChannelSms, NotificationChannelAudio:
+		return true
+	}
+	return false
+}
+
+func (e NotificationChannel) String() string {
+	return string(e)
+}
+
+func (e *NotificationChannel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationChannel", str)
+	}
+	return nil
+}
+
+func (e NotificationChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationChannel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationChannel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationDeliveryMode string
+
+const (
+	NotificationDeliveryModeImmediate   NotificationDeliveryMode = "IMMEDIATE"
+	NotificationDeliveryModeDailyDigest NotificationDeliveryMode = "DAILY_DIGEST"
+)
+
+var AllNotificationDeliveryMode = []NotificationDeliveryMode{
+	NotificationDeliveryModeImmediate,
+	NotificationDeliveryModeDailyDigest,
+}
+
+func (e NotificationDeliveryMode) IsValid() bool {
+	switch e {
+	case NotificationDeliveryModeImmediate, NotificationDeliveryModeDailyDigest:
+		return true
+	}
+	return false
+}
+
+func (e NotificationDeliveryMode) String() string {
+	return string(e)
+}
+
+func (e *NotificationDeliveryMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationDeliveryMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationDeliveryMode", str)
+	}
+	return nil
+}
+
+func (e NotificationDeliveryMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationDeliveryMode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationDeliveryMode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryStatusQueued      NotificationDeliveryStatus = "QUEUED"
+	NotificationDeliveryStatusSent        NotificationDeliveryStatus = "SENT"
+	NotificationDeliveryStatusDelivered   NotificationDeliveryStatus = "DELIVERED"
+	NotificationDeliveryStatusUndelivered NotificationDeliveryStatus = "UNDELIVERED"
+	NotificationDeliveryStatusFailed      NotificationDeliveryStatus = "FAILED"
+)
+
+var AllNotificationDeliveryStatus = []NotificationDeliveryStatus{
+	NotificationDeliveryStatusQueued,
+	NotificationDeliveryStatusSent,
+	NotificationDeliveryStatusDelivered,
+	NotificationDeliveryStatusUndelivered,
+	NotificationDeliveryStatusFailed,
+}
+
+func (e NotificationDeliveryStatus) IsValid() bool {
+	switch e {
+	case NotificationDeliveryStatusQueued, NotificationDeliveryStatusSent, NotificationDeliveryStatusDelivered, NotificationDeliveryStatusUndelivered, NotificationDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e NotificationDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *NotificationDeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e NotificationDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationDeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationDeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationStatus string
+
+const (
+	NotificationStatusSent       NotificationStatus = "SENT"
+	NotificationStatusQueued     NotificationStatus = "QUEUED"
+	NotificationStatusSuppressed NotificationStatus = "SUPPRESSED"
+	NotificationStatusFailed     NotificationStatus = "FAILED"
+)
+
+var AllNotificationStatus = []NotificationStatus{
+	NotificationStatusSent,
+	NotificationStatusQueued,
+	NotificationStatusSuppressed,
+	NotificationStatusFailed,
+}
+
+func (e NotificationStatus) IsValid() bool {
+	switch e {
+	case NotificationStatusSent, NotificationStatusQueued, NotificationStatusSuppressed, NotificationStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e NotificationStatus) String() string {
+	return string(e)
+}
+
+func (e *NotificationStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationStatus", str)
+	}
+	return nil
+}
+
+func (e NotificationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeDoseReminder         NotificationType = "DOSE_REMINDER"
+	NotificationTypeLowStock             NotificationType = "LOW_STOCK"
+	NotificationTypeMissedDose           NotificationType = "MISSED_DOSE"
+	NotificationTypeCupAbsent            NotificationType = "CUP_ABSENT"
+	NotificationTypeEmptySilo            NotificationType = "EMPTY_SILO"
+	NotificationTypeRefillForecast       NotificationType = "REFILL_FORECAST"
+	NotificationTypeLotExpiry            NotificationType = "LOT_EXPIRY"
+	NotificationTypePrescriptionExpiring NotificationType = "PRESCRIPTION_EXPIRING"
+	NotificationTypeNoRefillsLeft        NotificationType = "NO_REFILLS_LEFT"
+)
+
+var AllNotificationType = []NotificationType{
+	NotificationTypeDoseReminder,
+	NotificationTypeLowStock,
+	NotificationTypeMissedDose,
+	NotificationTypeCupAbsent,
+	NotificationTypeEmptySilo,
+	NotificationTypeRefillForecast,
+	NotificationTypeLotExpiry,
+	NotificationTypePrescriptionExpiring,
+	NotificationTypeNoRefillsLeft,
+}
+
+func (e NotificationType) IsValid() bool {
+	switch e {
+	case NotificationTypeDoseReminder, NotificationTypeLowStock, NotificationTypeMissedDose, NotificationTypeCupAbsent, NotificationTypeEmptySilo, NotificationTypeRefillForecast, NotificationTypeLotExpiry, NotificationTypePrescriptionExpiring, NotificationTypeNoRefillsLeft:
+		return true
+	}
+	return false
+}
+
+func (e NotificationType) String() string {
+	return string(e)
+}
+
+func (e *NotificationType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationType", str)
+	}
+	return nil
+}
+
+func (e NotificationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type RefillDeliveryChannel string
+
+const (
+	RefillDeliveryChannelEmail   RefillDeliveryChannel = "EMAIL"
+	RefillDeliveryChannelFax     RefillDeliveryChannel = "FAX"
+	RefillDeliveryChannelWebhook RefillDeliveryChannel = "WEBHOOK"
+)
+
+var AllRefillDeliveryChannel = []RefillDeliveryChannel{
+	RefillDeliveryChannelEmail,
+	RefillDeliveryChannelFax,
+	RefillDeliveryChannelWebhook,
+}
+
+func (e RefillDeliveryChannel) IsValid() bool {
+	switch e {
+	case RefillDeliveryChannelEmail, RefillDeliveryChannelFax, RefillDeliveryChannelWebhook:
+		return true
+	}
+	return false
+}
+
+func (e RefillDeliveryChannel) String() string {
+	return string(e)
+}
+
+func (e *RefillDeliveryChannel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RefillDeliveryChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RefillDeliveryChannel", str)
+	}
+	return nil
+}
+
+func (e RefillDeliveryChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RefillDeliveryChannel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RefillDeliveryChannel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type RefillDeliveryStatus string
+
+const (
+	RefillDeliveryStatusSent   RefillDeliveryStatus = "SENT"
+	RefillDeliveryStatusFailed RefillDeliveryStatus = "FAILED"
+)
+
+var AllRefillDeliveryStatus = []RefillDeliveryStatus{
+	RefillDeliveryStatusSent,
+	RefillDeliveryStatusFailed,
+}
+
+func (e RefillDeliveryStatus) IsValid() bool {
+	switch e {
+	case RefillDeliveryStatusSent, RefillDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e RefillDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *RefillDeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RefillDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RefillDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e RefillDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RefillDeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RefillDeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type RefillRequestSource string
+
+const (
+	RefillRequestSourceManual   RefillRequestSource = "MANUAL"
+	RefillRequestSourceForecast RefillRequestSource = "FORECAST"
+)
+
+var AllRefillRequestSource = []RefillRequestSource{
+	RefillRequestSourceManual,
+	RefillRequestSourceForecast,
+}
+
+func (e RefillRequestSource) IsValid() bool {
+	switch e {
+	case RefillRequestSourceManual, RefillRequestSourceForecast:
+		return true
+	}
+	return false
+}
+
+func (e RefillRequestSource) String() string {
+	return string(e)
+}
+
+func (e *RefillRequestSource) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RefillRequestSource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RefillRequestSource", str)
+	}
+	return nil
+}
+
+func (e RefillRequestSource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RefillRequestSource) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RefillRequestSource) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type RefillRequestStatus string
+
+const (
+	RefillRequestStatusRequested RefillRequestStatus = "REQUESTED"
+	RefillRequestStatusReady     RefillRequestStatus = "READY"
+	RefillRequestStatusPickedUp  RefillRequestStatus = "PICKED_UP"
+	RefillRequestStatusCancelled RefillRequestStatus = "CANCELLED"
+)
+
+var AllRefillRequestStatus = []RefillRequestStatus{
+	RefillRequestStatusRequested,
+	RefillRequestStatusReady,
+	RefillRequestStatusPickedUp,
+	RefillRequestStatusCancelled,
+}
+
+func (e RefillRequestStatus) IsValid() bool {
+	switch e {
+	case RefillRequestStatusRequested, RefillRequestStatusReady, RefillRequestStatusPickedUp, RefillRequestStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (e RefillRequestStatus) String() string {
+	return string(e)
+}
+
+func (e *RefillRequestStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RefillRequestStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RefillRequestStatus", str)
+	}
+	return nil
+}
+
+func (e RefillRequestStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RefillRequestStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RefillRequestStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportFormat string
+
+const (
+	ReportFormatCSV ReportFormat = "CSV"
+	ReportFormatPDF ReportFormat = "PDF"
+)
+
+var AllReportFormat = []ReportFormat{
+	ReportFormatCSV,
+	ReportFormatPDF,
+}
+
+func (e ReportFormat) IsValid() bool {
+	switch e {
+	case ReportFormatCSV, ReportFormatPDF:
+		return true
+	}
+	return false
+}
+
+func (e ReportFormat) String() string {
+	return string(e)
+}
+
+func (e *ReportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportFormat", str)
+	}
+	return nil
+}
+
+func (e ReportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportFormat) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportFormat) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ScheduleStatus string
+
+const (
+	ScheduleStatusActive   ScheduleStatus = "ACTIVE"
+	ScheduleStatusPaused   ScheduleStatus = "PAUSED"
+	ScheduleStatusArchived ScheduleStatus = "ARCHIVED"
+)
+
+var AllScheduleStatus = []ScheduleStatus{
+	ScheduleStatusActive,
+	ScheduleStatusPaused,
+	ScheduleStatusArchived,
+}
+
+func (e ScheduleStatus) IsValid() bool {
+	switch e {
+	case ScheduleStatusActive, ScheduleStatusPaused, ScheduleStatusArchived:
+		return true
+	}
+	return false
+}
+
+func (e ScheduleStatus) String() string {
+	return string(e)
+}
+
+func (e *ScheduleStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ScheduleStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ScheduleStatus", str)
+	}
+	return nil
+}
+
+func (e ScheduleStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ScheduleStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ScheduleStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type StockMovementKind string
+
+const (
+	StockMovementKindRefill     StockMovementKind = "REFILL"
+	StockMovementKindDispense   StockMovementKind = "DISPENSE"
+	StockMovementKindAdjustment StockMovementKind = "ADJUSTMENT"
+	StockMovementKindWastage    StockMovementKind = "WASTAGE"
+	StockMovementKindCorrection StockMovementKind = "CORRECTION"
+)
+
+var AllStockMovementKind = []StockMovementKind{
+	StockMovementKindRefill,
+	StockMovementKindDispense,
+	StockMovementKindAdjustment,
+	StockMovementKindWastage,
+	StockMovementKindCorrection,
+}
+
+func (e StockMovementKind) IsValid() bool {
+	switch e {
+	case StockMovementKindRefill, StockMovementKindDispense, StockMovementKindAdjustment, StockMovementKindWastage, StockMovementKindCorrection:
+		return true
+	}
+	return false
+}
+
+func (e StockMovementKind) String() string {
+	return string(e)
+}
+
+func (e *StockMovementKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StockMovementKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StockMovementKind", str)
+	}
+	return nil
+}
+
+func (e StockMovementKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *StockMovementKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e StockMovementKind) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+)
+
+func (r *Resolver) upsertPrescription(ctx context.Context, input model.PrescriptionInput) (*model.Prescription, error) {
+	prescriber := strings.TrimSpace(input.PrescriberName)
+	switch {
+	case prescriber == "":
+		return nil, fmt.Errorf("prescriber name is required")
+	case input.QuantityPerFill < 1:
+		return nil, fmt.Errorf("quantity per fill must be positive")
+	case input.RefillsRemaining < 0:
+		return nil, fmt.Errorf("refills remaining cannot be negative")
+	}
+	writtenOn, err := dateFromString(input.WrittenOn, "writtenOn")
+	if err != nil {
+		return nil, err
+	}
+	expiresOn := nullTrimmedStringFromPtr(input.ExpiresOn)
+	if expiresOn.Valid {
+		if _, err := dateFromString(expiresOn.String, "expiresOn"); err != nil {
+			return nil, err
+		}
+		if expiresOn.String < writtenOn {
+			return nil, fmt.Errorf("expiresOn cannot be before writtenOn")
+		}
+	}
+	active := int64(1)
+	if input.Active != nil && !*input.Active {
+		active = 0
+	}
+	pharmacyID := nullTrimmedStringFromPtr(input.PharmacyID)
+	if pharmacyID.Valid {
+		if _, err := r.Queries.GetPharmacy(ctx, pharmacyID.String); err != nil {
+			return nil, fmt.Errorf("load pharmacy %s: %w", pharmacyID.String, err)
+		}
+	}
+	now := formatDBTime(time.Now())
+
+	if input.ID == nil || *input.ID == "" {
+		if _, err := r.Queries.GetMedication(ctx, input.MedicationID); err != nil {
+			return nil, fmt.Errorf("load medication %s: %w", input.MedicationID, err)
+		}
+		record, err := r.Queries.CreatePrescription(ctx, db.CreatePrescriptionParams{
+			ID:               uuid.NewString(),
+			MedicationID:     input.MedicationID,
+			PrescriberName:   prescriber,
+			PrescriberPhone:  nullTrimmedStringFromPtr(input.PrescriberPhone),
+			PharmacyName:     nullTrimmedStringFromPtr(input.PharmacyName),
+			PharmacyPhone:    nullTrimmedStringFromPtr(input.PharmacyPhone),
+			Sig:              nullTrimmedStringFromPtr(input.Sig),
+			QuantityPerFill:  int64(input.QuantityPerFill),
+			RefillsRemaining: int64(input.RefillsRemaining),
+			WrittenOn:        writtenOn,
+			ExpiresOn:        expiresOn,
+			Active:           active,
+			RxNumber:         nullTrimmedStringFromPtr(input.RxNumber),
+			PharmacyID:       pharmacyID,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create prescription: %w", err)
+		}
+		return buildPrescriptionModel(record)
+	}
+
+	existing, err := r.Queries.GetPrescription(ctx, *input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load prescription %s: %w", *input.ID, err)
+	}
+	if existing.MedicationID != input.MedicationID {
+		return nil, fmt.Errorf("prescription %s belongs to medication %s", existing.ID, existing.MedicationID)
+	}
+	record, err := r.Queries.UpdatePrescription(ctx, db.UpdatePrescriptionParams{
+		PrescriberName:   prescriber,
+		PrescriberPhone:  nullTrimmedStringFromPtr(input.PrescriberPhone),
+		PharmacyName:     nullTrimmedStringFromPtr(input.PharmacyName),
+		PharmacyPhone:    nullTrimmedStringFromPtr(input.PharmacyPhone),
+		Sig:              nullTrimmedStringFromPtr(input.Sig),
+		QuantityPerFill:  int64(input.QuantityPerFill),
+		RefillsRemaining: int64(input.RefillsRemaining),
+		WrittenOn:        writtenOn,
+		ExpiresOn:        expiresOn,
+		Active:           active,
+		RxNumber:         nullTrimmedStringFromPtr(input.RxNumber),
+		PharmacyID:       pharmacyID,
+		UpdatedAt:        now,
+		ID:               existing.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update prescription: %w", err)
+	}
+	return buildPrescriptionModel(record)
+}
+
+func (r *Resolver) loadPrescriptions(ctx context.Context, medicationID string) ([]*model.Prescription, error) {
+	rows, err := r.Queries.ListPrescriptionsByMedication(ctx, medicationID)
+	if err != nil {
+		return nil, fmt.Errorf("list prescriptions: %w", err)
+	}
+	result := make([]*model.Prescription, 0, len(rows))
+	for _, row := range rows {
+		item, err := buildPrescriptionModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// usePrescriptionRefill takes one refill from prescriptionID, or when it is
+// empty from the medication's refillable prescription that expires first.
+// It returns nil when no prescription applies. Run it inside withTx.
+func usePrescriptionRefill(ctx context.Context, q *db.Queries, medication db.Medication, prescriptionID string) (*db.Prescription, error) {
+	today := time.Now().UTC().Format(time.DateOnly)
+
+	var prescription db.Prescription
+	if prescriptionID != "" {
+		var err error
+		prescription, err = q.GetPrescription(ctx, prescriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("load prescription %s: %w", prescriptionID, err)
+		}
+		switch {
+		case prescription.MedicationID != medication.ID:
+			return nil, fmt.Errorf("prescription %s is not for medication %s", prescription.ID, medication.ID)
+		case prescription.Active == 0:
+			return nil, fmt.Errorf("prescription %s is not active", prescription.ID)
+		case prescription.ExpiresOn.Valid && prescription.ExpiresOn.String < today:
+			return nil, fmt.Errorf("prescription %s expired on %s", prescription.ID, prescription.ExpiresOn.String)
+		case prescription.RefillsRemaining == 0:
+			return nil, fmt.Errorf("prescription %s has no refills left", prescription.ID)
+		}
+	} else {
+		var err error
+		prescription, err = q.GetRefillablePrescription(ctx, db.GetRefillablePrescriptionParams{
+			MedicationID: medication.ID,
+			Today:        sql.NullString{String: today, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("find refillable prescription: %w", err)
+		}
+	}
+
+	updated, err := q.UsePrescriptionRefill(ctx, db.UsePrescriptionRefillParams{
+		UpdatedAt: formatDBTime(time.Now()),
+		ID:        prescription.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("use prescription refill: %w", err)
+	}
+	return &updated, nil
+}
+
+func dateFromString(value, field string) (string, error) {
+	value = strings.TrimSpace(value)
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return "", fmt.Errorf("%s must be YYYY-MM-DD", field)
+	}
+	return value, nil
+}
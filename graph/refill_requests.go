@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/pharmacy"
+)
+
+func (r *Resolver) upsertPharmacy(ctx context.Context, input model.PharmacyInput) (*model.Pharmacy, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("pharmacy name is required")
+	}
+	email := nullTrimmedStringFromPtr(input.Email)
+	faxEmail := nullTrimmedStringFromPtr(input.FaxEmail)
+	if email.Valid {
+		if _, err := mail.ParseAddress(email.String); err != nil {
+			return nil, fmt.Errorf("email is not a valid email address")
+		}
+	}
+	if faxEmail.Valid {
+		if _, err := mail.ParseAddress(faxEmail.String); err != nil {
+			return nil, fmt.Errorf("faxEmail is not a valid email address")
+		}
+	}
+	webhookURL := nullTrimmedStringFromPtr(input.WebhookURL)
+	if webhookURL.Valid {
+		parsed, err := url.Parse(webhookURL.String)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhookUrl must be an http or https URL")
+		}
+	}
+	now := formatDBTime(time.Now())
+
+	if input.ID == nil || *input.ID == "" {
+		record, err := r.Queries.CreatePharmacy(ctx, db.CreatePharmacyParams{
+			ID:         uuid.NewString(),
+			Name:       name,
+			Phone:      nullTrimmedStringFromPtr(input.Phone),
+			Email:      email,
+			FaxEmail:   faxEmail,
+			WebhookUrl: webhookURL,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create pharmacy: %w", err)
+		}
+		return buildPharmacyModel(record)
+	}
+
+	record, err := r.Queries.UpdatePharmacy(ctx, db.UpdatePharmacyParams{
+		Name:       name,
+		Phone:      nullTrimmedStringFromPtr(input.Phone),
+		Email:      email,
+		FaxEmail:   faxEmail,
+		WebhookUrl: webhookURL,
+		UpdatedAt:  now,
+		ID:         *input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update pharmacy %s: %w", *input.ID, err)
+	}
+	return buildPharmacyModel(record)
+}
+
+func (r *Resolver) loadPharmacies(ctx context.Context) ([]*model.Pharmacy, error) {
+	rows, err := r.Queries.ListPharmacies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list pharmacies: %w", err)
+	}
+	result := make([]*model.Pharmacy, 0, len(rows))
+	for _, row := range rows {
+		item, err := buildPharmacyModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (r *Resolver) requestRefill(ctx context.Context, input model.RefillRequestInput) (*model.RefillRequest, error) {
+	if r.Pharmacy == nil {
+		return nil, fmt.Errorf("refill requests are not configured")
+	}
+	req := pharmacy.Request{
+		MedicationID: input.MedicationID,
+		Source:       pharmacy.SourceManual,
+	}
+	if input.PrescriptionID != nil {
+		req.PrescriptionID = *input.PrescriptionID
+	}
+	if input.PharmacyID != nil {
+		req.PharmacyID = *input.PharmacyID
+	}
+	if input.Quantity != nil {
+		if *input.Quantity < 1 {
+			return nil, fmt.Errorf("quantity must be positive")
+		}
+		req.Quantity = int64(*input.Quantity)
+	}
+
+	record, err := r.Pharmacy.Request(ctx, req)
+	if errors.Is(err, pharmacy.ErrOpenRequest) {
+		return nil, fmt.Errorf("medication %s already has an open refill request", input.MedicationID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildRefillRequestModel(record)
+}
+
+func (r *Resolver) updateRefillRequestStatus(ctx context.Context, id string, status model.RefillRequestStatus) (*model.RefillRequest, error) {
+	record, err := pharmacy.SetStatus(ctx, r.Queries, id, string(status))
+	if err != nil {
+		return nil, err
+	}
+	return buildRefillRequestModel(record)
+}
+
+func (r *Resolver) loadRefillRequests(ctx context.Context, patientID string, status *model.RefillRequestStatus) ([]*model.RefillRequest, error) {
+	params := db.ListRefillRequestsByPatientParams{PatientID: patientID}
+	if status != nil {
+		params.Status = sql.NullString{String: string(*status), Valid: true}
+	}
+	rows, err := r.Queries.ListRefillRequestsByPatient(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list refill requests: %w", err)
+	}
+	result := make([]*model.RefillRequest, 0, len(rows))
+	for _, row := range rows {
+		item, err := buildRefillRequestModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (r *Resolver) loadRefillRequestDeliveries(ctx context.Context, requestID string) ([]*model.RefillRequestDelivery, error) {
+	rows, err := r.Queries.ListRefillRequestDeliveries(ctx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("list refill request deliveries: %w", err)
+	}
+	result := make([]*model.RefillRequestDelivery, 0, len(rows))
+	for _, row := range rows {
+		item, err := buildRefillRequestDeliveryModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
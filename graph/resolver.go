@@ -8,6 +8,7 @@ import (
 
 	"pillbox/graph/model"
 	"pillbox/internal/db"
+	"pillbox/internal/pharmacy"
 
 	"github.com/google/uuid"
 )
@@ -50,10 +51,50 @@ func (s *PendingDispenseStore) Pop(patientID string) *model.DispenseRequest {
 	return req
 }
 
+// SiloCalibrationStore holds pending silo calibration requests in memory,
+// one per patient, for the firmware to poll like pending dispenses.
+type SiloCalibrationStore struct {
+	mu       sync.Mutex
+	requests map[string]*model.SiloCalibrationRequest // keyed by patientId
+}
+
+var siloCalibrationStore = &SiloCalibrationStore{
+	requests: make(map[string]*model.SiloCalibrationRequest),
+}
+
+// Add queues a calibration for a patient (overwrites any existing)
+func (s *SiloCalibrationStore) Add(patientID, medicationID string, silo int) *model.SiloCalibrationRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := &model.SiloCalibrationRequest{
+		ID:           uuid.NewString(),
+		PatientID:    patientID,
+		Silo:         silo,
+		MedicationID: medicationID,
+		CreatedAt:    time.Now(),
+	}
+	s.requests[patientID] = req
+	return req
+}
+
+// Pop retrieves and removes the pending calibration for a patient
+func (s *SiloCalibrationStore) Pop(patientID string) *model.SiloCalibrationRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := s.requests[patientID]
+	if req != nil {
+		delete(s.requests, patientID)
+	}
+	return req
+}
+
 // Resolver wires application dependencies into GraphQL resolvers.
 type Resolver struct {
-	DB      *sql.DB
-	Queries *db.Queries
+	DB       *sql.DB
+	Queries  *db.Queries
+	Pharmacy *pharmacy.Service
 }
 
 func (r *Resolver) withTx(ctx context.Context, fn func(q *db.Queries) error) error {
@@ -0,0 +1,15 @@
+package graph
+
+import (
+	"testing"
+
+	"pillbox/internal/dbtest"
+)
+
+// newTestResolver returns a resolver over a freshly migrated database that
+// holds the demo seed data.
+func newTestResolver(t *testing.T) *Resolver {
+	t.Helper()
+	conn, queries := dbtest.Open(t)
+	return &Resolver{DB: conn, Queries: queries}
+}
@@ -10,6 +10,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"pillbox/graph/model"
 	"pillbox/internal/db"
 	"pillbox/internal/notifications"
@@ -18,6 +19,16 @@ import (
 	"github.com/google/uuid"
 )
 
+// InteractionWarnings is the resolver for the interactionWarnings field.
+func (r *medicationResolver) InteractionWarnings(ctx context.Context, obj *model.Medication) ([]*model.InteractionWarning, error) {
+	return r.medicationInteractionWarnings(ctx, obj)
+}
+
+// Prescriptions is the resolver for the prescriptions field.
+func (r *medicationResolver) Prescriptions(ctx context.Context, obj *model.Medication) ([]*model.Prescription, error) {
+	return r.loadPrescriptions(ctx, obj.ID)
+}
+
 // UpsertUser is the resolver for the upsertUser field.
 func (r *mutationResolver) UpsertUser(ctx context.Context, input model.UserInput) (*model.User, error) {
 	var passwordHash sql.NullString
@@ -28,33 +39,53 @@ func (r *mutationResolver) UpsertUser(ctx context.Context, input model.UserInput
 		}
 		passwordHash = sql.NullString{String: hashed, Valid: true}
 	}
+	phone, err := phoneFromPtr(input.Phone)
+	if err != nil {
+		return nil, err
+	}
 
 	if input.ID != nil && *input.ID != "" {
+		existing, err := r.Queries.GetUser(ctx, *input.ID)
+		if err != nil {
+			return nil, fmt.Errorf("load user %s: %w", *input.ID, err)
+		}
+		locale, err := localeFromPtr(input.Locale, existing.Locale)
+		if err != nil {
+			return nil, err
+		}
+
 		record, err := r.Queries.UpdateUser(ctx, db.UpdateUserParams{
 			Email:        input.Email,
 			FullName:     input.FullName,
-			Phone:        nullStringFromPtr(input.Phone),
+			Phone:        phone,
 			Timezone:     input.Timezone,
 			PasswordHash: passwordHash,
+			Locale:       locale,
 			ID:           *input.ID,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("upsert user: %w", err)
 		}
-		return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.CreatedAt, record.UpdatedAt)
+		return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.Locale, record.CreatedAt, record.UpdatedAt)
 	} else {
+		locale, err := localeFromPtr(input.Locale, notifications.DefaultLocale)
+		if err != nil {
+			return nil, err
+		}
+
 		record, err := r.Queries.CreateUser(ctx, db.CreateUserParams{
 			ID:           uuid.NewString(),
 			Email:        input.Email,
 			FullName:     input.FullName,
-			Phone:        nullStringFromPtr(input.Phone),
+			Phone:        phone,
 			Timezone:     input.Timezone,
 			PasswordHash: passwordHash,
+			Locale:       locale,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("upsert user: %w", err)
 		}
-		return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.CreatedAt, record.UpdatedAt)
+		return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.Locale, record.CreatedAt, record.UpdatedAt)
 	}
 }
 
@@ -76,20 +107,55 @@ func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (*
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.CreatedAt, record.UpdatedAt)
+	return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.Locale, record.CreatedAt, record.UpdatedAt)
 }
 
 // CreatePatient is the resolver for the createPatient field.
 func (r *mutationResolver) CreatePatient(ctx context.Context, input model.PatientInput) (*model.Patient, error) {
-	record, err := r.Queries.CreatePatient(ctx, db.CreatePatientParams{
-		ID:        uuid.NewString(),
-		UserID:    nullStringFromPtr(input.UserID),
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		Timezone:  input.Timezone,
+	locale, err := localeFromPtr(input.Locale, notifications.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	leadTime, err := leadTimeFromPtr(input.PharmacyLeadTimeDays, notifications.DefaultPharmacyLeadTimeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	siloCount, err := siloCountFromPtr(input.SiloCount, defaultSiloCount)
+	if err != nil {
+		return nil, err
+	}
+	showNames := int64(0)
+	if input.ShowMedicationNames != nil && *input.ShowMedicationNames {
+		showNames = 1
+	}
+	autoRefill := int64(0)
+	if input.AutoRefillRequests != nil && *input.AutoRefillRequests {
+		autoRefill = 1
+	}
+
+	var record db.Patient
+	err = r.withTx(ctx, func(qtx *db.Queries) error {
+		var err error
+		record, err = qtx.CreatePatient(ctx, db.CreatePatientParams{
+			ID:                   uuid.NewString(),
+			UserID:               nullStringFromPtr(input.UserID),
+			FirstName:            input.FirstName,
+			LastName:             input.LastName,
+			Timezone:             input.Timezone,
+			Locale:               locale,
+			PharmacyLeadTimeDays: leadTime,
+			SiloCount:            siloCount,
+			ShowMedicationNames:  showNames,
+			AutoRefillRequests:   autoRefill,
+		})
+		if err != nil {
+			return fmt.Errorf("create patient: %w", err)
+		}
+		return syncSilos(ctx, qtx, record.ID, siloCount)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("create patient: %w", err)
+		return nil, err
 	}
 
 	// Automatically set the newly created patient as the active patient
@@ -113,15 +179,56 @@ func (r *mutationResolver) UpdatePatient(ctx context.Context, id string, input m
 		userID = nullStringFromPtr(input.UserID)
 	}
 
-	record, err := r.Queries.UpdatePatient(ctx, db.UpdatePatientParams{
-		UserID:    userID,
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		Timezone:  input.Timezone,
-		ID:        id,
+	locale, err := localeFromPtr(input.Locale, existing.Locale)
+	if err != nil {
+		return nil, err
+	}
+	leadTime, err := leadTimeFromPtr(input.PharmacyLeadTimeDays, existing.PharmacyLeadTimeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	siloCount, err := siloCountFromPtr(input.SiloCount, existing.SiloCount)
+	if err != nil {
+		return nil, err
+	}
+	showNames := existing.ShowMedicationNames
+	if input.ShowMedicationNames != nil {
+		showNames = 0
+		if *input.ShowMedicationNames {
+			showNames = 1
+		}
+	}
+	autoRefill := existing.AutoRefillRequests
+	if input.AutoRefillRequests != nil {
+		autoRefill = 0
+		if *input.AutoRefillRequests {
+			autoRefill = 1
+		}
+	}
+
+	var record db.Patient
+	err = r.withTx(ctx, func(qtx *db.Queries) error {
+		var err error
+		record, err = qtx.UpdatePatient(ctx, db.UpdatePatientParams{
+			UserID:               userID,
+			FirstName:            input.FirstName,
+			LastName:             input.LastName,
+			Timezone:             input.Timezone,
+			Locale:               locale,
+			PharmacyLeadTimeDays: leadTime,
+			SiloCount:            siloCount,
+			ShowMedicationNames:  showNames,
+			AutoRefillRequests:   autoRefill,
+			ID:                   id,
+		})
+		if err != nil {
+			return fmt.Errorf("update patient: %w", err)
+		}
+		return syncSilos(ctx, qtx, record.ID, siloCount)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("update patient: %w", err)
+		return nil, err
 	}
 
 	return r.buildPatientModel(ctx, record)
@@ -129,12 +236,6 @@ func (r *mutationResolver) UpdatePatient(ctx context.Context, id string, input m
 
 // UpsertMedication is the resolver for the upsertMedication field.
 func (r *mutationResolver) UpsertMedication(ctx context.Context, input model.MedicationInput) (*model.Medication, error) {
-	defaultStock := func(existing int64) int64 {
-		if input.StockCount != nil {
-			return int64(*input.StockCount)
-		}
-		return existing
-	}
 	defaultLowStock := func(existing int64) int64 {
 		if input.LowStockThreshold != nil {
 			return int64(*input.LowStockThreshold)
@@ -147,12 +248,26 @@ func (r *mutationResolver) UpsertMedication(ctx context.Context, input model.Med
 		}
 		return existing
 	}
+	if input.StockCount != nil && *input.StockCount < 0 {
+		return nil, fmt.Errorf("stock count cannot be negative")
+	}
 
-	if input.ID == nil || *input.ID == "" {
-		stock := int64(0)
-		if input.StockCount != nil {
-			stock = int64(*input.StockCount)
+	// Setting stockCount is a stock take: the difference from the current
+	// count goes into the ledger as a correction.
+	correctStock := func(qtx *db.Queries, record db.Medication) (db.Medication, error) {
+		if input.StockCount == nil || int64(*input.StockCount) == record.StockCount {
+			return record, nil
 		}
+		_, after, _, err := applyStockMovement(ctx, qtx, stockChange{
+			MedicationID: record.ID,
+			Kind:         model.StockMovementKindCorrection,
+			Quantity:     int64(*input.StockCount) - record.StockCount,
+			Note:         ptrString("Stock count set by upsertMedication"),
+		})
+		return after, err
+	}
+
+	if input.ID == nil || *input.ID == "" {
 		lowStock := int64(0)
 		if input.LowStockThreshold != nil {
 			lowStock = int64(*input.LowStockThreshold)
@@ -162,42 +277,89 @@ func (r *mutationResolver) UpsertMedication(ctx context.Context, input model.Med
 		if input.Label != nil {
 			label = *input.Label
 		}
-		record, err := r.Queries.CreateMedication(ctx, db.CreateMedicationParams{
-			ID:                uuid.NewString(),
-			PatientID:         input.PatientID,
-			Label:             label,
-			Color:             nullStringFromPtr(input.Color),
-			StockCount:        stock,
-			LowStockThreshold: lowStock,
-			CartridgeIndex:    nullIntFromPtr(input.CartridgeIndex),
-			MaxDailyDose:      defaultMaxDailyDose(1),
+
+		var record db.Medication
+		err := r.withTx(ctx, func(qtx *db.Queries) error {
+			if input.CartridgeIndex != nil {
+				stock := int64(0)
+				if input.StockCount != nil {
+					stock = int64(*input.StockCount)
+				}
+				if err := validateSiloAssignment(ctx, qtx, input.PatientID, "", int64(*input.CartridgeIndex), stock); err != nil {
+					return err
+				}
+			}
+
+			created, err := qtx.CreateMedication(ctx, db.CreateMedicationParams{
+				ID:                uuid.NewString(),
+				PatientID:         input.PatientID,
+				Label:             label,
+				Color:             nullStringFromPtr(input.Color),
+				StockCount:        0,
+				LowStockThreshold: lowStock,
+				CartridgeIndex:    nullIntFromPtr(input.CartridgeIndex),
+				MaxDailyDose:      defaultMaxDailyDose(1),
+			})
+			if err != nil {
+				return fmt.Errorf("create medication: %w", err)
+			}
+			if input.CatalogID != nil {
+				if created, err = linkCatalogEntry(ctx, qtx, created.ID, *input.CatalogID); err != nil {
+					return err
+				}
+			}
+			record, err = correctStock(qtx, created)
+			return err
 		})
 		if err != nil {
-			return nil, fmt.Errorf("create medication: %w", err)
+			return nil, err
 		}
 		return buildMedicationModel(record)
 	}
 
-	existing, err := r.Queries.GetMedication(ctx, *input.ID)
-	if err != nil {
-		return nil, fmt.Errorf("load medication %s: %w", *input.ID, err)
-	}
+	var record db.Medication
+	err := r.withTx(ctx, func(qtx *db.Queries) error {
+		existing, err := qtx.GetMedication(ctx, *input.ID)
+		if err != nil {
+			return fmt.Errorf("load medication %s: %w", *input.ID, err)
+		}
 
-	label := existing.Label
-	if input.Label != nil {
-		label = *input.Label
-	}
-	record, err := r.Queries.UpdateMedication(ctx, db.UpdateMedicationParams{
-		Label:             label,
-		Color:             nullStringFromPtr(input.Color),
-		StockCount:        defaultStock(existing.StockCount),
-		LowStockThreshold: defaultLowStock(existing.LowStockThreshold),
-		CartridgeIndex:    nullIntFromPtr(input.CartridgeIndex),
-		MaxDailyDose:      defaultMaxDailyDose(existing.MaxDailyDose),
-		ID:                *input.ID,
+		if input.CartridgeIndex != nil {
+			stock := existing.StockCount
+			if input.StockCount != nil {
+				stock = int64(*input.StockCount)
+			}
+			if err := validateSiloAssignment(ctx, qtx, existing.PatientID, existing.ID, int64(*input.CartridgeIndex), stock); err != nil {
+				return err
+			}
+		}
+
+		label := existing.Label
+		if input.Label != nil {
+			label = *input.Label
+		}
+		updated, err := qtx.UpdateMedication(ctx, db.UpdateMedicationParams{
+			Label:             label,
+			Color:             nullStringFromPtr(input.Color),
+			StockCount:        existing.StockCount,
+			LowStockThreshold: defaultLowStock(existing.LowStockThreshold),
+			CartridgeIndex:    nullIntFromPtr(input.CartridgeIndex),
+			MaxDailyDose:      defaultMaxDailyDose(existing.MaxDailyDose),
+			ID:                *input.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("update medication: %w", err)
+		}
+		if input.CatalogID != nil {
+			if updated, err = linkCatalogEntry(ctx, qtx, updated.ID, *input.CatalogID); err != nil {
+				return err
+			}
+		}
+		record, err = correctStock(qtx, updated)
+		return err
 	})
 	if err != nil {
-		return nil, fmt.Errorf("update medication: %w", err)
+		return nil, err
 	}
 	return buildMedicationModel(record)
 }
@@ -210,6 +372,108 @@ func (r *mutationResolver) DeleteMedication(ctx context.Context, id string) (boo
 	return true, nil
 }
 
+// AssignMedicationToSilo is the resolver for the assignMedicationToSilo field.
+func (r *mutationResolver) AssignMedicationToSilo(ctx context.Context, medicationID string, silo *int) (*model.Medication, error) {
+	return r.assignMedicationToSilo(ctx, medicationID, silo)
+}
+
+// ConfigureSilo is the resolver for the configureSilo field.
+func (r *mutationResolver) ConfigureSilo(ctx context.Context, input model.SiloInput) (*model.Silo, error) {
+	return r.configureSilo(ctx, input)
+}
+
+// AdjustStock is the resolver for the adjustStock field.
+func (r *mutationResolver) AdjustStock(ctx context.Context, input model.StockAdjustmentInput) (*model.StockMovement, error) {
+	switch {
+	case input.Kind == model.StockMovementKindDispense:
+		return nil, fmt.Errorf("dispense movements are recorded by recordDispenseAction")
+	case !input.Kind.IsValid():
+		return nil, fmt.Errorf("invalid stock movement kind %q", input.Kind)
+	case input.Quantity == 0:
+		return nil, fmt.Errorf("quantity must not be zero")
+	case input.Kind == model.StockMovementKindWastage && input.Quantity > 0:
+		return nil, fmt.Errorf("wastage quantity must be negative")
+	case input.Kind == model.StockMovementKindRefill && input.Quantity < 0:
+		return nil, fmt.Errorf("refill quantity must be positive")
+	}
+
+	var movement db.StockMovement
+	err := r.withTx(ctx, func(qtx *db.Queries) error {
+		var err error
+		_, _, movement, err = applyStockMovement(ctx, qtx, stockChange{
+			MedicationID: input.MedicationID,
+			Kind:         input.Kind,
+			Quantity:     int64(input.Quantity),
+			Actor:        input.Actor,
+			Note:         input.Note,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildStockMovementModel(movement)
+}
+
+// RefillMedication is the resolver for the refillMedication field.
+func (r *mutationResolver) RefillMedication(ctx context.Context, medicationID string, quantityAdded int, lotNumber *string, expiresOn *string, actor *string, calibrateSilo *bool, prescriptionID *string) (*model.RefillResult, error) {
+	return r.refillMedication(ctx, refillRequest{
+		MedicationID:   medicationID,
+		Quantity:       quantityAdded,
+		LotNumber:      lotNumber,
+		ExpiresOn:      expiresOn,
+		Actor:          actor,
+		CalibrateSilo:  calibrateSilo != nil && *calibrateSilo,
+		PrescriptionID: prescriptionID,
+	})
+}
+
+// UpsertPrescription is the resolver for the upsertPrescription field.
+func (r *mutationResolver) UpsertPrescription(ctx context.Context, input model.PrescriptionInput) (*model.Prescription, error) {
+	return r.upsertPrescription(ctx, input)
+}
+
+// DeletePrescription is the resolver for the deletePrescription field.
+func (r *mutationResolver) DeletePrescription(ctx context.Context, id string) (bool, error) {
+	if err := r.Queries.DeletePrescription(ctx, id); err != nil {
+		return false, fmt.Errorf("delete prescription: %w", err)
+	}
+	return true, nil
+}
+
+// UpsertPharmacy is the resolver for the upsertPharmacy field.
+func (r *mutationResolver) UpsertPharmacy(ctx context.Context, input model.PharmacyInput) (*model.Pharmacy, error) {
+	return r.upsertPharmacy(ctx, input)
+}
+
+// DeletePharmacy is the resolver for the deletePharmacy field.
+func (r *mutationResolver) DeletePharmacy(ctx context.Context, id string) (bool, error) {
+	if err := r.Queries.DeletePharmacy(ctx, id); err != nil {
+		return false, fmt.Errorf("delete pharmacy: %w", err)
+	}
+	return true, nil
+}
+
+// RequestRefill is the resolver for the requestRefill field.
+func (r *mutationResolver) RequestRefill(ctx context.Context, input model.RefillRequestInput) (*model.RefillRequest, error) {
+	return r.requestRefill(ctx, input)
+}
+
+// UpdateRefillRequestStatus is the resolver for the updateRefillRequestStatus field.
+func (r *mutationResolver) UpdateRefillRequestStatus(ctx context.Context, id string, status model.RefillRequestStatus) (*model.RefillRequest, error) {
+	return r.updateRefillRequestStatus(ctx, id, status)
+}
+
+// ExportAdherenceReport is the resolver for the exportAdherenceReport field.
+func (r *mutationResolver) ExportAdherenceReport(ctx context.Context, patientID string, rangeArg model.DateRangeInput, format model.ReportFormat) (*model.ReportFile, error) {
+	return r.exportAdherenceReport(ctx, patientID, rangeArg, format)
+}
+
+// ImportMedicationRequests is the resolver for the importMedicationRequests field.
+func (r *mutationResolver) ImportMedicationRequests(ctx context.Context, patientID string, bundle string, confirm *string) (*model.MedicationImport, error) {
+	return r.importMedicationRequests(ctx, patientID, bundle, confirm)
+}
+
 // CreateSchedule is the resolver for the createSchedule field.
 func (r *mutationResolver) CreateSchedule(ctx context.Context, input model.ScheduleInput) (*model.Schedule, error) {
 	if len(input.Items) == 0 {
@@ -319,205 +583,158 @@ func (r *mutationResolver) ArchiveSchedule(ctx context.Context, id string) (*mod
 
 // RecordDispenseAction is the resolver for the recordDispenseAction field.
 func (r *mutationResolver) RecordDispenseAction(ctx context.Context, input model.DispenseActionInput) (*model.DispenseEvent, error) {
-	var (
-		record                  db.DispenseEvent
-		err                     error
-		shouldDecrementStock    bool
-		previousStatusWasTaken  bool
-	)
-
-	if input.EventID != nil && *input.EventID != "" {
-		existing, err := r.Queries.GetDispenseEvent(ctx, *input.EventID)
-		if err != nil {
-			return nil, fmt.Errorf("load dispense event %s: %w", *input.EventID, err)
-		}
-		previousStatusWasTaken = existing.Status == string(model.DispenseStatusTaken)
-
-		record, err = r.Queries.UpdateDispenseEvent(ctx, db.UpdateDispenseEventParams{
-			PatientID:    input.PatientID,
-			ScheduleID:   input.ScheduleID,
-			DueAtIso:     formatDBTime(input.DueAtIso),
-			ActedAtIso:   formatNullableTimePtr(input.ActedAtIso),
-			Status:       string(input.Status),
-			ActionSource: nullStringFromPtr(input.ActionSource),
-			ID:           *input.EventID,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("record dispense event: %w", err)
-		}
+	return r.recordDispenseAction(ctx, input)
+}
 
-		shouldDecrementStock = input.Status == model.DispenseStatusTaken && !previousStatusWasTaken
-	} else {
-		record, err = r.Queries.CreateDispenseEvent(ctx, db.CreateDispenseEventParams{
-			ID:           uuid.NewString(),
-			PatientID:    input.PatientID,
-			ScheduleID:   input.ScheduleID,
-			DueAtIso:     formatDBTime(input.DueAtIso),
-			ActedAtIso:   formatNullableTimePtr(input.ActedAtIso),
-			Status:       string(input.Status),
-			ActionSource: nullStringFromPtr(input.ActionSource),
-		})
-		if err != nil {
-			return nil, fmt.Errorf("record dispense event: %w", err)
-		}
+// RequestDispense is the resolver for the requestDispense field.
+// Creates a pending dispense request that the firmware will pick up.
+func (r *mutationResolver) RequestDispense(ctx context.Context, input model.DispenseRequestInput) (*model.DispenseRequest, error) {
+	req := pendingDispenseStore.Add(input.PatientID, input.Silo, input.Qty)
+	return req, nil
+}
 
-		shouldDecrementStock = input.Status == model.DispenseStatusTaken
+// SetActivePatient is the resolver for the setActivePatient field.
+func (r *mutationResolver) SetActivePatient(ctx context.Context, patientID string) (*model.Patient, error) {
+	// Verify the patient exists
+	record, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
 	}
 
-	if input.Status == model.DispenseStatusMissed ||
-	input.Status == model.DispenseStatusCupAbsent ||
-	input.Status == model.DispenseStatusEmptySilo {
-
-		patient, err := r.Queries.GetPatient(ctx, input.PatientID)
-		if err != nil {
-			return nil, fmt.Errorf("load patient %s for status notification: %w", input.PatientID, err)
-		}
-
-		if patient.UserID.Valid {
-			user, err := r.Queries.GetUser(ctx, patient.UserID.String)
-			if err != nil {
-				return nil, fmt.Errorf("load user %s for status notification: %w", patient.UserID.String, err)
-			}
-
-			if user.Phone.Valid && user.Phone.String != "" {
-				var message string
-
-				switch input.Status {
-				case model.DispenseStatusMissed:
-					message = buildMissedMedicationMessage(patient.FirstName)
-
-				case model.DispenseStatusCupAbsent:
-					message = buildCupAbsentMessage(patient.FirstName)
-
-				case model.DispenseStatusEmptySilo:
-					silo := int64(0)
-
-					items, err := r.Queries.ListScheduleItemsBySchedule(ctx, input.ScheduleID)
-					if err == nil && len(items) > 0 {
-						medication, err := r.Queries.GetMedication(ctx, items[0].MedicationID)
-						if err == nil && medication.CartridgeIndex.Valid {
-							silo = medication.CartridgeIndex.Int64
-						}
-					}
-
-					message = buildEmptySiloMessage(patient.FirstName, silo)
-				}
-
-				sender, err := notifications.NewTwilioSenderFromEnv()
-				if err != nil {
-					return nil, fmt.Errorf("init twilio sender for status notification: %w", err)
-				}
-
-				_, err = sender.SendSMS(ctx, user.Phone.String, message)
-				if err != nil {
-					return nil, fmt.Errorf("send status notification: %w", err)
-				}
-			}
-		}
+	// Set as active patient
+	if err := r.Queries.SetActivePatient(ctx, patientID); err != nil {
+		return nil, fmt.Errorf("set active patient: %w", err)
 	}
 
-	if shouldDecrementStock {
-		items, err := r.Queries.ListScheduleItemsBySchedule(ctx, input.ScheduleID)
-		if err != nil {
-			return nil, fmt.Errorf("load schedule items for stock update: %w", err)
-		}
-
-		patient, err := r.Queries.GetPatient(ctx, input.PatientID)
-		if err != nil {
-			return nil, fmt.Errorf("load patient %s for refill notification: %w", input.PatientID, err)
-		}
-
-		hasPhone := false
-		phoneNumber := ""
+	return r.buildPatientModel(ctx, record)
+}
 
-		if patient.UserID.Valid {
-			user, err := r.Queries.GetUser(ctx, patient.UserID.String)
-			if err != nil {
-				return nil, fmt.Errorf("load user %s for refill notification: %w", patient.UserID.String, err)
-			}
+// UpsertNotificationPreference is the resolver for the upsertNotificationPreference field.
+func (r *mutationResolver) UpsertNotificationPreference(ctx context.Context, input model.NotificationPreferenceInput) (*model.NotificationPreference, error) {
+	channel := model.NotificationChannelSms
+	if input.Channel != nil {
+		channel = *input.Channel
+	}
+	if channel == model.NotificationChannelAudio && input.Type != model.NotificationTypeDoseReminder {
+		return nil, fmt.Errorf("AUDIO channel is only available for DOSE_REMINDER notifications")
+	}
 
-			if user.Phone.Valid && user.Phone.String != "" {
-				hasPhone = true
-				phoneNumber = user.Phone.String
-			}
-		}
+	deliveryMode := model.NotificationDeliveryModeImmediate
+	if input.DeliveryMode != nil {
+		deliveryMode = *input.DeliveryMode
+	}
 
-		for _, item := range items {
-			medication, err := r.Queries.GetMedication(ctx, item.MedicationID)
-			if err != nil {
-				return nil, fmt.Errorf("load medication %s: %w", item.MedicationID, err)
-			}
+	digestTime := "09:00"
+	if input.DigestTime != nil {
+		digestTime = *input.DigestTime
+	}
+	if _, _, err := notifications.ParseClock(digestTime); err != nil {
+		return nil, fmt.Errorf("digest time: %w", err)
+	}
 
-			oldStock := medication.StockCount
-			newStock := oldStock - item.Qty
-			if newStock < 0 {
-				newStock = 0
-			}
+	quietStart, err := nullClockFromPtr(input.QuietHoursStart)
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours start: %w", err)
+	}
+	quietEnd, err := nullClockFromPtr(input.QuietHoursEnd)
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours end: %w", err)
+	}
+	if quietStart.Valid != quietEnd.Valid {
+		return nil, fmt.Errorf("quiet hours require both start and end")
+	}
 
-			_, err = r.Queries.UpdateMedication(ctx, db.UpdateMedicationParams{
-				Label:             medication.Label,
-				Color:             medication.Color,
-				StockCount:        newStock,
-				LowStockThreshold: medication.LowStockThreshold,
-				CartridgeIndex:    medication.CartridgeIndex,
-				MaxDailyDose:      medication.MaxDailyDose,
-				ID:                medication.ID,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("update medication stock %s: %w", medication.ID, err)
-			}
+	enabled := int64(0)
+	if input.Enabled {
+		enabled = 1
+	}
 
-			threshold := medication.LowStockThreshold
-			crossedLowThreshold := oldStock > threshold && newStock <= threshold
-			justEmptied := oldStock > 0 && newStock == 0
+	record, err := r.Queries.UpsertNotificationPreference(ctx, db.UpsertNotificationPreferenceParams{
+		ID:               uuid.NewString(),
+		UserID:           input.UserID,
+		NotificationType: string(input.Type),
+		Channel:          string(channel),
+		Enabled:          enabled,
+		QuietHoursStart:  quietStart,
+		QuietHoursEnd:    quietEnd,
+		DeliveryMode:     string(deliveryMode),
+		DigestTime:       digestTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upsert notification preference: %w", err)
+	}
+	return buildNotificationPreferenceModel(record)
+}
 
-			if crossedLowThreshold || justEmptied {
-				if hasPhone {
-					silo := int64(0)
-					if medication.CartridgeIndex.Valid {
-						silo = medication.CartridgeIndex.Int64
-					}
+// UpdateVoiceSettings is the resolver for the updateVoiceSettings field.
+func (r *mutationResolver) UpdateVoiceSettings(ctx context.Context, patientID string, input model.VoiceSettingsInput) (*model.VoiceSettings, error) {
+	patient, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s: %w", patientID, err)
+	}
 
-					message := buildRefillMessage(patient.FirstName, silo, newStock)
+	speakingRate := notifications.DefaultSpeakingRate
+	if input.SpeakingRate != nil {
+		speakingRate = *input.SpeakingRate
+	}
+	if speakingRate < notifications.MinSpeakingRate || speakingRate > notifications.MaxSpeakingRate {
+		return nil, fmt.Errorf("speaking rate must be between %.2f and %.1f", notifications.MinSpeakingRate, notifications.MaxSpeakingRate)
+	}
 
-					sender, err := notifications.NewTwilioSenderFromEnv()
-					if err != nil {
-						return nil, fmt.Errorf("init twilio sender for refill notification: %w", err)
-					}
+	sampleRate := notifications.DefaultSampleRateHz
+	if input.SampleRateHertz != nil {
+		sampleRate = *input.SampleRateHertz
+	}
+	if !notifications.IsSupportedSampleRate(sampleRate) {
+		return nil, fmt.Errorf("unsupported sample rate %d", sampleRate)
+	}
 
-					_, err = sender.SendSMS(ctx, phoneNumber, message)
-					if err != nil {
-						return nil, fmt.Errorf("send refill notification: %w", err)
-					}
-				}
-			}
-		}
+	languageCode, err := languageCodeFromPtr(input.LanguageCode)
+	if err != nil {
+		return nil, err
 	}
 
-	return buildDispenseEvent(record)
-}
+	if _, err := r.Queries.UpsertPatientVoiceSettings(ctx, db.UpsertPatientVoiceSettingsParams{
+		PatientID:    patient.ID,
+		VoiceName:    nullTrimmedStringFromPtr(input.VoiceName),
+		LanguageCode: languageCode,
+		SpeakingRate: speakingRate,
+		SampleRateHz: int64(sampleRate),
+	}); err != nil {
+		return nil, fmt.Errorf("update voice settings: %w", err)
+	}
 
-// RequestDispense is the resolver for the requestDispense field.
-// Creates a pending dispense request that the firmware will pick up.
-func (r *mutationResolver) RequestDispense(ctx context.Context, input model.DispenseRequestInput) (*model.DispenseRequest, error) {
-	req := pendingDispenseStore.Add(input.PatientID, input.Silo, input.Qty)
-	return req, nil
+	return r.loadVoiceSettings(ctx, patient.ID, patient.Locale)
 }
 
-// SetActivePatient is the resolver for the setActivePatient field.
-func (r *mutationResolver) SetActivePatient(ctx context.Context, patientID string) (*model.Patient, error) {
-	// Verify the patient exists
-	record, err := r.Queries.GetPatient(ctx, patientID)
+// UploadVoiceMessage is the resolver for the uploadVoiceMessage field.
+func (r *mutationResolver) UploadVoiceMessage(ctx context.Context, input model.VoiceMessageInput) (*model.VoiceMessage, error) {
+	if input.File.Size > notifications.MaxVoiceMessageBytes {
+		return nil, fmt.Errorf("recording larger than %d MiB", notifications.MaxVoiceMessageBytes>>20)
+	}
+	data, err := io.ReadAll(io.LimitReader(input.File.File, notifications.MaxVoiceMessageBytes+1))
 	if err != nil {
-		return nil, fmt.Errorf("patient not found: %w", err)
+		return nil, fmt.Errorf("read upload: %w", err)
 	}
 
-	// Set as active patient
-	if err := r.Queries.SetActivePatient(ctx, patientID); err != nil {
-		return nil, fmt.Errorf("set active patient: %w", err)
+	message, err := notifications.SaveVoiceMessage(ctx, r.Queries, notifications.VoiceMessageUpload{
+		PatientID:  input.PatientID,
+		ScheduleID: input.ScheduleID,
+		DueAt:      input.DueAtIso,
+		Data:       data,
+	})
+	if err != nil {
+		return nil, err
 	}
+	return buildVoiceMessageModel(message)
+}
 
-	return r.buildPatientModel(ctx, record)
+// DeleteVoiceMessage is the resolver for the deleteVoiceMessage field.
+func (r *mutationResolver) DeleteVoiceMessage(ctx context.Context, id string) (bool, error) {
+	if err := notifications.DeleteVoiceMessage(ctx, r.Queries, id); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Ping is the resolver for the ping field.
@@ -533,7 +750,7 @@ func (r *queryResolver) Users(ctx context.Context) ([]*model.User, error) {
 	}
 	result := make([]*model.User, 0, len(records))
 	for _, rec := range records {
-		user, err := r.buildUserModel(ctx, rec.ID, rec.Email, rec.FullName, rec.Phone, rec.Timezone, rec.CreatedAt, rec.UpdatedAt)
+		user, err := r.buildUserModel(ctx, rec.ID, rec.Email, rec.FullName, rec.Phone, rec.Timezone, rec.Locale, rec.CreatedAt, rec.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -548,7 +765,7 @@ func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error
 	if err != nil {
 		return nil, fmt.Errorf("get user: %w", err)
 	}
-	return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.CreatedAt, record.UpdatedAt)
+	return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.Locale, record.CreatedAt, record.UpdatedAt)
 }
 
 // UserByEmail is the resolver for the userByEmail field.
@@ -560,7 +777,7 @@ func (r *queryResolver) UserByEmail(ctx context.Context, email string) (*model.U
 		}
 		return nil, fmt.Errorf("get user by email: %w", err)
 	}
-	return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.CreatedAt, record.UpdatedAt)
+	return r.buildUserModel(ctx, record.ID, record.Email, record.FullName, record.Phone, record.Timezone, record.Locale, record.CreatedAt, record.UpdatedAt)
 }
 
 // Patient is the resolver for the patient field.
@@ -645,6 +862,69 @@ func (r *queryResolver) DispenseEvents(ctx context.Context, patientID string, ra
 	return events, nil
 }
 
+// AdherenceReport is the resolver for the adherenceReport field.
+func (r *queryResolver) AdherenceReport(ctx context.Context, patientID string, rangeArg model.DateRangeInput, groupBy *model.AdherenceGroupBy, onTimeMinutes *int) (*model.AdherenceReport, error) {
+	return r.adherenceReport(ctx, patientID, rangeArg, groupBy, onTimeMinutes)
+}
+
+// MedicationForecast is the resolver for the medicationForecast field.
+func (r *queryResolver) MedicationForecast(ctx context.Context, patientID string) ([]*model.MedicationForecast, error) {
+	patient, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s: %w", patientID, err)
+	}
+
+	forecasts, err := notifications.ForecastMedications(ctx, r.Queries, patient, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("forecast medications: %w", err)
+	}
+
+	result := make([]*model.MedicationForecast, 0, len(forecasts))
+	for _, forecast := range forecasts {
+		item, err := buildMedicationForecastModel(forecast)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// SearchMedicationCatalog is the resolver for the searchMedicationCatalog field.
+func (r *queryResolver) SearchMedicationCatalog(ctx context.Context, query string, limit *int) ([]*model.CatalogEntry, error) {
+	return r.searchMedicationCatalog(ctx, query, limit)
+}
+
+// CatalogEntry is the resolver for the catalogEntry field.
+func (r *queryResolver) CatalogEntry(ctx context.Context, id string) (*model.CatalogEntry, error) {
+	return r.loadCatalogEntry(ctx, id)
+}
+
+// MedicationInteractions is the resolver for the medicationInteractions field.
+func (r *queryResolver) MedicationInteractions(ctx context.Context, patientID string) ([]*model.InteractionWarning, error) {
+	return r.loadInteractionWarnings(ctx, patientID, nil)
+}
+
+// StockHistory is the resolver for the stockHistory field.
+func (r *queryResolver) StockHistory(ctx context.Context, medicationID string, rangeArg *model.DateRangeInput, limit *int) (*model.StockHistory, error) {
+	return r.loadStockHistory(ctx, medicationID, rangeArg, limit)
+}
+
+// MedicationLots is the resolver for the medicationLots field.
+func (r *queryResolver) MedicationLots(ctx context.Context, medicationID string, includeDepleted *bool) ([]*model.MedicationLot, error) {
+	return r.loadMedicationLots(ctx, medicationID, includeDepleted != nil && *includeDepleted)
+}
+
+// DispenseLots is the resolver for the dispenseLots field.
+func (r *queryResolver) DispenseLots(ctx context.Context, dispenseEventID string) ([]*model.LotConsumption, error) {
+	return r.loadDispenseLots(ctx, dispenseEventID)
+}
+
+// LotDispenseEvents is the resolver for the lotDispenseEvents field.
+func (r *queryResolver) LotDispenseEvents(ctx context.Context, lotID string) ([]*model.DispenseEvent, error) {
+	return r.loadLotDispenseEvents(ctx, lotID)
+}
+
 // DueNow is the resolver for the dueNow field.
 // Returns schedules that are due within the specified time window (default +-1 minute).
 // This endpoint is designed for firmware to poll every minute.
@@ -722,8 +1002,6 @@ func (r *queryResolver) DueNow(ctx context.Context, patientID string, windowMinu
 	return result, nil
 }
 
-
-
 // PendingDispense is the resolver for the pendingDispense field.
 // Returns and clears any pending dispense request for the patient.
 // The firmware should poll this endpoint to check for manual dispense requests.
@@ -732,6 +1010,178 @@ func (r *queryResolver) PendingDispense(ctx context.Context, patientID string) (
 	return req, nil
 }
 
+// PendingCalibration is the resolver for the pendingCalibration field.
+func (r *queryResolver) PendingCalibration(ctx context.Context, patientID string) (*model.SiloCalibrationRequest, error) {
+	req := siloCalibrationStore.Pop(patientID)
+	if req == nil {
+		return nil, nil
+	}
+	if err := r.Queries.MarkSiloCalibrated(ctx, db.MarkSiloCalibratedParams{
+		CalibratedAt: sql.NullString{String: formatDBTime(time.Now()), Valid: true},
+		PatientID:    patientID,
+		SiloIndex:    int64(req.Silo),
+	}); err != nil {
+		return nil, fmt.Errorf("mark silo %d calibrated: %w", req.Silo, err)
+	}
+	return req, nil
+}
+
+// NotificationPreferences is the resolver for the notificationPreferences field.
+func (r *queryResolver) NotificationPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	return r.loadNotificationPreferences(ctx, userID)
+}
+
+// NotificationEvents is the resolver for the notificationEvents field.
+func (r *queryResolver) NotificationEvents(ctx context.Context, patientID string, rangeArg *model.DateRangeInput, channel *model.NotificationChannel, status *model.NotificationStatus, limit *int, offset *int) (*model.NotificationEventPage, error) {
+	pageSize := 50
+	if limit != nil {
+		pageSize = *limit
+	}
+	if pageSize < 1 || pageSize > maxNotificationPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxNotificationPageSize)
+	}
+	skip := 0
+	if offset != nil {
+		skip = *offset
+	}
+	if skip < 0 {
+		return nil, fmt.Errorf("offset must not be negative")
+	}
+
+	filter := db.ListNotificationEventsPageParams{
+		PatientID: patientID,
+		Limit:     int64(pageSize),
+		Offset:    int64(skip),
+	}
+	if rangeArg != nil {
+		if !rangeArg.End.After(rangeArg.Start) {
+			return nil, fmt.Errorf("range end must be after start")
+		}
+		filter.Start = sql.NullString{String: formatDBTime(rangeArg.Start), Valid: true}
+		filter.End = sql.NullString{String: formatDBTime(rangeArg.End), Valid: true}
+	}
+	if channel != nil {
+		filter.Channel = sql.NullString{String: string(*channel), Valid: true}
+	}
+	if status != nil {
+		filter.Status = sql.NullString{String: string(*status), Valid: true}
+	}
+
+	items, err := r.loadNotificationEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	total, err := r.Queries.CountNotificationEvents(ctx, db.CountNotificationEventsParams{
+		PatientID: filter.PatientID,
+		Start:     filter.Start,
+		End:       filter.End,
+		Channel:   filter.Channel,
+		Status:    filter.Status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("count notification events: %w", err)
+	}
+
+	return &model.NotificationEventPage{
+		Items:      items,
+		TotalCount: int(total),
+		HasMore:    skip+len(items) < int(total),
+	}, nil
+}
+
+// PreviewNotification is the resolver for the previewNotification field.
+func (r *queryResolver) PreviewNotification(ctx context.Context, patientID string, typeArg model.NotificationType, channel *model.NotificationChannel, locale *string) (*model.NotificationPreview, error) {
+	patient, err := r.Queries.GetPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("get patient: %w", err)
+	}
+
+	ch := model.NotificationChannelSms
+	if channel != nil {
+		ch = *channel
+	}
+
+	// Default to the recipient's locale: the caregiver reads SMS, the patient
+	// hears the device audio.
+	defaultLocale := patient.Locale
+	if ch == model.NotificationChannelSms && patient.UserID.Valid {
+		user, err := r.Queries.GetUser(ctx, patient.UserID.String)
+		if err != nil {
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+		defaultLocale = user.Locale
+	}
+	resolvedLocale, err := localeFromPtr(locale, defaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(patient.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now()
+	data := notifications.MessageData{
+		FirstName: patient.FirstName,
+		DueAt:     now.In(loc),
+		Silo:      1,
+	}
+
+	// Fill in sample values from the patient's own regimen where possible.
+	schedules, err := r.Queries.ListSchedulesByPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		if schedule.Status != string(model.ScheduleStatusActive) {
+			continue
+		}
+		items, err := r.Queries.ListScheduleItemsBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list schedule items: %w", err)
+		}
+		data.Medications = notifications.FormatDoseList(items, patient.ShowMedicationNames != 0)
+
+		if start, err := parseDBTime(schedule.StartDateIso); err == nil {
+			occurrences, err := ExpandRRULE(schedule.Rrule, start, now, now.Add(48*time.Hour))
+			if err == nil && len(occurrences) > 0 {
+				data.DueAt = occurrences[0].In(loc)
+			}
+		}
+		break
+	}
+
+	medications, err := r.Queries.ListMedicationsByPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("list medications: %w", err)
+	}
+	for _, medication := range medications {
+		if !medication.CartridgeIndex.Valid {
+			continue
+		}
+		data.Silo = medication.CartridgeIndex.Int64 + 1
+		data.Stock = medication.LowStockThreshold
+		break
+	}
+	data.RunOutAt = now.In(loc).AddDate(0, 0, notifications.RunOutAlertDays())
+	data.RefillBy = data.RunOutAt.AddDate(0, 0, -int(patient.PharmacyLeadTimeDays))
+	data.LotNumber = "A1234"
+	data.Prescriber = "Dr. Rivera"
+	data.ExpiresOn = now.In(loc).AddDate(0, 0, notifications.LotExpiryAlertDays())
+
+	message, err := notifications.RenderMessage(resolvedLocale, string(typeArg), string(ch), data)
+	if err != nil {
+		return nil, fmt.Errorf("preview notification: %w", err)
+	}
+
+	return &model.NotificationPreview{
+		Type:    typeArg,
+		Channel: ch,
+		Locale:  resolvedLocale,
+		Message: message,
+	}, nil
+}
+
 // ActivePatient is the resolver for the activePatient field.
 // Returns the currently active patient for firmware to use.
 func (r *queryResolver) ActivePatient(ctx context.Context) (*model.Patient, error) {
@@ -751,11 +1201,64 @@ func (r *queryResolver) ActivePatient(ctx context.Context) (*model.Patient, erro
 	return r.buildPatientModel(ctx, record)
 }
 
+// Pharmacies is the resolver for the pharmacies field.
+func (r *queryResolver) Pharmacies(ctx context.Context) ([]*model.Pharmacy, error) {
+	return r.loadPharmacies(ctx)
+}
+
+// RefillRequests is the resolver for the refillRequests field.
+func (r *queryResolver) RefillRequests(ctx context.Context, patientID string, status *model.RefillRequestStatus) ([]*model.RefillRequest, error) {
+	return r.loadRefillRequests(ctx, patientID, status)
+}
+
+// Medication is the resolver for the medication field.
+func (r *refillRequestResolver) Medication(ctx context.Context, obj *model.RefillRequest) (*model.Medication, error) {
+	record, err := r.Queries.GetMedication(ctx, obj.MedicationID)
+	if err != nil {
+		return nil, fmt.Errorf("load medication %s: %w", obj.MedicationID, err)
+	}
+	return buildMedicationModel(record)
+}
+
+// Pharmacy is the resolver for the pharmacy field.
+func (r *refillRequestResolver) Pharmacy(ctx context.Context, obj *model.RefillRequest) (*model.Pharmacy, error) {
+	if obj.PharmacyID == nil {
+		return nil, nil
+	}
+	record, err := r.Queries.GetPharmacy(ctx, *obj.PharmacyID)
+	if err != nil {
+		return nil, fmt.Errorf("load pharmacy %s: %w", *obj.PharmacyID, err)
+	}
+	return buildPharmacyModel(record)
+}
+
+// Deliveries is the resolver for the deliveries field.
+func (r *refillRequestResolver) Deliveries(ctx context.Context, obj *model.RefillRequest) ([]*model.RefillRequestDelivery, error) {
+	return r.loadRefillRequestDeliveries(ctx, obj.ID)
+}
+
+// InteractionWarnings is the resolver for the interactionWarnings field.
+func (r *scheduleResolver) InteractionWarnings(ctx context.Context, obj *model.Schedule) ([]*model.InteractionWarning, error) {
+	return r.scheduleInteractionWarnings(ctx, obj)
+}
+
+// Medication returns MedicationResolver implementation.
+func (r *Resolver) Medication() MedicationResolver { return &medicationResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// RefillRequest returns RefillRequestResolver implementation.
+func (r *Resolver) RefillRequest() RefillRequestResolver { return &refillRequestResolver{r} }
+
+// Schedule returns ScheduleResolver implementation.
+func (r *Resolver) Schedule() ScheduleResolver { return &scheduleResolver{r} }
+
+type medicationResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type refillRequestResolver struct{ *Resolver }
+type scheduleResolver struct{ *Resolver }
@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+)
+
+const (
+	defaultSiloCount    = 3
+	maxSiloCount        = 16
+	defaultSiloCapacity = 60
+)
+
+func siloCountFromPtr(val *int, fallback int64) (int64, error) {
+	if val == nil {
+		return fallback, nil
+	}
+	if *val < 1 || *val > maxSiloCount {
+		return 0, fmt.Errorf("silo count must be between 1 and %d", maxSiloCount)
+	}
+	return int64(*val), nil
+}
+
+// syncSilos makes the patient's silo rows match count, refusing to remove a
+// silo that still has a medication assigned. Run it inside withTx.
+func syncSilos(ctx context.Context, q *db.Queries, patientID string, count int64) error {
+	stranded, err := q.ListMedicationsFromSilo(ctx, db.ListMedicationsFromSiloParams{
+		PatientID:      patientID,
+		CartridgeIndex: sql.NullInt64{Int64: count, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("list medications in removed silos: %w", err)
+	}
+	if len(stranded) > 0 {
+		return fmt.Errorf("medication %s is assigned to silo %d; move it before reducing the silo count to %d", stranded[0].ID, stranded[0].CartridgeIndex.Int64, count)
+	}
+
+	if err := q.DeleteSilosFrom(ctx, db.DeleteSilosFromParams{
+		PatientID: patientID,
+		SiloIndex: count,
+	}); err != nil {
+		return fmt.Errorf("remove silos: %w", err)
+	}
+
+	now := formatDBTime(time.Now())
+	for i := int64(0); i < count; i++ {
+		if err := q.CreateSilo(ctx, db.CreateSiloParams{
+			ID:        uuid.NewString(),
+			PatientID: patientID,
+			SiloIndex: i,
+			Capacity:  defaultSiloCapacity,
+			UpdatedAt: now,
+		}); err != nil {
+			return fmt.Errorf("create silo %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateSiloAssignment checks that the patient's device has the silo, that
+// no other medication is loaded in it and that stock pills fit.
+func validateSiloAssignment(ctx context.Context, q *db.Queries, patientID, medicationID string, index int64, stock int64) error {
+	silo, err := q.GetSilo(ctx, db.GetSiloParams{
+		PatientID: patientID,
+		SiloIndex: index,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("patient %s has no silo %d", patientID, index)
+	}
+	if err != nil {
+		return fmt.Errorf("load silo %d: %w", index, err)
+	}
+
+	occupant, err := q.GetMedicationInSilo(ctx, db.GetMedicationInSiloParams{
+		PatientID:      patientID,
+		CartridgeIndex: sql.NullInt64{Int64: index, Valid: true},
+	})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("load medication in silo %d: %w", index, err)
+	case occupant.ID != medicationID:
+		return fmt.Errorf("silo %d is already assigned to medication %s", index, occupant.ID)
+	}
+
+	if stock > silo.Capacity {
+		return fmt.Errorf("%d pills do not fit in silo %d (capacity %d)", stock, index, silo.Capacity)
+	}
+	return nil
+}
+
+// assignMedicationToSilo moves a medication into a silo, or out of its silo
+// when index is nil.
+func (r *Resolver) assignMedicationToSilo(ctx context.Context, medicationID string, index *int) (*model.Medication, error) {
+	var record db.Medication
+	err := r.withTx(ctx, func(qtx *db.Queries) error {
+		medication, err := qtx.GetMedication(ctx, medicationID)
+		if err != nil {
+			return fmt.Errorf("load medication %s: %w", medicationID, err)
+		}
+
+		if index != nil {
+			if *index < 0 {
+				return fmt.Errorf("silo index cannot be negative")
+			}
+			if err := validateSiloAssignment(ctx, qtx, medication.PatientID, medication.ID, int64(*index), medication.StockCount); err != nil {
+				return err
+			}
+		}
+
+		record, err = qtx.SetMedicationSilo(ctx, db.SetMedicationSiloParams{
+			CartridgeIndex: nullIntFromPtr(index),
+			ID:             medication.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("assign medication to silo: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildMedicationModel(record)
+}
+
+// configureSilo updates a silo's capacity and pill size. The capacity cannot
+// drop below what is loaded.
+func (r *Resolver) configureSilo(ctx context.Context, input model.SiloInput) (*model.Silo, error) {
+	if input.Capacity < 1 {
+		return nil, fmt.Errorf("capacity must be positive")
+	}
+	if input.PillSizeMm != nil && *input.PillSizeMm <= 0 {
+		return nil, fmt.Errorf("pill size must be positive")
+	}
+
+	var (
+		silo     db.Silo
+		occupant *db.Medication
+	)
+	err := r.withTx(ctx, func(qtx *db.Queries) error {
+		medication, err := qtx.GetMedicationInSilo(ctx, db.GetMedicationInSiloParams{
+			PatientID:      input.PatientID,
+			CartridgeIndex: sql.NullInt64{Int64: int64(input.Index), Valid: true},
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return fmt.Errorf("load medication in silo %d: %w", input.Index, err)
+		default:
+			if medication.StockCount > int64(input.Capacity) {
+				return fmt.Errorf("silo %d holds %d pills, more than a capacity of %d", input.Index, medication.StockCount, input.Capacity)
+			}
+			occupant = &medication
+		}
+
+		pillSize := sql.NullFloat64{}
+		if input.PillSizeMm != nil {
+			pillSize = sql.NullFloat64{Float64: *input.PillSizeMm, Valid: true}
+		}
+		silo, err = qtx.UpdateSilo(ctx, db.UpdateSiloParams{
+			Capacity:   int64(input.Capacity),
+			PillSizeMm: pillSize,
+			UpdatedAt:  formatDBTime(time.Now()),
+			PatientID:  input.PatientID,
+			SiloIndex:  int64(input.Index),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("patient %s has no silo %d", input.PatientID, input.Index)
+		}
+		if err != nil {
+			return fmt.Errorf("update silo %d: %w", input.Index, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildSiloModel(silo, occupant)
+}
+
+func (r *Resolver) loadSilos(ctx context.Context, patientID string) ([]*model.Silo, error) {
+	rows, err := r.Queries.ListSilosByPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("list silos: %w", err)
+	}
+	medications, err := r.Queries.ListMedicationsByPatient(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("list medications: %w", err)
+	}
+
+	loaded := make(map[int64]*db.Medication, len(medications))
+	for i := range medications {
+		if medications[i].CartridgeIndex.Valid {
+			loaded[medications[i].CartridgeIndex.Int64] = &medications[i]
+		}
+	}
+
+	result := make([]*model.Silo, 0, len(rows))
+	for _, row := range rows {
+		silo, err := buildSiloModel(row, loaded[row.SiloIndex])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, silo)
+	}
+	return result, nil
+}
@@ -0,0 +1,377 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+	"pillbox/internal/pharmacy"
+)
+
+const (
+	defaultStockHistoryLimit = 100
+	maxStockHistoryLimit     = 1000
+)
+
+// stockChange is one movement to apply to a medication's stock.
+type stockChange struct {
+	MedicationID    string
+	Kind            model.StockMovementKind
+	Quantity        int64
+	Actor           *string
+	DispenseEventID string
+	Note            *string
+	LotNumber       *string
+	// ExpiresOn is a YYYY-MM-DD date.
+	ExpiresOn *string
+}
+
+// applyStockMovement adds change.Quantity to the medication's stock and
+// records it in the ledger. Stock never goes below zero: a decrement larger
+// than the stock only removes what was there and the shortfall is noted, so
+// the ledger always sums to stock_count. An increase that lifts stock above
+// the low-stock threshold clears the low-stock and run-out alerts, so the
+// next time stock runs low the caregiver hears about it again. Run it inside
+// withTx.
+func applyStockMovement(ctx context.Context, q *db.Queries, change stockChange) (before, after db.Medication, movement db.StockMovement, err error) {
+	before, err = q.GetMedication(ctx, change.MedicationID)
+	if err != nil {
+		return before, after, movement, fmt.Errorf("load medication %s: %w", change.MedicationID, err)
+	}
+
+	quantity := change.Quantity
+	note := change.Note
+	if before.StockCount+quantity < 0 {
+		shortfall := fmt.Sprintf("short by %d", -(before.StockCount + quantity))
+		if note != nil && *note != "" {
+			shortfall = *note + "; " + shortfall
+		}
+		note = &shortfall
+		quantity = -before.StockCount
+	}
+
+	after, err = q.AdjustMedicationStock(ctx, db.AdjustMedicationStockParams{
+		StockCount: quantity,
+		ID:         before.ID,
+	})
+	if err != nil {
+		return before, after, movement, fmt.Errorf("update medication stock %s: %w", before.ID, err)
+	}
+
+	movement, err = q.CreateStockMovement(ctx, db.CreateStockMovementParams{
+		ID:              uuid.NewString(),
+		MedicationID:    before.ID,
+		Kind:            string(change.Kind),
+		Quantity:        quantity,
+		BalanceAfter:    after.StockCount,
+		Actor:           nullTrimmedStringFromPtr(change.Actor),
+		DispenseEventID: nullableID(change.DispenseEventID),
+		Note:            nullTrimmedStringFromPtr(note),
+		CreatedAt:       formatDBTime(time.Now()),
+		LotNumber:       nullTrimmedStringFromPtr(change.LotNumber),
+		ExpiresOn:       nullTrimmedStringFromPtr(change.ExpiresOn),
+	})
+	if err != nil {
+		return before, after, movement, fmt.Errorf("record stock movement: %w", err)
+	}
+
+	if quantity > 0 && after.StockCount > after.LowStockThreshold &&
+		(after.LowStockAlertedAt.Valid || after.RunoutAlertedAt.Valid) {
+		if err := q.ClearStockAlerts(ctx, after.ID); err != nil {
+			return before, after, movement, fmt.Errorf("clear stock alerts: %w", err)
+		}
+		after.LowStockAlertedAt = sql.NullString{}
+		after.RunoutAlertedAt = sql.NullString{}
+	}
+
+	if err := allocateLots(ctx, q, after, movement, change); err != nil {
+		return before, after, movement, err
+	}
+	return before, after, movement, nil
+}
+
+// allocateLots keeps the medication's lots in step with a movement: added
+// pills become a new lot and removed pills are taken from the oldest loaded
+// lots first. Each lot touched is linked to the movement for tracing.
+func allocateLots(ctx context.Context, q *db.Queries, medication db.Medication, movement db.StockMovement, change stockChange) error {
+	if movement.Quantity > 0 {
+		lot, err := q.CreateMedicationLot(ctx, db.CreateMedicationLotParams{
+			ID:                uuid.NewString(),
+			MedicationID:      medication.ID,
+			LotNumber:         nullTrimmedStringFromPtr(change.LotNumber),
+			QuantityLoaded:    movement.Quantity,
+			QuantityRemaining: movement.Quantity,
+			ExpiresOn:         nullTrimmedStringFromPtr(change.ExpiresOn),
+			Silo:              medication.CartridgeIndex,
+			LoadedAt:          movement.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("create medication lot: %w", err)
+		}
+		return linkLot(ctx, q, movement.ID, lot.ID, movement.Quantity)
+	}
+
+	remaining := -movement.Quantity
+	if remaining == 0 {
+		return nil
+	}
+	lots, err := q.ListAvailableLotsFIFO(ctx, medication.ID)
+	if err != nil {
+		return fmt.Errorf("list medication lots: %w", err)
+	}
+	for _, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+		take := min(remaining, lot.QuantityRemaining)
+		if err := q.ConsumeMedicationLot(ctx, db.ConsumeMedicationLotParams{
+			QuantityRemaining: take,
+			ID:                lot.ID,
+		}); err != nil {
+			return fmt.Errorf("consume medication lot %s: %w", lot.ID, err)
+		}
+		if err := linkLot(ctx, q, movement.ID, lot.ID, -take); err != nil {
+			return err
+		}
+		remaining -= take
+	}
+	return nil
+}
+
+func linkLot(ctx context.Context, q *db.Queries, movementID, lotID string, quantity int64) error {
+	if err := q.CreateStockMovementLot(ctx, db.CreateStockMovementLotParams{
+		StockMovementID: movementID,
+		LotID:           lotID,
+		Quantity:        quantity,
+	}); err != nil {
+		return fmt.Errorf("link stock movement to lot: %w", err)
+	}
+	return nil
+}
+
+// refillRequest describes pills added to a medication by a refill.
+type refillRequest struct {
+	MedicationID string
+	Quantity     int
+	LotNumber    *string
+	// ExpiresOn is a YYYY-MM-DD date.
+	ExpiresOn     *string
+	Actor         *string
+	CalibrateSilo bool
+	// PrescriptionID picks the prescription the refill uses; nil picks the
+	// refillable one that expires first.
+	PrescriptionID *string
+}
+
+// refillMedication adds req.Quantity pills to the medication's stock and uses
+// a refill from its prescription; applyStockMovement re-arms the low-stock
+// and run-out alerts.
+func (r *Resolver) refillMedication(ctx context.Context, req refillRequest) (*model.RefillResult, error) {
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity added must be positive")
+	}
+	if req.ExpiresOn != nil && *req.ExpiresOn != "" {
+		if _, err := time.Parse(time.DateOnly, *req.ExpiresOn); err != nil {
+			return nil, fmt.Errorf("expiresOn must be YYYY-MM-DD")
+		}
+	}
+
+	var (
+		medication   db.Medication
+		movement     db.StockMovement
+		prescription *db.Prescription
+	)
+	err := r.withTx(ctx, func(qtx *db.Queries) error {
+		var err error
+		_, medication, movement, err = applyStockMovement(ctx, qtx, stockChange{
+			MedicationID: req.MedicationID,
+			Kind:         model.StockMovementKindRefill,
+			Quantity:     int64(req.Quantity),
+			Actor:        req.Actor,
+			LotNumber:    req.LotNumber,
+			ExpiresOn:    req.ExpiresOn,
+		})
+		if err != nil {
+			return err
+		}
+		if req.CalibrateSilo && !medication.CartridgeIndex.Valid {
+			return fmt.Errorf("medication %s is not loaded in a silo", medication.ID)
+		}
+		if medication.CartridgeIndex.Valid {
+			if err := validateSiloAssignment(ctx, qtx, medication.PatientID, medication.ID, medication.CartridgeIndex.Int64, medication.StockCount); err != nil {
+				return err
+			}
+		}
+
+		prescriptionID := ""
+		if req.PrescriptionID != nil {
+			prescriptionID = strings.TrimSpace(*req.PrescriptionID)
+		}
+		prescription, err = usePrescriptionRefill(ctx, qtx, medication, prescriptionID)
+		if err != nil {
+			return err
+		}
+
+		// Loading the pills means any open refill request was picked up.
+		open, err := qtx.GetOpenRefillRequest(ctx, medication.ID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return fmt.Errorf("load open refill request: %w", err)
+		default:
+			if _, err := pharmacy.SetStatus(ctx, qtx, open.ID, pharmacy.StatusPickedUp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	medicationModel, err := buildMedicationModel(medication)
+	if err != nil {
+		return nil, err
+	}
+	movementModel, err := buildStockMovementModel(movement)
+	if err != nil {
+		return nil, err
+	}
+	result := &model.RefillResult{
+		Medication: medicationModel,
+		Movement:   movementModel,
+	}
+
+	if prescription != nil {
+		result.Prescription, err = buildPrescriptionModel(*prescription)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.CalibrateSilo {
+		result.Calibration = siloCalibrationStore.Add(medication.PatientID, medication.ID, int(medication.CartridgeIndex.Int64))
+	}
+	return result, nil
+}
+
+func (r *Resolver) loadStockHistory(ctx context.Context, medicationID string, rangeArg *model.DateRangeInput, limit *int) (*model.StockHistory, error) {
+	medication, err := r.Queries.GetMedication(ctx, medicationID)
+	if err != nil {
+		return nil, fmt.Errorf("load medication %s: %w", medicationID, err)
+	}
+
+	params := db.ListStockMovementsByMedicationParams{
+		MedicationID: medication.ID,
+		Limit:        defaultStockHistoryLimit,
+	}
+	if limit != nil {
+		if *limit < 1 || *limit > maxStockHistoryLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxStockHistoryLimit)
+		}
+		params.Limit = int64(*limit)
+	}
+	if rangeArg != nil {
+		if !rangeArg.End.After(rangeArg.Start) {
+			return nil, fmt.Errorf("range end must be after start")
+		}
+		params.Start = sql.NullString{String: formatDBTime(rangeArg.Start), Valid: true}
+		params.End = sql.NullString{String: formatDBTime(rangeArg.End), Valid: true}
+	}
+
+	rows, err := r.Queries.ListStockMovementsByMedication(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list stock movements: %w", err)
+	}
+	movements := make([]*model.StockMovement, 0, len(rows))
+	for _, row := range rows {
+		movement, err := buildStockMovementModel(row)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	balance, err := r.Queries.SumStockMovements(ctx, medication.ID)
+	if err != nil {
+		return nil, fmt.Errorf("sum stock movements: %w", err)
+	}
+
+	return &model.StockHistory{
+		MedicationID:  medication.ID,
+		StockCount:    int(medication.StockCount),
+		LedgerBalance: int(balance),
+		Movements:     movements,
+	}, nil
+}
+
+func (r *Resolver) loadMedicationLots(ctx context.Context, medicationID string, includeDepleted bool) ([]*model.MedicationLot, error) {
+	rows, err := r.Queries.ListMedicationLots(ctx, db.ListMedicationLotsParams{
+		MedicationID:    medicationID,
+		IncludeDepleted: includeDepleted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list medication lots: %w", err)
+	}
+	result := make([]*model.MedicationLot, 0, len(rows))
+	for _, row := range rows {
+		lot, err := buildMedicationLotModel(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, lot)
+	}
+	return result, nil
+}
+
+func (r *Resolver) loadDispenseLots(ctx context.Context, dispenseEventID string) ([]*model.LotConsumption, error) {
+	rows, err := r.Queries.ListLotConsumptionsByDispenseEvent(ctx, nullableID(dispenseEventID))
+	if err != nil {
+		return nil, fmt.Errorf("list lots for dispense event %s: %w", dispenseEventID, err)
+	}
+	result := make([]*model.LotConsumption, 0, len(rows))
+	for _, row := range rows {
+		lot, err := buildMedicationLotModel(row.MedicationLot)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &model.LotConsumption{
+			Lot:             lot,
+			StockMovementID: row.StockMovementID,
+			Quantity:        int(row.Quantity),
+		})
+	}
+	return result, nil
+}
+
+func (r *Resolver) loadLotDispenseEvents(ctx context.Context, lotID string) ([]*model.DispenseEvent, error) {
+	if _, err := r.Queries.GetMedicationLot(ctx, lotID); err != nil {
+		return nil, fmt.Errorf("load medication lot %s: %w", lotID, err)
+	}
+	rows, err := r.Queries.ListDispenseEventsByLot(ctx, lotID)
+	if err != nil {
+		return nil, fmt.Errorf("list dispense events for lot %s: %w", lotID, err)
+	}
+	result := make([]*model.DispenseEvent, 0, len(rows))
+	for _, row := range rows {
+		event, err := buildDispenseEvent(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+func nullableID(id string) sql.NullString {
+	if id == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: id, Valid: true}
+}
@@ -0,0 +1,211 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"pillbox/graph/model"
+	"pillbox/internal/db"
+)
+
+func TestApplyStockMovementClampsAtZero(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		quantity     int64
+		note         *string
+		wantQuantity int64
+		wantStock    int64
+		wantNote     string
+	}{
+		{name: "within stock", quantity: -10, wantQuantity: -10, wantStock: 50},
+		{name: "exactly the stock", quantity: -60, wantQuantity: -60, wantStock: 0},
+		{name: "larger than stock", quantity: -75, wantQuantity: -60, wantStock: 0, wantNote: "short by 15"},
+		{name: "larger than stock with a note", quantity: -61, note: ptrString("Cup spilled"), wantQuantity: -60, wantStock: 0, wantNote: "Cup spilled; short by 1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestResolver(t)
+
+			var after db.Medication
+			var movement db.StockMovement
+			err := r.withTx(ctx, func(qtx *db.Queries) error {
+				var err error
+				_, after, movement, err = applyStockMovement(ctx, qtx, stockChange{
+					MedicationID: "med_demo_metformin",
+					Kind:         model.StockMovementKindWastage,
+					Quantity:     tc.quantity,
+					Note:         tc.note,
+				})
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if after.StockCount != tc.wantStock || movement.Quantity != tc.wantQuantity || movement.BalanceAfter != tc.wantStock {
+				t.Errorf("stock %d, movement %d with balance %d; want stock %d, movement %d",
+					after.StockCount, movement.Quantity, movement.BalanceAfter, tc.wantStock, tc.wantQuantity)
+			}
+			if movement.Note.String != tc.wantNote {
+				t.Errorf("note = %q, want %q", movement.Note.String, tc.wantNote)
+			}
+
+			balance, err := r.Queries.SumStockMovements(ctx, "med_demo_metformin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if balance != after.StockCount {
+				t.Errorf("ledger sums to %d, stock is %d", balance, after.StockCount)
+			}
+			lots, err := r.Queries.ListAvailableLotsFIFO(ctx, "med_demo_metformin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var remaining int64
+			for _, lot := range lots {
+				remaining += lot.QuantityRemaining
+			}
+			if remaining != after.StockCount {
+				t.Errorf("lots hold %d pills, stock is %d", remaining, after.StockCount)
+			}
+		})
+	}
+}
+
+func TestAllocateLotsFIFO(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+	if _, err := r.DB.Exec(`DELETE FROM medication_lots WHERE medication_id = 'med_demo_metformin'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.DB.Exec(`UPDATE medications SET stock_count = 30 WHERE id = 'med_demo_metformin'`); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second lot loaded expires first, and has already expired: the silo
+	// still hands out pills in the order they were loaded.
+	for _, lot := range []struct{ id, loadedAt, expiresOn string }{
+		{"lot_a", "2026-01-01T00:00:00Z", "2026-12-31"},
+		{"lot_b", "2026-02-01T00:00:00Z", "2026-03-01"},
+		{"lot_c", "2026-03-01T00:00:00Z", "2027-06-30"},
+	} {
+		if _, err := r.Queries.CreateMedicationLot(ctx, db.CreateMedicationLotParams{
+			ID:                lot.id,
+			MedicationID:      "med_demo_metformin",
+			QuantityLoaded:    10,
+			QuantityRemaining: 10,
+			ExpiresOn:         sql.NullString{String: lot.expiresOn, Valid: true},
+			LoadedAt:          lot.loadedAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type take struct {
+		lot string
+		qty int64
+	}
+	steps := []struct {
+		quantity  int64
+		want      []take
+		remaining map[string]int64
+	}{
+		{quantity: -15, want: []take{{"lot_a", -10}, {"lot_b", -5}}, remaining: map[string]int64{"lot_a": 0, "lot_b": 5, "lot_c": 10}},
+		{quantity: -8, want: []take{{"lot_b", -5}, {"lot_c", -3}}, remaining: map[string]int64{"lot_a": 0, "lot_b": 0, "lot_c": 7}},
+		// More than is left empties the last lot and nothing else.
+		{quantity: -20, want: []take{{"lot_c", -7}}, remaining: map[string]int64{"lot_a": 0, "lot_b": 0, "lot_c": 0}},
+	}
+	for i, step := range steps {
+		var movement db.StockMovement
+		if err := r.withTx(ctx, func(qtx *db.Queries) error {
+			var err error
+			_, _, movement, err = applyStockMovement(ctx, qtx, stockChange{
+				MedicationID: "med_demo_metformin",
+				Kind:         model.StockMovementKindWastage,
+				Quantity:     step.quantity,
+			})
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := r.DB.Query(`SELECT lot_id, quantity FROM stock_movement_lots WHERE stock_movement_id = ? ORDER BY rowid`, movement.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []take
+		for rows.Next() {
+			var tk take
+			if err := rows.Scan(&tk.lot, &tk.qty); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, tk)
+		}
+		rows.Close()
+		if len(got) != len(step.want) {
+			t.Fatalf("step %d: took %v, want %v", i, got, step.want)
+		}
+		for j := range got {
+			if got[j] != step.want[j] {
+				t.Fatalf("step %d: took %v, want %v", i, got, step.want)
+			}
+		}
+
+		for id, want := range step.remaining {
+			lot, err := r.Queries.GetMedicationLot(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lot.QuantityRemaining != want {
+				t.Errorf("step %d: %s has %d left, want %d", i, id, lot.QuantityRemaining, want)
+			}
+		}
+	}
+}
+
+func TestExpiringLotsSoonestFirst(t *testing.T) {
+	ctx := context.Background()
+	r := newTestResolver(t)
+
+	for _, lot := range []struct{ id, loadedAt, expiresOn string }{
+		{"lot_late", "2026-01-01T00:00:00Z", "2026-12-31"},
+		{"lot_expired", "2026-02-01T00:00:00Z", "2026-03-01"},
+		{"lot_soon", "2026-03-01T00:00:00Z", "2026-11-01"},
+		{"lot_later", "2026-04-01T00:00:00Z", "2027-06-30"},
+	} {
+		if _, err := r.Queries.CreateMedicationLot(ctx, db.CreateMedicationLotParams{
+			ID:                lot.id,
+			MedicationID:      "med_demo_metformin",
+			QuantityLoaded:    10,
+			QuantityRemaining: 10,
+			ExpiresOn:         sql.NullString{String: lot.expiresOn, Valid: true},
+			LoadedAt:          lot.loadedAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lots, err := r.Queries.ListExpiringLotsByPatient(ctx, db.ListExpiringLotsByPatientParams{
+		PatientID:     "patient_demo_001",
+		ExpiresBefore: sql.NullString{String: "2026-12-31", Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, lot := range lots {
+		got = append(got, lot.ID)
+	}
+	want := []string{"lot_expired", "lot_soon", "lot_late"}
+	if len(got) != len(want) {
+		t.Fatalf("expiring lots = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expiring lots = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package catalog loads reference drug data (names, strengths and dosage
+// forms) that medications can optionally be linked to.
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pillbox/internal/db"
+)
+
+// Entry is one drug product in a catalog dataset. JSON datasets are an array
+// of these objects; CSV datasets have a header row with the same names.
+type Entry struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Ingredient string `json:"ingredient"`
+	Strength   string `json:"strength"`
+	Unit       string `json:"unit"`
+	DosageForm string `json:"dosage_form"`
+}
+
+// PathFromEnv returns MEDICATION_CATALOG_PATH; empty means no catalog is
+// loaded.
+func PathFromEnv() string {
+	return strings.TrimSpace(os.Getenv("MEDICATION_CATALOG_PATH"))
+}
+
+// LoadFile reads a .csv or .json dataset and upserts every entry in one
+// transaction, returning how many were loaded. Entries already in the
+// catalog but missing from the file are kept so linked medications survive a
+// smaller extract.
+func LoadFile(ctx context.Context, conn *sql.DB, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("open catalog: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		entries, err = parseCSV(f)
+	case ".json":
+		entries, err = parseJSON(f)
+	default:
+		return 0, fmt.Errorf("unsupported catalog format %q (want .csv or .json)", filepath.Ext(path))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	q := db.New(tx)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for i, entry := range entries {
+		if err := validate(entry); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("catalog entry %d: %w", i+1, err)
+		}
+		if err := q.UpsertCatalogEntry(ctx, db.UpsertCatalogEntryParams{
+			ID:         strings.TrimSpace(entry.ID),
+			Name:       displayName(entry),
+			Ingredient: strings.TrimSpace(entry.Ingredient),
+			Strength:   nullableString(entry.Strength),
+			Unit:       nullableString(entry.Unit),
+			DosageForm: nullableString(entry.DosageForm),
+			UpdatedAt:  now,
+		}); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("save catalog entry %s: %w", entry.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// displayName is how the entry is written in reminders. Datasets without a
+// product name get one built from the other fields, e.g.
+// "Metformin 500 mg tablet".
+func displayName(entry Entry) string {
+	if name := strings.TrimSpace(entry.Name); name != "" {
+		return name
+	}
+	parts := make([]string, 0, 4)
+	for _, part := range []string{entry.Ingredient, entry.Strength, entry.Unit, entry.DosageForm} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func parseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read catalog header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"id", "ingredient"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("catalog is missing the %q column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read catalog: %w", err)
+		}
+		entries = append(entries, Entry{
+			ID:         field(record, "id"),
+			Name:       field(record, "name"),
+			Ingredient: field(record, "ingredient"),
+			Strength:   field(record, "strength"),
+			Unit:       field(record, "unit"),
+			DosageForm: field(record, "dosage_form"),
+		})
+	}
+	return entries, nil
+}
+
+func parseJSON(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode catalog: %w", err)
+	}
+	return entries, nil
+}
+
+func validate(entry Entry) error {
+	switch {
+	case strings.TrimSpace(entry.ID) == "":
+		return fmt.Errorf("id is required")
+	case strings.TrimSpace(entry.Ingredient) == "":
+		return fmt.Errorf("ingredient is required")
+	}
+	return nil
+}
+
+func nullableString(value string) sql.NullString {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: value, Valid: true}
+}
@@ -0,0 +1,227 @@
+package catalog
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The bundled tables are a reference for caregivers, not an exhaustive
+// clinical database: a missing warning does not mean a combination is safe.
+//
+//go:embed data/interactions.csv data/therapeutic_classes.csv
+var bundledData embed.FS
+
+// Severity ranks an interaction warning, most serious first.
+type Severity string
+
+const (
+	SeverityContraindicated Severity = "CONTRAINDICATED"
+	SeverityMajor           Severity = "MAJOR"
+	SeverityModerate        Severity = "MODERATE"
+	SeverityMinor           Severity = "MINOR"
+)
+
+var severityRank = map[Severity]int{
+	SeverityContraindicated: 0,
+	SeverityMajor:           1,
+	SeverityModerate:        2,
+	SeverityMinor:           3,
+}
+
+// WarningKind tells an interaction between two drugs apart from taking two
+// drugs that do the same job.
+type WarningKind string
+
+const (
+	KindInteraction      WarningKind = "INTERACTION"
+	KindDuplicateTherapy WarningKind = "DUPLICATE_THERAPY"
+)
+
+// Medication is a patient's medication as far as the checker is concerned.
+// Ingredient comes from its catalog entry; combination products list their
+// ingredients separated by "/" or ",".
+type Medication struct {
+	ID         string
+	Ingredient string
+}
+
+// Warning is one problem found between two medications.
+type Warning struct {
+	Kind        WarningKind
+	Severity    Severity
+	Medications [2]string
+	Ingredients [2]string
+	Description string
+}
+
+type interactionTable struct {
+	pairs   map[[2]string]Warning
+	classes map[string]string
+}
+
+var (
+	loadTableOnce sync.Once
+	table         interactionTable
+	tableErr      error
+)
+
+func bundledTable() (interactionTable, error) {
+	loadTableOnce.Do(func() {
+		table, tableErr = parseBundledTable()
+	})
+	return table, tableErr
+}
+
+func parseBundledTable() (interactionTable, error) {
+	t := interactionTable{
+		pairs:   make(map[[2]string]Warning),
+		classes: make(map[string]string),
+	}
+
+	rows, err := readBundledCSV("data/interactions.csv")
+	if err != nil {
+		return t, err
+	}
+	for _, row := range rows {
+		if len(row) != 4 {
+			return t, fmt.Errorf("interactions.csv: expected 4 columns, got %d", len(row))
+		}
+		severity := Severity(strings.ToUpper(strings.TrimSpace(row[2])))
+		if _, ok := severityRank[severity]; !ok {
+			return t, fmt.Errorf("interactions.csv: unknown severity %q", row[2])
+		}
+		t.pairs[pairKey(row[0], row[1])] = Warning{
+			Kind:        KindInteraction,
+			Severity:    severity,
+			Description: strings.TrimSpace(row[3]),
+		}
+	}
+
+	rows, err = readBundledCSV("data/therapeutic_classes.csv")
+	if err != nil {
+		return t, err
+	}
+	for _, row := range rows {
+		if len(row) != 2 {
+			return t, fmt.Errorf("therapeutic_classes.csv: expected 2 columns, got %d", len(row))
+		}
+		t.classes[normalizeIngredient(row[0])] = strings.TrimSpace(row[1])
+	}
+	return t, nil
+}
+
+func readBundledCSV(name string) ([][]string, error) {
+	raw, err := bundledData.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[1:], nil
+}
+
+// CheckInteractions compares every pair of medications against the bundled
+// interaction table and flags duplicate therapy: the same ingredient twice
+// (MAJOR) or two drugs of the same class (MODERATE). Medications without an
+// ingredient are skipped. Warnings come back most severe first.
+func CheckInteractions(medications []Medication) ([]Warning, error) {
+	t, err := bundledTable()
+	if err != nil {
+		return nil, fmt.Errorf("load interaction table: %w", err)
+	}
+
+	var warnings []Warning
+	for i := 0; i < len(medications); i++ {
+		for j := i + 1; j < len(medications); j++ {
+			a, b := medications[i], medications[j]
+			if a.ID == b.ID {
+				continue
+			}
+			if w, ok := t.checkPair(a, b); ok {
+				warnings = append(warnings, w)
+			}
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return severityRank[warnings[i].Severity] < severityRank[warnings[j].Severity]
+	})
+	return warnings, nil
+}
+
+// checkPair returns the most severe warning between two medications.
+func (t interactionTable) checkPair(a, b Medication) (Warning, bool) {
+	var (
+		best  Warning
+		found bool
+	)
+	consider := func(w Warning) {
+		if !found || severityRank[w.Severity] < severityRank[best.Severity] {
+			best, found = w, true
+		}
+	}
+
+	for _, ingredientA := range splitIngredients(a.Ingredient) {
+		for _, ingredientB := range splitIngredients(b.Ingredient) {
+			pair := [2]string{ingredientA, ingredientB}
+
+			if ingredientA == ingredientB {
+				consider(Warning{
+					Kind:        KindDuplicateTherapy,
+					Severity:    SeverityMajor,
+					Ingredients: pair,
+					Description: fmt.Sprintf("Both medications contain %s.", ingredientA),
+				})
+				continue
+			}
+			if w, ok := t.pairs[pairKey(ingredientA, ingredientB)]; ok {
+				w.Ingredients = pair
+				consider(w)
+			}
+			if class, ok := t.classes[ingredientA]; ok && class == t.classes[ingredientB] {
+				consider(Warning{
+					Kind:        KindDuplicateTherapy,
+					Severity:    SeverityModerate,
+					Ingredients: pair,
+					Description: fmt.Sprintf("Both medications are a %s (%s and %s).", class, ingredientA, ingredientB),
+				})
+			}
+		}
+	}
+
+	best.Medications = [2]string{a.ID, b.ID}
+	return best, found
+}
+
+func splitIngredients(value string) []string {
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == '/' || r == ',' })
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = normalizeIngredient(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func normalizeIngredient(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func pairKey(a, b string) [2]string {
+	a, b = normalizeIngredient(a), normalizeIngredient(b)
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
@@ -0,0 +1,469 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audio_messages.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createAudioMessage = `-- name: CreateAudioMessage :one
+INSERT INTO audio_messages (
+  id,
+  patient_id,
+  schedule_id,
+  due_at_iso,
+  file_path,
+  mime_type,
+  size_bytes,
+  expires_at,
+  sha256,
+  duration_ms,
+  sample_rate_hz
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, patient_id, schedule_id, due_at_iso, file_path, mime_type, size_bytes, created_at, delivered_at, acked_at, expires_at, expired_at, expiry_reason, purged_at, sha256, duration_ms, sample_rate_hz
+`
+
+type CreateAudioMessageParams struct {
+	ID           string         `json:"id"`
+	PatientID    string         `json:"patient_id"`
+	ScheduleID   string         `json:"schedule_id"`
+	DueAtIso     string         `json:"due_at_iso"`
+	FilePath     string         `json:"file_path"`
+	MimeType     string         `json:"mime_type"`
+	SizeBytes    int64          `json:"size_bytes"`
+	ExpiresAt    string         `json:"expires_at"`
+	Sha256       sql.NullString `json:"sha256"`
+	DurationMs   sql.NullInt64  `json:"duration_ms"`
+	SampleRateHz sql.NullInt64  `json:"sample_rate_hz"`
+}
+
+func (q *Queries) CreateAudioMessage(ctx context.Context, arg CreateAudioMessageParams) (AudioMessage, error) {
+	row := q.queryRow(ctx, q.createAudioMessageStmt, createAudioMessage,
+		arg.ID,
+		arg.PatientID,
+		arg.ScheduleID,
+		arg.DueAtIso,
+		arg.FilePath,
+		arg.MimeType,
+		arg.SizeBytes,
+		arg.ExpiresAt,
+		arg.Sha256,
+		arg.DurationMs,
+		arg.SampleRateHz,
+	)
+	var i AudioMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.MimeType,
+		&i.SizeBytes,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+		&i.AckedAt,
+		&i.ExpiresAt,
+		&i.ExpiredAt,
+		&i.ExpiryReason,
+		&i.PurgedAt,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+	)
+	return i, err
+}
+
+const createAudioMessageEncoding = `-- name: CreateAudioMessageEncoding :exec
+INSERT INTO audio_message_encodings (message_id, mime_type, file_path, size_bytes, sha256)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (message_id, mime_type) DO UPDATE SET
+  file_path = excluded.file_path,
+  size_bytes = excluded.size_bytes,
+  sha256 = excluded.sha256
+`
+
+type CreateAudioMessageEncodingParams struct {
+	MessageID string `json:"message_id"`
+	MimeType  string `json:"mime_type"`
+	FilePath  string `json:"file_path"`
+	SizeBytes int64  `json:"size_bytes"`
+	Sha256    string `json:"sha256"`
+}
+
+func (q *Queries) CreateAudioMessageEncoding(ctx context.Context, arg CreateAudioMessageEncodingParams) error {
+	_, err := q.exec(ctx, q.createAudioMessageEncodingStmt, createAudioMessageEncoding,
+		arg.MessageID,
+		arg.MimeType,
+		arg.FilePath,
+		arg.SizeBytes,
+		arg.Sha256,
+	)
+	return err
+}
+
+const expireResolvedAudioMessages = `-- name: ExpireResolvedAudioMessages :execrows
+UPDATE audio_messages
+SET expired_at = ?1,
+    expiry_reason = (
+      SELECT de.status FROM dispense_events de
+      WHERE de.patient_id = audio_messages.patient_id
+        AND de.schedule_id = audio_messages.schedule_id
+        AND de.due_at_iso = audio_messages.due_at_iso
+        AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+      LIMIT 1
+    )
+WHERE acked_at IS NULL
+  AND expired_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM dispense_events de
+    WHERE de.patient_id = audio_messages.patient_id
+      AND de.schedule_id = audio_messages.schedule_id
+      AND de.due_at_iso = audio_messages.due_at_iso
+      AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+  )
+`
+
+func (q *Queries) ExpireResolvedAudioMessages(ctx context.Context, now sql.NullString) (int64, error) {
+	result, err := q.exec(ctx, q.expireResolvedAudioMessagesStmt, expireResolvedAudioMessages, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const expireStaleAudioMessages = `-- name: ExpireStaleAudioMessages :execrows
+UPDATE audio_messages
+SET expired_at = ?1,
+    expiry_reason = 'AGE'
+WHERE acked_at IS NULL
+  AND expired_at IS NULL
+  AND expires_at <= ?1
+`
+
+func (q *Queries) ExpireStaleAudioMessages(ctx context.Context, now sql.NullString) (int64, error) {
+	result, err := q.exec(ctx, q.expireStaleAudioMessagesStmt, expireStaleAudioMessages, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const expireSupersededAudioMessages = `-- name: ExpireSupersededAudioMessages :exec
+UPDATE audio_messages
+SET expired_at = ?,
+    expiry_reason = 'SUPERSEDED'
+WHERE patient_id = ?
+  AND due_at_iso < ?
+  AND acked_at IS NULL
+  AND expired_at IS NULL
+`
+
+type ExpireSupersededAudioMessagesParams struct {
+	ExpiredAt sql.NullString `json:"expired_at"`
+	PatientID string         `json:"patient_id"`
+	DueAtIso  string         `json:"due_at_iso"`
+}
+
+func (q *Queries) ExpireSupersededAudioMessages(ctx context.Context, arg ExpireSupersededAudioMessagesParams) error {
+	_, err := q.exec(ctx, q.expireSupersededAudioMessagesStmt, expireSupersededAudioMessages, arg.ExpiredAt, arg.PatientID, arg.DueAtIso)
+	return err
+}
+
+const getAudioMessage = `-- name: GetAudioMessage :one
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, mime_type, size_bytes, created_at, delivered_at, acked_at, expires_at, expired_at, expiry_reason, purged_at, sha256, duration_ms, sample_rate_hz FROM audio_messages
+WHERE id = ?
+  AND patient_id = ?
+`
+
+type GetAudioMessageParams struct {
+	ID        string `json:"id"`
+	PatientID string `json:"patient_id"`
+}
+
+func (q *Queries) GetAudioMessage(ctx context.Context, arg GetAudioMessageParams) (AudioMessage, error) {
+	row := q.queryRow(ctx, q.getAudioMessageStmt, getAudioMessage, arg.ID, arg.PatientID)
+	var i AudioMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.MimeType,
+		&i.SizeBytes,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+		&i.AckedAt,
+		&i.ExpiresAt,
+		&i.ExpiredAt,
+		&i.ExpiryReason,
+		&i.PurgedAt,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+	)
+	return i, err
+}
+
+const getLatestPendingAudioMessage = `-- name: GetLatestPendingAudioMessage :one
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, mime_type, size_bytes, created_at, delivered_at, acked_at, expires_at, expired_at, expiry_reason, purged_at, sha256, duration_ms, sample_rate_hz FROM audio_messages am
+WHERE am.patient_id = ?
+  AND am.acked_at IS NULL
+  AND am.expired_at IS NULL
+  AND am.expires_at > ?
+  AND NOT EXISTS (
+    SELECT 1 FROM dispense_events de
+    WHERE de.patient_id = am.patient_id
+      AND de.schedule_id = am.schedule_id
+      AND de.due_at_iso = am.due_at_iso
+      AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+  )
+ORDER BY am.due_at_iso DESC, am.created_at DESC
+LIMIT 1
+`
+
+type GetLatestPendingAudioMessageParams struct {
+	PatientID string `json:"patient_id"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+func (q *Queries) GetLatestPendingAudioMessage(ctx context.Context, arg GetLatestPendingAudioMessageParams) (AudioMessage, error) {
+	row := q.queryRow(ctx, q.getLatestPendingAudioMessageStmt, getLatestPendingAudioMessage, arg.PatientID, arg.ExpiresAt)
+	var i AudioMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.MimeType,
+		&i.SizeBytes,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+		&i.AckedAt,
+		&i.ExpiresAt,
+		&i.ExpiredAt,
+		&i.ExpiryReason,
+		&i.PurgedAt,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+	)
+	return i, err
+}
+
+const getOldestPendingAudioMessage = `-- name: GetOldestPendingAudioMessage :one
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, mime_type, size_bytes, created_at, delivered_at, acked_at, expires_at, expired_at, expiry_reason, purged_at, sha256, duration_ms, sample_rate_hz FROM audio_messages am
+WHERE am.patient_id = ?
+  AND am.acked_at IS NULL
+  AND am.expired_at IS NULL
+  AND am.expires_at > ?
+  AND NOT EXISTS (
+    SELECT 1 FROM dispense_events de
+    WHERE de.patient_id = am.patient_id
+      AND de.schedule_id = am.schedule_id
+      AND de.due_at_iso = am.due_at_iso
+      AND de.status IN ('TAKEN', 'SKIPPED', 'MISSED')
+  )
+ORDER BY am.due_at_iso ASC, am.created_at ASC
+LIMIT 1
+`
+
+type GetOldestPendingAudioMessageParams struct {
+	PatientID string `json:"patient_id"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+func (q *Queries) GetOldestPendingAudioMessage(ctx context.Context, arg GetOldestPendingAudioMessageParams) (AudioMessage, error) {
+	row := q.queryRow(ctx, q.getOldestPendingAudioMessageStmt, getOldestPendingAudioMessage, arg.PatientID, arg.ExpiresAt)
+	var i AudioMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.MimeType,
+		&i.SizeBytes,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+		&i.AckedAt,
+		&i.ExpiresAt,
+		&i.ExpiredAt,
+		&i.ExpiryReason,
+		&i.PurgedAt,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+	)
+	return i, err
+}
+
+const listAudioMessageEncodings = `-- name: ListAudioMessageEncodings :many
+SELECT message_id, mime_type, file_path, size_bytes, sha256, created_at FROM audio_message_encodings
+WHERE message_id = ?
+ORDER BY created_at, mime_type
+`
+
+func (q *Queries) ListAudioMessageEncodings(ctx context.Context, messageID string) ([]AudioMessageEncoding, error) {
+	rows, err := q.query(ctx, q.listAudioMessageEncodingsStmt, listAudioMessageEncodings, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AudioMessageEncoding{}
+	for rows.Next() {
+		var i AudioMessageEncoding
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.MimeType,
+			&i.FilePath,
+			&i.SizeBytes,
+			&i.Sha256,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLiveAudioMessagePaths = `-- name: ListLiveAudioMessagePaths :many
+SELECT file_path FROM audio_messages
+WHERE purged_at IS NULL
+UNION ALL
+SELECT ame.file_path FROM audio_message_encodings ame
+JOIN audio_messages am ON am.id = ame.message_id
+WHERE am.purged_at IS NULL
+`
+
+func (q *Queries) ListLiveAudioMessagePaths(ctx context.Context) ([]string, error) {
+	rows, err := q.query(ctx, q.listLiveAudioMessagePathsStmt, listLiveAudioMessagePaths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var file_path string
+		if err := rows.Scan(&file_path); err != nil {
+			return nil, err
+		}
+		items = append(items, file_path)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableAudioMessages = `-- name: ListPurgeableAudioMessages :many
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, mime_type, size_bytes, created_at, delivered_at, acked_at, expires_at, expired_at, expiry_reason, purged_at, sha256, duration_ms, sample_rate_hz FROM audio_messages
+WHERE purged_at IS NULL
+  AND (acked_at IS NOT NULL OR expired_at IS NOT NULL)
+`
+
+func (q *Queries) ListPurgeableAudioMessages(ctx context.Context) ([]AudioMessage, error) {
+	rows, err := q.query(ctx, q.listPurgeableAudioMessagesStmt, listPurgeableAudioMessages)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AudioMessage{}
+	for rows.Next() {
+		var i AudioMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.ScheduleID,
+			&i.DueAtIso,
+			&i.FilePath,
+			&i.MimeType,
+			&i.SizeBytes,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+			&i.AckedAt,
+			&i.ExpiresAt,
+			&i.ExpiredAt,
+			&i.ExpiryReason,
+			&i.PurgedAt,
+			&i.Sha256,
+			&i.DurationMs,
+			&i.SampleRateHz,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAudioMessageAcked = `-- name: MarkAudioMessageAcked :exec
+UPDATE audio_messages
+SET acked_at = ?
+WHERE id = ?
+`
+
+type MarkAudioMessageAckedParams struct {
+	AckedAt sql.NullString `json:"acked_at"`
+	ID      string         `json:"id"`
+}
+
+func (q *Queries) MarkAudioMessageAcked(ctx context.Context, arg MarkAudioMessageAckedParams) error {
+	_, err := q.exec(ctx, q.markAudioMessageAckedStmt, markAudioMessageAcked, arg.AckedAt, arg.ID)
+	return err
+}
+
+const markAudioMessageDelivered = `-- name: MarkAudioMessageDelivered :exec
+UPDATE audio_messages
+SET delivered_at = COALESCE(delivered_at, ?)
+WHERE id = ?
+`
+
+type MarkAudioMessageDeliveredParams struct {
+	DeliveredAt sql.NullString `json:"delivered_at"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) MarkAudioMessageDelivered(ctx context.Context, arg MarkAudioMessageDeliveredParams) error {
+	_, err := q.exec(ctx, q.markAudioMessageDeliveredStmt, markAudioMessageDelivered, arg.DeliveredAt, arg.ID)
+	return err
+}
+
+const markAudioMessagePurged = `-- name: MarkAudioMessagePurged :exec
+UPDATE audio_messages
+SET purged_at = ?
+WHERE id = ?
+`
+
+type MarkAudioMessagePurgedParams struct {
+	PurgedAt sql.NullString `json:"purged_at"`
+	ID       string         `json:"id"`
+}
+
+func (q *Queries) MarkAudioMessagePurged(ctx context.Context, arg MarkAudioMessagePurgedParams) error {
+	_, err := q.exec(ctx, q.markAudioMessagePurgedStmt, markAudioMessagePurged, arg.PurgedAt, arg.ID)
+	return err
+}
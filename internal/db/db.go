@@ -24,122 +24,483 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.adjustMedicationStockStmt, err = db.PrepareContext(ctx, adjustMedicationStock); err != nil {
+		return nil, fmt.Errorf("error preparing query AdjustMedicationStock: %w", err)
+	}
 	if q.archiveScheduleStmt, err = db.PrepareContext(ctx, archiveSchedule); err != nil {
 		return nil, fmt.Errorf("error preparing query ArchiveSchedule: %w", err)
 	}
+	if q.cancelOutboxNotificationStmt, err = db.PrepareContext(ctx, cancelOutboxNotification); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelOutboxNotification: %w", err)
+	}
+	if q.cancelQueuedOutboxNotificationsStmt, err = db.PrepareContext(ctx, cancelQueuedOutboxNotifications); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelQueuedOutboxNotifications: %w", err)
+	}
+	if q.claimOutboxNotificationStmt, err = db.PrepareContext(ctx, claimOutboxNotification); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimOutboxNotification: %w", err)
+	}
+	if q.clearStockAlertsStmt, err = db.PrepareContext(ctx, clearStockAlerts); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearStockAlerts: %w", err)
+	}
+	if q.consumeMedicationLotStmt, err = db.PrepareContext(ctx, consumeMedicationLot); err != nil {
+		return nil, fmt.Errorf("error preparing query ConsumeMedicationLot: %w", err)
+	}
+	if q.countNotificationEventsStmt, err = db.PrepareContext(ctx, countNotificationEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query CountNotificationEvents: %w", err)
+	}
+	if q.createAudioMessageStmt, err = db.PrepareContext(ctx, createAudioMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAudioMessage: %w", err)
+	}
+	if q.createAudioMessageEncodingStmt, err = db.PrepareContext(ctx, createAudioMessageEncoding); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAudioMessageEncoding: %w", err)
+	}
 	if q.createDispenseEventStmt, err = db.PrepareContext(ctx, createDispenseEvent); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateDispenseEvent: %w", err)
 	}
+	if q.createDispenseIdempotencyKeyStmt, err = db.PrepareContext(ctx, createDispenseIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDispenseIdempotencyKey: %w", err)
+	}
 	if q.createMedicationStmt, err = db.PrepareContext(ctx, createMedication); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMedication: %w", err)
 	}
+	if q.createMedicationLotStmt, err = db.PrepareContext(ctx, createMedicationLot); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMedicationLot: %w", err)
+	}
 	if q.createNotificationEventStmt, err = db.PrepareContext(ctx, createNotificationEvent); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateNotificationEvent: %w", err)
 	}
 	if q.createPatientStmt, err = db.PrepareContext(ctx, createPatient); err != nil {
 		return nil, fmt.Errorf("error preparing query CreatePatient: %w", err)
 	}
+	if q.createPharmacyStmt, err = db.PrepareContext(ctx, createPharmacy); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePharmacy: %w", err)
+	}
+	if q.createPrescriptionStmt, err = db.PrepareContext(ctx, createPrescription); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePrescription: %w", err)
+	}
+	if q.createRefillRequestStmt, err = db.PrepareContext(ctx, createRefillRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRefillRequest: %w", err)
+	}
+	if q.createRefillRequestDeliveryStmt, err = db.PrepareContext(ctx, createRefillRequestDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRefillRequestDelivery: %w", err)
+	}
 	if q.createScheduleStmt, err = db.PrepareContext(ctx, createSchedule); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSchedule: %w", err)
 	}
 	if q.createScheduleItemStmt, err = db.PrepareContext(ctx, createScheduleItem); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateScheduleItem: %w", err)
 	}
+	if q.createSiloStmt, err = db.PrepareContext(ctx, createSilo); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSilo: %w", err)
+	}
+	if q.createStockMovementStmt, err = db.PrepareContext(ctx, createStockMovement); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStockMovement: %w", err)
+	}
+	if q.createStockMovementLotStmt, err = db.PrepareContext(ctx, createStockMovementLot); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStockMovementLot: %w", err)
+	}
 	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
 	}
+	if q.createVoiceMessageStmt, err = db.PrepareContext(ctx, createVoiceMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateVoiceMessage: %w", err)
+	}
+	if q.deactivateVoiceMessageStmt, err = db.PrepareContext(ctx, deactivateVoiceMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query DeactivateVoiceMessage: %w", err)
+	}
 	if q.deleteMedicationStmt, err = db.PrepareContext(ctx, deleteMedication); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMedication: %w", err)
 	}
+	if q.deletePharmacyStmt, err = db.PrepareContext(ctx, deletePharmacy); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePharmacy: %w", err)
+	}
+	if q.deletePrescriptionStmt, err = db.PrepareContext(ctx, deletePrescription); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePrescription: %w", err)
+	}
 	if q.deleteScheduleItemsByScheduleStmt, err = db.PrepareContext(ctx, deleteScheduleItemsBySchedule); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteScheduleItemsBySchedule: %w", err)
 	}
+	if q.deleteSilosFromStmt, err = db.PrepareContext(ctx, deleteSilosFrom); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSilosFrom: %w", err)
+	}
+	if q.deleteTTSCacheEntryStmt, err = db.PrepareContext(ctx, deleteTTSCacheEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTTSCacheEntry: %w", err)
+	}
+	if q.enqueueNotificationStmt, err = db.PrepareContext(ctx, enqueueNotification); err != nil {
+		return nil, fmt.Errorf("error preparing query EnqueueNotification: %w", err)
+	}
+	if q.expireResolvedAudioMessagesStmt, err = db.PrepareContext(ctx, expireResolvedAudioMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ExpireResolvedAudioMessages: %w", err)
+	}
+	if q.expireStaleAudioMessagesStmt, err = db.PrepareContext(ctx, expireStaleAudioMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ExpireStaleAudioMessages: %w", err)
+	}
+	if q.expireSupersededAudioMessagesStmt, err = db.PrepareContext(ctx, expireSupersededAudioMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ExpireSupersededAudioMessages: %w", err)
+	}
 	if q.getActivePatientStmt, err = db.PrepareContext(ctx, getActivePatient); err != nil {
 		return nil, fmt.Errorf("error preparing query GetActivePatient: %w", err)
 	}
+	if q.getAudioMessageStmt, err = db.PrepareContext(ctx, getAudioMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudioMessage: %w", err)
+	}
+	if q.getCatalogEntryStmt, err = db.PrepareContext(ctx, getCatalogEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCatalogEntry: %w", err)
+	}
 	if q.getDispenseEventStmt, err = db.PrepareContext(ctx, getDispenseEvent); err != nil {
 		return nil, fmt.Errorf("error preparing query GetDispenseEvent: %w", err)
 	}
+	if q.getDispenseEventByOccurrenceStmt, err = db.PrepareContext(ctx, getDispenseEventByOccurrence); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDispenseEventByOccurrence: %w", err)
+	}
+	if q.getDispenseIdempotencyKeyStmt, err = db.PrepareContext(ctx, getDispenseIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDispenseIdempotencyKey: %w", err)
+	}
+	if q.getLatestPendingAudioMessageStmt, err = db.PrepareContext(ctx, getLatestPendingAudioMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestPendingAudioMessage: %w", err)
+	}
 	if q.getMedicationStmt, err = db.PrepareContext(ctx, getMedication); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMedication: %w", err)
 	}
+	if q.getMedicationInSiloStmt, err = db.PrepareContext(ctx, getMedicationInSilo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMedicationInSilo: %w", err)
+	}
+	if q.getMedicationLotStmt, err = db.PrepareContext(ctx, getMedicationLot); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMedicationLot: %w", err)
+	}
 	if q.getNotificationEventByOccurrenceStmt, err = db.PrepareContext(ctx, getNotificationEventByOccurrence); err != nil {
 		return nil, fmt.Errorf("error preparing query GetNotificationEventByOccurrence: %w", err)
 	}
+	if q.getNotificationEventByProviderMessageIDStmt, err = db.PrepareContext(ctx, getNotificationEventByProviderMessageID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotificationEventByProviderMessageID: %w", err)
+	}
+	if q.getNotificationPreferenceStmt, err = db.PrepareContext(ctx, getNotificationPreference); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotificationPreference: %w", err)
+	}
+	if q.getOldestPendingAudioMessageStmt, err = db.PrepareContext(ctx, getOldestPendingAudioMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOldestPendingAudioMessage: %w", err)
+	}
+	if q.getOpenRefillRequestStmt, err = db.PrepareContext(ctx, getOpenRefillRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOpenRefillRequest: %w", err)
+	}
+	if q.getOutboxNotificationStmt, err = db.PrepareContext(ctx, getOutboxNotification); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOutboxNotification: %w", err)
+	}
 	if q.getPatientStmt, err = db.PrepareContext(ctx, getPatient); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPatient: %w", err)
 	}
+	if q.getPatientVoiceSettingsStmt, err = db.PrepareContext(ctx, getPatientVoiceSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPatientVoiceSettings: %w", err)
+	}
+	if q.getPharmacyStmt, err = db.PrepareContext(ctx, getPharmacy); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPharmacy: %w", err)
+	}
+	if q.getPrescriptionStmt, err = db.PrepareContext(ctx, getPrescription); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPrescription: %w", err)
+	}
+	if q.getRefillRequestStmt, err = db.PrepareContext(ctx, getRefillRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRefillRequest: %w", err)
+	}
+	if q.getRefillablePrescriptionStmt, err = db.PrepareContext(ctx, getRefillablePrescription); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRefillablePrescription: %w", err)
+	}
 	if q.getScheduleStmt, err = db.PrepareContext(ctx, getSchedule); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSchedule: %w", err)
 	}
+	if q.getSiloStmt, err = db.PrepareContext(ctx, getSilo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSilo: %w", err)
+	}
+	if q.getTTSCacheEntryStmt, err = db.PrepareContext(ctx, getTTSCacheEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTTSCacheEntry: %w", err)
+	}
+	if q.getTTSCacheSizeStmt, err = db.PrepareContext(ctx, getTTSCacheSize); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTTSCacheSize: %w", err)
+	}
 	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
 	}
 	if q.getUserByEmailStmt, err = db.PrepareContext(ctx, getUserByEmail); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByEmail: %w", err)
 	}
+	if q.getUserByPhoneStmt, err = db.PrepareContext(ctx, getUserByPhone); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByPhone: %w", err)
+	}
+	if q.getVoiceMessageStmt, err = db.PrepareContext(ctx, getVoiceMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVoiceMessage: %w", err)
+	}
+	if q.getVoiceMessageForOccurrenceStmt, err = db.PrepareContext(ctx, getVoiceMessageForOccurrence); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVoiceMessageForOccurrence: %w", err)
+	}
+	if q.hasDispenseMovementsStmt, err = db.PrepareContext(ctx, hasDispenseMovements); err != nil {
+		return nil, fmt.Errorf("error preparing query HasDispenseMovements: %w", err)
+	}
+	if q.listAudioMessageEncodingsStmt, err = db.PrepareContext(ctx, listAudioMessageEncodings); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAudioMessageEncodings: %w", err)
+	}
+	if q.listAvailableLotsFIFOStmt, err = db.PrepareContext(ctx, listAvailableLotsFIFO); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAvailableLotsFIFO: %w", err)
+	}
+	if q.listDispenseEventsByLotStmt, err = db.PrepareContext(ctx, listDispenseEventsByLot); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDispenseEventsByLot: %w", err)
+	}
 	if q.listDispenseEventsByPatientStmt, err = db.PrepareContext(ctx, listDispenseEventsByPatient); err != nil {
 		return nil, fmt.Errorf("error preparing query ListDispenseEventsByPatient: %w", err)
 	}
+	if q.listDueOutboxNotificationsStmt, err = db.PrepareContext(ctx, listDueOutboxNotifications); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDueOutboxNotifications: %w", err)
+	}
+	if q.listExpiringLotsByPatientStmt, err = db.PrepareContext(ctx, listExpiringLotsByPatient); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExpiringLotsByPatient: %w", err)
+	}
+	if q.listLiveAudioMessagePathsStmt, err = db.PrepareContext(ctx, listLiveAudioMessagePaths); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLiveAudioMessagePaths: %w", err)
+	}
+	if q.listLotConsumptionsByDispenseEventStmt, err = db.PrepareContext(ctx, listLotConsumptionsByDispenseEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLotConsumptionsByDispenseEvent: %w", err)
+	}
+	if q.listMedicationIngredientsByPatientStmt, err = db.PrepareContext(ctx, listMedicationIngredientsByPatient); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMedicationIngredientsByPatient: %w", err)
+	}
+	if q.listMedicationLotsStmt, err = db.PrepareContext(ctx, listMedicationLots); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMedicationLots: %w", err)
+	}
 	if q.listMedicationsByPatientStmt, err = db.PrepareContext(ctx, listMedicationsByPatient); err != nil {
 		return nil, fmt.Errorf("error preparing query ListMedicationsByPatient: %w", err)
 	}
+	if q.listMedicationsFromSiloStmt, err = db.PrepareContext(ctx, listMedicationsFromSilo); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMedicationsFromSilo: %w", err)
+	}
 	if q.listNotificationEventsByPatientStmt, err = db.PrepareContext(ctx, listNotificationEventsByPatient); err != nil {
 		return nil, fmt.Errorf("error preparing query ListNotificationEventsByPatient: %w", err)
 	}
+	if q.listNotificationEventsPageStmt, err = db.PrepareContext(ctx, listNotificationEventsPage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListNotificationEventsPage: %w", err)
+	}
+	if q.listNotificationPreferencesByUserStmt, err = db.PrepareContext(ctx, listNotificationPreferencesByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query ListNotificationPreferencesByUser: %w", err)
+	}
 	if q.listPatientsStmt, err = db.PrepareContext(ctx, listPatients); err != nil {
 		return nil, fmt.Errorf("error preparing query ListPatients: %w", err)
 	}
 	if q.listPatientsByUserStmt, err = db.PrepareContext(ctx, listPatientsByUser); err != nil {
 		return nil, fmt.Errorf("error preparing query ListPatientsByUser: %w", err)
 	}
+	if q.listPharmaciesStmt, err = db.PrepareContext(ctx, listPharmacies); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPharmacies: %w", err)
+	}
+	if q.listPrescriptionsByMedicationStmt, err = db.PrepareContext(ctx, listPrescriptionsByMedication); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPrescriptionsByMedication: %w", err)
+	}
+	if q.listPrescriptionsNeedingRenewalStmt, err = db.PrepareContext(ctx, listPrescriptionsNeedingRenewal); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPrescriptionsNeedingRenewal: %w", err)
+	}
+	if q.listPurgeableAudioMessagesStmt, err = db.PrepareContext(ctx, listPurgeableAudioMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPurgeableAudioMessages: %w", err)
+	}
+	if q.listRefillRequestDeliveriesStmt, err = db.PrepareContext(ctx, listRefillRequestDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRefillRequestDeliveries: %w", err)
+	}
+	if q.listRefillRequestsByPatientStmt, err = db.PrepareContext(ctx, listRefillRequestsByPatient); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRefillRequestsByPatient: %w", err)
+	}
 	if q.listScheduleItemsByScheduleStmt, err = db.PrepareContext(ctx, listScheduleItemsBySchedule); err != nil {
 		return nil, fmt.Errorf("error preparing query ListScheduleItemsBySchedule: %w", err)
 	}
 	if q.listSchedulesByPatientStmt, err = db.PrepareContext(ctx, listSchedulesByPatient); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSchedulesByPatient: %w", err)
 	}
+	if q.listSentRemindersByUserSinceStmt, err = db.PrepareContext(ctx, listSentRemindersByUserSince); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSentRemindersByUserSince: %w", err)
+	}
+	if q.listSilosByPatientStmt, err = db.PrepareContext(ctx, listSilosByPatient); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSilosByPatient: %w", err)
+	}
+	if q.listStockMovementsByMedicationStmt, err = db.PrepareContext(ctx, listStockMovementsByMedication); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStockMovementsByMedication: %w", err)
+	}
+	if q.listStockMovementsByPatientStmt, err = db.PrepareContext(ctx, listStockMovementsByPatient); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStockMovementsByPatient: %w", err)
+	}
+	if q.listTTSCacheEntriesByLastUsedStmt, err = db.PrepareContext(ctx, listTTSCacheEntriesByLastUsed); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTTSCacheEntriesByLastUsed: %w", err)
+	}
 	if q.listUsersStmt, err = db.PrepareContext(ctx, listUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListUsers: %w", err)
 	}
+	if q.listVoiceMessagesByPatientStmt, err = db.PrepareContext(ctx, listVoiceMessagesByPatient); err != nil {
+		return nil, fmt.Errorf("error preparing query ListVoiceMessagesByPatient: %w", err)
+	}
+	if q.markAudioMessageAckedStmt, err = db.PrepareContext(ctx, markAudioMessageAcked); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkAudioMessageAcked: %w", err)
+	}
+	if q.markAudioMessageDeliveredStmt, err = db.PrepareContext(ctx, markAudioMessageDelivered); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkAudioMessageDelivered: %w", err)
+	}
+	if q.markAudioMessagePurgedStmt, err = db.PrepareContext(ctx, markAudioMessagePurged); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkAudioMessagePurged: %w", err)
+	}
+	if q.markLotExpiryAlertedStmt, err = db.PrepareContext(ctx, markLotExpiryAlerted); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkLotExpiryAlerted: %w", err)
+	}
+	if q.markLowStockAlertedStmt, err = db.PrepareContext(ctx, markLowStockAlerted); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkLowStockAlerted: %w", err)
+	}
+	if q.markOutboxNotificationFailedStmt, err = db.PrepareContext(ctx, markOutboxNotificationFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxNotificationFailed: %w", err)
+	}
+	if q.markOutboxNotificationSentStmt, err = db.PrepareContext(ctx, markOutboxNotificationSent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxNotificationSent: %w", err)
+	}
+	if q.markPrescriptionExpiryAlertedStmt, err = db.PrepareContext(ctx, markPrescriptionExpiryAlerted); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkPrescriptionExpiryAlerted: %w", err)
+	}
+	if q.markPrescriptionNoRefillsAlertedStmt, err = db.PrepareContext(ctx, markPrescriptionNoRefillsAlerted); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkPrescriptionNoRefillsAlerted: %w", err)
+	}
+	if q.markRunoutAlertedStmt, err = db.PrepareContext(ctx, markRunoutAlerted); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkRunoutAlerted: %w", err)
+	}
+	if q.markSiloCalibratedStmt, err = db.PrepareContext(ctx, markSiloCalibrated); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkSiloCalibrated: %w", err)
+	}
+	if q.searchCatalogStmt, err = db.PrepareContext(ctx, searchCatalog); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchCatalog: %w", err)
+	}
 	if q.setActivePatientStmt, err = db.PrepareContext(ctx, setActivePatient); err != nil {
 		return nil, fmt.Errorf("error preparing query SetActivePatient: %w", err)
 	}
+	if q.setMedicationCatalogEntryStmt, err = db.PrepareContext(ctx, setMedicationCatalogEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMedicationCatalogEntry: %w", err)
+	}
+	if q.setMedicationSiloStmt, err = db.PrepareContext(ctx, setMedicationSilo); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMedicationSilo: %w", err)
+	}
+	if q.sumStockMovementsStmt, err = db.PrepareContext(ctx, sumStockMovements); err != nil {
+		return nil, fmt.Errorf("error preparing query SumStockMovements: %w", err)
+	}
+	if q.touchTTSCacheEntryStmt, err = db.PrepareContext(ctx, touchTTSCacheEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchTTSCacheEntry: %w", err)
+	}
 	if q.updateDispenseEventStmt, err = db.PrepareContext(ctx, updateDispenseEvent); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateDispenseEvent: %w", err)
 	}
 	if q.updateMedicationStmt, err = db.PrepareContext(ctx, updateMedication); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateMedication: %w", err)
 	}
+	if q.updateNotificationDeliveryStatusStmt, err = db.PrepareContext(ctx, updateNotificationDeliveryStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateNotificationDeliveryStatus: %w", err)
+	}
 	if q.updatePatientStmt, err = db.PrepareContext(ctx, updatePatient); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdatePatient: %w", err)
 	}
+	if q.updatePharmacyStmt, err = db.PrepareContext(ctx, updatePharmacy); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePharmacy: %w", err)
+	}
+	if q.updatePrescriptionStmt, err = db.PrepareContext(ctx, updatePrescription); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePrescription: %w", err)
+	}
+	if q.updateRefillRequestStatusStmt, err = db.PrepareContext(ctx, updateRefillRequestStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateRefillRequestStatus: %w", err)
+	}
 	if q.updateScheduleStmt, err = db.PrepareContext(ctx, updateSchedule); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSchedule: %w", err)
 	}
+	if q.updateSiloStmt, err = db.PrepareContext(ctx, updateSilo); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateSilo: %w", err)
+	}
 	if q.updateUserStmt, err = db.PrepareContext(ctx, updateUser); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateUser: %w", err)
 	}
+	if q.upsertCatalogEntryStmt, err = db.PrepareContext(ctx, upsertCatalogEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertCatalogEntry: %w", err)
+	}
+	if q.upsertNotificationPreferenceStmt, err = db.PrepareContext(ctx, upsertNotificationPreference); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertNotificationPreference: %w", err)
+	}
+	if q.upsertPatientVoiceSettingsStmt, err = db.PrepareContext(ctx, upsertPatientVoiceSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPatientVoiceSettings: %w", err)
+	}
+	if q.upsertTTSCacheEntryStmt, err = db.PrepareContext(ctx, upsertTTSCacheEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertTTSCacheEntry: %w", err)
+	}
+	if q.usePrescriptionRefillStmt, err = db.PrepareContext(ctx, usePrescriptionRefill); err != nil {
+		return nil, fmt.Errorf("error preparing query UsePrescriptionRefill: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.adjustMedicationStockStmt != nil {
+		if cerr := q.adjustMedicationStockStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing adjustMedicationStockStmt: %w", cerr)
+		}
+	}
 	if q.archiveScheduleStmt != nil {
 		if cerr := q.archiveScheduleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing archiveScheduleStmt: %w", cerr)
 		}
 	}
+	if q.cancelOutboxNotificationStmt != nil {
+		if cerr := q.cancelOutboxNotificationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelOutboxNotificationStmt: %w", cerr)
+		}
+	}
+	if q.cancelQueuedOutboxNotificationsStmt != nil {
+		if cerr := q.cancelQueuedOutboxNotificationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelQueuedOutboxNotificationsStmt: %w", cerr)
+		}
+	}
+	if q.claimOutboxNotificationStmt != nil {
+		if cerr := q.claimOutboxNotificationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimOutboxNotificationStmt: %w", cerr)
+		}
+	}
+	if q.clearStockAlertsStmt != nil {
+		if cerr := q.clearStockAlertsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearStockAlertsStmt: %w", cerr)
+		}
+	}
+	if q.consumeMedicationLotStmt != nil {
+		if cerr := q.consumeMedicationLotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing consumeMedicationLotStmt: %w", cerr)
+		}
+	}
+	if q.countNotificationEventsStmt != nil {
+		if cerr := q.countNotificationEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countNotificationEventsStmt: %w", cerr)
+		}
+	}
+	if q.createAudioMessageStmt != nil {
+		if cerr := q.createAudioMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAudioMessageStmt: %w", cerr)
+		}
+	}
+	if q.createAudioMessageEncodingStmt != nil {
+		if cerr := q.createAudioMessageEncodingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAudioMessageEncodingStmt: %w", cerr)
+		}
+	}
 	if q.createDispenseEventStmt != nil {
 		if cerr := q.createDispenseEventStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createDispenseEventStmt: %w", cerr)
 		}
 	}
+	if q.createDispenseIdempotencyKeyStmt != nil {
+		if cerr := q.createDispenseIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDispenseIdempotencyKeyStmt: %w", cerr)
+		}
+	}
 	if q.createMedicationStmt != nil {
 		if cerr := q.createMedicationStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createMedicationStmt: %w", cerr)
 		}
 	}
+	if q.createMedicationLotStmt != nil {
+		if cerr := q.createMedicationLotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMedicationLotStmt: %w", cerr)
+		}
+	}
 	if q.createNotificationEventStmt != nil {
 		if cerr := q.createNotificationEventStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createNotificationEventStmt: %w", cerr)
@@ -150,6 +511,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createPatientStmt: %w", cerr)
 		}
 	}
+	if q.createPharmacyStmt != nil {
+		if cerr := q.createPharmacyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPharmacyStmt: %w", cerr)
+		}
+	}
+	if q.createPrescriptionStmt != nil {
+		if cerr := q.createPrescriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPrescriptionStmt: %w", cerr)
+		}
+	}
+	if q.createRefillRequestStmt != nil {
+		if cerr := q.createRefillRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRefillRequestStmt: %w", cerr)
+		}
+	}
+	if q.createRefillRequestDeliveryStmt != nil {
+		if cerr := q.createRefillRequestDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRefillRequestDeliveryStmt: %w", cerr)
+		}
+	}
 	if q.createScheduleStmt != nil {
 		if cerr := q.createScheduleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createScheduleStmt: %w", cerr)
@@ -160,51 +541,216 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createScheduleItemStmt: %w", cerr)
 		}
 	}
+	if q.createSiloStmt != nil {
+		if cerr := q.createSiloStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSiloStmt: %w", cerr)
+		}
+	}
+	if q.createStockMovementStmt != nil {
+		if cerr := q.createStockMovementStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStockMovementStmt: %w", cerr)
+		}
+	}
+	if q.createStockMovementLotStmt != nil {
+		if cerr := q.createStockMovementLotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStockMovementLotStmt: %w", cerr)
+		}
+	}
 	if q.createUserStmt != nil {
 		if cerr := q.createUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
 		}
 	}
+	if q.createVoiceMessageStmt != nil {
+		if cerr := q.createVoiceMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createVoiceMessageStmt: %w", cerr)
+		}
+	}
+	if q.deactivateVoiceMessageStmt != nil {
+		if cerr := q.deactivateVoiceMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deactivateVoiceMessageStmt: %w", cerr)
+		}
+	}
 	if q.deleteMedicationStmt != nil {
 		if cerr := q.deleteMedicationStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteMedicationStmt: %w", cerr)
 		}
 	}
+	if q.deletePharmacyStmt != nil {
+		if cerr := q.deletePharmacyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePharmacyStmt: %w", cerr)
+		}
+	}
+	if q.deletePrescriptionStmt != nil {
+		if cerr := q.deletePrescriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePrescriptionStmt: %w", cerr)
+		}
+	}
 	if q.deleteScheduleItemsByScheduleStmt != nil {
 		if cerr := q.deleteScheduleItemsByScheduleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteScheduleItemsByScheduleStmt: %w", cerr)
 		}
 	}
+	if q.deleteSilosFromStmt != nil {
+		if cerr := q.deleteSilosFromStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSilosFromStmt: %w", cerr)
+		}
+	}
+	if q.deleteTTSCacheEntryStmt != nil {
+		if cerr := q.deleteTTSCacheEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTTSCacheEntryStmt: %w", cerr)
+		}
+	}
+	if q.enqueueNotificationStmt != nil {
+		if cerr := q.enqueueNotificationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enqueueNotificationStmt: %w", cerr)
+		}
+	}
+	if q.expireResolvedAudioMessagesStmt != nil {
+		if cerr := q.expireResolvedAudioMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing expireResolvedAudioMessagesStmt: %w", cerr)
+		}
+	}
+	if q.expireStaleAudioMessagesStmt != nil {
+		if cerr := q.expireStaleAudioMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing expireStaleAudioMessagesStmt: %w", cerr)
+		}
+	}
+	if q.expireSupersededAudioMessagesStmt != nil {
+		if cerr := q.expireSupersededAudioMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing expireSupersededAudioMessagesStmt: %w", cerr)
+		}
+	}
 	if q.getActivePatientStmt != nil {
 		if cerr := q.getActivePatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getActivePatientStmt: %w", cerr)
 		}
 	}
+	if q.getAudioMessageStmt != nil {
+		if cerr := q.getAudioMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudioMessageStmt: %w", cerr)
+		}
+	}
+	if q.getCatalogEntryStmt != nil {
+		if cerr := q.getCatalogEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCatalogEntryStmt: %w", cerr)
+		}
+	}
 	if q.getDispenseEventStmt != nil {
 		if cerr := q.getDispenseEventStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getDispenseEventStmt: %w", cerr)
 		}
 	}
+	if q.getDispenseEventByOccurrenceStmt != nil {
+		if cerr := q.getDispenseEventByOccurrenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDispenseEventByOccurrenceStmt: %w", cerr)
+		}
+	}
+	if q.getDispenseIdempotencyKeyStmt != nil {
+		if cerr := q.getDispenseIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDispenseIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.getLatestPendingAudioMessageStmt != nil {
+		if cerr := q.getLatestPendingAudioMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestPendingAudioMessageStmt: %w", cerr)
+		}
+	}
 	if q.getMedicationStmt != nil {
 		if cerr := q.getMedicationStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMedicationStmt: %w", cerr)
 		}
 	}
+	if q.getMedicationInSiloStmt != nil {
+		if cerr := q.getMedicationInSiloStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMedicationInSiloStmt: %w", cerr)
+		}
+	}
+	if q.getMedicationLotStmt != nil {
+		if cerr := q.getMedicationLotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMedicationLotStmt: %w", cerr)
+		}
+	}
 	if q.getNotificationEventByOccurrenceStmt != nil {
 		if cerr := q.getNotificationEventByOccurrenceStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getNotificationEventByOccurrenceStmt: %w", cerr)
 		}
 	}
+	if q.getNotificationEventByProviderMessageIDStmt != nil {
+		if cerr := q.getNotificationEventByProviderMessageIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotificationEventByProviderMessageIDStmt: %w", cerr)
+		}
+	}
+	if q.getNotificationPreferenceStmt != nil {
+		if cerr := q.getNotificationPreferenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotificationPreferenceStmt: %w", cerr)
+		}
+	}
+	if q.getOldestPendingAudioMessageStmt != nil {
+		if cerr := q.getOldestPendingAudioMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOldestPendingAudioMessageStmt: %w", cerr)
+		}
+	}
+	if q.getOpenRefillRequestStmt != nil {
+		if cerr := q.getOpenRefillRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOpenRefillRequestStmt: %w", cerr)
+		}
+	}
+	if q.getOutboxNotificationStmt != nil {
+		if cerr := q.getOutboxNotificationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOutboxNotificationStmt: %w", cerr)
+		}
+	}
 	if q.getPatientStmt != nil {
 		if cerr := q.getPatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getPatientStmt: %w", cerr)
 		}
 	}
+	if q.getPatientVoiceSettingsStmt != nil {
+		if cerr := q.getPatientVoiceSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPatientVoiceSettingsStmt: %w", cerr)
+		}
+	}
+	if q.getPharmacyStmt != nil {
+		if cerr := q.getPharmacyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPharmacyStmt: %w", cerr)
+		}
+	}
+	if q.getPrescriptionStmt != nil {
+		if cerr := q.getPrescriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPrescriptionStmt: %w", cerr)
+		}
+	}
+	if q.getRefillRequestStmt != nil {
+		if cerr := q.getRefillRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRefillRequestStmt: %w", cerr)
+		}
+	}
+	if q.getRefillablePrescriptionStmt != nil {
+		if cerr := q.getRefillablePrescriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRefillablePrescriptionStmt: %w", cerr)
+		}
+	}
 	if q.getScheduleStmt != nil {
 		if cerr := q.getScheduleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getScheduleStmt: %w", cerr)
 		}
 	}
+	if q.getSiloStmt != nil {
+		if cerr := q.getSiloStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSiloStmt: %w", cerr)
+		}
+	}
+	if q.getTTSCacheEntryStmt != nil {
+		if cerr := q.getTTSCacheEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTTSCacheEntryStmt: %w", cerr)
+		}
+	}
+	if q.getTTSCacheSizeStmt != nil {
+		if cerr := q.getTTSCacheSizeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTTSCacheSizeStmt: %w", cerr)
+		}
+	}
 	if q.getUserStmt != nil {
 		if cerr := q.getUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
@@ -215,21 +761,101 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserByEmailStmt: %w", cerr)
 		}
 	}
+	if q.getUserByPhoneStmt != nil {
+		if cerr := q.getUserByPhoneStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByPhoneStmt: %w", cerr)
+		}
+	}
+	if q.getVoiceMessageStmt != nil {
+		if cerr := q.getVoiceMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVoiceMessageStmt: %w", cerr)
+		}
+	}
+	if q.getVoiceMessageForOccurrenceStmt != nil {
+		if cerr := q.getVoiceMessageForOccurrenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVoiceMessageForOccurrenceStmt: %w", cerr)
+		}
+	}
+	if q.hasDispenseMovementsStmt != nil {
+		if cerr := q.hasDispenseMovementsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing hasDispenseMovementsStmt: %w", cerr)
+		}
+	}
+	if q.listAudioMessageEncodingsStmt != nil {
+		if cerr := q.listAudioMessageEncodingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAudioMessageEncodingsStmt: %w", cerr)
+		}
+	}
+	if q.listAvailableLotsFIFOStmt != nil {
+		if cerr := q.listAvailableLotsFIFOStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAvailableLotsFIFOStmt: %w", cerr)
+		}
+	}
+	if q.listDispenseEventsByLotStmt != nil {
+		if cerr := q.listDispenseEventsByLotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDispenseEventsByLotStmt: %w", cerr)
+		}
+	}
 	if q.listDispenseEventsByPatientStmt != nil {
 		if cerr := q.listDispenseEventsByPatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listDispenseEventsByPatientStmt: %w", cerr)
 		}
 	}
+	if q.listDueOutboxNotificationsStmt != nil {
+		if cerr := q.listDueOutboxNotificationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDueOutboxNotificationsStmt: %w", cerr)
+		}
+	}
+	if q.listExpiringLotsByPatientStmt != nil {
+		if cerr := q.listExpiringLotsByPatientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExpiringLotsByPatientStmt: %w", cerr)
+		}
+	}
+	if q.listLiveAudioMessagePathsStmt != nil {
+		if cerr := q.listLiveAudioMessagePathsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLiveAudioMessagePathsStmt: %w", cerr)
+		}
+	}
+	if q.listLotConsumptionsByDispenseEventStmt != nil {
+		if cerr := q.listLotConsumptionsByDispenseEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLotConsumptionsByDispenseEventStmt: %w", cerr)
+		}
+	}
+	if q.listMedicationIngredientsByPatientStmt != nil {
+		if cerr := q.listMedicationIngredientsByPatientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMedicationIngredientsByPatientStmt: %w", cerr)
+		}
+	}
+	if q.listMedicationLotsStmt != nil {
+		if cerr := q.listMedicationLotsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMedicationLotsStmt: %w", cerr)
+		}
+	}
 	if q.listMedicationsByPatientStmt != nil {
 		if cerr := q.listMedicationsByPatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listMedicationsByPatientStmt: %w", cerr)
 		}
 	}
+	if q.listMedicationsFromSiloStmt != nil {
+		if cerr := q.listMedicationsFromSiloStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMedicationsFromSiloStmt: %w", cerr)
+		}
+	}
 	if q.listNotificationEventsByPatientStmt != nil {
 		if cerr := q.listNotificationEventsByPatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listNotificationEventsByPatientStmt: %w", cerr)
 		}
 	}
+	if q.listNotificationEventsPageStmt != nil {
+		if cerr := q.listNotificationEventsPageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listNotificationEventsPageStmt: %w", cerr)
+		}
+	}
+	if q.listNotificationPreferencesByUserStmt != nil {
+		if cerr := q.listNotificationPreferencesByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listNotificationPreferencesByUserStmt: %w", cerr)
+		}
+	}
 	if q.listPatientsStmt != nil {
 		if cerr := q.listPatientsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listPatientsStmt: %w", cerr)
@@ -240,6 +866,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listPatientsByUserStmt: %w", cerr)
 		}
 	}
+	if q.listPharmaciesStmt != nil {
+		if cerr := q.listPharmaciesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPharmaciesStmt: %w", cerr)
+		}
+	}
+	if q.listPrescriptionsByMedicationStmt != nil {
+		if cerr := q.listPrescriptionsByMedicationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPrescriptionsByMedicationStmt: %w", cerr)
+		}
+	}
+	if q.listPrescriptionsNeedingRenewalStmt != nil {
+		if cerr := q.listPrescriptionsNeedingRenewalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPrescriptionsNeedingRenewalStmt: %w", cerr)
+		}
+	}
+	if q.listPurgeableAudioMessagesStmt != nil {
+		if cerr := q.listPurgeableAudioMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPurgeableAudioMessagesStmt: %w", cerr)
+		}
+	}
+	if q.listRefillRequestDeliveriesStmt != nil {
+		if cerr := q.listRefillRequestDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRefillRequestDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.listRefillRequestsByPatientStmt != nil {
+		if cerr := q.listRefillRequestsByPatientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRefillRequestsByPatientStmt: %w", cerr)
+		}
+	}
 	if q.listScheduleItemsByScheduleStmt != nil {
 		if cerr := q.listScheduleItemsByScheduleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listScheduleItemsByScheduleStmt: %w", cerr)
@@ -250,16 +906,126 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listSchedulesByPatientStmt: %w", cerr)
 		}
 	}
+	if q.listSentRemindersByUserSinceStmt != nil {
+		if cerr := q.listSentRemindersByUserSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSentRemindersByUserSinceStmt: %w", cerr)
+		}
+	}
+	if q.listSilosByPatientStmt != nil {
+		if cerr := q.listSilosByPatientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSilosByPatientStmt: %w", cerr)
+		}
+	}
+	if q.listStockMovementsByMedicationStmt != nil {
+		if cerr := q.listStockMovementsByMedicationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStockMovementsByMedicationStmt: %w", cerr)
+		}
+	}
+	if q.listStockMovementsByPatientStmt != nil {
+		if cerr := q.listStockMovementsByPatientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStockMovementsByPatientStmt: %w", cerr)
+		}
+	}
+	if q.listTTSCacheEntriesByLastUsedStmt != nil {
+		if cerr := q.listTTSCacheEntriesByLastUsedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTTSCacheEntriesByLastUsedStmt: %w", cerr)
+		}
+	}
 	if q.listUsersStmt != nil {
 		if cerr := q.listUsersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listUsersStmt: %w", cerr)
 		}
 	}
+	if q.listVoiceMessagesByPatientStmt != nil {
+		if cerr := q.listVoiceMessagesByPatientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listVoiceMessagesByPatientStmt: %w", cerr)
+		}
+	}
+	if q.markAudioMessageAckedStmt != nil {
+		if cerr := q.markAudioMessageAckedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markAudioMessageAckedStmt: %w", cerr)
+		}
+	}
+	if q.markAudioMessageDeliveredStmt != nil {
+		if cerr := q.markAudioMessageDeliveredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markAudioMessageDeliveredStmt: %w", cerr)
+		}
+	}
+	if q.markAudioMessagePurgedStmt != nil {
+		if cerr := q.markAudioMessagePurgedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markAudioMessagePurgedStmt: %w", cerr)
+		}
+	}
+	if q.markLotExpiryAlertedStmt != nil {
+		if cerr := q.markLotExpiryAlertedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markLotExpiryAlertedStmt: %w", cerr)
+		}
+	}
+	if q.markLowStockAlertedStmt != nil {
+		if cerr := q.markLowStockAlertedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markLowStockAlertedStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxNotificationFailedStmt != nil {
+		if cerr := q.markOutboxNotificationFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxNotificationFailedStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxNotificationSentStmt != nil {
+		if cerr := q.markOutboxNotificationSentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxNotificationSentStmt: %w", cerr)
+		}
+	}
+	if q.markPrescriptionExpiryAlertedStmt != nil {
+		if cerr := q.markPrescriptionExpiryAlertedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markPrescriptionExpiryAlertedStmt: %w", cerr)
+		}
+	}
+	if q.markPrescriptionNoRefillsAlertedStmt != nil {
+		if cerr := q.markPrescriptionNoRefillsAlertedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markPrescriptionNoRefillsAlertedStmt: %w", cerr)
+		}
+	}
+	if q.markRunoutAlertedStmt != nil {
+		if cerr := q.markRunoutAlertedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markRunoutAlertedStmt: %w", cerr)
+		}
+	}
+	if q.markSiloCalibratedStmt != nil {
+		if cerr := q.markSiloCalibratedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markSiloCalibratedStmt: %w", cerr)
+		}
+	}
+	if q.searchCatalogStmt != nil {
+		if cerr := q.searchCatalogStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchCatalogStmt: %w", cerr)
+		}
+	}
 	if q.setActivePatientStmt != nil {
 		if cerr := q.setActivePatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing setActivePatientStmt: %w", cerr)
 		}
 	}
+	if q.setMedicationCatalogEntryStmt != nil {
+		if cerr := q.setMedicationCatalogEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMedicationCatalogEntryStmt: %w", cerr)
+		}
+	}
+	if q.setMedicationSiloStmt != nil {
+		if cerr := q.setMedicationSiloStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMedicationSiloStmt: %w", cerr)
+		}
+	}
+	if q.sumStockMovementsStmt != nil {
+		if cerr := q.sumStockMovementsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing sumStockMovementsStmt: %w", cerr)
+		}
+	}
+	if q.touchTTSCacheEntryStmt != nil {
+		if cerr := q.touchTTSCacheEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchTTSCacheEntryStmt: %w", cerr)
+		}
+	}
 	if q.updateDispenseEventStmt != nil {
 		if cerr := q.updateDispenseEventStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateDispenseEventStmt: %w", cerr)
@@ -270,21 +1036,71 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateMedicationStmt: %w", cerr)
 		}
 	}
+	if q.updateNotificationDeliveryStatusStmt != nil {
+		if cerr := q.updateNotificationDeliveryStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateNotificationDeliveryStatusStmt: %w", cerr)
+		}
+	}
 	if q.updatePatientStmt != nil {
 		if cerr := q.updatePatientStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updatePatientStmt: %w", cerr)
 		}
 	}
+	if q.updatePharmacyStmt != nil {
+		if cerr := q.updatePharmacyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePharmacyStmt: %w", cerr)
+		}
+	}
+	if q.updatePrescriptionStmt != nil {
+		if cerr := q.updatePrescriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePrescriptionStmt: %w", cerr)
+		}
+	}
+	if q.updateRefillRequestStatusStmt != nil {
+		if cerr := q.updateRefillRequestStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateRefillRequestStatusStmt: %w", cerr)
+		}
+	}
 	if q.updateScheduleStmt != nil {
 		if cerr := q.updateScheduleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateScheduleStmt: %w", cerr)
 		}
 	}
+	if q.updateSiloStmt != nil {
+		if cerr := q.updateSiloStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateSiloStmt: %w", cerr)
+		}
+	}
 	if q.updateUserStmt != nil {
 		if cerr := q.updateUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateUserStmt: %w", cerr)
 		}
 	}
+	if q.upsertCatalogEntryStmt != nil {
+		if cerr := q.upsertCatalogEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertCatalogEntryStmt: %w", cerr)
+		}
+	}
+	if q.upsertNotificationPreferenceStmt != nil {
+		if cerr := q.upsertNotificationPreferenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertNotificationPreferenceStmt: %w", cerr)
+		}
+	}
+	if q.upsertPatientVoiceSettingsStmt != nil {
+		if cerr := q.upsertPatientVoiceSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPatientVoiceSettingsStmt: %w", cerr)
+		}
+	}
+	if q.upsertTTSCacheEntryStmt != nil {
+		if cerr := q.upsertTTSCacheEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertTTSCacheEntryStmt: %w", cerr)
+		}
+	}
+	if q.usePrescriptionRefillStmt != nil {
+		if cerr := q.usePrescriptionRefillStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing usePrescriptionRefillStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -322,77 +1138,281 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                                   DBTX
-	tx                                   *sql.Tx
-	archiveScheduleStmt                  *sql.Stmt
-	createDispenseEventStmt              *sql.Stmt
-	createMedicationStmt                 *sql.Stmt
-	createNotificationEventStmt          *sql.Stmt
-	createPatientStmt                    *sql.Stmt
-	createScheduleStmt                   *sql.Stmt
-	createScheduleItemStmt               *sql.Stmt
-	createUserStmt                       *sql.Stmt
-	deleteMedicationStmt                 *sql.Stmt
-	deleteScheduleItemsByScheduleStmt    *sql.Stmt
-	getActivePatientStmt                 *sql.Stmt
-	getDispenseEventStmt                 *sql.Stmt
-	getMedicationStmt                    *sql.Stmt
-	getNotificationEventByOccurrenceStmt *sql.Stmt
-	getPatientStmt                       *sql.Stmt
-	getScheduleStmt                      *sql.Stmt
-	getUserStmt                          *sql.Stmt
-	getUserByEmailStmt                   *sql.Stmt
-	listDispenseEventsByPatientStmt      *sql.Stmt
-	listMedicationsByPatientStmt         *sql.Stmt
-	listNotificationEventsByPatientStmt  *sql.Stmt
-	listPatientsStmt                     *sql.Stmt
-	listPatientsByUserStmt               *sql.Stmt
-	listScheduleItemsByScheduleStmt      *sql.Stmt
-	listSchedulesByPatientStmt           *sql.Stmt
-	listUsersStmt                        *sql.Stmt
-	setActivePatientStmt                 *sql.Stmt
-	updateDispenseEventStmt              *sql.Stmt
-	updateMedicationStmt                 *sql.Stmt
-	updatePatientStmt                    *sql.Stmt
-	updateScheduleStmt                   *sql.Stmt
-	updateUserStmt                       *sql.Stmt
+	db                                          DBTX
+	tx                                          *sql.Tx
+	adjustMedicationStockStmt                   *sql.Stmt
+	archiveScheduleStmt                         *sql.Stmt
+	cancelOutboxNotificationStmt                *sql.Stmt
+	cancelQueuedOutboxNotificationsStmt         *sql.Stmt
+	claimOutboxNotificationStmt                 *sql.Stmt
+	clearStockAlertsStmt                        *sql.Stmt
+	consumeMedicationLotStmt                    *sql.Stmt
+	countNotificationEventsStmt                 *sql.Stmt
+	createAudioMessageStmt                      *sql.Stmt
+	createAudioMessageEncodingStmt              *sql.Stmt
+	createDispenseEventStmt                     *sql.Stmt
+	createDispenseIdempotencyKeyStmt            *sql.Stmt
+	createMedicationStmt                        *sql.Stmt
+	createMedicationLotStmt                     *sql.Stmt
+	createNotificationEventStmt                 *sql.Stmt
+	createPatientStmt                           *sql.Stmt
+	createPharmacyStmt                          *sql.Stmt
+	createPrescriptionStmt                      *sql.Stmt
+	createRefillRequestStmt                     *sql.Stmt
+	createRefillRequestDeliveryStmt             *sql.Stmt
+	createScheduleStmt                          *sql.Stmt
+	createScheduleItemStmt                      *sql.Stmt
+	createSiloStmt                              *sql.Stmt
+	createStockMovementStmt                     *sql.Stmt
+	createStockMovementLotStmt                  *sql.Stmt
+	createUserStmt                              *sql.Stmt
+	createVoiceMessageStmt                      *sql.Stmt
+	deactivateVoiceMessageStmt                  *sql.Stmt
+	deleteMedicationStmt                        *sql.Stmt
+	deletePharmacyStmt                          *sql.Stmt
+	deletePrescriptionStmt                      *sql.Stmt
+	deleteScheduleItemsByScheduleStmt           *sql.Stmt
+	deleteSilosFromStmt                         *sql.Stmt
+	deleteTTSCacheEntryStmt                     *sql.Stmt
+	enqueueNotificationStmt                     *sql.Stmt
+	expireResolvedAudioMessagesStmt             *sql.Stmt
+	expireStaleAudioMessagesStmt                *sql.Stmt
+	expireSupersededAudioMessagesStmt           *sql.Stmt
+	getActivePatientStmt                        *sql.Stmt
+	getAudioMessageStmt                         *sql.Stmt
+	getCatalogEntryStmt                         *sql.Stmt
+	getDispenseEventStmt                        *sql.Stmt
+	getDispenseEventByOccurrenceStmt            *sql.Stmt
+	getDispenseIdempotencyKeyStmt               *sql.Stmt
+	getLatestPendingAudioMessageStmt            *sql.Stmt
+	getMedicationStmt                           *sql.Stmt
+	getMedicationInSiloStmt                     *sql.Stmt
+	getMedicationLotStmt                        *sql.Stmt
+	getNotificationEventByOccurrenceStmt        *sql.Stmt
+	getNotificationEventByProviderMessageIDStmt *sql.Stmt
+	getNotificationPreferenceStmt               *sql.Stmt
+	getOldestPendingAudioMessageStmt            *sql.Stmt
+	getOpenRefillRequestStmt                    *sql.Stmt
+	getOutboxNotificationStmt                   *sql.Stmt
+	getPatientStmt                              *sql.Stmt
+	getPatientVoiceSettingsStmt                 *sql.Stmt
+	getPharmacyStmt                             *sql.Stmt
+	getPrescriptionStmt                         *sql.Stmt
+	getRefillRequestStmt                        *sql.Stmt
+	getRefillablePrescriptionStmt               *sql.Stmt
+	getScheduleStmt                             *sql.Stmt
+	getSiloStmt                                 *sql.Stmt
+	getTTSCacheEntryStmt                        *sql.Stmt
+	getTTSCacheSizeStmt                         *sql.Stmt
+	getUserStmt                                 *sql.Stmt
+	getUserByEmailStmt                          *sql.Stmt
+	getUserByPhoneStmt                          *sql.Stmt
+	getVoiceMessageStmt                         *sql.Stmt
+	getVoiceMessageForOccurrenceStmt            *sql.Stmt
+	hasDispenseMovementsStmt                    *sql.Stmt
+	listAudioMessageEncodingsStmt               *sql.Stmt
+	listAvailableLotsFIFOStmt                   *sql.Stmt
+	listDispenseEventsByLotStmt                 *sql.Stmt
+	listDispenseEventsByPatientStmt             *sql.Stmt
+	listDueOutboxNotificationsStmt              *sql.Stmt
+	listExpiringLotsByPatientStmt               *sql.Stmt
+	listLiveAudioMessagePathsStmt               *sql.Stmt
+	listLotConsumptionsByDispenseEventStmt      *sql.Stmt
+	listMedicationIngredientsByPatientStmt      *sql.Stmt
+	listMedicationLotsStmt                      *sql.Stmt
+	listMedicationsByPatientStmt                *sql.Stmt
+	listMedicationsFromSiloStmt                 *sql.Stmt
+	listNotificationEventsByPatientStmt         *sql.Stmt
+	listNotificationEventsPageStmt              *sql.Stmt
+	listNotificationPreferencesByUserStmt       *sql.Stmt
+	listPatientsStmt                            *sql.Stmt
+	listPatientsByUserStmt                      *sql.Stmt
+	listPharmaciesStmt                          *sql.Stmt
+	listPrescriptionsByMedicationStmt           *sql.Stmt
+	listPrescriptionsNeedingRenewalStmt         *sql.Stmt
+	listPurgeableAudioMessagesStmt              *sql.Stmt
+	listRefillRequestDeliveriesStmt             *sql.Stmt
+	listRefillRequestsByPatientStmt             *sql.Stmt
+	listScheduleItemsByScheduleStmt             *sql.Stmt
+	listSchedulesByPatientStmt                  *sql.Stmt
+	listSentRemindersByUserSinceStmt            *sql.Stmt
+	listSilosByPatientStmt                      *sql.Stmt
+	listStockMovementsByMedicationStmt          *sql.Stmt
+	listStockMovementsByPatientStmt             *sql.Stmt
+	listTTSCacheEntriesByLastUsedStmt           *sql.Stmt
+	listUsersStmt                               *sql.Stmt
+	listVoiceMessagesByPatientStmt              *sql.Stmt
+	markAudioMessageAckedStmt                   *sql.Stmt
+	markAudioMessageDeliveredStmt               *sql.Stmt
+	markAudioMessagePurgedStmt                  *sql.Stmt
+	markLotExpiryAlertedStmt                    *sql.Stmt
+	markLowStockAlertedStmt                     *sql.Stmt
+	markOutboxNotificationFailedStmt            *sql.Stmt
+	markOutboxNotificationSentStmt              *sql.Stmt
+	markPrescriptionExpiryAlertedStmt           *sql.Stmt
+	markPrescriptionNoRefillsAlertedStmt        *sql.Stmt
+	markRunoutAlertedStmt                       *sql.Stmt
+	markSiloCalibratedStmt                      *sql.Stmt
+	searchCatalogStmt                           *sql.Stmt
+	setActivePatientStmt                        *sql.Stmt
+	setMedicationCatalogEntryStmt               *sql.Stmt
+	setMedicationSiloStmt                       *sql.Stmt
+	sumStockMovementsStmt                       *sql.Stmt
+	touchTTSCacheEntryStmt                      *sql.Stmt
+	updateDispenseEventStmt                     *sql.Stmt
+	updateMedicationStmt                        *sql.Stmt
+	updateNotificationDeliveryStatusStmt        *sql.Stmt
+	updatePatientStmt                           *sql.Stmt
+	updatePharmacyStmt                          *sql.Stmt
+	updatePrescriptionStmt                      *sql.Stmt
+	updateRefillRequestStatusStmt               *sql.Stmt
+	updateScheduleStmt                          *sql.Stmt
+	updateSiloStmt                              *sql.Stmt
+	updateUserStmt                              *sql.Stmt
+	upsertCatalogEntryStmt                      *sql.Stmt
+	upsertNotificationPreferenceStmt            *sql.Stmt
+	upsertPatientVoiceSettingsStmt              *sql.Stmt
+	upsertTTSCacheEntryStmt                     *sql.Stmt
+	usePrescriptionRefillStmt                   *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                                   tx,
-		tx:                                   tx,
-		archiveScheduleStmt:                  q.archiveScheduleStmt,
-		createDispenseEventStmt:              q.createDispenseEventStmt,
-		createMedicationStmt:                 q.createMedicationStmt,
-		createNotificationEventStmt:          q.createNotificationEventStmt,
-		createPatientStmt:                    q.createPatientStmt,
-		createScheduleStmt:                   q.createScheduleStmt,
-		createScheduleItemStmt:               q.createScheduleItemStmt,
-		createUserStmt:                       q.createUserStmt,
-		deleteMedicationStmt:                 q.deleteMedicationStmt,
-		deleteScheduleItemsByScheduleStmt:    q.deleteScheduleItemsByScheduleStmt,
-		getActivePatientStmt:                 q.getActivePatientStmt,
-		getDispenseEventStmt:                 q.getDispenseEventStmt,
-		getMedicationStmt:                    q.getMedicationStmt,
-		getNotificationEventByOccurrenceStmt: q.getNotificationEventByOccurrenceStmt,
-		getPatientStmt:                       q.getPatientStmt,
-		getScheduleStmt:                      q.getScheduleStmt,
-		getUserStmt:                          q.getUserStmt,
-		getUserByEmailStmt:                   q.getUserByEmailStmt,
-		listDispenseEventsByPatientStmt:      q.listDispenseEventsByPatientStmt,
-		listMedicationsByPatientStmt:         q.listMedicationsByPatientStmt,
-		listNotificationEventsByPatientStmt:  q.listNotificationEventsByPatientStmt,
-		listPatientsStmt:                     q.listPatientsStmt,
-		listPatientsByUserStmt:               q.listPatientsByUserStmt,
-		listScheduleItemsByScheduleStmt:      q.listScheduleItemsByScheduleStmt,
-		listSchedulesByPatientStmt:           q.listSchedulesByPatientStmt,
-		listUsersStmt:                        q.listUsersStmt,
-		setActivePatientStmt:                 q.setActivePatientStmt,
-		updateDispenseEventStmt:              q.updateDispenseEventStmt,
-		updateMedicationStmt:                 q.updateMedicationStmt,
-		updatePatientStmt:                    q.updatePatientStmt,
-		updateScheduleStmt:                   q.updateScheduleStmt,
-		updateUserStmt:                       q.updateUserStmt,
+		db:                                          tx,
+		tx:                                          tx,
+		adjustMedicationStockStmt:                   q.adjustMedicationStockStmt,
+		archiveScheduleStmt:                         q.archiveScheduleStmt,
+		cancelOutboxNotificationStmt:                q.cancelOutboxNotificationStmt,
+		cancelQueuedOutboxNotificationsStmt:         q.cancelQueuedOutboxNotificationsStmt,
+		claimOutboxNotificationStmt:                 q.claimOutboxNotificationStmt,
+		clearStockAlertsStmt:                        q.clearStockAlertsStmt,
+		consumeMedicationLotStmt:                    q.consumeMedicationLotStmt,
+		countNotificationEventsStmt:                 q.countNotificationEventsStmt,
+		createAudioMessageStmt:                      q.createAudioMessageStmt,
+		createAudioMessageEncodingStmt:              q.createAudioMessageEncodingStmt,
+		createDispenseEventStmt:                     q.createDispenseEventStmt,
+		createDispenseIdempotencyKeyStmt:            q.createDispenseIdempotencyKeyStmt,
+		createMedicationStmt:                        q.createMedicationStmt,
+		createMedicationLotStmt:                     q.createMedicationLotStmt,
+		createNotificationEventStmt:                 q.createNotificationEventStmt,
+		createPatientStmt:                           q.createPatientStmt,
+		createPharmacyStmt:                          q.createPharmacyStmt,
+		createPrescriptionStmt:                      q.createPrescriptionStmt,
+		createRefillRequestStmt:                     q.createRefillRequestStmt,
+		createRefillRequestDeliveryStmt:             q.createRefillRequestDeliveryStmt,
+		createScheduleStmt:                          q.createScheduleStmt,
+		createScheduleItemStmt:                      q.createScheduleItemStmt,
+		createSiloStmt:                              q.createSiloStmt,
+		createStockMovementStmt:                     q.createStockMovementStmt,
+		createStockMovementLotStmt:                  q.createStockMovementLotStmt,
+		createUserStmt:                              q.createUserStmt,
+		createVoiceMessageStmt:                      q.createVoiceMessageStmt,
+		deactivateVoiceMessageStmt:                  q.deactivateVoiceMessageStmt,
+		deleteMedicationStmt:                        q.deleteMedicationStmt,
+		deletePharmacyStmt:                          q.deletePharmacyStmt,
+		deletePrescriptionStmt:                      q.deletePrescriptionStmt,
+		deleteScheduleItemsByScheduleStmt:           q.deleteScheduleItemsByScheduleStmt,
+		deleteSilosFromStmt:                         q.deleteSilosFromStmt,
+		deleteTTSCacheEntryStmt:                     q.deleteTTSCacheEntryStmt,
+		enqueueNotificationStmt:                     q.enqueueNotificationStmt,
+		expireResolvedAudioMessagesStmt:             q.expireResolvedAudioMessagesStmt,
+		expireStaleAudioMessagesStmt:                q.expireStaleAudioMessagesStmt,
+		expireSupersededAudioMessagesStmt:           q.expireSupersededAudioMessagesStmt,
+		getActivePatientStmt:                        q.getActivePatientStmt,
+		getAudioMessageStmt:                         q.getAudioMessageStmt,
+		getCatalogEntryStmt:                         q.getCatalogEntryStmt,
+		getDispenseEventStmt:                        q.getDispenseEventStmt,
+		getDispenseEventByOccurrenceStmt:            q.getDispenseEventByOccurrenceStmt,
+		getDispenseIdempotencyKeyStmt:               q.getDispenseIdempotencyKeyStmt,
+		getLatestPendingAudioMessageStmt:            q.getLatestPendingAudioMessageStmt,
+		getMedicationStmt:                           q.getMedicationStmt,
+		getMedicationInSiloStmt:                     q.getMedicationInSiloStmt,
+		getMedicationLotStmt:                        q.getMedicationLotStmt,
+		getNotificationEventByOccurrenceStmt:        q.getNotificationEventByOccurrenceStmt,
+		getNotificationEventByProviderMessageIDStmt: q.getNotificationEventByProviderMessageIDStmt,
+		getNotificationPreferenceStmt:               q.getNotificationPreferenceStmt,
+		getOldestPendingAudioMessageStmt:            q.getOldestPendingAudioMessageStmt,
+		getOpenRefillRequestStmt:                    q.getOpenRefillRequestStmt,
+		getOutboxNotificationStmt:                   q.getOutboxNotificationStmt,
+		getPatientStmt:                              q.getPatientStmt,
+		getPatientVoiceSettingsStmt:                 q.getPatientVoiceSettingsStmt,
+		getPharmacyStmt:                             q.getPharmacyStmt,
+		getPrescriptionStmt:                         q.getPrescriptionStmt,
+		getRefillRequestStmt:                        q.getRefillRequestStmt,
+		getRefillablePrescriptionStmt:               q.getRefillablePrescriptionStmt,
+		getScheduleStmt:                             q.getScheduleStmt,
+		getSiloStmt:                                 q.getSiloStmt,
+		getTTSCacheEntryStmt:                        q.getTTSCacheEntryStmt,
+		getTTSCacheSizeStmt:                         q.getTTSCacheSizeStmt,
+		getUserStmt:                                 q.getUserStmt,
+		getUserByEmailStmt:                          q.getUserByEmailStmt,
+		getUserByPhoneStmt:                          q.getUserByPhoneStmt,
+		getVoiceMessageStmt:                         q.getVoiceMessageStmt,
+		getVoiceMessageForOccurrenceStmt:            q.getVoiceMessageForOccurrenceStmt,
+		hasDispenseMovementsStmt:                    q.hasDispenseMovementsStmt,
+		listAudioMessageEncodingsStmt:               q.listAudioMessageEncodingsStmt,
+		listAvailableLotsFIFOStmt:                   q.listAvailableLotsFIFOStmt,
+		listDispenseEventsByLotStmt:                 q.listDispenseEventsByLotStmt,
+		listDispenseEventsByPatientStmt:             q.listDispenseEventsByPatientStmt,
+		listDueOutboxNotificationsStmt:              q.listDueOutboxNotificationsStmt,
+		listExpiringLotsByPatientStmt:               q.listExpiringLotsByPatientStmt,
+		listLiveAudioMessagePathsStmt:               q.listLiveAudioMessagePathsStmt,
+		listLotConsumptionsByDispenseEventStmt:      q.listLotConsumptionsByDispenseEventStmt,
+		listMedicationIngredientsByPatientStmt:      q.listMedicationIngredientsByPatientStmt,
+		listMedicationLotsStmt:                      q.listMedicationLotsStmt,
+		listMedicationsByPatientStmt:                q.listMedicationsByPatientStmt,
+		listMedicationsFromSiloStmt:                 q.listMedicationsFromSiloStmt,
+		listNotificationEventsByPatientStmt:         q.listNotificationEventsByPatientStmt,
+		listNotificationEventsPageStmt:              q.listNotificationEventsPageStmt,
+		listNotificationPreferencesByUserStmt:       q.listNotificationPreferencesByUserStmt,
+		listPatientsStmt:                            q.listPatientsStmt,
+		listPatientsByUserStmt:                      q.listPatientsByUserStmt,
+		listPharmaciesStmt:                          q.listPharmaciesStmt,
+		listPrescriptionsByMedicationStmt:           q.listPrescriptionsByMedicationStmt,
+		listPrescriptionsNeedingRenewalStmt:         q.listPrescriptionsNeedingRenewalStmt,
+		listPurgeableAudioMessagesStmt:              q.listPurgeableAudioMessagesStmt,
+		listRefillRequestDeliveriesStmt:             q.listRefillRequestDeliveriesStmt,
+		listRefillRequestsByPatientStmt:             q.listRefillRequestsByPatientStmt,
+		listScheduleItemsByScheduleStmt:             q.listScheduleItemsByScheduleStmt,
+		listSchedulesByPatientStmt:                  q.listSchedulesByPatientStmt,
+		listSentRemindersByUserSinceStmt:            q.listSentRemindersByUserSinceStmt,
+		listSilosByPatientStmt:                      q.listSilosByPatientStmt,
+		listStockMovementsByMedicationStmt:          q.listStockMovementsByMedicationStmt,
+		listStockMovementsByPatientStmt:             q.listStockMovementsByPatientStmt,
+		listTTSCacheEntriesByLastUsedStmt:           q.listTTSCacheEntriesByLastUsedStmt,
+		listUsersStmt:                               q.listUsersStmt,
+		listVoiceMessagesByPatientStmt:              q.listVoiceMessagesByPatientStmt,
+		markAudioMessageAckedStmt:                   q.markAudioMessageAckedStmt,
+		markAudioMessageDeliveredStmt:               q.markAudioMessageDeliveredStmt,
+		markAudioMessagePurgedStmt:                  q.markAudioMessagePurgedStmt,
+		markLotExpiryAlertedStmt:                    q.markLotExpiryAlertedStmt,
+		markLowStockAlertedStmt:                     q.markLowStockAlertedStmt,
+		markOutboxNotificationFailedStmt:            q.markOutboxNotificationFailedStmt,
+		markOutboxNotificationSentStmt:              q.markOutboxNotificationSentStmt,
+		markPrescriptionExpiryAlertedStmt:           q.markPrescriptionExpiryAlertedStmt,
+		markPrescriptionNoRefillsAlertedStmt:        q.markPrescriptionNoRefillsAlertedStmt,
+		markRunoutAlertedStmt:                       q.markRunoutAlertedStmt,
+		markSiloCalibratedStmt:                      q.markSiloCalibratedStmt,
+		searchCatalogStmt:                           q.searchCatalogStmt,
+		setActivePatientStmt:                        q.setActivePatientStmt,
+		setMedicationCatalogEntryStmt:               q.setMedicationCatalogEntryStmt,
+		setMedicationSiloStmt:                       q.setMedicationSiloStmt,
+		sumStockMovementsStmt:                       q.sumStockMovementsStmt,
+		touchTTSCacheEntryStmt:                      q.touchTTSCacheEntryStmt,
+		updateDispenseEventStmt:                     q.updateDispenseEventStmt,
+		updateMedicationStmt:                        q.updateMedicationStmt,
+		updateNotificationDeliveryStatusStmt:        q.updateNotificationDeliveryStatusStmt,
+		updatePatientStmt:                           q.updatePatientStmt,
+		updatePharmacyStmt:                          q.updatePharmacyStmt,
+		updatePrescriptionStmt:                      q.updatePrescriptionStmt,
+		updateRefillRequestStatusStmt:               q.updateRefillRequestStatusStmt,
+		updateScheduleStmt:                          q.updateScheduleStmt,
+		updateSiloStmt:                              q.updateSiloStmt,
+		updateUserStmt:                              q.updateUserStmt,
+		upsertCatalogEntryStmt:                      q.upsertCatalogEntryStmt,
+		upsertNotificationPreferenceStmt:            q.upsertNotificationPreferenceStmt,
+		upsertPatientVoiceSettingsStmt:              q.upsertPatientVoiceSettingsStmt,
+		upsertTTSCacheEntryStmt:                     q.upsertTTSCacheEntryStmt,
+		usePrescriptionRefillStmt:                   q.usePrescriptionRefillStmt,
 	}
 }
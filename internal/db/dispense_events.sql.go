@@ -50,6 +50,32 @@ func (q *Queries) CreateDispenseEvent(ctx context.Context, arg CreateDispenseEve
 	return i, err
 }
 
+const createDispenseIdempotencyKey = `-- name: CreateDispenseIdempotencyKey :exec
+INSERT INTO dispense_idempotency_keys (patient_id, idempotency_key, dispense_event_id, schedule_id, due_at_iso, status)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateDispenseIdempotencyKeyParams struct {
+	PatientID       string `json:"patient_id"`
+	IdempotencyKey  string `json:"idempotency_key"`
+	DispenseEventID string `json:"dispense_event_id"`
+	ScheduleID      string `json:"schedule_id"`
+	DueAtIso        string `json:"due_at_iso"`
+	Status          string `json:"status"`
+}
+
+func (q *Queries) CreateDispenseIdempotencyKey(ctx context.Context, arg CreateDispenseIdempotencyKeyParams) error {
+	_, err := q.exec(ctx, q.createDispenseIdempotencyKeyStmt, createDispenseIdempotencyKey,
+		arg.PatientID,
+		arg.IdempotencyKey,
+		arg.DispenseEventID,
+		arg.ScheduleID,
+		arg.DueAtIso,
+		arg.Status,
+	)
+	return err
+}
+
 const getDispenseEvent = `-- name: GetDispenseEvent :one
 SELECT id, patient_id, schedule_id, due_at_iso, acted_at_iso, status, action_source, created_at
 FROM dispense_events
@@ -72,6 +98,64 @@ func (q *Queries) GetDispenseEvent(ctx context.Context, id string) (DispenseEven
 	return i, err
 }
 
+const getDispenseEventByOccurrence = `-- name: GetDispenseEventByOccurrence :one
+SELECT id, patient_id, schedule_id, due_at_iso, acted_at_iso, status, action_source, created_at
+FROM dispense_events
+WHERE patient_id = ?
+  AND schedule_id = ?
+  AND due_at_iso = ?
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetDispenseEventByOccurrenceParams struct {
+	PatientID  string `json:"patient_id"`
+	ScheduleID string `json:"schedule_id"`
+	DueAtIso   string `json:"due_at_iso"`
+}
+
+func (q *Queries) GetDispenseEventByOccurrence(ctx context.Context, arg GetDispenseEventByOccurrenceParams) (DispenseEvent, error) {
+	row := q.queryRow(ctx, q.getDispenseEventByOccurrenceStmt, getDispenseEventByOccurrence, arg.PatientID, arg.ScheduleID, arg.DueAtIso)
+	var i DispenseEvent
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.ActedAtIso,
+		&i.Status,
+		&i.ActionSource,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDispenseIdempotencyKey = `-- name: GetDispenseIdempotencyKey :one
+SELECT patient_id, idempotency_key, dispense_event_id, created_at, schedule_id, due_at_iso, status FROM dispense_idempotency_keys
+WHERE patient_id = ?
+  AND idempotency_key = ?
+`
+
+type GetDispenseIdempotencyKeyParams struct {
+	PatientID      string `json:"patient_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+func (q *Queries) GetDispenseIdempotencyKey(ctx context.Context, arg GetDispenseIdempotencyKeyParams) (DispenseIdempotencyKey, error) {
+	row := q.queryRow(ctx, q.getDispenseIdempotencyKeyStmt, getDispenseIdempotencyKey, arg.PatientID, arg.IdempotencyKey)
+	var i DispenseIdempotencyKey
+	err := row.Scan(
+		&i.PatientID,
+		&i.IdempotencyKey,
+		&i.DispenseEventID,
+		&i.CreatedAt,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.Status,
+	)
+	return i, err
+}
+
 const listDispenseEventsByPatient = `-- name: ListDispenseEventsByPatient :many
 SELECT id, patient_id, schedule_id, due_at_iso, acted_at_iso, status, action_source, created_at
 FROM dispense_events
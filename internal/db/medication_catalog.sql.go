@@ -0,0 +1,161 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: medication_catalog.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getCatalogEntry = `-- name: GetCatalogEntry :one
+SELECT id, name, ingredient, strength, unit, dosage_form, updated_at FROM medication_catalog
+WHERE id = ?
+`
+
+func (q *Queries) GetCatalogEntry(ctx context.Context, id string) (MedicationCatalog, error) {
+	row := q.queryRow(ctx, q.getCatalogEntryStmt, getCatalogEntry, id)
+	var i MedicationCatalog
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Ingredient,
+		&i.Strength,
+		&i.Unit,
+		&i.DosageForm,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listMedicationIngredientsByPatient = `-- name: ListMedicationIngredientsByPatient :many
+SELECT m.id, m.patient_id, m.label, m.color, m.stock_count, m.low_stock_threshold, m.cartridge_index, m.max_daily_dose, m.created_at, m.updated_at, m.low_stock_alerted_at, m.runout_alerted_at, m.catalog_id, mc.ingredient
+FROM medications m
+JOIN medication_catalog mc ON mc.id = m.catalog_id
+WHERE m.patient_id = ?
+ORDER BY m.cartridge_index
+`
+
+type ListMedicationIngredientsByPatientRow struct {
+	Medication Medication `json:"medication"`
+	Ingredient string     `json:"ingredient"`
+}
+
+func (q *Queries) ListMedicationIngredientsByPatient(ctx context.Context, patientID string) ([]ListMedicationIngredientsByPatientRow, error) {
+	rows, err := q.query(ctx, q.listMedicationIngredientsByPatientStmt, listMedicationIngredientsByPatient, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMedicationIngredientsByPatientRow{}
+	for rows.Next() {
+		var i ListMedicationIngredientsByPatientRow
+		if err := rows.Scan(
+			&i.Medication.ID,
+			&i.Medication.PatientID,
+			&i.Medication.Label,
+			&i.Medication.Color,
+			&i.Medication.StockCount,
+			&i.Medication.LowStockThreshold,
+			&i.Medication.CartridgeIndex,
+			&i.Medication.MaxDailyDose,
+			&i.Medication.CreatedAt,
+			&i.Medication.UpdatedAt,
+			&i.Medication.LowStockAlertedAt,
+			&i.Medication.RunoutAlertedAt,
+			&i.Medication.CatalogID,
+			&i.Ingredient,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchCatalog = `-- name: SearchCatalog :many
+SELECT id, name, ingredient, strength, unit, dosage_form, updated_at FROM medication_catalog
+WHERE instr(lower(name), lower(?1)) > 0
+   OR instr(lower(ingredient), lower(?1)) > 0
+ORDER BY name COLLATE NOCASE
+LIMIT ?2
+`
+
+type SearchCatalogParams struct {
+	Query string `json:"query"`
+	Limit int64  `json:"limit"`
+}
+
+func (q *Queries) SearchCatalog(ctx context.Context, arg SearchCatalogParams) ([]MedicationCatalog, error) {
+	rows, err := q.query(ctx, q.searchCatalogStmt, searchCatalog, arg.Query, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MedicationCatalog{}
+	for rows.Next() {
+		var i MedicationCatalog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Ingredient,
+			&i.Strength,
+			&i.Unit,
+			&i.DosageForm,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCatalogEntry = `-- name: UpsertCatalogEntry :exec
+INSERT INTO medication_catalog (id, name, ingredient, strength, unit, dosage_form, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+  name = excluded.name,
+  ingredient = excluded.ingredient,
+  strength = excluded.strength,
+  unit = excluded.unit,
+  dosage_form = excluded.dosage_form,
+  updated_at = excluded.updated_at
+`
+
+type UpsertCatalogEntryParams struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Ingredient string         `json:"ingredient"`
+	Strength   sql.NullString `json:"strength"`
+	Unit       sql.NullString `json:"unit"`
+	DosageForm sql.NullString `json:"dosage_form"`
+	UpdatedAt  string         `json:"updated_at"`
+}
+
+func (q *Queries) UpsertCatalogEntry(ctx context.Context, arg UpsertCatalogEntryParams) error {
+	_, err := q.exec(ctx, q.upsertCatalogEntryStmt, upsertCatalogEntry,
+		arg.ID,
+		arg.Name,
+		arg.Ingredient,
+		arg.Strength,
+		arg.Unit,
+		arg.DosageForm,
+		arg.UpdatedAt,
+	)
+	return err
+}
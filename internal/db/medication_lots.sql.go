@@ -0,0 +1,362 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: medication_lots.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const consumeMedicationLot = `-- name: ConsumeMedicationLot :exec
+UPDATE medication_lots
+SET quantity_remaining = quantity_remaining - ?
+WHERE id = ?
+`
+
+type ConsumeMedicationLotParams struct {
+	QuantityRemaining int64  `json:"quantity_remaining"`
+	ID                string `json:"id"`
+}
+
+func (q *Queries) ConsumeMedicationLot(ctx context.Context, arg ConsumeMedicationLotParams) error {
+	_, err := q.exec(ctx, q.consumeMedicationLotStmt, consumeMedicationLot, arg.QuantityRemaining, arg.ID)
+	return err
+}
+
+const createMedicationLot = `-- name: CreateMedicationLot :one
+INSERT INTO medication_lots (
+  id,
+  medication_id,
+  lot_number,
+  quantity_loaded,
+  quantity_remaining,
+  expires_on,
+  silo,
+  loaded_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, medication_id, lot_number, quantity_loaded, quantity_remaining, expires_on, silo, loaded_at, expiry_alerted_at
+`
+
+type CreateMedicationLotParams struct {
+	ID                string         `json:"id"`
+	MedicationID      string         `json:"medication_id"`
+	LotNumber         sql.NullString `json:"lot_number"`
+	QuantityLoaded    int64          `json:"quantity_loaded"`
+	QuantityRemaining int64          `json:"quantity_remaining"`
+	ExpiresOn         sql.NullString `json:"expires_on"`
+	Silo              sql.NullInt64  `json:"silo"`
+	LoadedAt          string         `json:"loaded_at"`
+}
+
+func (q *Queries) CreateMedicationLot(ctx context.Context, arg CreateMedicationLotParams) (MedicationLot, error) {
+	row := q.queryRow(ctx, q.createMedicationLotStmt, createMedicationLot,
+		arg.ID,
+		arg.MedicationID,
+		arg.LotNumber,
+		arg.QuantityLoaded,
+		arg.QuantityRemaining,
+		arg.ExpiresOn,
+		arg.Silo,
+		arg.LoadedAt,
+	)
+	var i MedicationLot
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.LotNumber,
+		&i.QuantityLoaded,
+		&i.QuantityRemaining,
+		&i.ExpiresOn,
+		&i.Silo,
+		&i.LoadedAt,
+		&i.ExpiryAlertedAt,
+	)
+	return i, err
+}
+
+const createStockMovementLot = `-- name: CreateStockMovementLot :exec
+INSERT INTO stock_movement_lots (stock_movement_id, lot_id, quantity)
+VALUES (?, ?, ?)
+`
+
+type CreateStockMovementLotParams struct {
+	StockMovementID string `json:"stock_movement_id"`
+	LotID           string `json:"lot_id"`
+	Quantity        int64  `json:"quantity"`
+}
+
+func (q *Queries) CreateStockMovementLot(ctx context.Context, arg CreateStockMovementLotParams) error {
+	_, err := q.exec(ctx, q.createStockMovementLotStmt, createStockMovementLot, arg.StockMovementID, arg.LotID, arg.Quantity)
+	return err
+}
+
+const getMedicationLot = `-- name: GetMedicationLot :one
+SELECT id, medication_id, lot_number, quantity_loaded, quantity_remaining, expires_on, silo, loaded_at, expiry_alerted_at FROM medication_lots
+WHERE id = ?
+`
+
+func (q *Queries) GetMedicationLot(ctx context.Context, id string) (MedicationLot, error) {
+	row := q.queryRow(ctx, q.getMedicationLotStmt, getMedicationLot, id)
+	var i MedicationLot
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.LotNumber,
+		&i.QuantityLoaded,
+		&i.QuantityRemaining,
+		&i.ExpiresOn,
+		&i.Silo,
+		&i.LoadedAt,
+		&i.ExpiryAlertedAt,
+	)
+	return i, err
+}
+
+const listAvailableLotsFIFO = `-- name: ListAvailableLotsFIFO :many
+SELECT id, medication_id, lot_number, quantity_loaded, quantity_remaining, expires_on, silo, loaded_at, expiry_alerted_at FROM medication_lots
+WHERE medication_id = ?
+  AND quantity_remaining > 0
+ORDER BY loaded_at, rowid
+`
+
+func (q *Queries) ListAvailableLotsFIFO(ctx context.Context, medicationID string) ([]MedicationLot, error) {
+	rows, err := q.query(ctx, q.listAvailableLotsFIFOStmt, listAvailableLotsFIFO, medicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MedicationLot{}
+	for rows.Next() {
+		var i MedicationLot
+		if err := rows.Scan(
+			&i.ID,
+			&i.MedicationID,
+			&i.LotNumber,
+			&i.QuantityLoaded,
+			&i.QuantityRemaining,
+			&i.ExpiresOn,
+			&i.Silo,
+			&i.LoadedAt,
+			&i.ExpiryAlertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDispenseEventsByLot = `-- name: ListDispenseEventsByLot :many
+SELECT DISTINCT de.id, de.patient_id, de.schedule_id, de.due_at_iso, de.acted_at_iso, de.status, de.action_source, de.created_at
+FROM stock_movement_lots sml
+JOIN stock_movements sm ON sm.id = sml.stock_movement_id
+JOIN dispense_events de ON de.id = sm.dispense_event_id
+WHERE sml.lot_id = ?
+ORDER BY de.due_at_iso DESC
+`
+
+func (q *Queries) ListDispenseEventsByLot(ctx context.Context, lotID string) ([]DispenseEvent, error) {
+	rows, err := q.query(ctx, q.listDispenseEventsByLotStmt, listDispenseEventsByLot, lotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DispenseEvent{}
+	for rows.Next() {
+		var i DispenseEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.ScheduleID,
+			&i.DueAtIso,
+			&i.ActedAtIso,
+			&i.Status,
+			&i.ActionSource,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiringLotsByPatient = `-- name: ListExpiringLotsByPatient :many
+SELECT l.id, l.medication_id, l.lot_number, l.quantity_loaded, l.quantity_remaining, l.expires_on, l.silo, l.loaded_at, l.expiry_alerted_at
+FROM medication_lots l
+JOIN medications m ON m.id = l.medication_id
+WHERE m.patient_id = ?1
+  AND l.quantity_remaining > 0
+  AND l.expires_on IS NOT NULL
+  AND l.expires_on <= ?2
+  AND l.expiry_alerted_at IS NULL
+ORDER BY l.expires_on
+`
+
+type ListExpiringLotsByPatientParams struct {
+	PatientID     string         `json:"patient_id"`
+	ExpiresBefore sql.NullString `json:"expires_before"`
+}
+
+func (q *Queries) ListExpiringLotsByPatient(ctx context.Context, arg ListExpiringLotsByPatientParams) ([]MedicationLot, error) {
+	rows, err := q.query(ctx, q.listExpiringLotsByPatientStmt, listExpiringLotsByPatient, arg.PatientID, arg.ExpiresBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MedicationLot{}
+	for rows.Next() {
+		var i MedicationLot
+		if err := rows.Scan(
+			&i.ID,
+			&i.MedicationID,
+			&i.LotNumber,
+			&i.QuantityLoaded,
+			&i.QuantityRemaining,
+			&i.ExpiresOn,
+			&i.Silo,
+			&i.LoadedAt,
+			&i.ExpiryAlertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLotConsumptionsByDispenseEvent = `-- name: ListLotConsumptionsByDispenseEvent :many
+SELECT
+  sml.stock_movement_id,
+  sml.quantity,
+  l.id, l.medication_id, l.lot_number, l.quantity_loaded, l.quantity_remaining, l.expires_on, l.silo, l.loaded_at, l.expiry_alerted_at
+FROM stock_movement_lots sml
+JOIN stock_movements sm ON sm.id = sml.stock_movement_id
+JOIN medication_lots l ON l.id = sml.lot_id
+WHERE sm.dispense_event_id = ?
+ORDER BY sm.created_at, l.loaded_at
+`
+
+type ListLotConsumptionsByDispenseEventRow struct {
+	StockMovementID string        `json:"stock_movement_id"`
+	Quantity        int64         `json:"quantity"`
+	MedicationLot   MedicationLot `json:"medication_lot"`
+}
+
+func (q *Queries) ListLotConsumptionsByDispenseEvent(ctx context.Context, dispenseEventID sql.NullString) ([]ListLotConsumptionsByDispenseEventRow, error) {
+	rows, err := q.query(ctx, q.listLotConsumptionsByDispenseEventStmt, listLotConsumptionsByDispenseEvent, dispenseEventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLotConsumptionsByDispenseEventRow{}
+	for rows.Next() {
+		var i ListLotConsumptionsByDispenseEventRow
+		if err := rows.Scan(
+			&i.StockMovementID,
+			&i.Quantity,
+			&i.MedicationLot.ID,
+			&i.MedicationLot.MedicationID,
+			&i.MedicationLot.LotNumber,
+			&i.MedicationLot.QuantityLoaded,
+			&i.MedicationLot.QuantityRemaining,
+			&i.MedicationLot.ExpiresOn,
+			&i.MedicationLot.Silo,
+			&i.MedicationLot.LoadedAt,
+			&i.MedicationLot.ExpiryAlertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMedicationLots = `-- name: ListMedicationLots :many
+SELECT id, medication_id, lot_number, quantity_loaded, quantity_remaining, expires_on, silo, loaded_at, expiry_alerted_at FROM medication_lots
+WHERE medication_id = ?1
+  AND (CAST(?2 AS BOOLEAN) OR quantity_remaining > 0)
+ORDER BY loaded_at, rowid
+`
+
+type ListMedicationLotsParams struct {
+	MedicationID    string `json:"medication_id"`
+	IncludeDepleted bool   `json:"include_depleted"`
+}
+
+func (q *Queries) ListMedicationLots(ctx context.Context, arg ListMedicationLotsParams) ([]MedicationLot, error) {
+	rows, err := q.query(ctx, q.listMedicationLotsStmt, listMedicationLots, arg.MedicationID, arg.IncludeDepleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MedicationLot{}
+	for rows.Next() {
+		var i MedicationLot
+		if err := rows.Scan(
+			&i.ID,
+			&i.MedicationID,
+			&i.LotNumber,
+			&i.QuantityLoaded,
+			&i.QuantityRemaining,
+			&i.ExpiresOn,
+			&i.Silo,
+			&i.LoadedAt,
+			&i.ExpiryAlertedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markLotExpiryAlerted = `-- name: MarkLotExpiryAlerted :exec
+UPDATE medication_lots
+SET expiry_alerted_at = ?
+WHERE id = ?
+`
+
+type MarkLotExpiryAlertedParams struct {
+	ExpiryAlertedAt sql.NullString `json:"expiry_alerted_at"`
+	ID              string         `json:"id"`
+}
+
+func (q *Queries) MarkLotExpiryAlerted(ctx context.Context, arg MarkLotExpiryAlertedParams) error {
+	_, err := q.exec(ctx, q.markLotExpiryAlertedStmt, markLotExpiryAlerted, arg.ExpiryAlertedAt, arg.ID)
+	return err
+}
@@ -10,10 +10,57 @@ import (
 	"database/sql"
 )
 
+const adjustMedicationStock = `-- name: AdjustMedicationStock :one
+UPDATE medications
+SET
+  stock_count = stock_count + ?,
+  updated_at = datetime('now')
+WHERE id = ?
+RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id
+`
+
+type AdjustMedicationStockParams struct {
+	StockCount int64  `json:"stock_count"`
+	ID         string `json:"id"`
+}
+
+func (q *Queries) AdjustMedicationStock(ctx context.Context, arg AdjustMedicationStockParams) (Medication, error) {
+	row := q.queryRow(ctx, q.adjustMedicationStockStmt, adjustMedicationStock, arg.StockCount, arg.ID)
+	var i Medication
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.Label,
+		&i.Color,
+		&i.StockCount,
+		&i.LowStockThreshold,
+		&i.CartridgeIndex,
+		&i.MaxDailyDose,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
+	)
+	return i, err
+}
+
+const clearStockAlerts = `-- name: ClearStockAlerts :exec
+UPDATE medications
+SET low_stock_alerted_at = NULL,
+    runout_alerted_at = NULL
+WHERE id = ?
+`
+
+func (q *Queries) ClearStockAlerts(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.clearStockAlertsStmt, clearStockAlerts, id)
+	return err
+}
+
 const createMedication = `-- name: CreateMedication :one
 INSERT INTO medications (id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose)
 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at
+RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id
 `
 
 type CreateMedicationParams struct {
@@ -50,6 +97,9 @@ func (q *Queries) CreateMedication(ctx context.Context, arg CreateMedicationPara
 		&i.MaxDailyDose,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
 	)
 	return i, err
 }
@@ -65,7 +115,7 @@ func (q *Queries) DeleteMedication(ctx context.Context, id string) error {
 }
 
 const getMedication = `-- name: GetMedication :one
-SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at FROM medications
+SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id FROM medications
 WHERE id = ?
 `
 
@@ -83,12 +133,46 @@ func (q *Queries) GetMedication(ctx context.Context, id string) (Medication, err
 		&i.MaxDailyDose,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
+	)
+	return i, err
+}
+
+const getMedicationInSilo = `-- name: GetMedicationInSilo :one
+SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id FROM medications
+WHERE patient_id = ? AND cartridge_index = ?
+`
+
+type GetMedicationInSiloParams struct {
+	PatientID      string        `json:"patient_id"`
+	CartridgeIndex sql.NullInt64 `json:"cartridge_index"`
+}
+
+func (q *Queries) GetMedicationInSilo(ctx context.Context, arg GetMedicationInSiloParams) (Medication, error) {
+	row := q.queryRow(ctx, q.getMedicationInSiloStmt, getMedicationInSilo, arg.PatientID, arg.CartridgeIndex)
+	var i Medication
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.Label,
+		&i.Color,
+		&i.StockCount,
+		&i.LowStockThreshold,
+		&i.CartridgeIndex,
+		&i.MaxDailyDose,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
 	)
 	return i, err
 }
 
 const listMedicationsByPatient = `-- name: ListMedicationsByPatient :many
-SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at FROM medications
+SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id FROM medications
 WHERE patient_id = ?
 ORDER BY cartridge_index
 `
@@ -113,6 +197,57 @@ func (q *Queries) ListMedicationsByPatient(ctx context.Context, patientID string
 			&i.MaxDailyDose,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LowStockAlertedAt,
+			&i.RunoutAlertedAt,
+			&i.CatalogID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMedicationsFromSilo = `-- name: ListMedicationsFromSilo :many
+SELECT id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id FROM medications
+WHERE patient_id = ? AND cartridge_index >= ?
+ORDER BY cartridge_index
+`
+
+type ListMedicationsFromSiloParams struct {
+	PatientID      string        `json:"patient_id"`
+	CartridgeIndex sql.NullInt64 `json:"cartridge_index"`
+}
+
+func (q *Queries) ListMedicationsFromSilo(ctx context.Context, arg ListMedicationsFromSiloParams) ([]Medication, error) {
+	rows, err := q.query(ctx, q.listMedicationsFromSiloStmt, listMedicationsFromSilo, arg.PatientID, arg.CartridgeIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Medication{}
+	for rows.Next() {
+		var i Medication
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.Label,
+			&i.Color,
+			&i.StockCount,
+			&i.LowStockThreshold,
+			&i.CartridgeIndex,
+			&i.MaxDailyDose,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LowStockAlertedAt,
+			&i.RunoutAlertedAt,
+			&i.CatalogID,
 		); err != nil {
 			return nil, err
 		}
@@ -127,6 +262,108 @@ func (q *Queries) ListMedicationsByPatient(ctx context.Context, patientID string
 	return items, nil
 }
 
+const markLowStockAlerted = `-- name: MarkLowStockAlerted :exec
+UPDATE medications
+SET low_stock_alerted_at = ?
+WHERE id = ?
+`
+
+type MarkLowStockAlertedParams struct {
+	LowStockAlertedAt sql.NullString `json:"low_stock_alerted_at"`
+	ID                string         `json:"id"`
+}
+
+func (q *Queries) MarkLowStockAlerted(ctx context.Context, arg MarkLowStockAlertedParams) error {
+	_, err := q.exec(ctx, q.markLowStockAlertedStmt, markLowStockAlerted, arg.LowStockAlertedAt, arg.ID)
+	return err
+}
+
+const markRunoutAlerted = `-- name: MarkRunoutAlerted :exec
+UPDATE medications
+SET runout_alerted_at = ?
+WHERE id = ?
+`
+
+type MarkRunoutAlertedParams struct {
+	RunoutAlertedAt sql.NullString `json:"runout_alerted_at"`
+	ID              string         `json:"id"`
+}
+
+func (q *Queries) MarkRunoutAlerted(ctx context.Context, arg MarkRunoutAlertedParams) error {
+	_, err := q.exec(ctx, q.markRunoutAlertedStmt, markRunoutAlerted, arg.RunoutAlertedAt, arg.ID)
+	return err
+}
+
+const setMedicationCatalogEntry = `-- name: SetMedicationCatalogEntry :one
+UPDATE medications
+SET
+  catalog_id = ?,
+  updated_at = datetime('now')
+WHERE id = ?
+RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id
+`
+
+type SetMedicationCatalogEntryParams struct {
+	CatalogID sql.NullString `json:"catalog_id"`
+	ID        string         `json:"id"`
+}
+
+func (q *Queries) SetMedicationCatalogEntry(ctx context.Context, arg SetMedicationCatalogEntryParams) (Medication, error) {
+	row := q.queryRow(ctx, q.setMedicationCatalogEntryStmt, setMedicationCatalogEntry, arg.CatalogID, arg.ID)
+	var i Medication
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.Label,
+		&i.Color,
+		&i.StockCount,
+		&i.LowStockThreshold,
+		&i.CartridgeIndex,
+		&i.MaxDailyDose,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
+	)
+	return i, err
+}
+
+const setMedicationSilo = `-- name: SetMedicationSilo :one
+UPDATE medications
+SET
+  cartridge_index = ?,
+  updated_at = datetime('now')
+WHERE id = ?
+RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id
+`
+
+type SetMedicationSiloParams struct {
+	CartridgeIndex sql.NullInt64 `json:"cartridge_index"`
+	ID             string        `json:"id"`
+}
+
+func (q *Queries) SetMedicationSilo(ctx context.Context, arg SetMedicationSiloParams) (Medication, error) {
+	row := q.queryRow(ctx, q.setMedicationSiloStmt, setMedicationSilo, arg.CartridgeIndex, arg.ID)
+	var i Medication
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.Label,
+		&i.Color,
+		&i.StockCount,
+		&i.LowStockThreshold,
+		&i.CartridgeIndex,
+		&i.MaxDailyDose,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
+	)
+	return i, err
+}
+
 const updateMedication = `-- name: UpdateMedication :one
 UPDATE medications
 SET
@@ -138,7 +375,7 @@ SET
   max_daily_dose = ?,
   updated_at = datetime('now')
 WHERE id = ?
-RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at
+RETURNING id, patient_id, label, color, stock_count, low_stock_threshold, cartridge_index, max_daily_dose, created_at, updated_at, low_stock_alerted_at, runout_alerted_at, catalog_id
 `
 
 type UpdateMedicationParams struct {
@@ -173,6 +410,9 @@ func (q *Queries) UpdateMedication(ctx context.Context, arg UpdateMedicationPara
 		&i.MaxDailyDose,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LowStockAlertedAt,
+		&i.RunoutAlertedAt,
+		&i.CatalogID,
 	)
 	return i, err
 }
@@ -14,6 +14,35 @@ type ActivePatient struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+type AudioMessage struct {
+	ID           string         `json:"id"`
+	PatientID    string         `json:"patient_id"`
+	ScheduleID   string         `json:"schedule_id"`
+	DueAtIso     string         `json:"due_at_iso"`
+	FilePath     string         `json:"file_path"`
+	MimeType     string         `json:"mime_type"`
+	SizeBytes    int64          `json:"size_bytes"`
+	CreatedAt    string         `json:"created_at"`
+	DeliveredAt  sql.NullString `json:"delivered_at"`
+	AckedAt      sql.NullString `json:"acked_at"`
+	ExpiresAt    string         `json:"expires_at"`
+	ExpiredAt    sql.NullString `json:"expired_at"`
+	ExpiryReason sql.NullString `json:"expiry_reason"`
+	PurgedAt     sql.NullString `json:"purged_at"`
+	Sha256       sql.NullString `json:"sha256"`
+	DurationMs   sql.NullInt64  `json:"duration_ms"`
+	SampleRateHz sql.NullInt64  `json:"sample_rate_hz"`
+}
+
+type AudioMessageEncoding struct {
+	MessageID string `json:"message_id"`
+	MimeType  string `json:"mime_type"`
+	FilePath  string `json:"file_path"`
+	SizeBytes int64  `json:"size_bytes"`
+	Sha256    string `json:"sha256"`
+	CreatedAt string `json:"created_at"`
+}
+
 type DispenseEvent struct {
 	ID           string         `json:"id"`
 	PatientID    string         `json:"patient_id"`
@@ -25,6 +54,16 @@ type DispenseEvent struct {
 	CreatedAt    string         `json:"created_at"`
 }
 
+type DispenseIdempotencyKey struct {
+	PatientID       string `json:"patient_id"`
+	IdempotencyKey  string `json:"idempotency_key"`
+	DispenseEventID string `json:"dispense_event_id"`
+	CreatedAt       string `json:"created_at"`
+	ScheduleID      string `json:"schedule_id"`
+	DueAtIso        string `json:"due_at_iso"`
+	Status          string `json:"status"`
+}
+
 type Medication struct {
 	ID                string         `json:"id"`
 	PatientID         string         `json:"patient_id"`
@@ -36,6 +75,31 @@ type Medication struct {
 	MaxDailyDose      int64          `json:"max_daily_dose"`
 	CreatedAt         string         `json:"created_at"`
 	UpdatedAt         string         `json:"updated_at"`
+	LowStockAlertedAt sql.NullString `json:"low_stock_alerted_at"`
+	RunoutAlertedAt   sql.NullString `json:"runout_alerted_at"`
+	CatalogID         sql.NullString `json:"catalog_id"`
+}
+
+type MedicationCatalog struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Ingredient string         `json:"ingredient"`
+	Strength   sql.NullString `json:"strength"`
+	Unit       sql.NullString `json:"unit"`
+	DosageForm sql.NullString `json:"dosage_form"`
+	UpdatedAt  string         `json:"updated_at"`
+}
+
+type MedicationLot struct {
+	ID                string         `json:"id"`
+	MedicationID      string         `json:"medication_id"`
+	LotNumber         sql.NullString `json:"lot_number"`
+	QuantityLoaded    int64          `json:"quantity_loaded"`
+	QuantityRemaining int64          `json:"quantity_remaining"`
+	ExpiresOn         sql.NullString `json:"expires_on"`
+	Silo              sql.NullInt64  `json:"silo"`
+	LoadedAt          string         `json:"loaded_at"`
+	ExpiryAlertedAt   sql.NullString `json:"expiry_alerted_at"`
 }
 
 type NotificationEvent struct {
@@ -51,32 +115,141 @@ type NotificationEvent struct {
 	ProviderMessageID sql.NullString `json:"provider_message_id"`
 	ErrorMessage      sql.NullString `json:"error_message"`
 	CreatedAt         string         `json:"created_at"`
+	DeliveryStatus    sql.NullString `json:"delivery_status"`
+	ErrorCode         sql.NullString `json:"error_code"`
+	DeliveryUpdatedAt sql.NullString `json:"delivery_updated_at"`
+}
+
+type NotificationOutbox struct {
+	ID                string         `json:"id"`
+	UserID            string         `json:"user_id"`
+	PatientID         string         `json:"patient_id"`
+	NotificationType  string         `json:"notification_type"`
+	Channel           string         `json:"channel"`
+	Destination       string         `json:"destination"`
+	Message           string         `json:"message"`
+	Digest            int64          `json:"digest"`
+	DeliverAfter      string         `json:"deliver_after"`
+	Status            string         `json:"status"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	ErrorMessage      sql.NullString `json:"error_message"`
+	SentAt            sql.NullString `json:"sent_at"`
+	CreatedAt         string         `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	ID               string         `json:"id"`
+	UserID           string         `json:"user_id"`
+	NotificationType string         `json:"notification_type"`
+	Channel          string         `json:"channel"`
+	Enabled          int64          `json:"enabled"`
+	QuietHoursStart  sql.NullString `json:"quiet_hours_start"`
+	QuietHoursEnd    sql.NullString `json:"quiet_hours_end"`
+	DeliveryMode     string         `json:"delivery_mode"`
+	DigestTime       string         `json:"digest_time"`
+	CreatedAt        string         `json:"created_at"`
+	UpdatedAt        string         `json:"updated_at"`
 }
 
 type Patient struct {
-	ID        string         `json:"id"`
-	UserID    sql.NullString `json:"user_id"`
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	Timezone  string         `json:"timezone"`
-	CreatedAt string         `json:"created_at"`
-	UpdatedAt string         `json:"updated_at"`
+	ID                   string         `json:"id"`
+	UserID               sql.NullString `json:"user_id"`
+	FirstName            string         `json:"first_name"`
+	LastName             string         `json:"last_name"`
+	Timezone             string         `json:"timezone"`
+	CreatedAt            string         `json:"created_at"`
+	UpdatedAt            string         `json:"updated_at"`
+	Locale               string         `json:"locale"`
+	PharmacyLeadTimeDays int64          `json:"pharmacy_lead_time_days"`
+	SiloCount            int64          `json:"silo_count"`
+	ShowMedicationNames  int64          `json:"show_medication_names"`
+	AutoRefillRequests   int64          `json:"auto_refill_requests"`
 }
 
-type Schedule struct {
+type PatientVoiceSetting struct {
+	PatientID    string         `json:"patient_id"`
+	VoiceName    sql.NullString `json:"voice_name"`
+	LanguageCode sql.NullString `json:"language_code"`
+	SpeakingRate float64        `json:"speaking_rate"`
+	SampleRateHz int64          `json:"sample_rate_hz"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+type Pharmacy struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Phone      sql.NullString `json:"phone"`
+	Email      sql.NullString `json:"email"`
+	FaxEmail   sql.NullString `json:"fax_email"`
+	WebhookUrl sql.NullString `json:"webhook_url"`
+	CreatedAt  string         `json:"created_at"`
+	UpdatedAt  string         `json:"updated_at"`
+}
+
+type Prescription struct {
+	ID                 string         `json:"id"`
+	MedicationID       string         `json:"medication_id"`
+	PrescriberName     string         `json:"prescriber_name"`
+	PrescriberPhone    sql.NullString `json:"prescriber_phone"`
+	PharmacyName       sql.NullString `json:"pharmacy_name"`
+	PharmacyPhone      sql.NullString `json:"pharmacy_phone"`
+	Sig                sql.NullString `json:"sig"`
+	QuantityPerFill    int64          `json:"quantity_per_fill"`
+	RefillsRemaining   int64          `json:"refills_remaining"`
+	WrittenOn          string         `json:"written_on"`
+	ExpiresOn          sql.NullString `json:"expires_on"`
+	Active             int64          `json:"active"`
+	ExpiryAlertedAt    sql.NullString `json:"expiry_alerted_at"`
+	NoRefillsAlertedAt sql.NullString `json:"no_refills_alerted_at"`
+	CreatedAt          string         `json:"created_at"`
+	UpdatedAt          string         `json:"updated_at"`
+	RxNumber           sql.NullString `json:"rx_number"`
+	PharmacyID         sql.NullString `json:"pharmacy_id"`
+}
+
+type RefillRequest struct {
 	ID             string         `json:"id"`
 	PatientID      string         `json:"patient_id"`
-	Title          string         `json:"title"`
-	Timezone       string         `json:"timezone"`
-	Rrule          string         `json:"rrule"`
-	StartDateIso   string         `json:"start_date_iso"`
-	EndDateIso     sql.NullString `json:"end_date_iso"`
-	LockoutMinutes int64          `json:"lockout_minutes"`
+	MedicationID   string         `json:"medication_id"`
+	PrescriptionID sql.NullString `json:"prescription_id"`
+	PharmacyID     sql.NullString `json:"pharmacy_id"`
+	RxNumber       sql.NullString `json:"rx_number"`
+	Quantity       int64          `json:"quantity"`
 	Status         string         `json:"status"`
-	CreatedAt      string         `json:"created_at"`
+	Source         string         `json:"source"`
+	RequestedAt    string         `json:"requested_at"`
+	ReadyAt        sql.NullString `json:"ready_at"`
+	PickedUpAt     sql.NullString `json:"picked_up_at"`
+	CancelledAt    sql.NullString `json:"cancelled_at"`
 	UpdatedAt      string         `json:"updated_at"`
 }
 
+type RefillRequestDelivery struct {
+	ID              string         `json:"id"`
+	RefillRequestID string         `json:"refill_request_id"`
+	Channel         string         `json:"channel"`
+	Destination     string         `json:"destination"`
+	Status          string         `json:"status"`
+	Error           sql.NullString `json:"error"`
+	AttemptedAt     string         `json:"attempted_at"`
+}
+
+type Schedule struct {
+	ID              string         `json:"id"`
+	PatientID       string         `json:"patient_id"`
+	Title           string         `json:"title"`
+	Timezone        string         `json:"timezone"`
+	Rrule           string         `json:"rrule"`
+	StartDateIso    string         `json:"start_date_iso"`
+	EndDateIso      sql.NullString `json:"end_date_iso"`
+	LockoutMinutes  int64          `json:"lockout_minutes"`
+	Status          string         `json:"status"`
+	CreatedAt       string         `json:"created_at"`
+	UpdatedAt       string         `json:"updated_at"`
+	StatusChangedAt sql.NullString `json:"status_changed_at"`
+}
+
 type ScheduleItem struct {
 	ID           string `json:"id"`
 	ScheduleID   string `json:"schedule_id"`
@@ -84,6 +257,45 @@ type ScheduleItem struct {
 	Qty          int64  `json:"qty"`
 }
 
+type Silo struct {
+	ID           string          `json:"id"`
+	PatientID    string          `json:"patient_id"`
+	SiloIndex    int64           `json:"silo_index"`
+	Capacity     int64           `json:"capacity"`
+	PillSizeMm   sql.NullFloat64 `json:"pill_size_mm"`
+	CalibratedAt sql.NullString  `json:"calibrated_at"`
+	UpdatedAt    string          `json:"updated_at"`
+}
+
+type StockMovement struct {
+	ID              string         `json:"id"`
+	MedicationID    string         `json:"medication_id"`
+	Kind            string         `json:"kind"`
+	Quantity        int64          `json:"quantity"`
+	BalanceAfter    int64          `json:"balance_after"`
+	Actor           sql.NullString `json:"actor"`
+	DispenseEventID sql.NullString `json:"dispense_event_id"`
+	Note            sql.NullString `json:"note"`
+	CreatedAt       string         `json:"created_at"`
+	LotNumber       sql.NullString `json:"lot_number"`
+	ExpiresOn       sql.NullString `json:"expires_on"`
+}
+
+type StockMovementLot struct {
+	StockMovementID string `json:"stock_movement_id"`
+	LotID           string `json:"lot_id"`
+	Quantity        int64  `json:"quantity"`
+}
+
+type TtsCache struct {
+	CacheKey   string `json:"cache_key"`
+	FilePath   string `json:"file_path"`
+	SizeBytes  int64  `json:"size_bytes"`
+	HitCount   int64  `json:"hit_count"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
 type User struct {
 	ID           string         `json:"id"`
 	Email        string         `json:"email"`
@@ -93,4 +305,21 @@ type User struct {
 	PasswordHash sql.NullString `json:"password_hash"`
 	CreatedAt    string         `json:"created_at"`
 	UpdatedAt    string         `json:"updated_at"`
+	Locale       string         `json:"locale"`
+}
+
+type VoiceMessage struct {
+	ID               string         `json:"id"`
+	PatientID        string         `json:"patient_id"`
+	ScheduleID       string         `json:"schedule_id"`
+	DueAtIso         sql.NullString `json:"due_at_iso"`
+	FilePath         string         `json:"file_path"`
+	OriginalMimeType string         `json:"original_mime_type"`
+	SizeBytes        int64          `json:"size_bytes"`
+	Sha256           string         `json:"sha256"`
+	DurationMs       int64          `json:"duration_ms"`
+	SampleRateHz     int64          `json:"sample_rate_hz"`
+	Active           int64          `json:"active"`
+	CreatedAt        string         `json:"created_at"`
+	UpdatedAt        string         `json:"updated_at"`
 }
@@ -10,6 +10,37 @@ import (
 	"database/sql"
 )
 
+const countNotificationEvents = `-- name: CountNotificationEvents :one
+SELECT COUNT(*)
+FROM notification_events
+WHERE patient_id = ?1
+  AND (CAST(?2 AS TEXT) IS NULL OR due_at_iso >= ?2)
+  AND (CAST(?3 AS TEXT) IS NULL OR due_at_iso < ?3)
+  AND (CAST(?4 AS TEXT) IS NULL OR channel = ?4)
+  AND (CAST(?5 AS TEXT) IS NULL OR status = ?5)
+`
+
+type CountNotificationEventsParams struct {
+	PatientID string         `json:"patient_id"`
+	Start     sql.NullString `json:"start"`
+	End       sql.NullString `json:"end"`
+	Channel   sql.NullString `json:"channel"`
+	Status    sql.NullString `json:"status"`
+}
+
+func (q *Queries) CountNotificationEvents(ctx context.Context, arg CountNotificationEventsParams) (int64, error) {
+	row := q.queryRow(ctx, q.countNotificationEventsStmt, countNotificationEvents,
+		arg.PatientID,
+		arg.Start,
+		arg.End,
+		arg.Channel,
+		arg.Status,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createNotificationEvent = `-- name: CreateNotificationEvent :one
 INSERT INTO notification_events (
   id,
@@ -22,9 +53,10 @@ INSERT INTO notification_events (
   message,
   status,
   provider_message_id,
-  error_message
+  error_message,
+  delivery_status
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING
   id,
   patient_id,
@@ -37,7 +69,10 @@ RETURNING
   status,
   provider_message_id,
   error_message,
-  created_at
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
 `
 
 type CreateNotificationEventParams struct {
@@ -52,6 +87,7 @@ type CreateNotificationEventParams struct {
 	Status            string         `json:"status"`
 	ProviderMessageID sql.NullString `json:"provider_message_id"`
 	ErrorMessage      sql.NullString `json:"error_message"`
+	DeliveryStatus    sql.NullString `json:"delivery_status"`
 }
 
 func (q *Queries) CreateNotificationEvent(ctx context.Context, arg CreateNotificationEventParams) (NotificationEvent, error) {
@@ -67,6 +103,7 @@ func (q *Queries) CreateNotificationEvent(ctx context.Context, arg CreateNotific
 		arg.Status,
 		arg.ProviderMessageID,
 		arg.ErrorMessage,
+		arg.DeliveryStatus,
 	)
 	var i NotificationEvent
 	err := row.Scan(
@@ -82,6 +119,9 @@ func (q *Queries) CreateNotificationEvent(ctx context.Context, arg CreateNotific
 		&i.ProviderMessageID,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.DeliveryStatus,
+		&i.ErrorCode,
+		&i.DeliveryUpdatedAt,
 	)
 	return i, err
 }
@@ -99,7 +139,10 @@ SELECT
   status,
   provider_message_id,
   error_message,
-  created_at
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
 FROM notification_events
 WHERE patient_id = ?
   AND schedule_id = ?
@@ -135,6 +178,54 @@ func (q *Queries) GetNotificationEventByOccurrence(ctx context.Context, arg GetN
 		&i.ProviderMessageID,
 		&i.ErrorMessage,
 		&i.CreatedAt,
+		&i.DeliveryStatus,
+		&i.ErrorCode,
+		&i.DeliveryUpdatedAt,
+	)
+	return i, err
+}
+
+const getNotificationEventByProviderMessageID = `-- name: GetNotificationEventByProviderMessageID :one
+SELECT
+  id,
+  patient_id,
+  schedule_id,
+  user_id,
+  due_at_iso,
+  channel,
+  destination,
+  message,
+  status,
+  provider_message_id,
+  error_message,
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
+FROM notification_events
+WHERE provider_message_id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetNotificationEventByProviderMessageID(ctx context.Context, providerMessageID sql.NullString) (NotificationEvent, error) {
+	row := q.queryRow(ctx, q.getNotificationEventByProviderMessageIDStmt, getNotificationEventByProviderMessageID, providerMessageID)
+	var i NotificationEvent
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.UserID,
+		&i.DueAtIso,
+		&i.Channel,
+		&i.Destination,
+		&i.Message,
+		&i.Status,
+		&i.ProviderMessageID,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.DeliveryStatus,
+		&i.ErrorCode,
+		&i.DeliveryUpdatedAt,
 	)
 	return i, err
 }
@@ -152,7 +243,10 @@ SELECT
   status,
   provider_message_id,
   error_message,
-  created_at
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
 FROM notification_events
 WHERE patient_id = ?
 ORDER BY created_at DESC
@@ -180,6 +274,93 @@ func (q *Queries) ListNotificationEventsByPatient(ctx context.Context, patientID
 			&i.ProviderMessageID,
 			&i.ErrorMessage,
 			&i.CreatedAt,
+			&i.DeliveryStatus,
+			&i.ErrorCode,
+			&i.DeliveryUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationEventsPage = `-- name: ListNotificationEventsPage :many
+SELECT
+  id,
+  patient_id,
+  schedule_id,
+  user_id,
+  due_at_iso,
+  channel,
+  destination,
+  message,
+  status,
+  provider_message_id,
+  error_message,
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
+FROM notification_events
+WHERE patient_id = ?1
+  AND (CAST(?2 AS TEXT) IS NULL OR due_at_iso >= ?2)
+  AND (CAST(?3 AS TEXT) IS NULL OR due_at_iso < ?3)
+  AND (CAST(?4 AS TEXT) IS NULL OR channel = ?4)
+  AND (CAST(?5 AS TEXT) IS NULL OR status = ?5)
+ORDER BY due_at_iso DESC, created_at DESC
+LIMIT ?7 OFFSET ?6
+`
+
+type ListNotificationEventsPageParams struct {
+	PatientID string         `json:"patient_id"`
+	Start     sql.NullString `json:"start"`
+	End       sql.NullString `json:"end"`
+	Channel   sql.NullString `json:"channel"`
+	Status    sql.NullString `json:"status"`
+	Offset    int64          `json:"offset"`
+	Limit     int64          `json:"limit"`
+}
+
+func (q *Queries) ListNotificationEventsPage(ctx context.Context, arg ListNotificationEventsPageParams) ([]NotificationEvent, error) {
+	rows, err := q.query(ctx, q.listNotificationEventsPageStmt, listNotificationEventsPage,
+		arg.PatientID,
+		arg.Start,
+		arg.End,
+		arg.Channel,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationEvent{}
+	for rows.Next() {
+		var i NotificationEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.ScheduleID,
+			&i.UserID,
+			&i.DueAtIso,
+			&i.Channel,
+			&i.Destination,
+			&i.Message,
+			&i.Status,
+			&i.ProviderMessageID,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.DeliveryStatus,
+			&i.ErrorCode,
+			&i.DeliveryUpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -193,3 +374,100 @@ func (q *Queries) ListNotificationEventsByPatient(ctx context.Context, patientID
 	}
 	return items, nil
 }
+
+const listSentRemindersByUserSince = `-- name: ListSentRemindersByUserSince :many
+SELECT
+  id,
+  patient_id,
+  schedule_id,
+  user_id,
+  due_at_iso,
+  channel,
+  destination,
+  message,
+  status,
+  provider_message_id,
+  error_message,
+  created_at,
+  delivery_status,
+  error_code,
+  delivery_updated_at
+FROM notification_events
+WHERE user_id = ?
+  AND channel = 'SMS'
+  AND status = 'SENT'
+  AND due_at_iso >= ?
+ORDER BY due_at_iso DESC
+`
+
+type ListSentRemindersByUserSinceParams struct {
+	UserID   sql.NullString `json:"user_id"`
+	DueAtIso string         `json:"due_at_iso"`
+}
+
+func (q *Queries) ListSentRemindersByUserSince(ctx context.Context, arg ListSentRemindersByUserSinceParams) ([]NotificationEvent, error) {
+	rows, err := q.query(ctx, q.listSentRemindersByUserSinceStmt, listSentRemindersByUserSince, arg.UserID, arg.DueAtIso)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationEvent{}
+	for rows.Next() {
+		var i NotificationEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.ScheduleID,
+			&i.UserID,
+			&i.DueAtIso,
+			&i.Channel,
+			&i.Destination,
+			&i.Message,
+			&i.Status,
+			&i.ProviderMessageID,
+			&i.ErrorMessage,
+			&i.CreatedAt,
+			&i.DeliveryStatus,
+			&i.ErrorCode,
+			&i.DeliveryUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateNotificationDeliveryStatus = `-- name: UpdateNotificationDeliveryStatus :exec
+UPDATE notification_events
+SET delivery_status = ?,
+    error_code = ?,
+    error_message = COALESCE(?, error_message),
+    delivery_updated_at = ?
+WHERE id = ?
+`
+
+type UpdateNotificationDeliveryStatusParams struct {
+	DeliveryStatus    sql.NullString `json:"delivery_status"`
+	ErrorCode         sql.NullString `json:"error_code"`
+	ErrorMessage      sql.NullString `json:"error_message"`
+	DeliveryUpdatedAt sql.NullString `json:"delivery_updated_at"`
+	ID                string         `json:"id"`
+}
+
+func (q *Queries) UpdateNotificationDeliveryStatus(ctx context.Context, arg UpdateNotificationDeliveryStatusParams) error {
+	_, err := q.exec(ctx, q.updateNotificationDeliveryStatusStmt, updateNotificationDeliveryStatus,
+		arg.DeliveryStatus,
+		arg.ErrorCode,
+		arg.ErrorMessage,
+		arg.DeliveryUpdatedAt,
+		arg.ID,
+	)
+	return err
+}
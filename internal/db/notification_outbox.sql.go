@@ -0,0 +1,227 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notification_outbox.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const cancelOutboxNotification = `-- name: CancelOutboxNotification :exec
+UPDATE notification_outbox
+SET status = 'CANCELLED'
+WHERE id = ?
+`
+
+func (q *Queries) CancelOutboxNotification(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.cancelOutboxNotificationStmt, cancelOutboxNotification, id)
+	return err
+}
+
+const cancelQueuedOutboxNotifications = `-- name: CancelQueuedOutboxNotifications :exec
+UPDATE notification_outbox
+SET status = 'CANCELLED'
+WHERE user_id = ?
+  AND patient_id = ?
+  AND notification_type = ?
+  AND status = 'QUEUED'
+`
+
+type CancelQueuedOutboxNotificationsParams struct {
+	UserID           string `json:"user_id"`
+	PatientID        string `json:"patient_id"`
+	NotificationType string `json:"notification_type"`
+}
+
+func (q *Queries) CancelQueuedOutboxNotifications(ctx context.Context, arg CancelQueuedOutboxNotificationsParams) error {
+	_, err := q.exec(ctx, q.cancelQueuedOutboxNotificationsStmt, cancelQueuedOutboxNotifications, arg.UserID, arg.PatientID, arg.NotificationType)
+	return err
+}
+
+const claimOutboxNotification = `-- name: ClaimOutboxNotification :execrows
+UPDATE notification_outbox
+SET status = 'SENDING'
+WHERE id = ?
+  AND status = 'QUEUED'
+`
+
+func (q *Queries) ClaimOutboxNotification(ctx context.Context, id string) (int64, error) {
+	result, err := q.exec(ctx, q.claimOutboxNotificationStmt, claimOutboxNotification, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const enqueueNotification = `-- name: EnqueueNotification :one
+INSERT INTO notification_outbox (
+  id,
+  user_id,
+  patient_id,
+  notification_type,
+  channel,
+  destination,
+  message,
+  digest,
+  deliver_after
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, user_id, patient_id, notification_type, channel, destination, message, digest, deliver_after, status, provider_message_id, error_message, sent_at, created_at
+`
+
+type EnqueueNotificationParams struct {
+	ID               string `json:"id"`
+	UserID           string `json:"user_id"`
+	PatientID        string `json:"patient_id"`
+	NotificationType string `json:"notification_type"`
+	Channel          string `json:"channel"`
+	Destination      string `json:"destination"`
+	Message          string `json:"message"`
+	Digest           int64  `json:"digest"`
+	DeliverAfter     string `json:"deliver_after"`
+}
+
+func (q *Queries) EnqueueNotification(ctx context.Context, arg EnqueueNotificationParams) (NotificationOutbox, error) {
+	row := q.queryRow(ctx, q.enqueueNotificationStmt, enqueueNotification,
+		arg.ID,
+		arg.UserID,
+		arg.PatientID,
+		arg.NotificationType,
+		arg.Channel,
+		arg.Destination,
+		arg.Message,
+		arg.Digest,
+		arg.DeliverAfter,
+	)
+	var i NotificationOutbox
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PatientID,
+		&i.NotificationType,
+		&i.Channel,
+		&i.Destination,
+		&i.Message,
+		&i.Digest,
+		&i.DeliverAfter,
+		&i.Status,
+		&i.ProviderMessageID,
+		&i.ErrorMessage,
+		&i.SentAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOutboxNotification = `-- name: GetOutboxNotification :one
+SELECT id, user_id, patient_id, notification_type, channel, destination, message, digest, deliver_after, status, provider_message_id, error_message, sent_at, created_at FROM notification_outbox
+WHERE id = ?
+`
+
+func (q *Queries) GetOutboxNotification(ctx context.Context, id string) (NotificationOutbox, error) {
+	row := q.queryRow(ctx, q.getOutboxNotificationStmt, getOutboxNotification, id)
+	var i NotificationOutbox
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PatientID,
+		&i.NotificationType,
+		&i.Channel,
+		&i.Destination,
+		&i.Message,
+		&i.Digest,
+		&i.DeliverAfter,
+		&i.Status,
+		&i.ProviderMessageID,
+		&i.ErrorMessage,
+		&i.SentAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listDueOutboxNotifications = `-- name: ListDueOutboxNotifications :many
+SELECT id, user_id, patient_id, notification_type, channel, destination, message, digest, deliver_after, status, provider_message_id, error_message, sent_at, created_at FROM notification_outbox
+WHERE status = 'QUEUED'
+  AND deliver_after <= ?
+ORDER BY user_id, channel, destination, created_at
+`
+
+func (q *Queries) ListDueOutboxNotifications(ctx context.Context, deliverAfter string) ([]NotificationOutbox, error) {
+	rows, err := q.query(ctx, q.listDueOutboxNotificationsStmt, listDueOutboxNotifications, deliverAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationOutbox{}
+	for rows.Next() {
+		var i NotificationOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.PatientID,
+			&i.NotificationType,
+			&i.Channel,
+			&i.Destination,
+			&i.Message,
+			&i.Digest,
+			&i.DeliverAfter,
+			&i.Status,
+			&i.ProviderMessageID,
+			&i.ErrorMessage,
+			&i.SentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxNotificationFailed = `-- name: MarkOutboxNotificationFailed :exec
+UPDATE notification_outbox
+SET
+  status = 'FAILED',
+  error_message = ?
+WHERE id = ?
+`
+
+type MarkOutboxNotificationFailedParams struct {
+	ErrorMessage sql.NullString `json:"error_message"`
+	ID           string         `json:"id"`
+}
+
+func (q *Queries) MarkOutboxNotificationFailed(ctx context.Context, arg MarkOutboxNotificationFailedParams) error {
+	_, err := q.exec(ctx, q.markOutboxNotificationFailedStmt, markOutboxNotificationFailed, arg.ErrorMessage, arg.ID)
+	return err
+}
+
+const markOutboxNotificationSent = `-- name: MarkOutboxNotificationSent :exec
+UPDATE notification_outbox
+SET
+  status = 'SENT',
+  provider_message_id = ?,
+  error_message = NULL,
+  sent_at = datetime('now')
+WHERE id = ?
+`
+
+type MarkOutboxNotificationSentParams struct {
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	ID                string         `json:"id"`
+}
+
+func (q *Queries) MarkOutboxNotificationSent(ctx context.Context, arg MarkOutboxNotificationSentParams) error {
+	_, err := q.exec(ctx, q.markOutboxNotificationSentStmt, markOutboxNotificationSent, arg.ProviderMessageID, arg.ID)
+	return err
+}
@@ -0,0 +1,148 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notification_preferences.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getNotificationPreference = `-- name: GetNotificationPreference :one
+SELECT id, user_id, notification_type, channel, enabled, quiet_hours_start, quiet_hours_end, delivery_mode, digest_time, created_at, updated_at FROM notification_preferences
+WHERE user_id = ?
+  AND notification_type = ?
+  AND channel = ?
+`
+
+type GetNotificationPreferenceParams struct {
+	UserID           string `json:"user_id"`
+	NotificationType string `json:"notification_type"`
+	Channel          string `json:"channel"`
+}
+
+func (q *Queries) GetNotificationPreference(ctx context.Context, arg GetNotificationPreferenceParams) (NotificationPreference, error) {
+	row := q.queryRow(ctx, q.getNotificationPreferenceStmt, getNotificationPreference, arg.UserID, arg.NotificationType, arg.Channel)
+	var i NotificationPreference
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.NotificationType,
+		&i.Channel,
+		&i.Enabled,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.DeliveryMode,
+		&i.DigestTime,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listNotificationPreferencesByUser = `-- name: ListNotificationPreferencesByUser :many
+SELECT id, user_id, notification_type, channel, enabled, quiet_hours_start, quiet_hours_end, delivery_mode, digest_time, created_at, updated_at FROM notification_preferences
+WHERE user_id = ?
+ORDER BY notification_type, channel
+`
+
+func (q *Queries) ListNotificationPreferencesByUser(ctx context.Context, userID string) ([]NotificationPreference, error) {
+	rows, err := q.query(ctx, q.listNotificationPreferencesByUserStmt, listNotificationPreferencesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationPreference{}
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.NotificationType,
+			&i.Channel,
+			&i.Enabled,
+			&i.QuietHoursStart,
+			&i.QuietHoursEnd,
+			&i.DeliveryMode,
+			&i.DigestTime,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertNotificationPreference = `-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (
+  id,
+  user_id,
+  notification_type,
+  channel,
+  enabled,
+  quiet_hours_start,
+  quiet_hours_end,
+  delivery_mode,
+  digest_time
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, notification_type, channel) DO UPDATE SET
+  enabled = excluded.enabled,
+  quiet_hours_start = excluded.quiet_hours_start,
+  quiet_hours_end = excluded.quiet_hours_end,
+  delivery_mode = excluded.delivery_mode,
+  digest_time = excluded.digest_time,
+  updated_at = datetime('now')
+RETURNING id, user_id, notification_type, channel, enabled, quiet_hours_start, quiet_hours_end, delivery_mode, digest_time, created_at, updated_at
+`
+
+type UpsertNotificationPreferenceParams struct {
+	ID               string         `json:"id"`
+	UserID           string         `json:"user_id"`
+	NotificationType string         `json:"notification_type"`
+	Channel          string         `json:"channel"`
+	Enabled          int64          `json:"enabled"`
+	QuietHoursStart  sql.NullString `json:"quiet_hours_start"`
+	QuietHoursEnd    sql.NullString `json:"quiet_hours_end"`
+	DeliveryMode     string         `json:"delivery_mode"`
+	DigestTime       string         `json:"digest_time"`
+}
+
+func (q *Queries) UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error) {
+	row := q.queryRow(ctx, q.upsertNotificationPreferenceStmt, upsertNotificationPreference,
+		arg.ID,
+		arg.UserID,
+		arg.NotificationType,
+		arg.Channel,
+		arg.Enabled,
+		arg.QuietHoursStart,
+		arg.QuietHoursEnd,
+		arg.DeliveryMode,
+		arg.DigestTime,
+	)
+	var i NotificationPreference
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.NotificationType,
+		&i.Channel,
+		&i.Enabled,
+		&i.QuietHoursStart,
+		&i.QuietHoursEnd,
+		&i.DeliveryMode,
+		&i.DigestTime,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,78 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: patient_voice_settings.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getPatientVoiceSettings = `-- name: GetPatientVoiceSettings :one
+SELECT patient_id, voice_name, language_code, speaking_rate, sample_rate_hz, created_at, updated_at FROM patient_voice_settings
+WHERE patient_id = ?
+`
+
+func (q *Queries) GetPatientVoiceSettings(ctx context.Context, patientID string) (PatientVoiceSetting, error) {
+	row := q.queryRow(ctx, q.getPatientVoiceSettingsStmt, getPatientVoiceSettings, patientID)
+	var i PatientVoiceSetting
+	err := row.Scan(
+		&i.PatientID,
+		&i.VoiceName,
+		&i.LanguageCode,
+		&i.SpeakingRate,
+		&i.SampleRateHz,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertPatientVoiceSettings = `-- name: UpsertPatientVoiceSettings :one
+INSERT INTO patient_voice_settings (
+  patient_id,
+  voice_name,
+  language_code,
+  speaking_rate,
+  sample_rate_hz
+)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (patient_id) DO UPDATE SET
+  voice_name = excluded.voice_name,
+  language_code = excluded.language_code,
+  speaking_rate = excluded.speaking_rate,
+  sample_rate_hz = excluded.sample_rate_hz,
+  updated_at = datetime('now')
+RETURNING patient_id, voice_name, language_code, speaking_rate, sample_rate_hz, created_at, updated_at
+`
+
+type UpsertPatientVoiceSettingsParams struct {
+	PatientID    string         `json:"patient_id"`
+	VoiceName    sql.NullString `json:"voice_name"`
+	LanguageCode sql.NullString `json:"language_code"`
+	SpeakingRate float64        `json:"speaking_rate"`
+	SampleRateHz int64          `json:"sample_rate_hz"`
+}
+
+func (q *Queries) UpsertPatientVoiceSettings(ctx context.Context, arg UpsertPatientVoiceSettingsParams) (PatientVoiceSetting, error) {
+	row := q.queryRow(ctx, q.upsertPatientVoiceSettingsStmt, upsertPatientVoiceSettings,
+		arg.PatientID,
+		arg.VoiceName,
+		arg.LanguageCode,
+		arg.SpeakingRate,
+		arg.SampleRateHz,
+	)
+	var i PatientVoiceSetting
+	err := row.Scan(
+		&i.PatientID,
+		&i.VoiceName,
+		&i.LanguageCode,
+		&i.SpeakingRate,
+		&i.SampleRateHz,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
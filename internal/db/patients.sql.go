@@ -11,17 +11,22 @@ import (
 )
 
 const createPatient = `-- name: CreatePatient :one
-INSERT INTO patients (id, user_id, first_name, last_name, timezone)
-VALUES (?, ?, ?, ?, ?)
-RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at
+INSERT INTO patients (id, user_id, first_name, last_name, timezone, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 `
 
 type CreatePatientParams struct {
-	ID        string         `json:"id"`
-	UserID    sql.NullString `json:"user_id"`
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	Timezone  string         `json:"timezone"`
+	ID                   string         `json:"id"`
+	UserID               sql.NullString `json:"user_id"`
+	FirstName            string         `json:"first_name"`
+	LastName             string         `json:"last_name"`
+	Timezone             string         `json:"timezone"`
+	Locale               string         `json:"locale"`
+	PharmacyLeadTimeDays int64          `json:"pharmacy_lead_time_days"`
+	SiloCount            int64          `json:"silo_count"`
+	ShowMedicationNames  int64          `json:"show_medication_names"`
+	AutoRefillRequests   int64          `json:"auto_refill_requests"`
 }
 
 func (q *Queries) CreatePatient(ctx context.Context, arg CreatePatientParams) (Patient, error) {
@@ -31,6 +36,11 @@ func (q *Queries) CreatePatient(ctx context.Context, arg CreatePatientParams) (P
 		arg.FirstName,
 		arg.LastName,
 		arg.Timezone,
+		arg.Locale,
+		arg.PharmacyLeadTimeDays,
+		arg.SiloCount,
+		arg.ShowMedicationNames,
+		arg.AutoRefillRequests,
 	)
 	var i Patient
 	err := row.Scan(
@@ -41,12 +51,17 @@ func (q *Queries) CreatePatient(ctx context.Context, arg CreatePatientParams) (P
 		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.PharmacyLeadTimeDays,
+		&i.SiloCount,
+		&i.ShowMedicationNames,
+		&i.AutoRefillRequests,
 	)
 	return i, err
 }
 
 const getPatient = `-- name: GetPatient :one
-SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at
+SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 FROM patients
 WHERE id = ?
 `
@@ -62,12 +77,17 @@ func (q *Queries) GetPatient(ctx context.Context, id string) (Patient, error) {
 		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.PharmacyLeadTimeDays,
+		&i.SiloCount,
+		&i.ShowMedicationNames,
+		&i.AutoRefillRequests,
 	)
 	return i, err
 }
 
 const listPatients = `-- name: ListPatients :many
-SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at
+SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 FROM patients
 ORDER BY created_at DESC
 `
@@ -89,6 +109,11 @@ func (q *Queries) ListPatients(ctx context.Context) ([]Patient, error) {
 			&i.Timezone,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Locale,
+			&i.PharmacyLeadTimeDays,
+			&i.SiloCount,
+			&i.ShowMedicationNames,
+			&i.AutoRefillRequests,
 		); err != nil {
 			return nil, err
 		}
@@ -104,7 +129,7 @@ func (q *Queries) ListPatients(ctx context.Context) ([]Patient, error) {
 }
 
 const listPatientsByUser = `-- name: ListPatientsByUser :many
-SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at
+SELECT id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 FROM patients
 WHERE user_id = ?
 ORDER BY created_at DESC
@@ -127,6 +152,11 @@ func (q *Queries) ListPatientsByUser(ctx context.Context, userID sql.NullString)
 			&i.Timezone,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Locale,
+			&i.PharmacyLeadTimeDays,
+			&i.SiloCount,
+			&i.ShowMedicationNames,
+			&i.AutoRefillRequests,
 		); err != nil {
 			return nil, err
 		}
@@ -148,17 +178,27 @@ SET
   first_name = ?,
   last_name = ?,
   timezone = ?,
+  locale = ?,
+  pharmacy_lead_time_days = ?,
+  silo_count = ?,
+  show_medication_names = ?,
+  auto_refill_requests = ?,
   updated_at = datetime('now')
 WHERE id = ?
-RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at
+RETURNING id, user_id, first_name, last_name, timezone, created_at, updated_at, locale, pharmacy_lead_time_days, silo_count, show_medication_names, auto_refill_requests
 `
 
 type UpdatePatientParams struct {
-	UserID    sql.NullString `json:"user_id"`
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	Timezone  string         `json:"timezone"`
-	ID        string         `json:"id"`
+	UserID               sql.NullString `json:"user_id"`
+	FirstName            string         `json:"first_name"`
+	LastName             string         `json:"last_name"`
+	Timezone             string         `json:"timezone"`
+	Locale               string         `json:"locale"`
+	PharmacyLeadTimeDays int64          `json:"pharmacy_lead_time_days"`
+	SiloCount            int64          `json:"silo_count"`
+	ShowMedicationNames  int64          `json:"show_medication_names"`
+	AutoRefillRequests   int64          `json:"auto_refill_requests"`
+	ID                   string         `json:"id"`
 }
 
 func (q *Queries) UpdatePatient(ctx context.Context, arg UpdatePatientParams) (Patient, error) {
@@ -167,6 +207,11 @@ func (q *Queries) UpdatePatient(ctx context.Context, arg UpdatePatientParams) (P
 		arg.FirstName,
 		arg.LastName,
 		arg.Timezone,
+		arg.Locale,
+		arg.PharmacyLeadTimeDays,
+		arg.SiloCount,
+		arg.ShowMedicationNames,
+		arg.AutoRefillRequests,
 		arg.ID,
 	)
 	var i Patient
@@ -178,6 +223,11 @@ func (q *Queries) UpdatePatient(ctx context.Context, arg UpdatePatientParams) (P
 		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+		&i.PharmacyLeadTimeDays,
+		&i.SiloCount,
+		&i.ShowMedicationNames,
+		&i.AutoRefillRequests,
 	)
 	return i, err
 }
@@ -0,0 +1,168 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: pharmacies.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createPharmacy = `-- name: CreatePharmacy :one
+INSERT INTO pharmacies (id, name, phone, email, fax_email, webhook_url, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, phone, email, fax_email, webhook_url, created_at, updated_at
+`
+
+type CreatePharmacyParams struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Phone      sql.NullString `json:"phone"`
+	Email      sql.NullString `json:"email"`
+	FaxEmail   sql.NullString `json:"fax_email"`
+	WebhookUrl sql.NullString `json:"webhook_url"`
+	CreatedAt  string         `json:"created_at"`
+	UpdatedAt  string         `json:"updated_at"`
+}
+
+func (q *Queries) CreatePharmacy(ctx context.Context, arg CreatePharmacyParams) (Pharmacy, error) {
+	row := q.queryRow(ctx, q.createPharmacyStmt, createPharmacy,
+		arg.ID,
+		arg.Name,
+		arg.Phone,
+		arg.Email,
+		arg.FaxEmail,
+		arg.WebhookUrl,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Pharmacy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Phone,
+		&i.Email,
+		&i.FaxEmail,
+		&i.WebhookUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deletePharmacy = `-- name: DeletePharmacy :exec
+DELETE FROM pharmacies
+WHERE id = ?
+`
+
+func (q *Queries) DeletePharmacy(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.deletePharmacyStmt, deletePharmacy, id)
+	return err
+}
+
+const getPharmacy = `-- name: GetPharmacy :one
+SELECT id, name, phone, email, fax_email, webhook_url, created_at, updated_at FROM pharmacies
+WHERE id = ?
+`
+
+func (q *Queries) GetPharmacy(ctx context.Context, id string) (Pharmacy, error) {
+	row := q.queryRow(ctx, q.getPharmacyStmt, getPharmacy, id)
+	var i Pharmacy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Phone,
+		&i.Email,
+		&i.FaxEmail,
+		&i.WebhookUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listPharmacies = `-- name: ListPharmacies :many
+SELECT id, name, phone, email, fax_email, webhook_url, created_at, updated_at FROM pharmacies
+ORDER BY name COLLATE NOCASE
+`
+
+func (q *Queries) ListPharmacies(ctx context.Context) ([]Pharmacy, error) {
+	rows, err := q.query(ctx, q.listPharmaciesStmt, listPharmacies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Pharmacy{}
+	for rows.Next() {
+		var i Pharmacy
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Phone,
+			&i.Email,
+			&i.FaxEmail,
+			&i.WebhookUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePharmacy = `-- name: UpdatePharmacy :one
+UPDATE pharmacies
+SET
+  name = ?,
+  phone = ?,
+  email = ?,
+  fax_email = ?,
+  webhook_url = ?,
+  updated_at = ?
+WHERE id = ?
+RETURNING id, name, phone, email, fax_email, webhook_url, created_at, updated_at
+`
+
+type UpdatePharmacyParams struct {
+	Name       string         `json:"name"`
+	Phone      sql.NullString `json:"phone"`
+	Email      sql.NullString `json:"email"`
+	FaxEmail   sql.NullString `json:"fax_email"`
+	WebhookUrl sql.NullString `json:"webhook_url"`
+	UpdatedAt  string         `json:"updated_at"`
+	ID         string         `json:"id"`
+}
+
+func (q *Queries) UpdatePharmacy(ctx context.Context, arg UpdatePharmacyParams) (Pharmacy, error) {
+	row := q.queryRow(ctx, q.updatePharmacyStmt, updatePharmacy,
+		arg.Name,
+		arg.Phone,
+		arg.Email,
+		arg.FaxEmail,
+		arg.WebhookUrl,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	var i Pharmacy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Phone,
+		&i.Email,
+		&i.FaxEmail,
+		&i.WebhookUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
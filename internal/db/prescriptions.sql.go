@@ -0,0 +1,444 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: prescriptions.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createPrescription = `-- name: CreatePrescription :one
+INSERT INTO prescriptions (
+  id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone,
+  sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, rx_number, pharmacy_id,
+  created_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone, sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, expiry_alerted_at, no_refills_alerted_at, created_at, updated_at, rx_number, pharmacy_id
+`
+
+type CreatePrescriptionParams struct {
+	ID               string         `json:"id"`
+	MedicationID     string         `json:"medication_id"`
+	PrescriberName   string         `json:"prescriber_name"`
+	PrescriberPhone  sql.NullString `json:"prescriber_phone"`
+	PharmacyName     sql.NullString `json:"pharmacy_name"`
+	PharmacyPhone    sql.NullString `json:"pharmacy_phone"`
+	Sig              sql.NullString `json:"sig"`
+	QuantityPerFill  int64          `json:"quantity_per_fill"`
+	RefillsRemaining int64          `json:"refills_remaining"`
+	WrittenOn        string         `json:"written_on"`
+	ExpiresOn        sql.NullString `json:"expires_on"`
+	Active           int64          `json:"active"`
+	RxNumber         sql.NullString `json:"rx_number"`
+	PharmacyID       sql.NullString `json:"pharmacy_id"`
+	CreatedAt        string         `json:"created_at"`
+	UpdatedAt        string         `json:"updated_at"`
+}
+
+func (q *Queries) CreatePrescription(ctx context.Context, arg CreatePrescriptionParams) (Prescription, error) {
+	row := q.queryRow(ctx, q.createPrescriptionStmt, createPrescription,
+		arg.ID,
+		arg.MedicationID,
+		arg.PrescriberName,
+		arg.PrescriberPhone,
+		arg.PharmacyName,
+		arg.PharmacyPhone,
+		arg.Sig,
+		arg.QuantityPerFill,
+		arg.RefillsRemaining,
+		arg.WrittenOn,
+		arg.ExpiresOn,
+		arg.Active,
+		arg.RxNumber,
+		arg.PharmacyID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Prescription
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.PrescriberName,
+		&i.PrescriberPhone,
+		&i.PharmacyName,
+		&i.PharmacyPhone,
+		&i.Sig,
+		&i.QuantityPerFill,
+		&i.RefillsRemaining,
+		&i.WrittenOn,
+		&i.ExpiresOn,
+		&i.Active,
+		&i.ExpiryAlertedAt,
+		&i.NoRefillsAlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RxNumber,
+		&i.PharmacyID,
+	)
+	return i, err
+}
+
+const deletePrescription = `-- name: DeletePrescription :exec
+DELETE FROM prescriptions
+WHERE id = ?
+`
+
+func (q *Queries) DeletePrescription(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.deletePrescriptionStmt, deletePrescription, id)
+	return err
+}
+
+const getPrescription = `-- name: GetPrescription :one
+SELECT id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone, sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, expiry_alerted_at, no_refills_alerted_at, created_at, updated_at, rx_number, pharmacy_id FROM prescriptions
+WHERE id = ?
+`
+
+func (q *Queries) GetPrescription(ctx context.Context, id string) (Prescription, error) {
+	row := q.queryRow(ctx, q.getPrescriptionStmt, getPrescription, id)
+	var i Prescription
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.PrescriberName,
+		&i.PrescriberPhone,
+		&i.PharmacyName,
+		&i.PharmacyPhone,
+		&i.Sig,
+		&i.QuantityPerFill,
+		&i.RefillsRemaining,
+		&i.WrittenOn,
+		&i.ExpiresOn,
+		&i.Active,
+		&i.ExpiryAlertedAt,
+		&i.NoRefillsAlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RxNumber,
+		&i.PharmacyID,
+	)
+	return i, err
+}
+
+const getRefillablePrescription = `-- name: GetRefillablePrescription :one
+SELECT id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone, sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, expiry_alerted_at, no_refills_alerted_at, created_at, updated_at, rx_number, pharmacy_id FROM prescriptions
+WHERE medication_id = ?1
+  AND active = 1
+  AND refills_remaining > 0
+  AND (expires_on IS NULL OR expires_on >= ?2)
+ORDER BY expires_on IS NULL, expires_on, written_on
+LIMIT 1
+`
+
+type GetRefillablePrescriptionParams struct {
+	MedicationID string         `json:"medication_id"`
+	Today        sql.NullString `json:"today"`
+}
+
+// The active, unexpired prescription with refills left that expires first.
+func (q *Queries) GetRefillablePrescription(ctx context.Context, arg GetRefillablePrescriptionParams) (Prescription, error) {
+	row := q.queryRow(ctx, q.getRefillablePrescriptionStmt, getRefillablePrescription, arg.MedicationID, arg.Today)
+	var i Prescription
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.PrescriberName,
+		&i.PrescriberPhone,
+		&i.PharmacyName,
+		&i.PharmacyPhone,
+		&i.Sig,
+		&i.QuantityPerFill,
+		&i.RefillsRemaining,
+		&i.WrittenOn,
+		&i.ExpiresOn,
+		&i.Active,
+		&i.ExpiryAlertedAt,
+		&i.NoRefillsAlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RxNumber,
+		&i.PharmacyID,
+	)
+	return i, err
+}
+
+const listPrescriptionsByMedication = `-- name: ListPrescriptionsByMedication :many
+SELECT id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone, sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, expiry_alerted_at, no_refills_alerted_at, created_at, updated_at, rx_number, pharmacy_id FROM prescriptions
+WHERE medication_id = ?
+ORDER BY active DESC, written_on DESC
+`
+
+func (q *Queries) ListPrescriptionsByMedication(ctx context.Context, medicationID string) ([]Prescription, error) {
+	rows, err := q.query(ctx, q.listPrescriptionsByMedicationStmt, listPrescriptionsByMedication, medicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Prescription{}
+	for rows.Next() {
+		var i Prescription
+		if err := rows.Scan(
+			&i.ID,
+			&i.MedicationID,
+			&i.PrescriberName,
+			&i.PrescriberPhone,
+			&i.PharmacyName,
+			&i.PharmacyPhone,
+			&i.Sig,
+			&i.QuantityPerFill,
+			&i.RefillsRemaining,
+			&i.WrittenOn,
+			&i.ExpiresOn,
+			&i.Active,
+			&i.ExpiryAlertedAt,
+			&i.NoRefillsAlertedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RxNumber,
+			&i.PharmacyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPrescriptionsNeedingRenewal = `-- name: ListPrescriptionsNeedingRenewal :many
+SELECT p.id, p.medication_id, p.prescriber_name, p.prescriber_phone, p.pharmacy_name, p.pharmacy_phone, p.sig, p.quantity_per_fill, p.refills_remaining, p.written_on, p.expires_on, p.active, p.expiry_alerted_at, p.no_refills_alerted_at, p.created_at, p.updated_at, p.rx_number, p.pharmacy_id, m.label AS medication_label, m.cartridge_index AS medication_cartridge_index
+FROM prescriptions p
+JOIN medications m ON m.id = p.medication_id
+WHERE m.patient_id = ?1
+  AND p.active = 1
+  AND (
+    (p.expiry_alerted_at IS NULL AND p.expires_on IS NOT NULL AND p.expires_on <= ?2)
+    OR (p.no_refills_alerted_at IS NULL AND p.refills_remaining = 0)
+  )
+ORDER BY p.expires_on
+`
+
+type ListPrescriptionsNeedingRenewalParams struct {
+	PatientID     string         `json:"patient_id"`
+	ExpiresBefore sql.NullString `json:"expires_before"`
+}
+
+type ListPrescriptionsNeedingRenewalRow struct {
+	Prescription             Prescription  `json:"prescription"`
+	MedicationLabel          string        `json:"medication_label"`
+	MedicationCartridgeIndex sql.NullInt64 `json:"medication_cartridge_index"`
+}
+
+// Active prescriptions of the patient's medications that expire by the
+// cutoff or have no refills left, and haven't been alerted about yet.
+func (q *Queries) ListPrescriptionsNeedingRenewal(ctx context.Context, arg ListPrescriptionsNeedingRenewalParams) ([]ListPrescriptionsNeedingRenewalRow, error) {
+	rows, err := q.query(ctx, q.listPrescriptionsNeedingRenewalStmt, listPrescriptionsNeedingRenewal, arg.PatientID, arg.ExpiresBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPrescriptionsNeedingRenewalRow{}
+	for rows.Next() {
+		var i ListPrescriptionsNeedingRenewalRow
+		if err := rows.Scan(
+			&i.Prescription.ID,
+			&i.Prescription.MedicationID,
+			&i.Prescription.PrescriberName,
+			&i.Prescription.PrescriberPhone,
+			&i.Prescription.PharmacyName,
+			&i.Prescription.PharmacyPhone,
+			&i.Prescription.Sig,
+			&i.Prescription.QuantityPerFill,
+			&i.Prescription.RefillsRemaining,
+			&i.Prescription.WrittenOn,
+			&i.Prescription.ExpiresOn,
+			&i.Prescription.Active,
+			&i.Prescription.ExpiryAlertedAt,
+			&i.Prescription.NoRefillsAlertedAt,
+			&i.Prescription.CreatedAt,
+			&i.Prescription.UpdatedAt,
+			&i.Prescription.RxNumber,
+			&i.Prescription.PharmacyID,
+			&i.MedicationLabel,
+			&i.MedicationCartridgeIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPrescriptionExpiryAlerted = `-- name: MarkPrescriptionExpiryAlerted :exec
+UPDATE prescriptions
+SET expiry_alerted_at = ?
+WHERE id = ?
+`
+
+type MarkPrescriptionExpiryAlertedParams struct {
+	ExpiryAlertedAt sql.NullString `json:"expiry_alerted_at"`
+	ID              string         `json:"id"`
+}
+
+func (q *Queries) MarkPrescriptionExpiryAlerted(ctx context.Context, arg MarkPrescriptionExpiryAlertedParams) error {
+	_, err := q.exec(ctx, q.markPrescriptionExpiryAlertedStmt, markPrescriptionExpiryAlerted, arg.ExpiryAlertedAt, arg.ID)
+	return err
+}
+
+const markPrescriptionNoRefillsAlerted = `-- name: MarkPrescriptionNoRefillsAlerted :exec
+UPDATE prescriptions
+SET no_refills_alerted_at = ?
+WHERE id = ?
+`
+
+type MarkPrescriptionNoRefillsAlertedParams struct {
+	NoRefillsAlertedAt sql.NullString `json:"no_refills_alerted_at"`
+	ID                 string         `json:"id"`
+}
+
+func (q *Queries) MarkPrescriptionNoRefillsAlerted(ctx context.Context, arg MarkPrescriptionNoRefillsAlertedParams) error {
+	_, err := q.exec(ctx, q.markPrescriptionNoRefillsAlertedStmt, markPrescriptionNoRefillsAlerted, arg.NoRefillsAlertedAt, arg.ID)
+	return err
+}
+
+const updatePrescription = `-- name: UpdatePrescription :one
+UPDATE prescriptions
+SET
+  prescriber_name = ?1,
+  prescriber_phone = ?2,
+  pharmacy_name = ?3,
+  pharmacy_phone = ?4,
+  sig = ?5,
+  quantity_per_fill = ?6,
+  refills_remaining = ?7,
+  written_on = ?8,
+  expires_on = ?9,
+  active = ?10,
+  rx_number = ?11,
+  pharmacy_id = ?12,
+  expiry_alerted_at = CASE
+    WHEN expires_on IS ?9 THEN expiry_alerted_at
+    ELSE NULL
+  END,
+  no_refills_alerted_at = CASE
+    WHEN refills_remaining <= 0 AND ?7 > 0 THEN NULL
+    ELSE no_refills_alerted_at
+  END,
+  updated_at = ?13
+WHERE id = ?14
+RETURNING id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone, sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, expiry_alerted_at, no_refills_alerted_at, created_at, updated_at, rx_number, pharmacy_id
+`
+
+type UpdatePrescriptionParams struct {
+	PrescriberName   string         `json:"prescriber_name"`
+	PrescriberPhone  sql.NullString `json:"prescriber_phone"`
+	PharmacyName     sql.NullString `json:"pharmacy_name"`
+	PharmacyPhone    sql.NullString `json:"pharmacy_phone"`
+	Sig              sql.NullString `json:"sig"`
+	QuantityPerFill  int64          `json:"quantity_per_fill"`
+	RefillsRemaining int64          `json:"refills_remaining"`
+	WrittenOn        string         `json:"written_on"`
+	ExpiresOn        sql.NullString `json:"expires_on"`
+	Active           int64          `json:"active"`
+	RxNumber         sql.NullString `json:"rx_number"`
+	PharmacyID       sql.NullString `json:"pharmacy_id"`
+	UpdatedAt        string         `json:"updated_at"`
+	ID               string         `json:"id"`
+}
+
+// The renewal alerts re-arm only when what they warned about changes: a new
+// expiry date, or refills added to a prescription that had none left.
+func (q *Queries) UpdatePrescription(ctx context.Context, arg UpdatePrescriptionParams) (Prescription, error) {
+	row := q.queryRow(ctx, q.updatePrescriptionStmt, updatePrescription,
+		arg.PrescriberName,
+		arg.PrescriberPhone,
+		arg.PharmacyName,
+		arg.PharmacyPhone,
+		arg.Sig,
+		arg.QuantityPerFill,
+		arg.RefillsRemaining,
+		arg.WrittenOn,
+		arg.ExpiresOn,
+		arg.Active,
+		arg.RxNumber,
+		arg.PharmacyID,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	var i Prescription
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.PrescriberName,
+		&i.PrescriberPhone,
+		&i.PharmacyName,
+		&i.PharmacyPhone,
+		&i.Sig,
+		&i.QuantityPerFill,
+		&i.RefillsRemaining,
+		&i.WrittenOn,
+		&i.ExpiresOn,
+		&i.Active,
+		&i.ExpiryAlertedAt,
+		&i.NoRefillsAlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RxNumber,
+		&i.PharmacyID,
+	)
+	return i, err
+}
+
+const usePrescriptionRefill = `-- name: UsePrescriptionRefill :one
+UPDATE prescriptions
+SET
+  refills_remaining = refills_remaining - 1,
+  updated_at = ?
+WHERE id = ? AND refills_remaining > 0
+RETURNING id, medication_id, prescriber_name, prescriber_phone, pharmacy_name, pharmacy_phone, sig, quantity_per_fill, refills_remaining, written_on, expires_on, active, expiry_alerted_at, no_refills_alerted_at, created_at, updated_at, rx_number, pharmacy_id
+`
+
+type UsePrescriptionRefillParams struct {
+	UpdatedAt string `json:"updated_at"`
+	ID        string `json:"id"`
+}
+
+func (q *Queries) UsePrescriptionRefill(ctx context.Context, arg UsePrescriptionRefillParams) (Prescription, error) {
+	row := q.queryRow(ctx, q.usePrescriptionRefillStmt, usePrescriptionRefill, arg.UpdatedAt, arg.ID)
+	var i Prescription
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.PrescriberName,
+		&i.PrescriberPhone,
+		&i.PharmacyName,
+		&i.PharmacyPhone,
+		&i.Sig,
+		&i.QuantityPerFill,
+		&i.RefillsRemaining,
+		&i.WrittenOn,
+		&i.ExpiresOn,
+		&i.Active,
+		&i.ExpiryAlertedAt,
+		&i.NoRefillsAlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RxNumber,
+		&i.PharmacyID,
+	)
+	return i, err
+}
@@ -10,38 +10,146 @@ import (
 )
 
 type Querier interface {
+	AdjustMedicationStock(ctx context.Context, arg AdjustMedicationStockParams) (Medication, error)
 	ArchiveSchedule(ctx context.Context, id string) (Schedule, error)
+	CancelOutboxNotification(ctx context.Context, id string) error
+	CancelQueuedOutboxNotifications(ctx context.Context, arg CancelQueuedOutboxNotificationsParams) error
+	ClaimOutboxNotification(ctx context.Context, id string) (int64, error)
+	ClearStockAlerts(ctx context.Context, id string) error
+	ConsumeMedicationLot(ctx context.Context, arg ConsumeMedicationLotParams) error
+	CountNotificationEvents(ctx context.Context, arg CountNotificationEventsParams) (int64, error)
+	CreateAudioMessage(ctx context.Context, arg CreateAudioMessageParams) (AudioMessage, error)
+	CreateAudioMessageEncoding(ctx context.Context, arg CreateAudioMessageEncodingParams) error
 	CreateDispenseEvent(ctx context.Context, arg CreateDispenseEventParams) (DispenseEvent, error)
+	CreateDispenseIdempotencyKey(ctx context.Context, arg CreateDispenseIdempotencyKeyParams) error
 	CreateMedication(ctx context.Context, arg CreateMedicationParams) (Medication, error)
+	CreateMedicationLot(ctx context.Context, arg CreateMedicationLotParams) (MedicationLot, error)
 	CreateNotificationEvent(ctx context.Context, arg CreateNotificationEventParams) (NotificationEvent, error)
 	CreatePatient(ctx context.Context, arg CreatePatientParams) (Patient, error)
+	CreatePharmacy(ctx context.Context, arg CreatePharmacyParams) (Pharmacy, error)
+	CreatePrescription(ctx context.Context, arg CreatePrescriptionParams) (Prescription, error)
+	CreateRefillRequest(ctx context.Context, arg CreateRefillRequestParams) (RefillRequest, error)
+	CreateRefillRequestDelivery(ctx context.Context, arg CreateRefillRequestDeliveryParams) (RefillRequestDelivery, error)
 	CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error)
 	CreateScheduleItem(ctx context.Context, arg CreateScheduleItemParams) (ScheduleItem, error)
+	CreateSilo(ctx context.Context, arg CreateSiloParams) error
+	CreateStockMovement(ctx context.Context, arg CreateStockMovementParams) (StockMovement, error)
+	CreateStockMovementLot(ctx context.Context, arg CreateStockMovementLotParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVoiceMessage(ctx context.Context, arg CreateVoiceMessageParams) (VoiceMessage, error)
+	DeactivateVoiceMessage(ctx context.Context, id string) error
 	DeleteMedication(ctx context.Context, id string) error
+	DeletePharmacy(ctx context.Context, id string) error
+	DeletePrescription(ctx context.Context, id string) error
 	DeleteScheduleItemsBySchedule(ctx context.Context, scheduleID string) error
+	DeleteSilosFrom(ctx context.Context, arg DeleteSilosFromParams) error
+	DeleteTTSCacheEntry(ctx context.Context, cacheKey string) error
+	EnqueueNotification(ctx context.Context, arg EnqueueNotificationParams) (NotificationOutbox, error)
+	ExpireResolvedAudioMessages(ctx context.Context, now sql.NullString) (int64, error)
+	ExpireStaleAudioMessages(ctx context.Context, now sql.NullString) (int64, error)
+	ExpireSupersededAudioMessages(ctx context.Context, arg ExpireSupersededAudioMessagesParams) error
 	GetActivePatient(ctx context.Context) (GetActivePatientRow, error)
+	GetAudioMessage(ctx context.Context, arg GetAudioMessageParams) (AudioMessage, error)
+	GetCatalogEntry(ctx context.Context, id string) (MedicationCatalog, error)
 	GetDispenseEvent(ctx context.Context, id string) (DispenseEvent, error)
+	GetDispenseEventByOccurrence(ctx context.Context, arg GetDispenseEventByOccurrenceParams) (DispenseEvent, error)
+	GetDispenseIdempotencyKey(ctx context.Context, arg GetDispenseIdempotencyKeyParams) (DispenseIdempotencyKey, error)
+	GetLatestPendingAudioMessage(ctx context.Context, arg GetLatestPendingAudioMessageParams) (AudioMessage, error)
 	GetMedication(ctx context.Context, id string) (Medication, error)
+	GetMedicationInSilo(ctx context.Context, arg GetMedicationInSiloParams) (Medication, error)
+	GetMedicationLot(ctx context.Context, id string) (MedicationLot, error)
 	GetNotificationEventByOccurrence(ctx context.Context, arg GetNotificationEventByOccurrenceParams) (NotificationEvent, error)
+	GetNotificationEventByProviderMessageID(ctx context.Context, providerMessageID sql.NullString) (NotificationEvent, error)
+	GetNotificationPreference(ctx context.Context, arg GetNotificationPreferenceParams) (NotificationPreference, error)
+	GetOldestPendingAudioMessage(ctx context.Context, arg GetOldestPendingAudioMessageParams) (AudioMessage, error)
+	GetOpenRefillRequest(ctx context.Context, medicationID string) (RefillRequest, error)
+	GetOutboxNotification(ctx context.Context, id string) (NotificationOutbox, error)
 	GetPatient(ctx context.Context, id string) (Patient, error)
+	GetPatientVoiceSettings(ctx context.Context, patientID string) (PatientVoiceSetting, error)
+	GetPharmacy(ctx context.Context, id string) (Pharmacy, error)
+	GetPrescription(ctx context.Context, id string) (Prescription, error)
+	GetRefillRequest(ctx context.Context, id string) (RefillRequest, error)
+	// The active, unexpired prescription with refills left that expires first.
+	GetRefillablePrescription(ctx context.Context, arg GetRefillablePrescriptionParams) (Prescription, error)
 	GetSchedule(ctx context.Context, id string) (Schedule, error)
+	GetSilo(ctx context.Context, arg GetSiloParams) (Silo, error)
+	GetTTSCacheEntry(ctx context.Context, cacheKey string) (TtsCache, error)
+	GetTTSCacheSize(ctx context.Context) (int64, error)
 	GetUser(ctx context.Context, id string) (GetUserRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByPhone(ctx context.Context, phone sql.NullString) (GetUserByPhoneRow, error)
+	GetVoiceMessage(ctx context.Context, id string) (VoiceMessage, error)
+	GetVoiceMessageForOccurrence(ctx context.Context, arg GetVoiceMessageForOccurrenceParams) (VoiceMessage, error)
+	HasDispenseMovements(ctx context.Context, dispenseEventID sql.NullString) (int64, error)
+	ListAudioMessageEncodings(ctx context.Context, messageID string) ([]AudioMessageEncoding, error)
+	ListAvailableLotsFIFO(ctx context.Context, medicationID string) ([]MedicationLot, error)
+	ListDispenseEventsByLot(ctx context.Context, lotID string) ([]DispenseEvent, error)
 	ListDispenseEventsByPatient(ctx context.Context, arg ListDispenseEventsByPatientParams) ([]DispenseEvent, error)
+	ListDueOutboxNotifications(ctx context.Context, deliverAfter string) ([]NotificationOutbox, error)
+	ListExpiringLotsByPatient(ctx context.Context, arg ListExpiringLotsByPatientParams) ([]MedicationLot, error)
+	ListLiveAudioMessagePaths(ctx context.Context) ([]string, error)
+	ListLotConsumptionsByDispenseEvent(ctx context.Context, dispenseEventID sql.NullString) ([]ListLotConsumptionsByDispenseEventRow, error)
+	ListMedicationIngredientsByPatient(ctx context.Context, patientID string) ([]ListMedicationIngredientsByPatientRow, error)
+	ListMedicationLots(ctx context.Context, arg ListMedicationLotsParams) ([]MedicationLot, error)
 	ListMedicationsByPatient(ctx context.Context, patientID string) ([]Medication, error)
+	ListMedicationsFromSilo(ctx context.Context, arg ListMedicationsFromSiloParams) ([]Medication, error)
 	ListNotificationEventsByPatient(ctx context.Context, patientID string) ([]NotificationEvent, error)
+	ListNotificationEventsPage(ctx context.Context, arg ListNotificationEventsPageParams) ([]NotificationEvent, error)
+	ListNotificationPreferencesByUser(ctx context.Context, userID string) ([]NotificationPreference, error)
 	ListPatients(ctx context.Context) ([]Patient, error)
 	ListPatientsByUser(ctx context.Context, userID sql.NullString) ([]Patient, error)
+	ListPharmacies(ctx context.Context) ([]Pharmacy, error)
+	ListPrescriptionsByMedication(ctx context.Context, medicationID string) ([]Prescription, error)
+	// Active prescriptions of the patient's medications that expire by the
+	// cutoff or have no refills left, and haven't been alerted about yet.
+	ListPrescriptionsNeedingRenewal(ctx context.Context, arg ListPrescriptionsNeedingRenewalParams) ([]ListPrescriptionsNeedingRenewalRow, error)
+	ListPurgeableAudioMessages(ctx context.Context) ([]AudioMessage, error)
+	ListRefillRequestDeliveries(ctx context.Context, refillRequestID string) ([]RefillRequestDelivery, error)
+	ListRefillRequestsByPatient(ctx context.Context, arg ListRefillRequestsByPatientParams) ([]RefillRequest, error)
 	ListScheduleItemsBySchedule(ctx context.Context, scheduleID string) ([]ListScheduleItemsByScheduleRow, error)
 	ListSchedulesByPatient(ctx context.Context, patientID string) ([]Schedule, error)
+	ListSentRemindersByUserSince(ctx context.Context, arg ListSentRemindersByUserSinceParams) ([]NotificationEvent, error)
+	ListSilosByPatient(ctx context.Context, patientID string) ([]Silo, error)
+	ListStockMovementsByMedication(ctx context.Context, arg ListStockMovementsByMedicationParams) ([]StockMovement, error)
+	ListStockMovementsByPatient(ctx context.Context, arg ListStockMovementsByPatientParams) ([]ListStockMovementsByPatientRow, error)
+	ListTTSCacheEntriesByLastUsed(ctx context.Context) ([]TtsCache, error)
 	ListUsers(ctx context.Context) ([]ListUsersRow, error)
+	ListVoiceMessagesByPatient(ctx context.Context, patientID string) ([]VoiceMessage, error)
+	MarkAudioMessageAcked(ctx context.Context, arg MarkAudioMessageAckedParams) error
+	MarkAudioMessageDelivered(ctx context.Context, arg MarkAudioMessageDeliveredParams) error
+	MarkAudioMessagePurged(ctx context.Context, arg MarkAudioMessagePurgedParams) error
+	MarkLotExpiryAlerted(ctx context.Context, arg MarkLotExpiryAlertedParams) error
+	MarkLowStockAlerted(ctx context.Context, arg MarkLowStockAlertedParams) error
+	MarkOutboxNotificationFailed(ctx context.Context, arg MarkOutboxNotificationFailedParams) error
+	MarkOutboxNotificationSent(ctx context.Context, arg MarkOutboxNotificationSentParams) error
+	MarkPrescriptionExpiryAlerted(ctx context.Context, arg MarkPrescriptionExpiryAlertedParams) error
+	MarkPrescriptionNoRefillsAlerted(ctx context.Context, arg MarkPrescriptionNoRefillsAlertedParams) error
+	MarkRunoutAlerted(ctx context.Context, arg MarkRunoutAlertedParams) error
+	MarkSiloCalibrated(ctx context.Context, arg MarkSiloCalibratedParams) error
+	SearchCatalog(ctx context.Context, arg SearchCatalogParams) ([]MedicationCatalog, error)
 	SetActivePatient(ctx context.Context, patientID string) error
+	SetMedicationCatalogEntry(ctx context.Context, arg SetMedicationCatalogEntryParams) (Medication, error)
+	SetMedicationSilo(ctx context.Context, arg SetMedicationSiloParams) (Medication, error)
+	SumStockMovements(ctx context.Context, medicationID string) (int64, error)
+	TouchTTSCacheEntry(ctx context.Context, arg TouchTTSCacheEntryParams) error
 	UpdateDispenseEvent(ctx context.Context, arg UpdateDispenseEventParams) (DispenseEvent, error)
 	UpdateMedication(ctx context.Context, arg UpdateMedicationParams) (Medication, error)
+	UpdateNotificationDeliveryStatus(ctx context.Context, arg UpdateNotificationDeliveryStatusParams) error
 	UpdatePatient(ctx context.Context, arg UpdatePatientParams) (Patient, error)
+	UpdatePharmacy(ctx context.Context, arg UpdatePharmacyParams) (Pharmacy, error)
+	// The renewal alerts re-arm only when what they warned about changes: a new
+	// expiry date, or refills added to a prescription that had none left.
+	UpdatePrescription(ctx context.Context, arg UpdatePrescriptionParams) (Prescription, error)
+	// Stamps the time of the status being entered; earlier stamps are kept.
+	UpdateRefillRequestStatus(ctx context.Context, arg UpdateRefillRequestStatusParams) (RefillRequest, error)
 	UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (Schedule, error)
+	UpdateSilo(ctx context.Context, arg UpdateSiloParams) (Silo, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpsertCatalogEntry(ctx context.Context, arg UpsertCatalogEntryParams) error
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error)
+	UpsertPatientVoiceSettings(ctx context.Context, arg UpsertPatientVoiceSettingsParams) (PatientVoiceSetting, error)
+	UpsertTTSCacheEntry(ctx context.Context, arg UpsertTTSCacheEntryParams) error
+	UsePrescriptionRefill(ctx context.Context, arg UsePrescriptionRefillParams) (Prescription, error)
 }
 
 var _ Querier = (*Queries)(nil)
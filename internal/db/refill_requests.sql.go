@@ -0,0 +1,287 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: refill_requests.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createRefillRequest = `-- name: CreateRefillRequest :one
+INSERT INTO refill_requests (
+  id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number,
+  quantity, status, source, requested_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, 'REQUESTED', ?, ?, ?)
+RETURNING id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number, quantity, status, source, requested_at, ready_at, picked_up_at, cancelled_at, updated_at
+`
+
+type CreateRefillRequestParams struct {
+	ID             string         `json:"id"`
+	PatientID      string         `json:"patient_id"`
+	MedicationID   string         `json:"medication_id"`
+	PrescriptionID sql.NullString `json:"prescription_id"`
+	PharmacyID     sql.NullString `json:"pharmacy_id"`
+	RxNumber       sql.NullString `json:"rx_number"`
+	Quantity       int64          `json:"quantity"`
+	Source         string         `json:"source"`
+	RequestedAt    string         `json:"requested_at"`
+	UpdatedAt      string         `json:"updated_at"`
+}
+
+func (q *Queries) CreateRefillRequest(ctx context.Context, arg CreateRefillRequestParams) (RefillRequest, error) {
+	row := q.queryRow(ctx, q.createRefillRequestStmt, createRefillRequest,
+		arg.ID,
+		arg.PatientID,
+		arg.MedicationID,
+		arg.PrescriptionID,
+		arg.PharmacyID,
+		arg.RxNumber,
+		arg.Quantity,
+		arg.Source,
+		arg.RequestedAt,
+		arg.UpdatedAt,
+	)
+	var i RefillRequest
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.MedicationID,
+		&i.PrescriptionID,
+		&i.PharmacyID,
+		&i.RxNumber,
+		&i.Quantity,
+		&i.Status,
+		&i.Source,
+		&i.RequestedAt,
+		&i.ReadyAt,
+		&i.PickedUpAt,
+		&i.CancelledAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRefillRequestDelivery = `-- name: CreateRefillRequestDelivery :one
+INSERT INTO refill_request_deliveries (id, refill_request_id, channel, destination, status, error, attempted_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, refill_request_id, channel, destination, status, error, attempted_at
+`
+
+type CreateRefillRequestDeliveryParams struct {
+	ID              string         `json:"id"`
+	RefillRequestID string         `json:"refill_request_id"`
+	Channel         string         `json:"channel"`
+	Destination     string         `json:"destination"`
+	Status          string         `json:"status"`
+	Error           sql.NullString `json:"error"`
+	AttemptedAt     string         `json:"attempted_at"`
+}
+
+func (q *Queries) CreateRefillRequestDelivery(ctx context.Context, arg CreateRefillRequestDeliveryParams) (RefillRequestDelivery, error) {
+	row := q.queryRow(ctx, q.createRefillRequestDeliveryStmt, createRefillRequestDelivery,
+		arg.ID,
+		arg.RefillRequestID,
+		arg.Channel,
+		arg.Destination,
+		arg.Status,
+		arg.Error,
+		arg.AttemptedAt,
+	)
+	var i RefillRequestDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.RefillRequestID,
+		&i.Channel,
+		&i.Destination,
+		&i.Status,
+		&i.Error,
+		&i.AttemptedAt,
+	)
+	return i, err
+}
+
+const getOpenRefillRequest = `-- name: GetOpenRefillRequest :one
+SELECT id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number, quantity, status, source, requested_at, ready_at, picked_up_at, cancelled_at, updated_at FROM refill_requests
+WHERE medication_id = ? AND status IN ('REQUESTED', 'READY')
+`
+
+func (q *Queries) GetOpenRefillRequest(ctx context.Context, medicationID string) (RefillRequest, error) {
+	row := q.queryRow(ctx, q.getOpenRefillRequestStmt, getOpenRefillRequest, medicationID)
+	var i RefillRequest
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.MedicationID,
+		&i.PrescriptionID,
+		&i.PharmacyID,
+		&i.RxNumber,
+		&i.Quantity,
+		&i.Status,
+		&i.Source,
+		&i.RequestedAt,
+		&i.ReadyAt,
+		&i.PickedUpAt,
+		&i.CancelledAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRefillRequest = `-- name: GetRefillRequest :one
+SELECT id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number, quantity, status, source, requested_at, ready_at, picked_up_at, cancelled_at, updated_at FROM refill_requests
+WHERE id = ?
+`
+
+func (q *Queries) GetRefillRequest(ctx context.Context, id string) (RefillRequest, error) {
+	row := q.queryRow(ctx, q.getRefillRequestStmt, getRefillRequest, id)
+	var i RefillRequest
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.MedicationID,
+		&i.PrescriptionID,
+		&i.PharmacyID,
+		&i.RxNumber,
+		&i.Quantity,
+		&i.Status,
+		&i.Source,
+		&i.RequestedAt,
+		&i.ReadyAt,
+		&i.PickedUpAt,
+		&i.CancelledAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listRefillRequestDeliveries = `-- name: ListRefillRequestDeliveries :many
+SELECT id, refill_request_id, channel, destination, status, error, attempted_at FROM refill_request_deliveries
+WHERE refill_request_id = ?
+ORDER BY attempted_at, channel
+`
+
+func (q *Queries) ListRefillRequestDeliveries(ctx context.Context, refillRequestID string) ([]RefillRequestDelivery, error) {
+	rows, err := q.query(ctx, q.listRefillRequestDeliveriesStmt, listRefillRequestDeliveries, refillRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RefillRequestDelivery{}
+	for rows.Next() {
+		var i RefillRequestDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.RefillRequestID,
+			&i.Channel,
+			&i.Destination,
+			&i.Status,
+			&i.Error,
+			&i.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRefillRequestsByPatient = `-- name: ListRefillRequestsByPatient :many
+SELECT id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number, quantity, status, source, requested_at, ready_at, picked_up_at, cancelled_at, updated_at FROM refill_requests
+WHERE patient_id = ?1
+  AND (CAST(?2 AS TEXT) IS NULL OR status = ?2)
+ORDER BY requested_at DESC
+`
+
+type ListRefillRequestsByPatientParams struct {
+	PatientID string         `json:"patient_id"`
+	Status    sql.NullString `json:"status"`
+}
+
+func (q *Queries) ListRefillRequestsByPatient(ctx context.Context, arg ListRefillRequestsByPatientParams) ([]RefillRequest, error) {
+	rows, err := q.query(ctx, q.listRefillRequestsByPatientStmt, listRefillRequestsByPatient, arg.PatientID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RefillRequest{}
+	for rows.Next() {
+		var i RefillRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.MedicationID,
+			&i.PrescriptionID,
+			&i.PharmacyID,
+			&i.RxNumber,
+			&i.Quantity,
+			&i.Status,
+			&i.Source,
+			&i.RequestedAt,
+			&i.ReadyAt,
+			&i.PickedUpAt,
+			&i.CancelledAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateRefillRequestStatus = `-- name: UpdateRefillRequestStatus :one
+UPDATE refill_requests
+SET
+  status = ?1,
+  ready_at = CASE WHEN ?1 = 'READY' THEN ?2 ELSE ready_at END,
+  picked_up_at = CASE WHEN ?1 = 'PICKED_UP' THEN ?2 ELSE picked_up_at END,
+  cancelled_at = CASE WHEN ?1 = 'CANCELLED' THEN ?2 ELSE cancelled_at END,
+  updated_at = ?2
+WHERE id = ?3
+RETURNING id, patient_id, medication_id, prescription_id, pharmacy_id, rx_number, quantity, status, source, requested_at, ready_at, picked_up_at, cancelled_at, updated_at
+`
+
+type UpdateRefillRequestStatusParams struct {
+	Status    string `json:"status"`
+	UpdatedAt string `json:"updated_at"`
+	ID        string `json:"id"`
+}
+
+// Stamps the time of the status being entered; earlier stamps are kept.
+func (q *Queries) UpdateRefillRequestStatus(ctx context.Context, arg UpdateRefillRequestStatusParams) (RefillRequest, error) {
+	row := q.queryRow(ctx, q.updateRefillRequestStatusStmt, updateRefillRequestStatus, arg.Status, arg.UpdatedAt, arg.ID)
+	var i RefillRequest
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.MedicationID,
+		&i.PrescriptionID,
+		&i.PharmacyID,
+		&i.RxNumber,
+		&i.Quantity,
+		&i.Status,
+		&i.Source,
+		&i.RequestedAt,
+		&i.ReadyAt,
+		&i.PickedUpAt,
+		&i.CancelledAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -13,10 +13,11 @@ import (
 const archiveSchedule = `-- name: ArchiveSchedule :one
 UPDATE schedules
 SET
+  status_changed_at = CASE WHEN status = 'ARCHIVED' THEN status_changed_at ELSE datetime('now') END,
   status = 'ARCHIVED',
   updated_at = datetime('now')
 WHERE id = ?
-RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 `
 
 func (q *Queries) ArchiveSchedule(ctx context.Context, id string) (Schedule, error) {
@@ -34,6 +35,7 @@ func (q *Queries) ArchiveSchedule(ctx context.Context, id string) (Schedule, err
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -41,7 +43,7 @@ func (q *Queries) ArchiveSchedule(ctx context.Context, id string) (Schedule, err
 const createSchedule = `-- name: CreateSchedule :one
 INSERT INTO schedules (id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status)
 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 `
 
 type CreateScheduleParams struct {
@@ -81,6 +83,7 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -126,7 +129,7 @@ func (q *Queries) DeleteScheduleItemsBySchedule(ctx context.Context, scheduleID
 }
 
 const getSchedule = `-- name: GetSchedule :one
-SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 FROM schedules
 WHERE id = ?
 `
@@ -146,6 +149,7 @@ func (q *Queries) GetSchedule(ctx context.Context, id string) (Schedule, error)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -165,9 +169,12 @@ SELECT
   m.cartridge_index AS medication_cartridge_index,
   m.max_daily_dose AS medication_max_daily_dose,
   m.created_at AS medication_created_at,
-  m.updated_at AS medication_updated_at
+  m.updated_at AS medication_updated_at,
+  m.catalog_id AS medication_catalog_id,
+  mc.name AS medication_catalog_name
 FROM schedule_items si
 JOIN medications m ON m.id = si.medication_id
+LEFT JOIN medication_catalog mc ON mc.id = m.catalog_id
 WHERE si.schedule_id = ?
 ORDER BY m.cartridge_index
 `
@@ -187,6 +194,8 @@ type ListScheduleItemsByScheduleRow struct {
 	MedicationMaxDailyDose      int64          `json:"medication_max_daily_dose"`
 	MedicationCreatedAt         string         `json:"medication_created_at"`
 	MedicationUpdatedAt         string         `json:"medication_updated_at"`
+	MedicationCatalogID         sql.NullString `json:"medication_catalog_id"`
+	MedicationCatalogName       sql.NullString `json:"medication_catalog_name"`
 }
 
 func (q *Queries) ListScheduleItemsBySchedule(ctx context.Context, scheduleID string) ([]ListScheduleItemsByScheduleRow, error) {
@@ -213,6 +222,8 @@ func (q *Queries) ListScheduleItemsBySchedule(ctx context.Context, scheduleID st
 			&i.MedicationMaxDailyDose,
 			&i.MedicationCreatedAt,
 			&i.MedicationUpdatedAt,
+			&i.MedicationCatalogID,
+			&i.MedicationCatalogName,
 		); err != nil {
 			return nil, err
 		}
@@ -228,7 +239,7 @@ func (q *Queries) ListScheduleItemsBySchedule(ctx context.Context, scheduleID st
 }
 
 const listSchedulesByPatient = `-- name: ListSchedulesByPatient :many
-SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+SELECT id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 FROM schedules
 WHERE patient_id = ?
 ORDER BY created_at DESC
@@ -255,6 +266,7 @@ func (q *Queries) ListSchedulesByPatient(ctx context.Context, patientID string)
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.StatusChangedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -272,16 +284,17 @@ func (q *Queries) ListSchedulesByPatient(ctx context.Context, patientID string)
 const updateSchedule = `-- name: UpdateSchedule :one
 UPDATE schedules
 SET
-  title = ?,
-  timezone = ?,
-  rrule = ?,
-  start_date_iso = ?,
-  end_date_iso = ?,
-  lockout_minutes = ?,
-  status = ?,
+  title = ?1,
+  timezone = ?2,
+  rrule = ?3,
+  start_date_iso = ?4,
+  end_date_iso = ?5,
+  lockout_minutes = ?6,
+  status_changed_at = CASE WHEN status = ?7 THEN status_changed_at ELSE datetime('now') END,
+  status = ?7,
   updated_at = datetime('now')
-WHERE id = ?
-RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at
+WHERE id = ?8
+RETURNING id, patient_id, title, timezone, rrule, start_date_iso, end_date_iso, lockout_minutes, status, created_at, updated_at, status_changed_at
 `
 
 type UpdateScheduleParams struct {
@@ -319,6 +332,7 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: silos.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createSilo = `-- name: CreateSilo :exec
+INSERT INTO silos (id, patient_id, silo_index, capacity, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (patient_id, silo_index) DO NOTHING
+`
+
+type CreateSiloParams struct {
+	ID        string `json:"id"`
+	PatientID string `json:"patient_id"`
+	SiloIndex int64  `json:"silo_index"`
+	Capacity  int64  `json:"capacity"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (q *Queries) CreateSilo(ctx context.Context, arg CreateSiloParams) error {
+	_, err := q.exec(ctx, q.createSiloStmt, createSilo,
+		arg.ID,
+		arg.PatientID,
+		arg.SiloIndex,
+		arg.Capacity,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const deleteSilosFrom = `-- name: DeleteSilosFrom :exec
+DELETE FROM silos
+WHERE patient_id = ? AND silo_index >= ?
+`
+
+type DeleteSilosFromParams struct {
+	PatientID string `json:"patient_id"`
+	SiloIndex int64  `json:"silo_index"`
+}
+
+func (q *Queries) DeleteSilosFrom(ctx context.Context, arg DeleteSilosFromParams) error {
+	_, err := q.exec(ctx, q.deleteSilosFromStmt, deleteSilosFrom, arg.PatientID, arg.SiloIndex)
+	return err
+}
+
+const getSilo = `-- name: GetSilo :one
+SELECT id, patient_id, silo_index, capacity, pill_size_mm, calibrated_at, updated_at FROM silos
+WHERE patient_id = ? AND silo_index = ?
+`
+
+type GetSiloParams struct {
+	PatientID string `json:"patient_id"`
+	SiloIndex int64  `json:"silo_index"`
+}
+
+func (q *Queries) GetSilo(ctx context.Context, arg GetSiloParams) (Silo, error) {
+	row := q.queryRow(ctx, q.getSiloStmt, getSilo, arg.PatientID, arg.SiloIndex)
+	var i Silo
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.SiloIndex,
+		&i.Capacity,
+		&i.PillSizeMm,
+		&i.CalibratedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listSilosByPatient = `-- name: ListSilosByPatient :many
+SELECT id, patient_id, silo_index, capacity, pill_size_mm, calibrated_at, updated_at FROM silos
+WHERE patient_id = ?
+ORDER BY silo_index
+`
+
+func (q *Queries) ListSilosByPatient(ctx context.Context, patientID string) ([]Silo, error) {
+	rows, err := q.query(ctx, q.listSilosByPatientStmt, listSilosByPatient, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Silo{}
+	for rows.Next() {
+		var i Silo
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.SiloIndex,
+			&i.Capacity,
+			&i.PillSizeMm,
+			&i.CalibratedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markSiloCalibrated = `-- name: MarkSiloCalibrated :exec
+UPDATE silos
+SET calibrated_at = ?
+WHERE patient_id = ? AND silo_index = ?
+`
+
+type MarkSiloCalibratedParams struct {
+	CalibratedAt sql.NullString `json:"calibrated_at"`
+	PatientID    string         `json:"patient_id"`
+	SiloIndex    int64          `json:"silo_index"`
+}
+
+func (q *Queries) MarkSiloCalibrated(ctx context.Context, arg MarkSiloCalibratedParams) error {
+	_, err := q.exec(ctx, q.markSiloCalibratedStmt, markSiloCalibrated, arg.CalibratedAt, arg.PatientID, arg.SiloIndex)
+	return err
+}
+
+const updateSilo = `-- name: UpdateSilo :one
+UPDATE silos
+SET
+  capacity = ?,
+  pill_size_mm = ?,
+  updated_at = ?
+WHERE patient_id = ? AND silo_index = ?
+RETURNING id, patient_id, silo_index, capacity, pill_size_mm, calibrated_at, updated_at
+`
+
+type UpdateSiloParams struct {
+	Capacity   int64           `json:"capacity"`
+	PillSizeMm sql.NullFloat64 `json:"pill_size_mm"`
+	UpdatedAt  string          `json:"updated_at"`
+	PatientID  string          `json:"patient_id"`
+	SiloIndex  int64           `json:"silo_index"`
+}
+
+func (q *Queries) UpdateSilo(ctx context.Context, arg UpdateSiloParams) (Silo, error) {
+	row := q.queryRow(ctx, q.updateSiloStmt, updateSilo,
+		arg.Capacity,
+		arg.PillSizeMm,
+		arg.UpdatedAt,
+		arg.PatientID,
+		arg.SiloIndex,
+	)
+	var i Silo
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.SiloIndex,
+		&i.Capacity,
+		&i.PillSizeMm,
+		&i.CalibratedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,224 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: stock_movements.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createStockMovement = `-- name: CreateStockMovement :one
+INSERT INTO stock_movements (
+  id,
+  medication_id,
+  kind,
+  quantity,
+  balance_after,
+  actor,
+  dispense_event_id,
+  note,
+  created_at,
+  lot_number,
+  expires_on
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, medication_id, kind, quantity, balance_after, actor, dispense_event_id, note, created_at, lot_number, expires_on
+`
+
+type CreateStockMovementParams struct {
+	ID              string         `json:"id"`
+	MedicationID    string         `json:"medication_id"`
+	Kind            string         `json:"kind"`
+	Quantity        int64          `json:"quantity"`
+	BalanceAfter    int64          `json:"balance_after"`
+	Actor           sql.NullString `json:"actor"`
+	DispenseEventID sql.NullString `json:"dispense_event_id"`
+	Note            sql.NullString `json:"note"`
+	CreatedAt       string         `json:"created_at"`
+	LotNumber       sql.NullString `json:"lot_number"`
+	ExpiresOn       sql.NullString `json:"expires_on"`
+}
+
+func (q *Queries) CreateStockMovement(ctx context.Context, arg CreateStockMovementParams) (StockMovement, error) {
+	row := q.queryRow(ctx, q.createStockMovementStmt, createStockMovement,
+		arg.ID,
+		arg.MedicationID,
+		arg.Kind,
+		arg.Quantity,
+		arg.BalanceAfter,
+		arg.Actor,
+		arg.DispenseEventID,
+		arg.Note,
+		arg.CreatedAt,
+		arg.LotNumber,
+		arg.ExpiresOn,
+	)
+	var i StockMovement
+	err := row.Scan(
+		&i.ID,
+		&i.MedicationID,
+		&i.Kind,
+		&i.Quantity,
+		&i.BalanceAfter,
+		&i.Actor,
+		&i.DispenseEventID,
+		&i.Note,
+		&i.CreatedAt,
+		&i.LotNumber,
+		&i.ExpiresOn,
+	)
+	return i, err
+}
+
+const hasDispenseMovements = `-- name: HasDispenseMovements :one
+SELECT EXISTS (
+  SELECT 1 FROM stock_movements
+  WHERE dispense_event_id = ? AND kind = 'DISPENSE'
+) AS dispensed
+`
+
+func (q *Queries) HasDispenseMovements(ctx context.Context, dispenseEventID sql.NullString) (int64, error) {
+	row := q.queryRow(ctx, q.hasDispenseMovementsStmt, hasDispenseMovements, dispenseEventID)
+	var dispensed int64
+	err := row.Scan(&dispensed)
+	return dispensed, err
+}
+
+const listStockMovementsByMedication = `-- name: ListStockMovementsByMedication :many
+SELECT id, medication_id, kind, quantity, balance_after, actor, dispense_event_id, note, created_at, lot_number, expires_on FROM stock_movements
+WHERE medication_id = ?1
+  AND (CAST(?2 AS TEXT) IS NULL OR created_at >= ?2)
+  AND (CAST(?3 AS TEXT) IS NULL OR created_at < ?3)
+ORDER BY created_at DESC, rowid DESC
+LIMIT ?4
+`
+
+type ListStockMovementsByMedicationParams struct {
+	MedicationID string         `json:"medication_id"`
+	Start        sql.NullString `json:"start"`
+	End          sql.NullString `json:"end"`
+	Limit        int64          `json:"limit"`
+}
+
+func (q *Queries) ListStockMovementsByMedication(ctx context.Context, arg ListStockMovementsByMedicationParams) ([]StockMovement, error) {
+	rows, err := q.query(ctx, q.listStockMovementsByMedicationStmt, listStockMovementsByMedication,
+		arg.MedicationID,
+		arg.Start,
+		arg.End,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []StockMovement{}
+	for rows.Next() {
+		var i StockMovement
+		if err := rows.Scan(
+			&i.ID,
+			&i.MedicationID,
+			&i.Kind,
+			&i.Quantity,
+			&i.BalanceAfter,
+			&i.Actor,
+			&i.DispenseEventID,
+			&i.Note,
+			&i.CreatedAt,
+			&i.LotNumber,
+			&i.ExpiresOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStockMovementsByPatient = `-- name: ListStockMovementsByPatient :many
+SELECT sm.id, sm.medication_id, sm.kind, sm.quantity, sm.balance_after, sm.actor, sm.dispense_event_id, sm.note, sm.created_at, sm.lot_number, sm.expires_on, m.label AS medication_label, mc.name AS medication_catalog_name
+FROM stock_movements sm
+JOIN medications m ON m.id = sm.medication_id
+LEFT JOIN medication_catalog mc ON mc.id = m.catalog_id
+WHERE m.patient_id = ?1
+  AND sm.kind = ?2
+  AND sm.created_at >= ?3
+  AND sm.created_at < ?4
+ORDER BY sm.created_at, sm.rowid
+`
+
+type ListStockMovementsByPatientParams struct {
+	PatientID string `json:"patient_id"`
+	Kind      string `json:"kind"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+}
+
+type ListStockMovementsByPatientRow struct {
+	StockMovement         StockMovement  `json:"stock_movement"`
+	MedicationLabel       string         `json:"medication_label"`
+	MedicationCatalogName sql.NullString `json:"medication_catalog_name"`
+}
+
+func (q *Queries) ListStockMovementsByPatient(ctx context.Context, arg ListStockMovementsByPatientParams) ([]ListStockMovementsByPatientRow, error) {
+	rows, err := q.query(ctx, q.listStockMovementsByPatientStmt, listStockMovementsByPatient,
+		arg.PatientID,
+		arg.Kind,
+		arg.Start,
+		arg.End,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStockMovementsByPatientRow{}
+	for rows.Next() {
+		var i ListStockMovementsByPatientRow
+		if err := rows.Scan(
+			&i.StockMovement.ID,
+			&i.StockMovement.MedicationID,
+			&i.StockMovement.Kind,
+			&i.StockMovement.Quantity,
+			&i.StockMovement.BalanceAfter,
+			&i.StockMovement.Actor,
+			&i.StockMovement.DispenseEventID,
+			&i.StockMovement.Note,
+			&i.StockMovement.CreatedAt,
+			&i.StockMovement.LotNumber,
+			&i.StockMovement.ExpiresOn,
+			&i.MedicationLabel,
+			&i.MedicationCatalogName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumStockMovements = `-- name: SumStockMovements :one
+SELECT CAST(COALESCE(SUM(quantity), 0) AS INTEGER) AS total
+FROM stock_movements
+WHERE medication_id = ?
+`
+
+func (q *Queries) SumStockMovements(ctx context.Context, medicationID string) (int64, error) {
+	row := q.queryRow(ctx, q.sumStockMovementsStmt, sumStockMovements, medicationID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: tts_cache.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteTTSCacheEntry = `-- name: DeleteTTSCacheEntry :exec
+DELETE FROM tts_cache
+WHERE cache_key = ?
+`
+
+func (q *Queries) DeleteTTSCacheEntry(ctx context.Context, cacheKey string) error {
+	_, err := q.exec(ctx, q.deleteTTSCacheEntryStmt, deleteTTSCacheEntry, cacheKey)
+	return err
+}
+
+const getTTSCacheEntry = `-- name: GetTTSCacheEntry :one
+SELECT cache_key, file_path, size_bytes, hit_count, created_at, last_used_at FROM tts_cache
+WHERE cache_key = ?
+`
+
+func (q *Queries) GetTTSCacheEntry(ctx context.Context, cacheKey string) (TtsCache, error) {
+	row := q.queryRow(ctx, q.getTTSCacheEntryStmt, getTTSCacheEntry, cacheKey)
+	var i TtsCache
+	err := row.Scan(
+		&i.CacheKey,
+		&i.FilePath,
+		&i.SizeBytes,
+		&i.HitCount,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getTTSCacheSize = `-- name: GetTTSCacheSize :one
+SELECT CAST(COALESCE(SUM(size_bytes), 0) AS INTEGER) AS total_bytes
+FROM tts_cache
+`
+
+func (q *Queries) GetTTSCacheSize(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.getTTSCacheSizeStmt, getTTSCacheSize)
+	var total_bytes int64
+	err := row.Scan(&total_bytes)
+	return total_bytes, err
+}
+
+const listTTSCacheEntriesByLastUsed = `-- name: ListTTSCacheEntriesByLastUsed :many
+SELECT cache_key, file_path, size_bytes, hit_count, created_at, last_used_at FROM tts_cache
+ORDER BY last_used_at ASC
+`
+
+func (q *Queries) ListTTSCacheEntriesByLastUsed(ctx context.Context) ([]TtsCache, error) {
+	rows, err := q.query(ctx, q.listTTSCacheEntriesByLastUsedStmt, listTTSCacheEntriesByLastUsed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TtsCache{}
+	for rows.Next() {
+		var i TtsCache
+		if err := rows.Scan(
+			&i.CacheKey,
+			&i.FilePath,
+			&i.SizeBytes,
+			&i.HitCount,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchTTSCacheEntry = `-- name: TouchTTSCacheEntry :exec
+UPDATE tts_cache
+SET hit_count = hit_count + 1,
+    last_used_at = ?
+WHERE cache_key = ?
+`
+
+type TouchTTSCacheEntryParams struct {
+	LastUsedAt string `json:"last_used_at"`
+	CacheKey   string `json:"cache_key"`
+}
+
+func (q *Queries) TouchTTSCacheEntry(ctx context.Context, arg TouchTTSCacheEntryParams) error {
+	_, err := q.exec(ctx, q.touchTTSCacheEntryStmt, touchTTSCacheEntry, arg.LastUsedAt, arg.CacheKey)
+	return err
+}
+
+const upsertTTSCacheEntry = `-- name: UpsertTTSCacheEntry :exec
+INSERT INTO tts_cache (cache_key, file_path, size_bytes, last_used_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (cache_key) DO UPDATE SET
+  file_path = excluded.file_path,
+  size_bytes = excluded.size_bytes,
+  last_used_at = excluded.last_used_at
+`
+
+type UpsertTTSCacheEntryParams struct {
+	CacheKey   string `json:"cache_key"`
+	FilePath   string `json:"file_path"`
+	SizeBytes  int64  `json:"size_bytes"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+func (q *Queries) UpsertTTSCacheEntry(ctx context.Context, arg UpsertTTSCacheEntryParams) error {
+	_, err := q.exec(ctx, q.upsertTTSCacheEntryStmt, upsertTTSCacheEntry,
+		arg.CacheKey,
+		arg.FilePath,
+		arg.SizeBytes,
+		arg.LastUsedAt,
+	)
+	return err
+}
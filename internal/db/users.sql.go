@@ -17,9 +17,10 @@ INSERT INTO users (
   full_name,
   phone,
   timezone,
-  password_hash
+  password_hash,
+  locale
 )
-VALUES (?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 RETURNING
   id,
   email,
@@ -28,7 +29,8 @@ RETURNING
   timezone,
   password_hash,
   created_at,
-  updated_at
+  updated_at,
+  locale
 `
 
 type CreateUserParams struct {
@@ -38,6 +40,7 @@ type CreateUserParams struct {
 	Phone        sql.NullString `json:"phone"`
 	Timezone     string         `json:"timezone"`
 	PasswordHash sql.NullString `json:"password_hash"`
+	Locale       string         `json:"locale"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -48,6 +51,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Phone,
 		arg.Timezone,
 		arg.PasswordHash,
+		arg.Locale,
 	)
 	var i User
 	err := row.Scan(
@@ -59,6 +63,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -71,7 +76,8 @@ SELECT
   phone,
   timezone,
   created_at,
-  updated_at
+  updated_at,
+  locale
 FROM users
 WHERE id = ?
 `
@@ -84,6 +90,7 @@ type GetUserRow struct {
 	Timezone  string         `json:"timezone"`
 	CreatedAt string         `json:"created_at"`
 	UpdatedAt string         `json:"updated_at"`
+	Locale    string         `json:"locale"`
 }
 
 func (q *Queries) GetUser(ctx context.Context, id string) (GetUserRow, error) {
@@ -97,6 +104,7 @@ func (q *Queries) GetUser(ctx context.Context, id string) (GetUserRow, error) {
 		&i.Timezone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -110,7 +118,8 @@ SELECT
   timezone,
   password_hash,
   created_at,
-  updated_at
+  updated_at,
+  locale
 FROM users
 WHERE email = ?
 `
@@ -127,6 +136,50 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
+	)
+	return i, err
+}
+
+const getUserByPhone = `-- name: GetUserByPhone :one
+SELECT
+  id,
+  email,
+  full_name,
+  phone,
+  timezone,
+  created_at,
+  updated_at,
+  locale
+FROM users
+WHERE phone = ?
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetUserByPhoneRow struct {
+	ID        string         `json:"id"`
+	Email     string         `json:"email"`
+	FullName  string         `json:"full_name"`
+	Phone     sql.NullString `json:"phone"`
+	Timezone  string         `json:"timezone"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
+	Locale    string         `json:"locale"`
+}
+
+func (q *Queries) GetUserByPhone(ctx context.Context, phone sql.NullString) (GetUserByPhoneRow, error) {
+	row := q.queryRow(ctx, q.getUserByPhoneStmt, getUserByPhone, phone)
+	var i GetUserByPhoneRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.Phone,
+		&i.Timezone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -139,7 +192,8 @@ SELECT
   phone,
   timezone,
   created_at,
-  updated_at
+  updated_at,
+  locale
 FROM users
 ORDER BY created_at DESC
 `
@@ -152,6 +206,7 @@ type ListUsersRow struct {
 	Timezone  string         `json:"timezone"`
 	CreatedAt string         `json:"created_at"`
 	UpdatedAt string         `json:"updated_at"`
+	Locale    string         `json:"locale"`
 }
 
 func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
@@ -171,6 +226,7 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 			&i.Timezone,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Locale,
 		); err != nil {
 			return nil, err
 		}
@@ -193,6 +249,7 @@ SET
   phone = ?,
   timezone = ?,
   password_hash = ?,
+  locale = ?,
   updated_at = datetime('now')
 WHERE id = ?
 RETURNING
@@ -203,7 +260,8 @@ RETURNING
   timezone,
   password_hash,
   created_at,
-  updated_at
+  updated_at,
+  locale
 `
 
 type UpdateUserParams struct {
@@ -212,6 +270,7 @@ type UpdateUserParams struct {
 	Phone        sql.NullString `json:"phone"`
 	Timezone     string         `json:"timezone"`
 	PasswordHash sql.NullString `json:"password_hash"`
+	Locale       string         `json:"locale"`
 	ID           string         `json:"id"`
 }
 
@@ -222,6 +281,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Phone,
 		arg.Timezone,
 		arg.PasswordHash,
+		arg.Locale,
 		arg.ID,
 	)
 	var i User
@@ -234,6 +294,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
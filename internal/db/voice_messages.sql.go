@@ -0,0 +1,192 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: voice_messages.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createVoiceMessage = `-- name: CreateVoiceMessage :one
+INSERT INTO voice_messages (
+  id,
+  patient_id,
+  schedule_id,
+  due_at_iso,
+  file_path,
+  original_mime_type,
+  size_bytes,
+  sha256,
+  duration_ms,
+  sample_rate_hz
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, patient_id, schedule_id, due_at_iso, file_path, original_mime_type, size_bytes, sha256, duration_ms, sample_rate_hz, active, created_at, updated_at
+`
+
+type CreateVoiceMessageParams struct {
+	ID               string         `json:"id"`
+	PatientID        string         `json:"patient_id"`
+	ScheduleID       string         `json:"schedule_id"`
+	DueAtIso         sql.NullString `json:"due_at_iso"`
+	FilePath         string         `json:"file_path"`
+	OriginalMimeType string         `json:"original_mime_type"`
+	SizeBytes        int64          `json:"size_bytes"`
+	Sha256           string         `json:"sha256"`
+	DurationMs       int64          `json:"duration_ms"`
+	SampleRateHz     int64          `json:"sample_rate_hz"`
+}
+
+func (q *Queries) CreateVoiceMessage(ctx context.Context, arg CreateVoiceMessageParams) (VoiceMessage, error) {
+	row := q.queryRow(ctx, q.createVoiceMessageStmt, createVoiceMessage,
+		arg.ID,
+		arg.PatientID,
+		arg.ScheduleID,
+		arg.DueAtIso,
+		arg.FilePath,
+		arg.OriginalMimeType,
+		arg.SizeBytes,
+		arg.Sha256,
+		arg.DurationMs,
+		arg.SampleRateHz,
+	)
+	var i VoiceMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.OriginalMimeType,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deactivateVoiceMessage = `-- name: DeactivateVoiceMessage :exec
+UPDATE voice_messages
+SET active = 0,
+    updated_at = datetime('now')
+WHERE id = ?
+`
+
+func (q *Queries) DeactivateVoiceMessage(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.deactivateVoiceMessageStmt, deactivateVoiceMessage, id)
+	return err
+}
+
+const getVoiceMessage = `-- name: GetVoiceMessage :one
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, original_mime_type, size_bytes, sha256, duration_ms, sample_rate_hz, active, created_at, updated_at FROM voice_messages
+WHERE id = ?
+`
+
+func (q *Queries) GetVoiceMessage(ctx context.Context, id string) (VoiceMessage, error) {
+	row := q.queryRow(ctx, q.getVoiceMessageStmt, getVoiceMessage, id)
+	var i VoiceMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.OriginalMimeType,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getVoiceMessageForOccurrence = `-- name: GetVoiceMessageForOccurrence :one
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, original_mime_type, size_bytes, sha256, duration_ms, sample_rate_hz, active, created_at, updated_at FROM voice_messages
+WHERE patient_id = ?
+  AND schedule_id = ?
+  AND active = 1
+  AND (due_at_iso = ?3 OR due_at_iso IS NULL)
+ORDER BY due_at_iso IS NULL, created_at DESC
+LIMIT 1
+`
+
+type GetVoiceMessageForOccurrenceParams struct {
+	PatientID  string         `json:"patient_id"`
+	ScheduleID string         `json:"schedule_id"`
+	DueAtIso   sql.NullString `json:"due_at_iso"`
+}
+
+func (q *Queries) GetVoiceMessageForOccurrence(ctx context.Context, arg GetVoiceMessageForOccurrenceParams) (VoiceMessage, error) {
+	row := q.queryRow(ctx, q.getVoiceMessageForOccurrenceStmt, getVoiceMessageForOccurrence, arg.PatientID, arg.ScheduleID, arg.DueAtIso)
+	var i VoiceMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.ScheduleID,
+		&i.DueAtIso,
+		&i.FilePath,
+		&i.OriginalMimeType,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.DurationMs,
+		&i.SampleRateHz,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listVoiceMessagesByPatient = `-- name: ListVoiceMessagesByPatient :many
+SELECT id, patient_id, schedule_id, due_at_iso, file_path, original_mime_type, size_bytes, sha256, duration_ms, sample_rate_hz, active, created_at, updated_at FROM voice_messages
+WHERE patient_id = ?
+  AND active = 1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListVoiceMessagesByPatient(ctx context.Context, patientID string) ([]VoiceMessage, error) {
+	rows, err := q.query(ctx, q.listVoiceMessagesByPatientStmt, listVoiceMessagesByPatient, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []VoiceMessage{}
+	for rows.Next() {
+		var i VoiceMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.ScheduleID,
+			&i.DueAtIso,
+			&i.FilePath,
+			&i.OriginalMimeType,
+			&i.SizeBytes,
+			&i.Sha256,
+			&i.DurationMs,
+			&i.SampleRateHz,
+			&i.Active,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,47 @@
+// Package dbtest opens a migrated SQLite database for tests. The schema and
+// demo seed data come from db/migrations, the same files the server embeds.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	_ "github.com/glebarez/sqlite"
+	"github.com/pressly/goose/v3"
+
+	"pillbox/internal/db"
+)
+
+// Open returns a fresh database in the test's temp directory with every
+// migration applied. It is closed when the test ends.
+func Open(t testing.TB) (*sql.DB, *db.Queries) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	conn, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(ON)&_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, conn, os.DirFS(migrationsDir()))
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	return conn, db.New(conn)
+}
+
+// migrationsDir finds db/migrations from this file, so tests in any package
+// can use it.
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "db", "migrations")
+}
@@ -0,0 +1,355 @@
+package fhir
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pillbox/internal/db"
+)
+
+// searchTypes lists the searchable medication resources with the names
+// their date parameter goes by; "date" is accepted on all of them.
+var searchTypes = map[string][]string{
+	"MedicationRequest":        {"date"},
+	"MedicationAdministration": {"date", "effective-time"},
+	"MedicationStatement":      {"date", "effective"},
+}
+
+// Handler serves the read-only FHIR API under /fhir/:
+//
+//	GET /fhir/metadata
+//	GET /fhir/Patient[?_id=]
+//	GET /fhir/Patient/{id}
+//	GET /fhir/MedicationRequest?patient=&date=&status=&_id=
+//	GET /fhir/MedicationAdministration?patient=&date=&status=&_id=
+//	GET /fhir/MedicationStatement?patient=&date=&status=&_id=
+//
+// Medication searches need a patient. Every resource is validated before
+// it is sent, so a mapping bug is a 500 rather than a malformed resource in
+// the clinic's EHR.
+type Handler struct {
+	queries    *db.Queries
+	codeSystem string
+}
+
+func NewHandler(queries *db.Queries, codeSystem string) *Handler {
+	return &Handler{
+		queries:    queries,
+		codeSystem: codeSystem,
+	}
+}
+
+// requestError is a failure the client can fix, reported as an
+// OperationOutcome with an issue type code.
+type requestError struct {
+	status int
+	code   string
+	msg    string
+}
+
+func (e *requestError) Error() string {
+	return e.msg
+}
+
+func badRequest(code, format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeOutcome(w, &requestError{status: http.StatusMethodNotAllowed, code: "not-supported", msg: "the FHIR API is read-only"})
+		return
+	}
+
+	// /fhir/{type}[/{id}]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "fhir" {
+		writeOutcome(w, &requestError{status: http.StatusNotFound, code: "not-found", msg: "unknown FHIR path"})
+		return
+	}
+
+	var (
+		res Resource
+		err error
+	)
+	resourceType := parts[1]
+	switch {
+	case resourceType == "metadata" && len(parts) == 2:
+		res = capabilityStatement(time.Now())
+	case resourceType == "Patient" && len(parts) == 3:
+		res, err = h.readPatient(r.Context(), parts[2])
+	case resourceType == "Patient":
+		res, err = h.searchPatients(r)
+	case searchTypes[resourceType] != nil && len(parts) == 2:
+		res, err = h.searchMedications(r, resourceType)
+	case searchTypes[resourceType] != nil:
+		err = badRequest("not-supported", "read is not supported for %s; search with _id and patient", resourceType)
+	default:
+		err = &requestError{status: http.StatusNotFound, code: "not-supported", msg: fmt.Sprintf("resource type %q is not served", resourceType)}
+	}
+	if err != nil {
+		var reqErr *requestError
+		if !errors.As(err, &reqErr) {
+			log.Printf("fhir %s: %v", r.URL.String(), err)
+			reqErr = &requestError{status: http.StatusInternalServerError, code: "exception", msg: "internal error"}
+		}
+		writeOutcome(w, reqErr)
+		return
+	}
+	if err := Validate(res); err != nil {
+		log.Printf("fhir %s: %v", r.URL.String(), err)
+		writeOutcome(w, &requestError{status: http.StatusInternalServerError, code: "exception", msg: "internal error"})
+		return
+	}
+	writeResource(w, http.StatusOK, res)
+}
+
+func (h *Handler) readPatient(ctx context.Context, id string) (Resource, error) {
+	patient, err := h.queries.GetPatient(ctx, dbID(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &requestError{status: http.StatusNotFound, code: "not-found", msg: fmt.Sprintf("Patient/%s is not known", id)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s: %w", id, err)
+	}
+	return patientResource(patient)
+}
+
+func (h *Handler) searchPatients(r *http.Request) (Resource, error) {
+	query := r.URL.Query()
+	if err := checkParams(query, "_id"); err != nil {
+		return nil, badRequest("not-supported", "%s", err.Error())
+	}
+	ids := tokenSet(query["_id"])
+
+	patients, err := h.queries.ListPatients(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("list patients: %w", err)
+	}
+	var resources []Resource
+	for _, patient := range patients {
+		if ids != nil && !ids[fhirID(patient.ID)] {
+			continue
+		}
+		res, err := patientResource(patient)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return searchBundle(r, "Patient", resources)
+}
+
+func (h *Handler) searchMedications(r *http.Request, resourceType string) (Resource, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	dateParams := searchTypes[resourceType]
+	if err := checkParams(query, append([]string{"patient", "subject", "_id", "status"}, dateParams...)...); err != nil {
+		return nil, badRequest("not-supported", "%s", err.Error())
+	}
+
+	patients := append(query["patient"], query["subject"]...)
+	if len(patients) != 1 {
+		return nil, badRequest("required", "%s search needs exactly one patient parameter", resourceType)
+	}
+	patientID, err := referenceID(patients[0], "Patient")
+	if err != nil {
+		return nil, badRequest("value", "patient: %s", err.Error())
+	}
+	var dateValues []string
+	for _, name := range dateParams {
+		dateValues = append(dateValues, query[name]...)
+	}
+	// Dates without an offset are read in the patient's timezone below; this
+	// pass only rejects bad values before the patient is known.
+	if _, err := parseDateParams(dateValues, time.UTC); err != nil {
+		return nil, badRequest("value", "%s", err.Error())
+	}
+	patient, err := h.queries.GetPatient(ctx, patientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return searchBundle(r, resourceType, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load patient %s: %w", patientID, err)
+	}
+
+	now := time.Now()
+	rec, err := loadRecord(ctx, h.queries, patient, h.codeSystem, now)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := parseDateParams(dateValues, rec.loc)
+	if err != nil {
+		return nil, badRequest("value", "%s", err.Error())
+	}
+
+	var matches []match
+	switch resourceType {
+	case "MedicationRequest":
+		matches, err = rec.medicationRequests()
+	case "MedicationStatement":
+		matches, err = rec.medicationStatements(now)
+	case "MedicationAdministration":
+		var events []db.DispenseEvent
+		events, err = h.dispenseEvents(ctx, patient.ID, dates)
+		if err == nil {
+			matches, err = rec.medicationAdministrations(events)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := tokenSet(query["_id"])
+	statuses := tokenSet(query["status"])
+	var resources []Resource
+	for _, m := range matches {
+		if ids != nil && !ids[m.resource.GetID()] {
+			continue
+		}
+		if statuses != nil && !statuses[m.status] {
+			continue
+		}
+		if !dates.overlaps(m.start, m.end) {
+			continue
+		}
+		resources = append(resources, m.resource)
+	}
+	return searchBundle(r, resourceType, resources)
+}
+
+// dispenseEvents loads the events that can fall in the date range. Events
+// are stored by due time but administrations are dated when the cup was
+// taken, so the window is widened by a day and the exact match left to the
+// caller.
+func (h *Handler) dispenseEvents(ctx context.Context, patientID string, dates dateRange) ([]db.DispenseEvent, error) {
+	params := db.ListDispenseEventsByPatientParams{
+		PatientID:  patientID,
+		DueAtIso:   "",
+		DueAtIso_2: "9999-12-31T23:59:59Z",
+	}
+	if !dates.start.IsZero() {
+		params.DueAtIso = formatInstant(dates.start.Add(-24 * time.Hour))
+	}
+	if !dates.end.IsZero() {
+		params.DueAtIso_2 = formatInstant(dates.end.Add(24 * time.Hour))
+	}
+	events, err := h.queries.ListDispenseEventsByPatient(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list dispense events: %w", err)
+	}
+	return events, nil
+}
+
+// searchBundle wraps validated matches in a searchset Bundle.
+func searchBundle(r *http.Request, resourceType string, resources []Resource) (Resource, error) {
+	base := baseURL(r)
+	total := len(resources)
+	self := base + "/" + resourceType
+	if r.URL.RawQuery != "" {
+		self += "?" + r.URL.RawQuery
+	}
+	bundle := &Bundle{
+		ResourceType: "Bundle",
+		Meta:         &Meta{LastUpdated: formatInstant(time.Now())},
+		Type:         "searchset",
+		Total:        &total,
+		Link:         []BundleLink{{Relation: "self", URL: self}},
+	}
+	for _, res := range resources {
+		if err := Validate(res); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(res)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Entry = append(bundle.Entry, BundleEntry{
+			FullURL:  base + "/" + res.GetResourceType() + "/" + res.GetID(),
+			Resource: data,
+			Search:   &BundleEntrySearch{Mode: "match"},
+		})
+	}
+	return bundle, nil
+}
+
+// baseURL is the service base as the client reached it, for the absolute
+// fullUrl and self links a searchset needs.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host + "/fhir"
+}
+
+func capabilityStatement(now time.Time) *CapabilityStatement {
+	search := []CapabilityInteraction{{Code: "search-type"}}
+	medicationParams := func(dateParams ...string) []CapabilitySearchParam {
+		params := []CapabilitySearchParam{
+			{Name: "patient", Type: "reference", Documentation: "Required"},
+			{Name: "subject", Type: "reference", Documentation: "Same as patient"},
+			{Name: "_id", Type: "token"},
+			{Name: "status", Type: "token"},
+		}
+		for _, name := range dateParams {
+			params = append(params, CapabilitySearchParam{Name: name, Type: "date"})
+		}
+		return params
+	}
+	return &CapabilityStatement{
+		ResourceType: "CapabilityStatement",
+		Status:       "active",
+		Date:         formatInstant(now),
+		Kind:         "instance",
+		FHIRVersion:  Version,
+		Format:       []string{"json"},
+		Rest: []CapabilityRest{{
+			Mode: "server",
+			Resource: []CapabilityResource{
+				{
+					Type:        "Patient",
+					Interaction: []CapabilityInteraction{{Code: "read"}, {Code: "search-type"}},
+					SearchParam: []CapabilitySearchParam{{Name: "_id", Type: "token"}},
+				},
+				{Type: "MedicationRequest", Interaction: search, SearchParam: medicationParams(searchTypes["MedicationRequest"]...)},
+				{Type: "MedicationAdministration", Interaction: search, SearchParam: medicationParams(searchTypes["MedicationAdministration"]...)},
+				{Type: "MedicationStatement", Interaction: search, SearchParam: medicationParams(searchTypes["MedicationStatement"]...)},
+			},
+		}},
+	}
+}
+
+func writeOutcome(w http.ResponseWriter, err *requestError) {
+	severity := "error"
+	if err.status >= http.StatusInternalServerError {
+		severity = "fatal"
+	}
+	writeResource(w, err.status, &OperationOutcome{
+		ResourceType: "OperationOutcome",
+		Issue:        []OperationOutcomeIssue{{Severity: severity, Code: err.code, Diagnostics: err.msg}},
+	})
+}
+
+func writeResource(w http.ResponseWriter, status int, res Resource) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(res)
+}
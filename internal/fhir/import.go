@@ -0,0 +1,635 @@
+package fhir
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// Clock times, in minutes after midnight, used for dosage instructions
+// that give a frequency without saying when.
+var (
+	// Once to four times a day follow the usual QD/BID/TID/QID rounds.
+	defaultRounds = map[int][]int{
+		1: {8 * 60},
+		2: {8 * 60, 20 * 60},
+		3: {8 * 60, 14 * 60, 20 * 60},
+		4: {8 * 60, 12 * 60, 16 * 60, 20 * 60},
+	}
+	// EventTiming codes that are a time of day.
+	whenTimes = map[string]int{
+		"WAKE":       7 * 60,
+		"PHS":        7 * 60,
+		"MORN.early": 7 * 60,
+		"MORN":       8 * 60,
+		"MORN.late":  10 * 60,
+		"NOON":       12 * 60,
+		"AFT.early":  13 * 60,
+		"AFT":        14 * 60,
+		"AFT.late":   16 * 60,
+		"EVE.early":  17 * 60,
+		"EVE":        18 * 60,
+		"EVE.late":   20 * 60,
+		"NIGHT":      22 * 60,
+		"HS":         22 * 60,
+	}
+	// Meal times; the offset counts back from them for AC* codes and on
+	// from them for PC* codes.
+	breakfast, lunch, dinner = 8 * 60, 12 * 60, 18 * 60
+	mealTimes                = map[string]int{
+		"CM": breakfast, "ACM": breakfast, "PCM": breakfast,
+		"CD": lunch, "ACD": lunch, "PCD": lunch,
+		"CV": dinner, "ACV": dinner, "PCV": dinner,
+	}
+	// Spread of N days a week when a weekly timing names no days.
+	weekSpreads = map[int][]string{
+		1: {"mon"},
+		2: {"mon", "thu"},
+		3: {"mon", "wed", "fri"},
+		4: {"mon", "tue", "thu", "fri"},
+		5: {"mon", "tue", "wed", "thu", "fri"},
+		6: {"mon", "tue", "wed", "thu", "fri", "sat"},
+		7: {"mon", "tue", "wed", "thu", "fri", "sat", "sun"},
+	}
+)
+
+// ImportedRequest is one MedicationRequest of an import bundle, translated
+// into dispenser terms.
+type ImportedRequest struct {
+	// Source names the request in messages: MedicationRequest/{id}, or the
+	// bundle entry when it has no id.
+	Source   string
+	Name     string
+	Codings  []Coding
+	OnHold   bool
+	Dosages  []ImportedDosage
+	Warnings []string
+}
+
+// ImportedDosage is one dosage instruction: Qty units on every occurrence
+// of each rule.
+type ImportedDosage struct {
+	Qty   int
+	Rules []ImportedRule
+}
+
+// ImportedRule is a recurrence the dispenser can run. RRule has the
+// "RRULE:" prefix schedules are stored with; its times are local to the
+// patient and Start is the local midnight or moment it begins from.
+type ImportedRule struct {
+	RRule string
+	Start time.Time
+	End   *time.Time
+}
+
+// ImportIssue explains why a request, or part of one, was not imported.
+type ImportIssue struct {
+	Source  string
+	Message string
+}
+
+// ParseMedicationRequestBundle reads a FHIR R4 Bundle and translates each
+// MedicationRequest's dosage instructions into recurrence rules in loc.
+// Requests that cannot be scheduled are returned as issues instead; an
+// error means the bundle itself is unusable. Schedules never start before
+// now, so an old prescription does not arrive with months of missed doses.
+func ParseMedicationRequestBundle(data []byte, loc *time.Location, now time.Time) ([]ImportedRequest, []ImportIssue, error) {
+	var bundle struct {
+		ResourceType string `json:"resourceType"`
+		Entry        []struct {
+			Resource json.RawMessage `json:"resource"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, nil, fmt.Errorf("bundle is not valid JSON: %w", err)
+	}
+	if bundle.ResourceType != "Bundle" {
+		return nil, nil, fmt.Errorf("expected a Bundle, got %q", bundle.ResourceType)
+	}
+	if len(bundle.Entry) == 0 {
+		return nil, nil, fmt.Errorf("bundle has no entries")
+	}
+
+	var (
+		requests []ImportedRequest
+		skipped  []ImportIssue
+	)
+	for i, entry := range bundle.Entry {
+		source := fmt.Sprintf("entry[%d]", i)
+		var header struct {
+			ResourceType string `json:"resourceType"`
+			ID           string `json:"id"`
+		}
+		if len(entry.Resource) == 0 || json.Unmarshal(entry.Resource, &header) != nil {
+			skipped = append(skipped, ImportIssue{Source: source, Message: "entry has no resource"})
+			continue
+		}
+		if header.ResourceType != "MedicationRequest" {
+			skipped = append(skipped, ImportIssue{Source: source, Message: fmt.Sprintf("%s resources are not imported", header.ResourceType)})
+			continue
+		}
+		if header.ID != "" {
+			source = "MedicationRequest/" + header.ID
+		}
+
+		var res MedicationRequest
+		if err := json.Unmarshal(entry.Resource, &res); err != nil {
+			skipped = append(skipped, ImportIssue{Source: source, Message: fmt.Sprintf("unreadable: %v", err)})
+			continue
+		}
+		request, err := importRequest(&res, source, loc, now)
+		if err != nil {
+			skipped = append(skipped, ImportIssue{Source: source, Message: err.Error()})
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests, skipped, nil
+}
+
+func importRequest(res *MedicationRequest, source string, loc *time.Location, now time.Time) (ImportedRequest, error) {
+	if err := Validate(res); err != nil {
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			return ImportedRequest{}, fmt.Errorf("not a valid MedicationRequest: %s", strings.Join(invalid.Issues, "; "))
+		}
+		return ImportedRequest{}, err
+	}
+	if res.MedicationCodeableConcept == nil {
+		return ImportedRequest{}, fmt.Errorf("medicationReference is not supported; send the drug as medicationCodeableConcept")
+	}
+
+	request := ImportedRequest{Source: source, Codings: res.MedicationCodeableConcept.Coding}
+	switch res.Status {
+	case "active":
+	case "on-hold":
+		request.OnHold = true
+	default:
+		return ImportedRequest{}, fmt.Errorf("status %s is not imported; only active and on-hold requests are", res.Status)
+	}
+
+	request.Name = strings.TrimSpace(res.MedicationCodeableConcept.Text)
+	for _, coding := range res.MedicationCodeableConcept.Coding {
+		if request.Name != "" {
+			break
+		}
+		request.Name = strings.TrimSpace(coding.Display)
+		if request.Name == "" {
+			request.Name = strings.TrimSpace(coding.Code)
+		}
+	}
+
+	if request.Name == "" {
+		return ImportedRequest{}, fmt.Errorf("medication has no text, display or code to name it by")
+	}
+	if len(res.DosageInstruction) == 0 {
+		return ImportedRequest{}, fmt.Errorf("no dosageInstruction to schedule")
+	}
+	for i, instruction := range res.DosageInstruction {
+		dosage, warnings, err := importDosage(instruction, loc, now)
+		if err != nil {
+			if len(res.DosageInstruction) == 1 {
+				return ImportedRequest{}, err
+			}
+			request.Warnings = append(request.Warnings, fmt.Sprintf("dosageInstruction[%d] skipped: %v", i, err))
+			continue
+		}
+		for _, warning := range warnings {
+			if len(res.DosageInstruction) > 1 {
+				warning = fmt.Sprintf("dosageInstruction[%d]: %s", i, warning)
+			}
+			request.Warnings = append(request.Warnings, warning)
+		}
+		request.Dosages = append(request.Dosages, dosage)
+	}
+	if len(request.Dosages) == 0 {
+		return ImportedRequest{}, fmt.Errorf("none of the dosage instructions can be scheduled")
+	}
+	return request, nil
+}
+
+func importDosage(dosage Dosage, loc *time.Location, now time.Time) (ImportedDosage, []string, error) {
+	if dosage.AsNeededBoolean {
+		return ImportedDosage{}, nil, fmt.Errorf("as-needed doses are not scheduled")
+	}
+	qty, warnings, err := dosageQty(dosage)
+	if err != nil {
+		return ImportedDosage{}, nil, err
+	}
+	if dosage.Timing == nil || dosage.Timing.Repeat == nil {
+		if dosage.Timing != nil && len(dosage.Timing.Event) > 0 {
+			return ImportedDosage{}, nil, fmt.Errorf("one-off event times are not supported; a repeat is needed")
+		}
+		return ImportedDosage{}, nil, fmt.Errorf("no timing.repeat to schedule")
+	}
+	rules, ruleWarnings, err := TimingRules(*dosage.Timing, loc, now)
+	if err != nil {
+		return ImportedDosage{}, nil, err
+	}
+	return ImportedDosage{Qty: qty, Rules: rules}, append(warnings, ruleWarnings...), nil
+}
+
+// dosageQty is the number of units dispensed per dose.
+func dosageQty(dosage Dosage) (int, []string, error) {
+	var quantity *Quantity
+	var warnings []string
+	for _, doseAndRate := range dosage.DoseAndRate {
+		if doseAndRate.DoseQuantity != nil {
+			quantity = doseAndRate.DoseQuantity
+			break
+		}
+		if doseAndRate.DoseRange != nil && doseAndRate.DoseRange.Low != nil {
+			quantity = doseAndRate.DoseRange.Low
+			warnings = append(warnings, "dose range given; the low end is scheduled")
+			break
+		}
+	}
+	if quantity == nil {
+		return 1, []string{"no dose given; 1 is scheduled"}, nil
+	}
+	if quantity.Value <= 0 || quantity.Value != math.Trunc(quantity.Value) {
+		return 0, nil, fmt.Errorf("dose of %s cannot be dispensed; whole units are needed", strconv.FormatFloat(quantity.Value, 'f', -1, 64))
+	}
+	return int(quantity.Value), warnings, nil
+}
+
+// TimingRules translates a Timing into recurrence rules. One rule usually
+// does, but times of day that do not share their minutes (08:00 and 20:30)
+// need one rule each, since BYHOUR and BYMINUTE combine. A timing whose
+// code.text is an RRULE, as this server exports, is used as is; when
+// boundsPeriod.start gives its time too, the rule resumes from there so
+// what it took from its start carries over.
+func TimingRules(timing Timing, loc *time.Location, now time.Time) ([]ImportedRule, []string, error) {
+	repeat := timing.Repeat
+	var warnings []string
+
+	start := now.In(loc).Truncate(time.Minute)
+	// anchor is boundsPeriod.start when it is a dateTime, not a date.
+	var anchor time.Time
+	var end *time.Time
+	if repeat.BoundsPeriod != nil {
+		if repeat.BoundsPeriod.Start != "" {
+			from, _, err := parseDateValue(repeat.BoundsPeriod.Start, loc)
+			if err != nil {
+				return nil, nil, fmt.Errorf("boundsPeriod.start: %w", err)
+			}
+			if strings.Contains(repeat.BoundsPeriod.Start, "T") {
+				anchor = from.In(loc)
+			}
+			if from = startOfDay(from.In(loc)); from.After(start) {
+				start = from
+			}
+		}
+		if repeat.BoundsPeriod.End != "" {
+			_, to, err := parseDateValue(repeat.BoundsPeriod.End, loc)
+			if err != nil {
+				return nil, nil, fmt.Errorf("boundsPeriod.end: %w", err)
+			}
+			// The end is inclusive: the last second of its span.
+			to = to.Add(-time.Second)
+			end = &to
+		}
+	}
+	if repeat.BoundsDuration != nil {
+		to, err := addDuration(start, *repeat.BoundsDuration)
+		if err != nil {
+			return nil, nil, fmt.Errorf("boundsDuration: %w", err)
+		}
+		if end == nil || to.Before(*end) {
+			end = &to
+		}
+	}
+	if end != nil && !end.After(start) {
+		return nil, nil, fmt.Errorf("the dosage ended on %s", end.In(loc).Format(time.DateOnly))
+	}
+	if repeat.FrequencyMax > 0 || repeat.PeriodMax > 0 {
+		warnings = append(warnings, "frequencyMax/periodMax ignored; the most frequent rate is scheduled")
+	}
+
+	var parts [][]string
+	fromCode := timing.Code != nil && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(timing.Code.Text)), "RRULE:")
+	if fromCode {
+		rule := strings.TrimSpace(timing.Code.Text)[6:]
+		if _, err := rrule.StrToROption(rule); err != nil {
+			return nil, nil, fmt.Errorf("timing.code.text: %w", err)
+		}
+		parts = [][]string{strings.Split(rule, ";")}
+		if !anchor.IsZero() {
+			// Its time of day, weekday or hourly phase may come from its
+			// start; resume it at its next occurrence instead of now.
+			next, resumed, err := resumeRule(rule, anchor, start)
+			if err != nil {
+				return nil, nil, fmt.Errorf("timing.code.text: %w", err)
+			}
+			if end != nil && next.After(*end) {
+				return nil, nil, fmt.Errorf("the dosage ended on %s", end.In(loc).Format(time.DateOnly))
+			}
+			start = next
+			parts = [][]string{strings.Split(resumed, ";")}
+		} else if !strings.Contains(strings.ToUpper(rule), "BYHOUR=") {
+			// The rule's time of day came from its own start, which the
+			// import does not keep; take it from timeOfDay instead.
+			times, err := clockTimes(repeat, 1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(times) == 0 {
+				return nil, nil, fmt.Errorf("timing.code.text has no BYHOUR and the repeat no timeOfDay")
+			}
+			parts = nil
+			for _, group := range groupTimes(times) {
+				parts = append(parts, append(strings.Split(rule, ";"), "BYHOUR="+joinInts(group.hours), "BYMINUTE="+strconv.Itoa(group.minute)))
+			}
+		}
+	} else {
+		var err error
+		if parts, err = ruleParts(repeat); err != nil {
+			return nil, nil, err
+		}
+	}
+	// A rule from code.text carries its own COUNT.
+	if repeat.Count > 0 && !fromCode {
+		for i := range parts {
+			parts[i] = append(parts[i], fmt.Sprintf("COUNT=%d", repeat.Count))
+		}
+		if len(parts) > 1 {
+			warnings = append(warnings, "count applies to each time of day separately")
+		}
+	}
+
+	rules := make([]ImportedRule, 0, len(parts))
+	for _, part := range parts {
+		rule := strings.Join(part, ";")
+		if _, err := rrule.StrToROption(rule); err != nil {
+			return nil, nil, fmt.Errorf("translated rule %s: %w", rule, err)
+		}
+		rules = append(rules, ImportedRule{RRule: "RRULE:" + rule, Start: start, End: end})
+	}
+	return rules, warnings, nil
+}
+
+// resumeRule restarts rule, which began at anchor, at its first occurrence
+// at or after from. A COUNT is lowered by the occurrences already past.
+func resumeRule(rule string, anchor, from time.Time) (time.Time, string, error) {
+	opt, err := rrule.StrToROption(rule)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	opt.Dtstart = anchor
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	next := r.After(from, true)
+	if next.IsZero() {
+		return time.Time{}, "", fmt.Errorf("no doses are left after %s", from.Format(time.DateOnly))
+	}
+	if opt.Count == 0 {
+		return next, rule, nil
+	}
+	past := len(r.Between(anchor, next, true)) - 1
+	parts := strings.Split(rule, ";")
+	for i, part := range parts {
+		if strings.HasPrefix(strings.ToUpper(part), "COUNT=") {
+			parts[i] = fmt.Sprintf("COUNT=%d", opt.Count-past)
+		}
+	}
+	return next, strings.Join(parts, ";"), nil
+}
+
+// ruleParts builds the RRULE parts for a repeat, one list per rule.
+func ruleParts(repeat *TimingRepeat) ([][]string, error) {
+	period := repeat.Period
+	if period == 0 {
+		period = 1
+	}
+	if period < 0 || period != math.Trunc(period) {
+		return nil, fmt.Errorf("period %s is not a whole number", strconv.FormatFloat(period, 'f', -1, 64))
+	}
+	interval := int(period)
+	frequency := max(repeat.Frequency, 1)
+	if repeat.PeriodUnit == "" {
+		return nil, fmt.Errorf("timing.repeat has no periodUnit")
+	}
+
+	times, err := clockTimes(repeat, frequency)
+	if err != nil {
+		return nil, err
+	}
+	if repeat.Frequency == 0 && len(times) > 0 {
+		// Times of day alone mean one dose at each.
+		frequency = len(times)
+	}
+	days := make([]string, 0, len(repeat.DayOfWeek))
+	for _, day := range repeat.DayOfWeek {
+		days = append(days, strings.ToUpper(day[:2]))
+	}
+
+	var freq string
+	switch repeat.PeriodUnit {
+	case "d":
+		freq = "DAILY"
+		if times == nil {
+			rounds, ok := defaultRounds[frequency]
+			if !ok {
+				if 24%frequency != 0 {
+					return nil, fmt.Errorf("%d doses a day cannot be spread evenly; give timeOfDay", frequency)
+				}
+				for hour := 0; hour < 24; hour += 24 / frequency {
+					rounds = append(rounds, hour*60)
+				}
+			}
+			times = rounds
+		}
+	case "wk":
+		freq = "WEEKLY"
+		if times == nil {
+			times = defaultRounds[1]
+		}
+		if len(days) == 0 {
+			if frequency%len(times) != 0 {
+				return nil, fmt.Errorf("%d doses a week do not fit %d times a day; give dayOfWeek", frequency, len(times))
+			}
+			spread, ok := weekSpreads[frequency/len(times)]
+			if !ok {
+				return nil, fmt.Errorf("%d dosing days a week is more than a week has", frequency/len(times))
+			}
+			for _, day := range spread {
+				days = append(days, strings.ToUpper(day[:2]))
+			}
+		}
+	case "mo", "a":
+		freq = map[string]string{"mo": "MONTHLY", "a": "YEARLY"}[repeat.PeriodUnit]
+		if times == nil {
+			if frequency != 1 {
+				return nil, fmt.Errorf("%d doses per %s cannot be placed; give timeOfDay", frequency, repeat.PeriodUnit)
+			}
+			times = defaultRounds[1]
+		}
+	case "h":
+		if times != nil {
+			return nil, fmt.Errorf("hourly timings cannot also give times of day")
+		}
+		if interval%frequency != 0 {
+			return nil, fmt.Errorf("%d doses every %d hours are not evenly spaced", frequency, interval)
+		}
+		every := interval / frequency
+		if 24%every != 0 {
+			// Doses drift around the clock; anchor them on the hour.
+			parts := []string{"FREQ=HOURLY", fmt.Sprintf("INTERVAL=%d", every), "BYMINUTE=0", "BYSECOND=0"}
+			if len(days) > 0 {
+				parts = append(parts, "BYDAY="+strings.Join(days, ","))
+			}
+			return [][]string{parts}, nil
+		}
+		// Every 4, 6, 8 or 12 hours lands on the same clock times each day,
+		// starting from the first morning round.
+		freq, interval = "DAILY", 1
+		for hour := 0; hour < 24; hour += every {
+			times = append(times, ((defaultRounds[1][0]/60+hour)%24)*60)
+		}
+		sort.Ints(times)
+	default:
+		return nil, fmt.Errorf("dosing every %s is more often than the dispenser runs", repeat.PeriodUnit)
+	}
+
+	base := []string{"FREQ=" + freq}
+	if interval > 1 {
+		base = append(base, fmt.Sprintf("INTERVAL=%d", interval))
+	}
+	if len(days) > 0 {
+		base = append(base, "BYDAY="+strings.Join(days, ","))
+	}
+
+	var parts [][]string
+	for _, group := range groupTimes(times) {
+		part := append(append([]string(nil), base...), "BYHOUR="+joinInts(group.hours), "BYMINUTE="+strconv.Itoa(group.minute))
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// clockTimes reads the explicit times of a repeat, from timeOfDay or when,
+// as sorted minutes after midnight; nil means none were given.
+func clockTimes(repeat *TimingRepeat, frequency int) ([]int, error) {
+	seen := make(map[int]bool)
+	var times []int
+	add := func(minutes int) {
+		minutes = ((minutes % (24 * 60)) + 24*60) % (24 * 60)
+		if !seen[minutes] {
+			seen[minutes] = true
+			times = append(times, minutes)
+		}
+	}
+
+	for _, value := range repeat.TimeOfDay {
+		t, err := time.Parse("15:04:05", value)
+		if err != nil {
+			return nil, fmt.Errorf("timeOfDay %q: %w", value, err)
+		}
+		add(t.Hour()*60 + t.Minute())
+	}
+	for _, when := range repeat.When {
+		if minutes, ok := whenTimes[when]; ok {
+			add(minutes)
+			continue
+		}
+		var meals []int
+		switch when {
+		case "C", "AC", "PC":
+			// No meal named: take as many as the frequency asks for,
+			// breakfast first.
+			switch {
+			case frequency >= 3:
+				meals = []int{breakfast, lunch, dinner}
+			case frequency == 2:
+				meals = []int{breakfast, dinner}
+			default:
+				meals = []int{breakfast}
+			}
+		default:
+			meal, ok := mealTimes[when]
+			if !ok {
+				return nil, fmt.Errorf("when %q has no clock time the dispenser can use; give timeOfDay", when)
+			}
+			meals = []int{meal}
+		}
+		offset := repeat.Offset
+		if strings.HasPrefix(when, "AC") {
+			offset = -offset
+		}
+		for _, meal := range meals {
+			add(meal + offset)
+		}
+	}
+	sort.Ints(times)
+	return times, nil
+}
+
+type timeGroup struct {
+	minute int
+	hours  []int
+}
+
+// groupTimes splits clock times by their minute so each group is one
+// BYHOUR/BYMINUTE pair.
+func groupTimes(times []int) []timeGroup {
+	var groups []timeGroup
+	index := make(map[int]int)
+	for _, t := range times {
+		i, ok := index[t%60]
+		if !ok {
+			i = len(groups)
+			index[t%60] = i
+			groups = append(groups, timeGroup{minute: t % 60})
+		}
+		groups[i].hours = append(groups[i].hours, t/60)
+	}
+	return groups
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// addDuration adds a UCUM time quantity; calendar units follow the
+// calendar.
+func addDuration(t time.Time, duration Duration) (time.Time, error) {
+	if duration.Value <= 0 || duration.Value != math.Trunc(duration.Value) {
+		return time.Time{}, fmt.Errorf("%s is not a whole positive duration", strconv.FormatFloat(duration.Value, 'f', -1, 64))
+	}
+	n := int(duration.Value)
+	unit := duration.Code
+	if unit == "" {
+		unit = duration.Unit
+	}
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "h", "hour", "hours":
+		return t.Add(time.Duration(n) * time.Hour), nil
+	case "d", "day", "days":
+		return t.AddDate(0, 0, n), nil
+	case "wk", "week", "weeks":
+		return t.AddDate(0, 0, 7*n), nil
+	case "mo", "month", "months":
+		return t.AddDate(0, n, 0), nil
+	case "a", "year", "years":
+		return t.AddDate(n, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("unit %q is not supported", unit)
+	}
+}
@@ -0,0 +1,469 @@
+package fhir
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockTimes(t *testing.T) {
+	tests := []struct {
+		name      string
+		repeat    TimingRepeat
+		frequency int
+		want      []int
+		wantErr   string
+	}{
+		{name: "none given", repeat: TimingRepeat{}, frequency: 2, want: nil},
+		{
+			name:   "timeOfDay sorted without duplicates",
+			repeat: TimingRepeat{TimeOfDay: []string{"20:30:00", "08:00:00", "08:00:00"}},
+			want:   []int{8 * 60, 20*60 + 30},
+		},
+		{
+			name:   "when codes that are a time of day",
+			repeat: TimingRepeat{When: []string{"HS", "MORN"}},
+			want:   []int{8 * 60, 22 * 60},
+		},
+		{
+			name:   "before breakfast",
+			repeat: TimingRepeat{When: []string{"ACM"}, Offset: 30},
+			want:   []int{7*60 + 30},
+		},
+		{
+			name:   "after dinner",
+			repeat: TimingRepeat{When: []string{"PCV"}, Offset: 60},
+			want:   []int{19 * 60},
+		},
+		{
+			name:   "with lunch",
+			repeat: TimingRepeat{When: []string{"CD"}},
+			want:   []int{12 * 60},
+		},
+		{
+			name:   "offset before midnight wraps",
+			repeat: TimingRepeat{When: []string{"ACM"}, Offset: 600},
+			want:   []int{22 * 60},
+		},
+		{
+			name:      "meals once a day",
+			repeat:    TimingRepeat{When: []string{"AC"}, Offset: 15},
+			frequency: 1,
+			want:      []int{7*60 + 45},
+		},
+		{
+			name:      "meals twice a day",
+			repeat:    TimingRepeat{When: []string{"C"}},
+			frequency: 2,
+			want:      []int{8 * 60, 18 * 60},
+		},
+		{
+			name:      "meals three times a day",
+			repeat:    TimingRepeat{When: []string{"PC"}, Offset: 30},
+			frequency: 3,
+			want:      []int{8*60 + 30, 12*60 + 30, 18*60 + 30},
+		},
+		{
+			name:   "timeOfDay and when together",
+			repeat: TimingRepeat{TimeOfDay: []string{"12:00:00"}, When: []string{"NOON", "EVE"}},
+			want:   []int{12 * 60, 18 * 60},
+		},
+		{name: "when without a clock time", repeat: TimingRepeat{When: []string{"PRN"}}, wantErr: `when "PRN"`},
+		{name: "bad timeOfDay", repeat: TimingRepeat{TimeOfDay: []string{"8am"}}, wantErr: `timeOfDay "8am"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := clockTimes(&tc.repeat, tc.frequency)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("clockTimes error = %v, want one mentioning %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("clockTimes: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("clockTimes = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleParts(t *testing.T) {
+	tests := []struct {
+		name    string
+		repeat  TimingRepeat
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "once a day",
+			repeat: TimingRepeat{Frequency: 1, Period: 1, PeriodUnit: "d"},
+			want:   []string{"FREQ=DAILY;BYHOUR=8;BYMINUTE=0"},
+		},
+		{
+			name:   "BID default rounds",
+			repeat: TimingRepeat{Frequency: 2, Period: 1, PeriodUnit: "d"},
+			want:   []string{"FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0"},
+		},
+		{
+			name:   "TID default rounds",
+			repeat: TimingRepeat{Frequency: 3, Period: 1, PeriodUnit: "d"},
+			want:   []string{"FREQ=DAILY;BYHOUR=8,14,20;BYMINUTE=0"},
+		},
+		{
+			name:   "six a day spread over the clock",
+			repeat: TimingRepeat{Frequency: 6, Period: 1, PeriodUnit: "d"},
+			want:   []string{"FREQ=DAILY;BYHOUR=0,4,8,12,16,20;BYMINUTE=0"},
+		},
+		{
+			name:    "five a day cannot be spread",
+			repeat:  TimingRepeat{Frequency: 5, Period: 1, PeriodUnit: "d"},
+			wantErr: "cannot be spread evenly",
+		},
+		{
+			name:   "every other day",
+			repeat: TimingRepeat{Frequency: 1, Period: 2, PeriodUnit: "d"},
+			want:   []string{"FREQ=DAILY;INTERVAL=2;BYHOUR=8;BYMINUTE=0"},
+		},
+		{
+			name:   "timeOfDay sharing a minute",
+			repeat: TimingRepeat{PeriodUnit: "d", TimeOfDay: []string{"09:15:00", "21:15:00"}},
+			want:   []string{"FREQ=DAILY;BYHOUR=9,21;BYMINUTE=15"},
+		},
+		{
+			name:   "mixed-minute timeOfDay splits into rules",
+			repeat: TimingRepeat{PeriodUnit: "d", TimeOfDay: []string{"08:00:00", "20:30:00", "13:00:00"}},
+			want: []string{
+				"FREQ=DAILY;BYHOUR=8,13;BYMINUTE=0",
+				"FREQ=DAILY;BYHOUR=20;BYMINUTE=30",
+			},
+		},
+		{
+			name:   "before breakfast and dinner",
+			repeat: TimingRepeat{Frequency: 2, Period: 1, PeriodUnit: "d", When: []string{"ACM", "ACV"}, Offset: 30},
+			want:   []string{"FREQ=DAILY;BYHOUR=7,17;BYMINUTE=30"},
+		},
+		{
+			name:   "with meals three times a day",
+			repeat: TimingRepeat{Frequency: 3, Period: 1, PeriodUnit: "d", When: []string{"C"}},
+			want:   []string{"FREQ=DAILY;BYHOUR=8,12,18;BYMINUTE=0"},
+		},
+		{
+			name:   "weekly on named days",
+			repeat: TimingRepeat{Frequency: 1, Period: 2, PeriodUnit: "wk", DayOfWeek: []string{"sat"}},
+			want:   []string{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SA;BYHOUR=8;BYMINUTE=0"},
+		},
+		{
+			name:   "three a week spread without dayOfWeek",
+			repeat: TimingRepeat{Frequency: 3, Period: 1, PeriodUnit: "wk"},
+			want:   []string{"FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=8;BYMINUTE=0"},
+		},
+		{
+			name:   "four a week twice a day spread over two days",
+			repeat: TimingRepeat{Frequency: 4, Period: 1, PeriodUnit: "wk", TimeOfDay: []string{"08:00:00", "20:00:00"}},
+			want:   []string{"FREQ=WEEKLY;BYDAY=MO,TH;BYHOUR=8,20;BYMINUTE=0"},
+		},
+		{
+			name:    "weekly doses that do not fit the times of day",
+			repeat:  TimingRepeat{Frequency: 3, Period: 1, PeriodUnit: "wk", TimeOfDay: []string{"08:00:00", "20:00:00"}},
+			wantErr: "give dayOfWeek",
+		},
+		{
+			name:    "more dosing days than a week has",
+			repeat:  TimingRepeat{Frequency: 8, Period: 1, PeriodUnit: "wk"},
+			wantErr: "more than a week has",
+		},
+		{
+			name:   "monthly",
+			repeat: TimingRepeat{Frequency: 1, Period: 1, PeriodUnit: "mo"},
+			want:   []string{"FREQ=MONTHLY;BYHOUR=8;BYMINUTE=0"},
+		},
+		{
+			name:    "twice a month without times",
+			repeat:  TimingRepeat{Frequency: 2, Period: 1, PeriodUnit: "mo"},
+			wantErr: "cannot be placed",
+		},
+		{
+			name:   "yearly",
+			repeat: TimingRepeat{Frequency: 1, Period: 1, PeriodUnit: "a", TimeOfDay: []string{"10:00:00"}},
+			want:   []string{"FREQ=YEARLY;BYHOUR=10;BYMINUTE=0"},
+		},
+		{
+			name:   "every 8 hours lands on the same clock times",
+			repeat: TimingRepeat{Frequency: 1, Period: 8, PeriodUnit: "h"},
+			want:   []string{"FREQ=DAILY;BYHOUR=0,8,16;BYMINUTE=0"},
+		},
+		{
+			name:   "twice every 12 hours",
+			repeat: TimingRepeat{Frequency: 2, Period: 12, PeriodUnit: "h"},
+			want:   []string{"FREQ=DAILY;BYHOUR=2,8,14,20;BYMINUTE=0"},
+		},
+		{
+			name:   "every 5 hours drifts around the clock",
+			repeat: TimingRepeat{Frequency: 1, Period: 5, PeriodUnit: "h"},
+			want:   []string{"FREQ=HOURLY;INTERVAL=5;BYMINUTE=0;BYSECOND=0"},
+		},
+		{
+			name:   "every 10 hours on weekdays",
+			repeat: TimingRepeat{Frequency: 1, Period: 10, PeriodUnit: "h", DayOfWeek: []string{"mon", "fri"}},
+			want:   []string{"FREQ=HOURLY;INTERVAL=10;BYMINUTE=0;BYSECOND=0;BYDAY=MO,FR"},
+		},
+		{
+			name:    "hourly doses not evenly spaced",
+			repeat:  TimingRepeat{Frequency: 2, Period: 5, PeriodUnit: "h"},
+			wantErr: "not evenly spaced",
+		},
+		{
+			name:    "hourly with times of day",
+			repeat:  TimingRepeat{Frequency: 1, Period: 6, PeriodUnit: "h", TimeOfDay: []string{"08:00:00"}},
+			wantErr: "cannot also give times of day",
+		},
+		{
+			name:    "more often than hourly",
+			repeat:  TimingRepeat{Frequency: 1, Period: 30, PeriodUnit: "min"},
+			wantErr: "more often than the dispenser runs",
+		},
+		{
+			name:    "no period unit",
+			repeat:  TimingRepeat{Frequency: 1, Period: 1},
+			wantErr: "no periodUnit",
+		},
+		{
+			name:    "fractional period",
+			repeat:  TimingRepeat{Frequency: 1, Period: 1.5, PeriodUnit: "d"},
+			wantErr: "not a whole number",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parts, err := ruleParts(&tc.repeat)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ruleParts error = %v, want one mentioning %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ruleParts: %v", err)
+			}
+			got := make([]string, len(parts))
+			for i, part := range parts {
+				got[i] = strings.Join(part, ";")
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ruleParts = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTimingRules(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(value string) time.Time {
+		t.Helper()
+		parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, ny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	now := at("2026-03-05 10:17:42")
+
+	tests := []struct {
+		name         string
+		timing       Timing
+		wantRules    []string
+		wantStart    time.Time
+		wantEnd      time.Time
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name:      "starts now without bounds",
+			timing:    Timing{Repeat: &TimingRepeat{Frequency: 2, Period: 1, PeriodUnit: "d"}},
+			wantRules: []string{"RRULE:FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0"},
+			wantStart: at("2026-03-05 10:17:00"),
+		},
+		{
+			name: "boundsPeriod in the future",
+			timing: Timing{Repeat: &TimingRepeat{
+				Frequency: 1, Period: 1, PeriodUnit: "d",
+				BoundsPeriod: &Period{Start: "2026-04-01", End: "2026-04-30"},
+			}},
+			wantRules: []string{"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0"},
+			wantStart: at("2026-04-01 00:00:00"),
+			wantEnd:   at("2026-04-30 23:59:59"),
+		},
+		{
+			name: "boundsPeriod started in the past",
+			timing: Timing{Repeat: &TimingRepeat{
+				Frequency: 1, Period: 1, PeriodUnit: "d",
+				BoundsPeriod: &Period{Start: "2026-01-01"},
+			}},
+			wantRules: []string{"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0"},
+			wantStart: at("2026-03-05 10:17:00"),
+		},
+		{
+			name: "boundsPeriod already over",
+			timing: Timing{Repeat: &TimingRepeat{
+				Frequency: 1, Period: 1, PeriodUnit: "d",
+				BoundsPeriod: &Period{Start: "2026-01-01", End: "2026-01-31"},
+			}},
+			wantErr: "the dosage ended on 2026-01-31",
+		},
+		{
+			name: "boundsDuration",
+			timing: Timing{Repeat: &TimingRepeat{
+				Frequency: 1, Period: 1, PeriodUnit: "d",
+				BoundsDuration: &Duration{Value: 10, Code: "d"},
+			}},
+			wantRules: []string{"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0"},
+			wantStart: at("2026-03-05 10:17:00"),
+			wantEnd:   at("2026-03-15 10:17:00"),
+		},
+		{
+			name: "boundsDuration counts from the boundsPeriod start",
+			timing: Timing{Repeat: &TimingRepeat{
+				Frequency: 1, Period: 1, PeriodUnit: "d",
+				BoundsPeriod:   &Period{Start: "2026-04-01", End: "2026-04-30"},
+				BoundsDuration: &Duration{Value: 1, Unit: "week"},
+			}},
+			wantRules: []string{"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0"},
+			wantStart: at("2026-04-01 00:00:00"),
+			wantEnd:   at("2026-04-08 00:00:00"),
+		},
+		{
+			name: "boundsDuration in an unknown unit",
+			timing: Timing{Repeat: &TimingRepeat{
+				Frequency: 1, Period: 1, PeriodUnit: "d",
+				BoundsDuration: &Duration{Value: 3, Code: "fortnight"},
+			}},
+			wantErr: "boundsDuration",
+		},
+		{
+			name:      "count on one rule",
+			timing:    Timing{Repeat: &TimingRepeat{Frequency: 2, Period: 1, PeriodUnit: "d", Count: 14}},
+			wantRules: []string{"RRULE:FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0;COUNT=14"},
+			wantStart: at("2026-03-05 10:17:00"),
+		},
+		{
+			name: "count on several rules",
+			timing: Timing{Repeat: &TimingRepeat{
+				PeriodUnit: "d", Count: 10,
+				TimeOfDay: []string{"08:00:00", "20:30:00"},
+			}},
+			wantRules: []string{
+				"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0;COUNT=10",
+				"RRULE:FREQ=DAILY;BYHOUR=20;BYMINUTE=30;COUNT=10",
+			},
+			wantStart:    at("2026-03-05 10:17:00"),
+			wantWarnings: []string{"count applies to each time of day separately"},
+		},
+		{
+			name:         "frequencyMax",
+			timing:       Timing{Repeat: &TimingRepeat{Frequency: 1, FrequencyMax: 2, Period: 1, PeriodUnit: "d"}},
+			wantRules:    []string{"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0"},
+			wantStart:    at("2026-03-05 10:17:00"),
+			wantWarnings: []string{"frequencyMax/periodMax ignored; the most frequent rate is scheduled"},
+		},
+		{
+			name: "code.text with its own times and count",
+			timing: Timing{
+				Repeat: &TimingRepeat{Frequency: 1, Period: 1, PeriodUnit: "wk", Count: 4},
+				Code:   &CodeableConcept{Text: "RRULE:FREQ=WEEKLY;BYDAY=MO;BYHOUR=9;BYMINUTE=15;COUNT=4"},
+			},
+			wantRules: []string{"RRULE:FREQ=WEEKLY;BYDAY=MO;BYHOUR=9;BYMINUTE=15;COUNT=4"},
+			wantStart: at("2026-03-05 10:17:00"),
+		},
+		{
+			name: "code.text times from mixed-minute timeOfDay",
+			timing: Timing{
+				Repeat: &TimingRepeat{PeriodUnit: "d", TimeOfDay: []string{"08:00:00", "20:30:00"}},
+				Code:   &CodeableConcept{Text: "RRULE:FREQ=DAILY"},
+			},
+			wantRules: []string{
+				"RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0",
+				"RRULE:FREQ=DAILY;BYHOUR=20;BYMINUTE=30",
+			},
+			wantStart: at("2026-03-05 10:17:00"),
+		},
+		{
+			name: "code.text resumes from a boundsPeriod dateTime",
+			timing: Timing{
+				Repeat: &TimingRepeat{
+					Frequency: 1, Period: 1, PeriodUnit: "wk", DayOfWeek: []string{"wed"},
+					BoundsPeriod: &Period{Start: "2026-01-07T08:00:00-05:00"},
+				},
+				Code: &CodeableConcept{Text: "RRULE:FREQ=WEEKLY;COUNT=10"},
+			},
+			wantRules: []string{"RRULE:FREQ=WEEKLY;COUNT=1"},
+			wantStart: at("2026-03-11 08:00:00"),
+		},
+		{
+			name: "code.text with no doses left",
+			timing: Timing{
+				Repeat: &TimingRepeat{
+					Frequency: 1, Period: 1, PeriodUnit: "wk",
+					BoundsPeriod: &Period{Start: "2026-01-07T08:00:00-05:00"},
+				},
+				Code: &CodeableConcept{Text: "RRULE:FREQ=WEEKLY;COUNT=5"},
+			},
+			wantErr: "no doses are left",
+		},
+		{
+			name: "code.text without times",
+			timing: Timing{
+				Repeat: &TimingRepeat{Frequency: 1, Period: 1, PeriodUnit: "d"},
+				Code:   &CodeableConcept{Text: "RRULE:FREQ=DAILY"},
+			},
+			wantErr: "no BYHOUR",
+		},
+		{
+			name: "code.text that is not a rule",
+			timing: Timing{
+				Repeat: &TimingRepeat{Frequency: 1, Period: 1, PeriodUnit: "d"},
+				Code:   &CodeableConcept{Text: "RRULE:FREQ=SOMETIMES"},
+			},
+			wantErr: "timing.code.text",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, warnings, err := TimingRules(tc.timing, ny, now)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("TimingRules error = %v, want one mentioning %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TimingRules: %v", err)
+			}
+			got := make([]string, len(rules))
+			for i, rule := range rules {
+				got[i] = rule.RRule
+				if !rule.Start.Equal(tc.wantStart) {
+					t.Errorf("rule %d start = %v, want %v", i, rule.Start, tc.wantStart)
+				}
+				switch {
+				case tc.wantEnd.IsZero() && rule.End != nil:
+					t.Errorf("rule %d end = %v, want none", i, *rule.End)
+				case !tc.wantEnd.IsZero() && (rule.End == nil || !rule.End.Equal(tc.wantEnd)):
+					t.Errorf("rule %d end = %v, want %v", i, rule.End, tc.wantEnd)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.wantRules) {
+				t.Errorf("rules = %q, want %q", got, tc.wantRules)
+			}
+			if !reflect.DeepEqual(warnings, tc.wantWarnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tc.wantWarnings)
+			}
+		})
+	}
+}
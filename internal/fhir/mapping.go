@@ -0,0 +1,421 @@
+package fhir
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"pillbox/internal/db"
+	"pillbox/internal/notifications"
+)
+
+// MedicationCodeSystemFromEnv returns FHIR_MEDICATION_CODE_SYSTEM, the code
+// system of the loaded medication catalog's ids. It defaults to RxNorm;
+// setting it empty leaves catalog-linked medications as text only.
+func MedicationCodeSystemFromEnv() string {
+	if system, ok := os.LookupEnv("FHIR_MEDICATION_CODE_SYSTEM"); ok {
+		return strings.TrimSpace(system)
+	}
+	return RxNormSystem
+}
+
+// Row ids are UUIDs, which are valid FHIR ids. Seeded rows use underscores,
+// which FHIR ids do not allow, so they are swapped for dots and back.
+func fhirID(id string) string {
+	return strings.ReplaceAll(id, "_", ".")
+}
+
+func dbID(id string) string {
+	return strings.ReplaceAll(id, ".", "_")
+}
+
+// derivedID names resources that have no row of their own, such as one
+// medication of a schedule. Two UUIDs do not fit the 64 character limit, so
+// the parts are hashed; the same parts always give the same id.
+func derivedID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "/")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func patientReference(patientID string) Reference {
+	return Reference{Reference: "Patient/" + fhirID(patientID)}
+}
+
+func medicationRequestID(scheduleID, medicationID string) string {
+	return derivedID("MedicationRequest", scheduleID, medicationID)
+}
+
+func patientResource(patient db.Patient) (*Patient, error) {
+	updated, err := parseDBTime(patient.UpdatedAt, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("patient %s updated_at: %w", patient.ID, err)
+	}
+	active := true
+	res := &Patient{
+		ResourceType: "Patient",
+		ID:           fhirID(patient.ID),
+		Meta:         &Meta{LastUpdated: formatInstant(updated)},
+		Active:       &active,
+	}
+	if tz := strings.TrimSpace(patient.Timezone); tz != "" {
+		res.Extension = []Extension{{URL: timezoneExtension, ValueCode: tz}}
+	}
+
+	name := HumanName{Use: "official", Family: strings.TrimSpace(patient.LastName)}
+	if given := strings.TrimSpace(patient.FirstName); given != "" {
+		name.Given = []string{given}
+	}
+	name.Text = strings.TrimSpace(strings.Join(append(name.Given, name.Family), " "))
+	if name.Text != "" {
+		res.Name = []HumanName{name}
+	}
+
+	if locale := strings.TrimSpace(patient.Locale); locale != "" {
+		res.Communication = []PatientCommunication{{
+			Language:  CodeableConcept{Coding: []Coding{{System: languageSystem, Code: locale}}},
+			Preferred: true,
+		}}
+	}
+	return res, nil
+}
+
+// record holds what one patient's medication resources are built from.
+type record struct {
+	patient     db.Patient
+	loc         *time.Location
+	codeSystem  string
+	schedules   []scheduleRecord
+	medications []db.Medication
+	catalog     map[string]db.MedicationCatalog
+}
+
+type scheduleRecord struct {
+	schedule db.Schedule
+	items    []db.ListScheduleItemsByScheduleRow
+	timing   *Timing
+	start    time.Time
+	// end is when the schedule stops producing doses, if it does.
+	end    *time.Time
+	status string
+}
+
+func loadRecord(ctx context.Context, queries *db.Queries, patient db.Patient, codeSystem string, now time.Time) (*record, error) {
+	rec := &record{
+		patient:    patient,
+		loc:        notifications.PatientLocation(patient.Timezone),
+		codeSystem: codeSystem,
+		catalog:    make(map[string]db.MedicationCatalog),
+	}
+
+	medications, err := queries.ListMedicationsByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list medications: %w", err)
+	}
+	rec.medications = medications
+	for _, medication := range medications {
+		if !medication.CatalogID.Valid {
+			continue
+		}
+		if _, ok := rec.catalog[medication.CatalogID.String]; ok {
+			continue
+		}
+		entry, err := queries.GetCatalogEntry(ctx, medication.CatalogID.String)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load catalog entry %s: %w", medication.CatalogID.String, err)
+		}
+		rec.catalog[entry.ID] = entry
+	}
+
+	schedules, err := queries.ListSchedulesByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		items, err := queries.ListScheduleItemsBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list schedule items for %s: %w", schedule.ID, err)
+		}
+		timing, err := scheduleTiming(schedule, rec.loc)
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseDBTime(schedule.StartDateIso, rec.loc)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s start: %w", schedule.ID, err)
+		}
+		sr := scheduleRecord{schedule: schedule, items: items, timing: timing, start: start}
+		if bounds := timing.Repeat.BoundsPeriod; bounds.End != "" {
+			end, err := time.Parse(time.RFC3339, bounds.End)
+			if err != nil {
+				return nil, err
+			}
+			sr.end = &end
+		}
+		sr.status = requestStatus(schedule, sr.end, now)
+		// An archived schedule stopped when it was archived.
+		if schedule.Status == "ARCHIVED" && sr.end == nil {
+			archived, err := parseDBTime(schedule.UpdatedAt, time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("schedule %s updated_at: %w", schedule.ID, err)
+			}
+			sr.end = &archived
+		}
+		rec.schedules = append(rec.schedules, sr)
+	}
+	return rec, nil
+}
+
+// requestStatus maps a schedule onto the MedicationRequest status codes.
+func requestStatus(schedule db.Schedule, end *time.Time, now time.Time) string {
+	if end != nil && !end.After(now) {
+		return "completed"
+	}
+	switch schedule.Status {
+	case "PAUSED":
+		return "on-hold"
+	case "ARCHIVED":
+		return "stopped"
+	default:
+		return "active"
+	}
+}
+
+// medicationConcept names a medication by its catalog entry when it has
+// one. The export goes to the care team, like the printable report, so drug
+// names are shared whatever the patient's reminder privacy setting.
+func (rec *record) medicationConcept(label string, catalogID sql.NullString) *CodeableConcept {
+	concept := &CodeableConcept{Text: strings.TrimSpace(label)}
+	if !catalogID.Valid {
+		return concept
+	}
+	entry, ok := rec.catalog[catalogID.String]
+	if !ok {
+		return concept
+	}
+	concept.Text = entry.Name
+	if rec.codeSystem != "" {
+		concept.Coding = []Coding{{System: rec.codeSystem, Code: entry.ID, Display: entry.Name}}
+	}
+	return concept
+}
+
+func (rec *record) dose(qty int64, catalogID sql.NullString) *Quantity {
+	quantity := &Quantity{Value: float64(qty)}
+	if entry, ok := rec.catalog[catalogID.String]; catalogID.Valid && ok && entry.DosageForm.Valid {
+		quantity.Unit = strings.ToLower(strings.TrimSpace(entry.DosageForm.String))
+	}
+	return quantity
+}
+
+func (rec *record) dosage(sr scheduleRecord, item db.ListScheduleItemsByScheduleRow) Dosage {
+	return Dosage{
+		Text:        sr.schedule.Title,
+		Timing:      sr.timing,
+		DoseAndRate: []DoseAndRate{{DoseQuantity: rec.dose(item.Qty, item.MedicationCatalogID)}},
+	}
+}
+
+// match is a built resource with the span the date search compares against.
+type match struct {
+	resource Resource
+	status   string
+	start    time.Time
+	end      *time.Time
+}
+
+// medicationRequests returns one MedicationRequest per medication of each
+// schedule, since a request names a single medication. Requests of the same
+// schedule share its id as their groupIdentifier.
+func (rec *record) medicationRequests() ([]match, error) {
+	var matches []match
+	for _, sr := range rec.schedules {
+		created, err := parseDBTime(sr.schedule.CreatedAt, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s created_at: %w", sr.schedule.ID, err)
+		}
+		updated, err := parseDBTime(sr.schedule.UpdatedAt, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s updated_at: %w", sr.schedule.ID, err)
+		}
+		for _, item := range sr.items {
+			res := &MedicationRequest{
+				ResourceType:              "MedicationRequest",
+				ID:                        medicationRequestID(sr.schedule.ID, item.MedicationID),
+				Meta:                      &Meta{LastUpdated: formatInstant(updated)},
+				Status:                    sr.status,
+				Intent:                    "plan",
+				MedicationCodeableConcept: rec.medicationConcept(item.MedicationLabel, item.MedicationCatalogID),
+				Subject:                   patientReference(rec.patient.ID),
+				AuthoredOn:                formatDateTime(created, rec.loc),
+				GroupIdentifier:           &Identifier{Value: sr.schedule.ID},
+				DosageInstruction:         []Dosage{rec.dosage(sr, item)},
+			}
+			matches = append(matches, match{resource: res, status: res.Status, start: sr.start, end: sr.end})
+		}
+	}
+	return matches, nil
+}
+
+// medicationAdministrations returns one MedicationAdministration per
+// medication of each TAKEN dispense event. Events do not record what was in
+// the cup, so the medications are the schedule's current ones.
+func (rec *record) medicationAdministrations(events []db.DispenseEvent) ([]match, error) {
+	schedules := make(map[string]scheduleRecord, len(rec.schedules))
+	for _, sr := range rec.schedules {
+		schedules[sr.schedule.ID] = sr
+	}
+
+	var matches []match
+	for _, event := range events {
+		if event.Status != "TAKEN" {
+			continue
+		}
+		sr, ok := schedules[event.ScheduleID]
+		if !ok {
+			continue
+		}
+		effective, err := parseDBTime(event.DueAtIso, rec.loc)
+		if err != nil {
+			return nil, fmt.Errorf("dispense event %s due_at: %w", event.ID, err)
+		}
+		if event.ActedAtIso.Valid && strings.TrimSpace(event.ActedAtIso.String) != "" {
+			if effective, err = parseDBTime(event.ActedAtIso.String, rec.loc); err != nil {
+				return nil, fmt.Errorf("dispense event %s acted_at: %w", event.ID, err)
+			}
+		}
+		created, err := parseDBTime(event.CreatedAt, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("dispense event %s created_at: %w", event.ID, err)
+		}
+		for _, item := range sr.items {
+			res := &MedicationAdministration{
+				ResourceType:              "MedicationAdministration",
+				ID:                        derivedID("MedicationAdministration", event.ID, item.MedicationID),
+				Meta:                      &Meta{LastUpdated: formatInstant(created)},
+				Status:                    "completed",
+				MedicationCodeableConcept: rec.medicationConcept(item.MedicationLabel, item.MedicationCatalogID),
+				Subject:                   patientReference(rec.patient.ID),
+				EffectiveDateTime:         formatDateTime(effective, rec.loc),
+				Request:                   &Reference{Reference: "MedicationRequest/" + medicationRequestID(sr.schedule.ID, item.MedicationID)},
+				Dosage:                    &AdministrationDosage{Dose: rec.dose(item.Qty, item.MedicationCatalogID)},
+			}
+			at := effective
+			matches = append(matches, match{resource: res, status: res.Status, start: at, end: &at})
+		}
+	}
+	return matches, nil
+}
+
+// medicationStatements returns one MedicationStatement per scheduled
+// medication, summarising every schedule it is part of. Medications that
+// are in a silo but on no schedule are left out: nothing says they are
+// taken.
+func (rec *record) medicationStatements(now time.Time) ([]match, error) {
+	var matches []match
+	for _, medication := range rec.medications {
+		var (
+			basedOn  []Reference
+			current  []Dosage
+			past     []Dosage
+			statuses = make(map[string]bool)
+			start    time.Time
+			end      *time.Time
+		)
+		for _, sr := range rec.schedules {
+			for _, item := range sr.items {
+				if item.MedicationID != medication.ID {
+					continue
+				}
+				basedOn = append(basedOn, Reference{Reference: "MedicationRequest/" + medicationRequestID(sr.schedule.ID, medication.ID)})
+				statuses[sr.status] = true
+				if start.IsZero() || sr.start.Before(start) {
+					start = sr.start
+				}
+				if sr.status == "active" || sr.status == "on-hold" {
+					current = append(current, rec.dosage(sr, item))
+				} else {
+					past = append(past, rec.dosage(sr, item))
+					if sr.end != nil && (end == nil || sr.end.After(*end)) {
+						end = sr.end
+					}
+				}
+			}
+		}
+		if len(basedOn) == 0 {
+			continue
+		}
+
+		status := "stopped"
+		switch {
+		case statuses["active"]:
+			status = "active"
+		case statuses["on-hold"]:
+			status = "on-hold"
+		case statuses["completed"] && !statuses["stopped"]:
+			status = "completed"
+		}
+		dosage := current
+		if len(current) > 0 {
+			end = nil
+		} else {
+			dosage = past
+		}
+
+		updated, err := parseDBTime(medication.UpdatedAt, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("medication %s updated_at: %w", medication.ID, err)
+		}
+		period := &Period{Start: formatDateTime(start, rec.loc)}
+		if end != nil {
+			period.End = formatDateTime(*end, rec.loc)
+		}
+		res := &MedicationStatement{
+			ResourceType:              "MedicationStatement",
+			ID:                        fhirID(medication.ID),
+			Meta:                      &Meta{LastUpdated: formatInstant(updated)},
+			BasedOn:                   basedOn,
+			Status:                    status,
+			MedicationCodeableConcept: rec.medicationConcept(medication.Label, medication.CatalogID),
+			Subject:                   patientReference(rec.patient.ID),
+			EffectivePeriod:           period,
+			DateAsserted:              formatDateTime(now, rec.loc),
+			Dosage:                    dosage,
+		}
+		matches = append(matches, match{resource: res, status: status, start: start, end: end})
+	}
+	return matches, nil
+}
+
+func parseDBTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unable to parse time %q", value)
+}
+
+// formatDateTime writes a FHIR dateTime in the patient's offset, to the
+// second.
+func formatDateTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Truncate(time.Second).Format(time.RFC3339)
+}
+
+func formatInstant(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,262 @@
+// Package fhir exposes patients, schedules and dispense history as read-only
+// FHIR R4 JSON resources so clinic EHRs can pull a patient's regimen and
+// what was actually taken, and translates MedicationRequest bundles from
+// those EHRs into dispenser schedules.
+package fhir
+
+import "encoding/json"
+
+// Version is the FHIR release the resources follow.
+const Version = "4.0.1"
+
+// ContentType is the FHIR JSON media type.
+const ContentType = "application/fhir+json; charset=utf-8"
+
+const (
+	// RxNormSystem is the default code system for catalog-linked
+	// medications; see MedicationCodeSystemFromEnv.
+	RxNormSystem = "http://www.nlm.nih.gov/research/umls/rxnorm"
+
+	timezoneExtension = "http://hl7.org/fhir/StructureDefinition/timezone"
+	languageSystem    = "urn:ietf:bcp:47"
+)
+
+// Resource is implemented by every resource type the server returns.
+type Resource interface {
+	GetResourceType() string
+	GetID() string
+	validate(v *validator)
+}
+
+type Meta struct {
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+type Extension struct {
+	URL       string `json:"url"`
+	ValueCode string `json:"valueCode,omitempty"`
+}
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type Period struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+type Quantity struct {
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+	System string  `json:"system,omitempty"`
+	Code   string  `json:"code,omitempty"`
+}
+
+type HumanName struct {
+	Use    string   `json:"use,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+
+type PatientCommunication struct {
+	Language  CodeableConcept `json:"language"`
+	Preferred bool            `json:"preferred,omitempty"`
+}
+
+type Patient struct {
+	ResourceType  string                 `json:"resourceType"`
+	ID            string                 `json:"id,omitempty"`
+	Meta          *Meta                  `json:"meta,omitempty"`
+	Extension     []Extension            `json:"extension,omitempty"`
+	Active        *bool                  `json:"active,omitempty"`
+	Name          []HumanName            `json:"name,omitempty"`
+	Communication []PatientCommunication `json:"communication,omitempty"`
+}
+
+// Duration is a Quantity of time; Code holds the UCUM unit (d, wk, mo, a,
+// h, min, s).
+type Duration = Quantity
+
+type TimingRepeat struct {
+	BoundsDuration *Duration `json:"boundsDuration,omitempty"`
+	BoundsPeriod   *Period   `json:"boundsPeriod,omitempty"`
+	Count          int       `json:"count,omitempty"`
+	Frequency      int       `json:"frequency,omitempty"`
+	FrequencyMax   int       `json:"frequencyMax,omitempty"`
+	Period         float64   `json:"period,omitempty"`
+	PeriodMax      float64   `json:"periodMax,omitempty"`
+	PeriodUnit     string    `json:"periodUnit,omitempty"`
+	DayOfWeek      []string  `json:"dayOfWeek,omitempty"`
+	TimeOfDay      []string  `json:"timeOfDay,omitempty"`
+	When           []string  `json:"when,omitempty"`
+	Offset         int       `json:"offset,omitempty"`
+}
+
+type Timing struct {
+	Event  []string         `json:"event,omitempty"`
+	Repeat *TimingRepeat    `json:"repeat,omitempty"`
+	Code   *CodeableConcept `json:"code,omitempty"`
+}
+
+type Range struct {
+	Low  *Quantity `json:"low,omitempty"`
+	High *Quantity `json:"high,omitempty"`
+}
+
+type DoseAndRate struct {
+	DoseRange    *Range    `json:"doseRange,omitempty"`
+	DoseQuantity *Quantity `json:"doseQuantity,omitempty"`
+}
+
+type Dosage struct {
+	Sequence        int           `json:"sequence,omitempty"`
+	Text            string        `json:"text,omitempty"`
+	Timing          *Timing       `json:"timing,omitempty"`
+	AsNeededBoolean bool          `json:"asNeededBoolean,omitempty"`
+	DoseAndRate     []DoseAndRate `json:"doseAndRate,omitempty"`
+}
+
+type MedicationRequest struct {
+	ResourceType              string           `json:"resourceType"`
+	ID                        string           `json:"id,omitempty"`
+	Meta                      *Meta            `json:"meta,omitempty"`
+	Status                    string           `json:"status"`
+	Intent                    string           `json:"intent"`
+	MedicationCodeableConcept *CodeableConcept `json:"medicationCodeableConcept,omitempty"`
+	MedicationReference       *Reference       `json:"medicationReference,omitempty"`
+	Subject                   Reference        `json:"subject"`
+	AuthoredOn                string           `json:"authoredOn,omitempty"`
+	GroupIdentifier           *Identifier      `json:"groupIdentifier,omitempty"`
+	DosageInstruction         []Dosage         `json:"dosageInstruction,omitempty"`
+}
+
+type AdministrationDosage struct {
+	Text string    `json:"text,omitempty"`
+	Dose *Quantity `json:"dose,omitempty"`
+}
+
+type MedicationAdministration struct {
+	ResourceType              string                `json:"resourceType"`
+	ID                        string                `json:"id,omitempty"`
+	Meta                      *Meta                 `json:"meta,omitempty"`
+	Status                    string                `json:"status"`
+	MedicationCodeableConcept *CodeableConcept      `json:"medicationCodeableConcept,omitempty"`
+	Subject                   Reference             `json:"subject"`
+	EffectiveDateTime         string                `json:"effectiveDateTime,omitempty"`
+	Request                   *Reference            `json:"request,omitempty"`
+	Dosage                    *AdministrationDosage `json:"dosage,omitempty"`
+}
+
+type MedicationStatement struct {
+	ResourceType              string           `json:"resourceType"`
+	ID                        string           `json:"id,omitempty"`
+	Meta                      *Meta            `json:"meta,omitempty"`
+	BasedOn                   []Reference      `json:"basedOn,omitempty"`
+	Status                    string           `json:"status"`
+	MedicationCodeableConcept *CodeableConcept `json:"medicationCodeableConcept,omitempty"`
+	Subject                   Reference        `json:"subject"`
+	EffectivePeriod           *Period          `json:"effectivePeriod,omitempty"`
+	DateAsserted              string           `json:"dateAsserted,omitempty"`
+	Dosage                    []Dosage         `json:"dosage,omitempty"`
+}
+
+type BundleLink struct {
+	Relation string `json:"relation"`
+	URL      string `json:"url"`
+}
+
+type BundleEntrySearch struct {
+	Mode string `json:"mode,omitempty"`
+}
+
+type BundleEntry struct {
+	FullURL  string             `json:"fullUrl,omitempty"`
+	Resource json.RawMessage    `json:"resource,omitempty"`
+	Search   *BundleEntrySearch `json:"search,omitempty"`
+}
+
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	ID           string        `json:"id,omitempty"`
+	Meta         *Meta         `json:"meta,omitempty"`
+	Type         string        `json:"type"`
+	Total        *int          `json:"total,omitempty"`
+	Link         []BundleLink  `json:"link,omitempty"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+type OperationOutcomeIssue struct {
+	Severity    string `json:"severity"`
+	Code        string `json:"code"`
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
+
+type OperationOutcome struct {
+	ResourceType string                  `json:"resourceType"`
+	Issue        []OperationOutcomeIssue `json:"issue"`
+}
+
+type CapabilitySearchParam struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+type CapabilityInteraction struct {
+	Code string `json:"code"`
+}
+
+type CapabilityResource struct {
+	Type        string                  `json:"type"`
+	Interaction []CapabilityInteraction `json:"interaction"`
+	SearchParam []CapabilitySearchParam `json:"searchParam,omitempty"`
+}
+
+type CapabilityRest struct {
+	Mode     string               `json:"mode"`
+	Resource []CapabilityResource `json:"resource"`
+}
+
+type CapabilityStatement struct {
+	ResourceType string           `json:"resourceType"`
+	Status       string           `json:"status"`
+	Date         string           `json:"date"`
+	Kind         string           `json:"kind"`
+	FHIRVersion  string           `json:"fhirVersion"`
+	Format       []string         `json:"format"`
+	Rest         []CapabilityRest `json:"rest"`
+}
+
+func (p *Patient) GetResourceType() string                  { return "Patient" }
+func (p *Patient) GetID() string                            { return p.ID }
+func (m *MedicationRequest) GetResourceType() string        { return "MedicationRequest" }
+func (m *MedicationRequest) GetID() string                  { return m.ID }
+func (m *MedicationAdministration) GetResourceType() string { return "MedicationAdministration" }
+func (m *MedicationAdministration) GetID() string           { return m.ID }
+func (m *MedicationStatement) GetResourceType() string      { return "MedicationStatement" }
+func (m *MedicationStatement) GetID() string                { return m.ID }
+func (c *CapabilityStatement) GetResourceType() string      { return "CapabilityStatement" }
+func (c *CapabilityStatement) GetID() string                { return "" }
+func (o *OperationOutcome) GetResourceType() string         { return "OperationOutcome" }
+func (o *OperationOutcome) GetID() string                   { return "" }
+func (b *Bundle) GetResourceType() string                   { return "Bundle" }
+func (b *Bundle) GetID() string                             { return b.ID }
@@ -0,0 +1,163 @@
+package fhir
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// dateRange is the span left after applying every date parameter of a
+// search; a zero bound is open.
+type dateRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// parseDateParams applies each date parameter in turn, so
+// date=ge2026-01-01&date=lt2026-02-01 is January. A value is the whole span
+// of its precision (2026-01 is the month) and values without an offset are
+// read in loc, the patient's timezone. The prefixes narrow the span: eq
+// (the default) to the value, ge/gt to from its start/end, le/lt to until
+// its end/start; sa and eb are read as gt and lt.
+func parseDateParams(values []string, loc *time.Location) (dateRange, error) {
+	var r dateRange
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, ",") {
+			return dateRange{}, fmt.Errorf("date does not support several values in one parameter")
+		}
+		prefix := "eq"
+		if len(value) > 2 && value[0] >= 'a' && value[0] <= 'z' {
+			prefix, value = value[:2], value[2:]
+		}
+		from, to, err := parseDateValue(value, loc)
+		if err != nil {
+			return dateRange{}, err
+		}
+		switch prefix {
+		case "eq":
+			r.narrow(from, to)
+		case "ge":
+			r.narrow(from, time.Time{})
+		case "gt", "sa":
+			r.narrow(to, time.Time{})
+		case "le":
+			r.narrow(time.Time{}, to)
+		case "lt", "eb":
+			r.narrow(time.Time{}, from)
+		default:
+			return dateRange{}, fmt.Errorf("date prefix %q is not supported", prefix)
+		}
+	}
+	return r, nil
+}
+
+func (r *dateRange) narrow(start, end time.Time) {
+	if !start.IsZero() && (r.start.IsZero() || start.After(r.start)) {
+		r.start = start
+	}
+	if !end.IsZero() && (r.end.IsZero() || end.Before(r.end)) {
+		r.end = end
+	}
+}
+
+// overlaps reports whether [start, end) meets the range; a nil end is still
+// ongoing.
+func (r dateRange) overlaps(start time.Time, end *time.Time) bool {
+	if !r.end.IsZero() && !start.Before(r.end) {
+		return false
+	}
+	if !r.start.IsZero() && end != nil {
+		// A point in time is a zero length span that still counts at the
+		// range's start.
+		if end.Equal(start) {
+			return !start.Before(r.start)
+		}
+		return end.After(r.start)
+	}
+	return true
+}
+
+// parseDateValue returns the span a FHIR date or dateTime covers.
+func parseDateValue(value string, loc *time.Location) (time.Time, time.Time, error) {
+	layouts := []struct {
+		layout string
+		span   func(time.Time) time.Time
+	}{
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+		{"2006-01-02T15:04", func(t time.Time) time.Time { return t.Add(time.Minute) }},
+		{"2006-01-02T15:04:05", func(t time.Time) time.Time { return t.Add(time.Second) }},
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, t.Add(time.Second), nil
+	}
+	for _, candidate := range layouts {
+		if t, err := time.ParseInLocation(candidate.layout, value, loc); err == nil {
+			return t, candidate.span(t), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("date %q is not a FHIR date or dateTime", value)
+}
+
+// referenceID reads a patient search value: a bare id, Patient/id or an
+// absolute URL ending in Patient/id.
+func referenceID(value, resourceType string) (string, error) {
+	value = strings.TrimRight(strings.TrimSpace(value), "/")
+	if value == "" {
+		return "", fmt.Errorf("empty reference")
+	}
+	parts := strings.Split(value, "/")
+	if len(parts) == 1 {
+		return dbID(value), nil
+	}
+	if parts[len(parts)-2] != resourceType {
+		return "", fmt.Errorf("reference %q is not a %s", value, resourceType)
+	}
+	return dbID(parts[len(parts)-1]), nil
+}
+
+// tokenSet collects comma separated token values (OR) from every repeat of
+// a parameter.
+func tokenSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, value := range values {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				set[token] = true
+			}
+		}
+	}
+	return set
+}
+
+// checkParams rejects parameters the resource does not support rather than
+// silently returning everything. Result parameters such as _count and
+// _format are accepted and ignored.
+func checkParams(query url.Values, supported ...string) error {
+	for name := range query {
+		base, _, _ := strings.Cut(name, ":")
+		if strings.HasPrefix(base, "_") && base != "_id" {
+			continue
+		}
+		known := false
+		for _, candidate := range supported {
+			if base == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("search parameter %q is not supported", name)
+		}
+		if base != name {
+			return fmt.Errorf("search modifier on %q is not supported", name)
+		}
+	}
+	return nil
+}
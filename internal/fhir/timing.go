@@ -0,0 +1,122 @@
+package fhir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+
+	"pillbox/internal/db"
+)
+
+var weekdayCodes = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// scheduleTiming describes a schedule's recurrence as a FHIR Timing. Times
+// of day are local to the patient, like the dispenser's own expansion. The
+// original rule is kept in code.text because Timing cannot express every
+// RRULE (BYSETPOS, nth weekdays of a month).
+func scheduleTiming(schedule db.Schedule, loc *time.Location) (*Timing, error) {
+	start, err := parseDBTime(schedule.StartDateIso, loc)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s start: %w", schedule.ID, err)
+	}
+	start = start.In(loc)
+
+	rule := strings.TrimSpace(schedule.Rrule)
+	if strings.HasPrefix(strings.ToUpper(rule), "RRULE:") {
+		rule = rule[6:]
+	}
+	opt, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s rule: %w", schedule.ID, err)
+	}
+
+	bounds := &Period{Start: formatDateTime(start, loc)}
+	var end *time.Time
+	if schedule.EndDateIso.Valid && strings.TrimSpace(schedule.EndDateIso.String) != "" {
+		parsed, err := parseDBTime(schedule.EndDateIso.String, loc)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s end: %w", schedule.ID, err)
+		}
+		end = &parsed
+	}
+	if !opt.Until.IsZero() && (end == nil || opt.Until.Before(*end)) {
+		until := opt.Until
+		end = &until
+	}
+	if end != nil {
+		bounds.End = formatDateTime(*end, loc)
+	}
+
+	repeat := &TimingRepeat{
+		BoundsPeriod: bounds,
+		Count:        opt.Count,
+		Period:       float64(max(opt.Interval, 1)),
+	}
+
+	var days []string
+	for _, day := range opt.Byweekday {
+		days = append(days, weekdayCodes[day.Day()])
+	}
+
+	switch opt.Freq {
+	case rrule.SECONDLY, rrule.MINUTELY, rrule.HOURLY:
+		repeat.Frequency = 1
+		repeat.PeriodUnit = map[rrule.Frequency]string{rrule.SECONDLY: "s", rrule.MINUTELY: "min", rrule.HOURLY: "h"}[opt.Freq]
+		repeat.DayOfWeek = days
+	default:
+		repeat.TimeOfDay = timesOfDay(opt, start)
+		perDay := len(repeat.TimeOfDay)
+		switch opt.Freq {
+		case rrule.DAILY:
+			repeat.PeriodUnit = "d"
+			repeat.Frequency = perDay
+			repeat.DayOfWeek = days
+		case rrule.WEEKLY:
+			if len(days) == 0 {
+				days = []string{weekdayCodes[(int(start.Weekday())+6)%7]}
+			}
+			repeat.PeriodUnit = "wk"
+			repeat.Frequency = perDay * len(days)
+			repeat.DayOfWeek = days
+		case rrule.MONTHLY:
+			repeat.PeriodUnit = "mo"
+			repeat.Frequency = perDay * max(len(opt.Bymonthday), len(opt.Byweekday), 1)
+		case rrule.YEARLY:
+			repeat.PeriodUnit = "a"
+			repeat.Frequency = perDay * max(len(opt.Bymonth), 1)
+		}
+	}
+
+	return &Timing{
+		Repeat: repeat,
+		Code:   &CodeableConcept{Text: "RRULE:" + rule},
+	}, nil
+}
+
+// timesOfDay lists the rule's BYHOUR/BYMINUTE/BYSECOND combinations, each
+// part defaulting to the start time's, as FHIR times.
+func timesOfDay(opt *rrule.ROption, start time.Time) []string {
+	hours, minutes, seconds := opt.Byhour, opt.Byminute, opt.Bysecond
+	if len(hours) == 0 {
+		hours = []int{start.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{start.Minute()}
+	}
+	if len(seconds) == 0 {
+		seconds = []int{start.Second()}
+	}
+	var times []string
+	for _, h := range hours {
+		for _, m := range minutes {
+			for _, s := range seconds {
+				times = append(times, fmt.Sprintf("%02d:%02d:%02d", h, m, s))
+			}
+		}
+	}
+	sort.Strings(times)
+	return times
+}
@@ -0,0 +1,110 @@
+package fhir
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teambition/rrule-go"
+
+	"pillbox/internal/db"
+)
+
+// expand lists a rule's occurrences in [from, to], starting it at dtstart
+// in dtstart's location the way the dispenser does.
+func expand(t *testing.T, rule string, dtstart time.Time, end *time.Time, from, to time.Time) []time.Time {
+	t.Helper()
+	opt, err := rrule.StrToROption(strings.TrimPrefix(rule, "RRULE:"))
+	if err != nil {
+		t.Fatalf("parse %s: %v", rule, err)
+	}
+	opt.Dtstart = dtstart
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		t.Fatalf("build %s: %v", rule, err)
+	}
+	var out []time.Time
+	for _, occurrence := range r.Between(from, to, true) {
+		if end == nil || !occurrence.After(*end) {
+			out = append(out, occurrence)
+		}
+	}
+	return out
+}
+
+func TestScheduleTimingRoundTrip(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three weeks from just before the spring DST change.
+	now := time.Date(2026, 3, 5, 10, 17, 42, 0, ny)
+	windowEnd := now.AddDate(0, 0, 21)
+
+	tests := []struct {
+		name  string
+		rrule string
+		start string
+		end   string
+	}{
+		{name: "daily at the start time", rrule: "RRULE:FREQ=DAILY", start: "2025-01-01T08:00:00Z"},
+		{name: "mixed-minute times", rrule: "RRULE:FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0,30", start: "2025-01-01T13:00:00Z"},
+		{name: "hours without minutes", rrule: "RRULE:FREQ=DAILY;BYHOUR=7,19", start: "2026-01-01T12:30:00Z"},
+		{name: "weekly on named days", rrule: "RRULE:FREQ=WEEKLY;BYDAY=MO,TH;BYHOUR=9;BYMINUTE=15", start: "2026-01-05T14:15:00Z"},
+		{name: "weekly on the start day", rrule: "RRULE:FREQ=WEEKLY", start: "2026-01-07T13:00:00Z"},
+		{name: "every other day", rrule: "RRULE:FREQ=DAILY;INTERVAL=2", start: "2026-01-02T13:00:00Z"},
+		{name: "monthly on days", rrule: "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,15", start: "2026-01-01T13:45:00Z"},
+		{name: "every six hours", rrule: "RRULE:FREQ=HOURLY;INTERVAL=6", start: "2026-01-01T05:00:00Z"},
+		{name: "with an end date", rrule: "RRULE:FREQ=DAILY;BYHOUR=21;BYMINUTE=0", start: "2026-01-01T00:00:00Z", end: "2026-03-15T00:00:00Z"},
+		{name: "with until", rrule: "RRULE:FREQ=DAILY;UNTIL=20260318T120000Z", start: "2026-01-01T13:00:00Z"},
+		{name: "with count", rrule: "RRULE:FREQ=DAILY;COUNT=70", start: "2026-01-01T13:00:00Z"},
+		{name: "not started yet", rrule: "RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0", start: "2026-03-10T13:00:00Z"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := db.Schedule{ID: "sched_test", Rrule: tc.rrule, StartDateIso: tc.start}
+			if tc.end != "" {
+				schedule.EndDateIso = sql.NullString{String: tc.end, Valid: true}
+			}
+			timing, err := scheduleTiming(schedule, ny)
+			if err != nil {
+				t.Fatalf("scheduleTiming: %v", err)
+			}
+
+			start, err := parseDBTime(tc.start, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var end *time.Time
+			if tc.end != "" {
+				parsed, err := parseDBTime(tc.end, ny)
+				if err != nil {
+					t.Fatal(err)
+				}
+				end = &parsed
+			}
+			want := expand(t, tc.rrule, start.In(ny), end, now, windowEnd)
+
+			rules, _, err := TimingRules(*timing, ny, now)
+			if err != nil {
+				t.Fatalf("TimingRules: %v", err)
+			}
+			var got []time.Time
+			for _, rule := range rules {
+				got = append(got, expand(t, rule.RRule, rule.Start, rule.End, now, windowEnd)...)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].Before(got[j]) })
+
+			if len(got) != len(want) {
+				t.Fatalf("round trip gives %d doses, want %d\n got %v\nwant %v", len(got), len(want), got, want)
+			}
+			for i := range want {
+				if !got[i].Equal(want[i]) {
+					t.Fatalf("dose %d = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
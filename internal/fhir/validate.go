@@ -0,0 +1,411 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Patterns from the R4 primitive type definitions.
+var (
+	idPattern       = regexp.MustCompile(`^[A-Za-z0-9\-\.]{1,64}$`)
+	dateTimePattern = regexp.MustCompile(`^([0-9]([0-9]([0-9][1-9]|[1-9]0)|[1-9]00)|[1-9]000)(-(0[1-9]|1[0-2])(-(0[1-9]|[1-2][0-9]|3[0-1])(T([01][0-9]|2[0-3]):[0-5][0-9]:([0-5][0-9]|60)(\.[0-9]+)?(Z|(\+|-)((0[0-9]|1[0-3]):[0-5][0-9]|14:00)))?)?)?$`)
+	instantPattern  = regexp.MustCompile(`^([0-9]([0-9]([0-9][1-9]|[1-9]0)|[1-9]00)|[1-9]000)-(0[1-9]|1[0-2])-(0[1-9]|[1-2][0-9]|3[0-1])T([01][0-9]|2[0-3]):[0-5][0-9]:([0-5][0-9]|60)(\.[0-9]+)?(Z|(\+|-)((0[0-9]|1[0-3]):[0-5][0-9]|14:00))$`)
+	timePattern     = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]:([0-5][0-9]|60)(\.[0-9]+)?$`)
+)
+
+// Required value sets used by the resources served here.
+var (
+	medicationRequestStatuses        = []string{"active", "on-hold", "cancelled", "completed", "entered-in-error", "stopped", "draft", "unknown"}
+	medicationRequestIntents         = []string{"proposal", "plan", "order", "original-order", "reflex-order", "filler-order", "instance-order", "option"}
+	medicationAdministrationStatuses = []string{"in-progress", "not-done", "on-hold", "completed", "entered-in-error", "stopped", "unknown"}
+	medicationStatementStatuses      = []string{"active", "completed", "entered-in-error", "intended", "stopped", "on-hold", "unknown", "not-taken"}
+	periodUnits                      = []string{"s", "min", "h", "d", "wk", "mo", "a"}
+	daysOfWeek                       = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	eventTimings                     = []string{
+		"MORN", "MORN.early", "MORN.late", "NOON", "AFT", "AFT.early", "AFT.late",
+		"EVE", "EVE.early", "EVE.late", "NIGHT", "PHS", "HS", "WAKE",
+		"C", "CM", "CD", "CV", "AC", "ACM", "ACD", "ACV", "PC", "PCM", "PCD", "PCV",
+	}
+	bundleTypes   = []string{"document", "message", "transaction", "transaction-response", "batch", "batch-response", "history", "searchset", "collection"}
+	issueSeverity = []string{"fatal", "error", "warning", "information"}
+)
+
+// ValidationError lists every rule a resource breaks, each prefixed with the
+// path of the offending element.
+type ValidationError struct {
+	ResourceType string
+	Issues       []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.ResourceType, strings.Join(e.Issues, "; "))
+}
+
+// Validate checks a resource against the R4 JSON structure: required
+// elements and value sets, primitive formats, the invariants that apply to
+// the elements used here, and the JSON rules (no nulls and no empty strings,
+// arrays or objects).
+func Validate(res Resource) error {
+	v := &validator{}
+	res.validate(v)
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", res.GetResourceType(), err)
+	}
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("decode %s: %w", res.GetResourceType(), err)
+	}
+	if doc["resourceType"] != res.GetResourceType() {
+		v.failf("resourceType", "must be %q", res.GetResourceType())
+	}
+	checkJSON(v, "", doc)
+
+	if len(v.issues) > 0 {
+		return &ValidationError{ResourceType: res.GetResourceType(), Issues: v.issues}
+	}
+	return nil
+}
+
+// checkJSON applies the FHIR JSON rules that hold for every element.
+func checkJSON(v *validator, path string, value any) {
+	switch val := value.(type) {
+	case nil:
+		v.failf(path, "must be omitted rather than null")
+	case string:
+		if strings.TrimSpace(val) == "" {
+			v.failf(path, "must not be empty")
+		}
+	case []any:
+		if len(val) == 0 {
+			v.failf(path, "must be omitted rather than empty")
+		}
+		for i, item := range val {
+			checkJSON(v, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	case map[string]any:
+		if len(val) == 0 {
+			v.failf(path, "must be omitted rather than empty")
+		}
+		for key, item := range val {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			checkJSON(v, child, item)
+		}
+	}
+}
+
+type validator struct {
+	issues []string
+}
+
+func (v *validator) failf(path, format string, args ...any) {
+	v.issues = append(v.issues, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(path, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.failf(path, "is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) code(path, value string, allowed []string) {
+	if !v.required(path, value) {
+		return
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.failf(path, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
+
+func (v *validator) id(path, value string) {
+	if value != "" && !idPattern.MatchString(value) {
+		v.failf(path, "%q is not a valid id", value)
+	}
+}
+
+func (v *validator) meta(path string, meta *Meta) {
+	if meta != nil && meta.LastUpdated != "" && !instantPattern.MatchString(meta.LastUpdated) {
+		v.failf(path+".lastUpdated", "%q is not a valid instant", meta.LastUpdated)
+	}
+}
+
+func (v *validator) dateTime(path, value string) {
+	if value != "" && !dateTimePattern.MatchString(value) {
+		v.failf(path, "%q is not a valid dateTime", value)
+	}
+}
+
+func (v *validator) period(path string, period *Period) {
+	if period == nil {
+		return
+	}
+	v.dateTime(path+".start", period.Start)
+	v.dateTime(path+".end", period.End)
+	// per-1: start shall have a lower value than end. Comparing needs full
+	// precision, which is all this server writes.
+	if period.Start != "" && period.End != "" {
+		start, errStart := time.Parse(time.RFC3339, period.Start)
+		end, errEnd := time.Parse(time.RFC3339, period.End)
+		if errStart == nil && errEnd == nil && end.Before(start) {
+			v.failf(path, "start must not be after end")
+		}
+	}
+}
+
+func (v *validator) reference(path string, ref *Reference, types ...string) {
+	if ref == nil {
+		return
+	}
+	if !v.required(path+".reference", ref.Reference) {
+		return
+	}
+	// Relative references are Type/id; absolute URLs end the same way.
+	parts := strings.Split(strings.TrimRight(ref.Reference, "/"), "/")
+	if len(parts) < 2 {
+		v.failf(path+".reference", "%q is not a Type/id reference", ref.Reference)
+		return
+	}
+	kind, id := parts[len(parts)-2], parts[len(parts)-1]
+	if !idPattern.MatchString(id) {
+		v.failf(path+".reference", "%q does not end in a valid id", ref.Reference)
+	}
+	for _, allowed := range types {
+		if kind == allowed {
+			return
+		}
+	}
+	v.failf(path+".reference", "must refer to %s", strings.Join(types, " or "))
+}
+
+func (v *validator) concept(path string, concept *CodeableConcept) {
+	if concept == nil {
+		v.failf(path, "is required")
+		return
+	}
+	if len(concept.Coding) == 0 && strings.TrimSpace(concept.Text) == "" {
+		v.failf(path, "needs a coding or text")
+	}
+	for i, coding := range concept.Coding {
+		codingPath := fmt.Sprintf("%s.coding[%d]", path, i)
+		if coding.Code != "" && coding.System == "" {
+			v.failf(codingPath+".system", "is required with a code")
+		}
+		if strings.ContainsAny(coding.System, " \t\n") {
+			v.failf(codingPath+".system", "%q is not a uri", coding.System)
+		}
+	}
+}
+
+func (v *validator) quantity(path string, quantity *Quantity) {
+	if quantity == nil {
+		return
+	}
+	if math.IsNaN(quantity.Value) || math.IsInf(quantity.Value, 0) {
+		v.failf(path+".value", "must be a number")
+	}
+	// qty-3: if a code for the unit is present, the system SHALL also be
+	// present.
+	if quantity.Code != "" && quantity.System == "" {
+		v.failf(path+".system", "is required with a code")
+	}
+}
+
+func (v *validator) timing(path string, timing *Timing) {
+	if timing == nil {
+		return
+	}
+	for i, event := range timing.Event {
+		v.dateTime(fmt.Sprintf("%s.event[%d]", path, i), event)
+	}
+	if timing.Code != nil {
+		v.concept(path+".code", timing.Code)
+	}
+	repeat := timing.Repeat
+	if repeat == nil {
+		return
+	}
+	path += ".repeat"
+	v.quantity(path+".boundsDuration", repeat.BoundsDuration)
+	v.period(path+".boundsPeriod", repeat.BoundsPeriod)
+	if repeat.Count < 0 {
+		v.failf(path+".count", "must be positive")
+	}
+	if repeat.Frequency < 0 {
+		v.failf(path+".frequency", "must be positive")
+	}
+	// tim-5: period SHALL be a non-negative value; tim-1/2: a period needs
+	// units.
+	if repeat.Period < 0 {
+		v.failf(path+".period", "must not be negative")
+	}
+	if repeat.Period > 0 {
+		v.code(path+".periodUnit", repeat.PeriodUnit, periodUnits)
+	} else if repeat.PeriodUnit != "" {
+		v.failf(path+".period", "is required with periodUnit")
+	}
+	for i, day := range repeat.DayOfWeek {
+		v.code(fmt.Sprintf("%s.dayOfWeek[%d]", path, i), day, daysOfWeek)
+	}
+	for i, value := range repeat.TimeOfDay {
+		if !timePattern.MatchString(value) {
+			v.failf(fmt.Sprintf("%s.timeOfDay[%d]", path, i), "%q is not a valid time", value)
+		}
+	}
+	for i, when := range repeat.When {
+		v.code(fmt.Sprintf("%s.when[%d]", path, i), when, eventTimings)
+	}
+	// tim-9: an offset needs a when that is not a meal itself; tim-10: a
+	// timeOfDay excludes when.
+	if repeat.Offset != 0 {
+		if len(repeat.When) == 0 {
+			v.failf(path+".offset", "requires when")
+		}
+		for _, when := range repeat.When {
+			if when == "C" || when == "CM" || when == "CD" || when == "CV" {
+				v.failf(path+".offset", "is not allowed with when %s", when)
+			}
+		}
+	}
+	if len(repeat.TimeOfDay) > 0 && len(repeat.When) > 0 {
+		v.failf(path, "timeOfDay and when are mutually exclusive")
+	}
+}
+
+func (v *validator) dosage(path string, dosage Dosage) {
+	v.timing(path+".timing", dosage.Timing)
+	for i, doseAndRate := range dosage.DoseAndRate {
+		itemPath := fmt.Sprintf("%s.doseAndRate[%d]", path, i)
+		if doseAndRate.DoseQuantity != nil && doseAndRate.DoseRange != nil {
+			v.failf(itemPath, "dose[x] takes one of doseQuantity and doseRange")
+		}
+		v.quantity(itemPath+".doseQuantity", doseAndRate.DoseQuantity)
+		if doseAndRate.DoseRange != nil {
+			v.quantity(itemPath+".doseRange.low", doseAndRate.DoseRange.Low)
+			v.quantity(itemPath+".doseRange.high", doseAndRate.DoseRange.High)
+		}
+	}
+}
+
+func (p *Patient) validate(v *validator) {
+	v.id("id", p.ID)
+	v.meta("meta", p.Meta)
+	for i, ext := range p.Extension {
+		v.required(fmt.Sprintf("extension[%d].url", i), ext.URL)
+	}
+	for i, communication := range p.Communication {
+		v.concept(fmt.Sprintf("communication[%d].language", i), &communication.Language)
+	}
+}
+
+func (m *MedicationRequest) validate(v *validator) {
+	v.id("id", m.ID)
+	v.meta("meta", m.Meta)
+	v.code("status", m.Status, medicationRequestStatuses)
+	v.code("intent", m.Intent, medicationRequestIntents)
+	switch {
+	case m.MedicationReference != nil && m.MedicationCodeableConcept != nil:
+		v.failf("medication[x]", "takes one of medicationCodeableConcept and medicationReference")
+	case m.MedicationReference != nil:
+		v.reference("medicationReference", m.MedicationReference, "Medication")
+	default:
+		v.concept("medicationCodeableConcept", m.MedicationCodeableConcept)
+	}
+	v.reference("subject", &m.Subject, "Patient", "Group")
+	v.dateTime("authoredOn", m.AuthoredOn)
+	for i, dosage := range m.DosageInstruction {
+		v.dosage(fmt.Sprintf("dosageInstruction[%d]", i), dosage)
+	}
+}
+
+func (m *MedicationAdministration) validate(v *validator) {
+	v.id("id", m.ID)
+	v.meta("meta", m.Meta)
+	v.code("status", m.Status, medicationAdministrationStatuses)
+	v.concept("medicationCodeableConcept", m.MedicationCodeableConcept)
+	v.reference("subject", &m.Subject, "Patient", "Group")
+	if v.required("effectiveDateTime", m.EffectiveDateTime) {
+		v.dateTime("effectiveDateTime", m.EffectiveDateTime)
+	}
+	v.reference("request", m.Request, "MedicationRequest")
+	// mad-1: dosage SHALL have at least one of dosage.dose or dosage.rate[x].
+	if m.Dosage != nil {
+		if m.Dosage.Dose == nil {
+			v.failf("dosage.dose", "is required")
+		}
+		v.quantity("dosage.dose", m.Dosage.Dose)
+	}
+}
+
+func (m *MedicationStatement) validate(v *validator) {
+	v.id("id", m.ID)
+	v.meta("meta", m.Meta)
+	for i, ref := range m.BasedOn {
+		v.reference(fmt.Sprintf("basedOn[%d]", i), &ref, "MedicationRequest", "CarePlan", "ServiceRequest")
+	}
+	v.code("status", m.Status, medicationStatementStatuses)
+	v.concept("medicationCodeableConcept", m.MedicationCodeableConcept)
+	v.reference("subject", &m.Subject, "Patient", "Group")
+	v.period("effectivePeriod", m.EffectivePeriod)
+	v.dateTime("dateAsserted", m.DateAsserted)
+	for i, dosage := range m.Dosage {
+		v.dosage(fmt.Sprintf("dosage[%d]", i), dosage)
+	}
+}
+
+func (b *Bundle) validate(v *validator) {
+	v.id("id", b.ID)
+	v.meta("meta", b.Meta)
+	v.code("type", b.Type, bundleTypes)
+	// bdl-1: total only when a search or history.
+	if b.Total != nil && b.Type != "searchset" && b.Type != "history" {
+		v.failf("total", "is only allowed on searchset and history bundles")
+	}
+	seen := make(map[string]bool, len(b.Entry))
+	for i, entry := range b.Entry {
+		path := fmt.Sprintf("entry[%d]", i)
+		// bdl-7: FullUrl must be unique in a bundle.
+		if entry.FullURL != "" {
+			if seen[entry.FullURL] {
+				v.failf(path+".fullUrl", "%q is repeated", entry.FullURL)
+			}
+			seen[entry.FullURL] = true
+		}
+		// bdl-2: entry.search only when a search.
+		if entry.Search != nil && b.Type != "searchset" {
+			v.failf(path+".search", "is only allowed on searchset bundles")
+		}
+	}
+}
+
+func (o *OperationOutcome) validate(v *validator) {
+	if len(o.Issue) == 0 {
+		v.failf("issue", "is required")
+	}
+	for i, issue := range o.Issue {
+		v.code(fmt.Sprintf("issue[%d].severity", i), issue.Severity, issueSeverity)
+		v.required(fmt.Sprintf("issue[%d].code", i), issue.Code)
+	}
+}
+
+func (c *CapabilityStatement) validate(v *validator) {
+	v.code("status", c.Status, []string{"draft", "active", "retired", "unknown"})
+	v.dateTime("date", c.Date)
+	v.code("kind", c.Kind, []string{"instance", "capability", "requirements"})
+	v.required("fhirVersion", c.FHIRVersion)
+}
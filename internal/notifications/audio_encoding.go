@@ -0,0 +1,185 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MimeWAV  = "audio/wav"
+	MimeOpus = "audio/ogg"
+	MimeMP3  = "audio/mpeg"
+
+	transcodeTimeout = 60 * time.Second
+)
+
+// audioEncoding describes a format the device may download a clip in.
+type audioEncoding struct {
+	Name      string
+	MimeType  string
+	Extension string
+	// ffmpegArgs are the output options used to produce it from a WAV.
+	ffmpegArgs []string
+}
+
+var audioEncodings = []audioEncoding{
+	{Name: "wav", MimeType: MimeWAV, Extension: ".wav"},
+	{Name: "opus", MimeType: MimeOpus, Extension: ".ogg", ffmpegArgs: []string{"-c:a", "libopus", "-b:a", "24k"}},
+	{Name: "mp3", MimeType: MimeMP3, Extension: ".mp3", ffmpegArgs: []string{"-c:a", "libmp3lame", "-b:a", "32k"}},
+}
+
+// mimeAliases maps non-canonical types clients send to the canonical one.
+var mimeAliases = map[string]string{
+	"audio/x-wav":    MimeWAV,
+	"audio/wave":     MimeWAV,
+	"audio/vnd.wave": MimeWAV,
+	"audio/opus":     MimeOpus,
+	"audio/mp3":      MimeMP3,
+}
+
+func encodingByName(name string) (audioEncoding, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, enc := range audioEncodings {
+		if enc.Name == name {
+			return enc, true
+		}
+	}
+	return audioEncoding{}, false
+}
+
+func canonicalMime(mime string) string {
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	if alias, ok := mimeAliases[mime]; ok {
+		return alias
+	}
+	return mime
+}
+
+// AudioExtraEncodings reads AUDIO_EXTRA_ENCODINGS, a comma-separated list of
+// formats ("opus", "mp3") to transcode each reminder into besides WAV.
+func AudioExtraEncodings() []audioEncoding {
+	var result []audioEncoding
+	for _, name := range strings.Split(os.Getenv("AUDIO_EXTRA_ENCODINGS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.EqualFold(name, "wav") {
+			continue
+		}
+		enc, ok := encodingByName(name)
+		if !ok {
+			log.Printf("audio: unknown encoding %q in AUDIO_EXTRA_ENCODINGS", name)
+			continue
+		}
+		result = append(result, enc)
+	}
+	return result
+}
+
+// negotiateAudio picks the available MIME type the Accept header prefers.
+// available is in server preference order, which breaks ties. An empty
+// Accept header gets the first type.
+func negotiateAudio(accept string, available []string) (string, bool) {
+	if len(available) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return available[0], true
+	}
+
+	type mediaRange struct {
+		mime string
+		q    float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mr := mediaRange{mime: canonicalMime(fields[0]), q: 1}
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					mr.q = q
+				}
+			}
+		}
+		if mr.mime != "" {
+			ranges = append(ranges, mr)
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, mime := range available {
+		// The most specific matching range decides the quality.
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			var s int
+			switch {
+			case mr.mime == mime:
+				s = 2
+			case mr.mime == "audio/*":
+				s = 1
+			case mr.mime == "*/*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mime, q
+		}
+	}
+	return best, best != ""
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// runFFmpeg converts src into dst using the given output options. The binary
+// comes from FFMPEG_PATH, defaulting to ffmpeg on PATH.
+func runFFmpeg(ctx context.Context, src, dst string, outputArgs ...string) error {
+	command := strings.TrimSpace(os.Getenv("FFMPEG_PATH"))
+	if command == "" {
+		command = "ffmpeg"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, transcodeTimeout)
+	defer cancel()
+
+	args := append([]string{"-hide_banner", "-loglevel", "error", "-y", "-i", src}, outputArgs...)
+	args = append(args, dst)
+	cmd := exec.CommandContext(ctx, command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
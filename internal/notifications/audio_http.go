@@ -1,28 +1,29 @@
 package notifications
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
-)
 
-type AudioHTTPHandler struct{}
+	"pillbox/internal/db"
+)
 
-func NewAudioHTTPHandler() *AudioHTTPHandler {
-	return &AudioHTTPHandler{}
+type AudioHTTPHandler struct {
+	queries *db.Queries
 }
 
-type pendingAudioFile struct {
-	Filename     string
-	ScheduleID   string
-	DueAtUTC     string
-	DueAtTime    time.Time
-	AbsolutePath string
+func NewAudioHTTPHandler(queries *db.Queries) *AudioHTTPHandler {
+	return &AudioHTTPHandler{
+		queries: queries,
+	}
 }
 
 func (h *AudioHTTPHandler) HandleNextAudio(w http.ResponseWriter, r *http.Request) {
@@ -39,30 +40,61 @@ func (h *AudioHTTPHandler) HandleNextAudio(w http.ResponseWriter, r *http.Reques
 	}
 
 	patientID := parts[1]
-	files, err := listPendingAudio(patientID)
+	next, err := h.nextPendingMessage(r.Context(), patientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"message":    "No pending audio",
+			"patient_id": patientID,
+		})
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]any{
 			"error": err.Error(),
 		})
 		return
 	}
-	if len(files) == 0 {
-		writeJSON(w, http.StatusNotFound, map[string]any{
-			"message":    "No pending audio",
-			"patient_id": patientID,
+
+	variants, err := h.audioVariants(r.Context(), next)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": err.Error(),
 		})
 		return
 	}
 
-	next := files[0]
+	audioURL := "/audio/" + patientID + "/" + next.ID
+	encodings := make([]map[string]any, 0, len(variants))
+	for _, v := range variants {
+		encodings = append(encodings, map[string]any{
+			"mime_type":  v.MimeType,
+			"size_bytes": v.SizeBytes,
+			"sha256":     v.Sha256,
+			"url":        audioURL + "?format=" + v.Format,
+		})
+	}
+	manifest := map[string]any{
+		"mime_type":  variants[0].MimeType,
+		"size_bytes": variants[0].SizeBytes,
+		"sha256":     variants[0].Sha256,
+		"channels":   1,
+		"encodings":  encodings,
+	}
+	if durationMs, sampleRate, ok := wavDetails(next); ok {
+		manifest["duration_ms"] = durationMs
+		manifest["sample_rate_hz"] = sampleRate
+	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
+		"id":          next.ID,
 		"patient_id":  patientID,
 		"schedule_id": next.ScheduleID,
-		"due_at_utc":  next.DueAtUTC,
-		"filename":    next.Filename,
-		"audio_url":   "/audio/" + patientID + "/" + next.Filename,
-		"ack_url":     "/patients/" + patientID + "/ack/" + next.Filename,
+		"due_at_utc":  next.DueAtIso,
+		"expires_at":  next.ExpiresAt,
+		"filename":    filepath.Base(next.FilePath),
+		"audio_url":   audioURL,
+		"ack_url":     "/patients/" + patientID + "/ack/" + next.ID,
+		"manifest":    manifest,
 	})
 }
 
@@ -72,32 +104,62 @@ func (h *AudioHTTPHandler) HandleServeAudio(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// /audio/{patientID}/{filename}
+	// /audio/{patientID}/{messageID}
 	parts := splitPath(r.URL.Path)
 	if len(parts) != 3 || parts[0] != "audio" {
 		http.NotFound(w, r)
 		return
 	}
 
-	patientID := parts[1]
-	filename := filepath.Base(parts[2])
+	message, ok := h.loadMessage(w, r, parts[1], parts[2])
+	if !ok {
+		return
+	}
 
-	fullPath := filepath.Join(AudioBaseDir(), patientID, filename)
-	if !isSafeUnderBase(fullPath, filepath.Join(AudioBaseDir(), patientID)) {
+	variants, err := h.audioVariants(r.Context(), message)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	variant, ok := selectVariant(w, r, variants)
+	if !ok {
+		return
+	}
+	if !isSafeUnderBase(variant.FilePath, AudioBaseDir()) {
 		writeJSON(w, http.StatusBadRequest, map[string]any{
 			"error": "invalid file path",
 		})
 		return
 	}
 
-	info, err := os.Stat(fullPath)
+	f, err := os.Open(variant.FilePath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
 	if err != nil || info.IsDir() {
 		http.NotFound(w, r)
 		return
 	}
 
-	w.Header().Set("Content-Type", "audio/wav")
-	http.ServeFile(w, r, fullPath)
+	if err := h.queries.MarkAudioMessageDelivered(r.Context(), db.MarkAudioMessageDeliveredParams{
+		DeliveredAt: sql.NullString{String: formatDBTime(time.Now()), Valid: true},
+		ID:          message.ID,
+	}); err != nil {
+		log.Printf("audio: mark %s delivered: %v", message.ID, err)
+	}
+
+	// ServeContent handles Range, If-Range and If-None-Match against the
+	// checksum ETag, so the device can resume interrupted downloads.
+	w.Header().Set("Content-Type", variant.MimeType)
+	w.Header().Set("ETag", `"`+variant.Sha256+`"`)
+	w.Header().Set("Vary", "Accept")
+	http.ServeContent(w, r, "", info.ModTime(), f)
 }
 
 func (h *AudioHTTPHandler) HandleAckAudio(w http.ResponseWriter, r *http.Request) {
@@ -106,123 +168,194 @@ func (h *AudioHTTPHandler) HandleAckAudio(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// /patients/{patientID}/ack/{filename}
+	// /patients/{patientID}/ack/{messageID}
 	parts := splitPath(r.URL.Path)
 	if len(parts) != 4 || parts[0] != "patients" || parts[2] != "ack" {
 		http.NotFound(w, r)
 		return
 	}
 
-	patientID := parts[1]
-	filename := filepath.Base(parts[3])
-
-	src := filepath.Join(AudioBaseDir(), patientID, filename)
-	patientDir := filepath.Join(AudioBaseDir(), patientID)
-	if !isSafeUnderBase(src, patientDir) {
-		writeJSON(w, http.StatusBadRequest, map[string]any{
-			"error": "invalid file path",
-		})
+	message, ok := h.loadMessage(w, r, parts[1], parts[3])
+	if !ok {
 		return
 	}
 
-	info, err := os.Stat(src)
-	if err != nil || info.IsDir() {
-		writeJSON(w, http.StatusNotFound, map[string]any{
-			"error": "audio file not found",
-		})
-		return
-	}
-
-	if err := os.Remove(src); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]any{
-			"error": err.Error(),
-		})
-		return
+	if !message.AckedAt.Valid {
+		if err := h.queries.MarkAudioMessageAcked(r.Context(), db.MarkAudioMessageAckedParams{
+			AckedAt: sql.NullString{String: formatDBTime(time.Now()), Valid: true},
+			ID:      message.ID,
+		}); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]any{
+				"error": err.Error(),
+			})
+			return
+		}
 	}
 
+	// The row stays as the audit record; the janitor removes the WAV.
 	writeJSON(w, http.StatusOK, map[string]any{
-		"message":    "Acknowledged and deleted",
-		"patient_id": patientID,
-		"filename":   filename,
+		"message":    "Acknowledged",
+		"id":         message.ID,
+		"patient_id": message.PatientID,
+		"filename":   filepath.Base(message.FilePath),
 	})
 }
 
-func listPendingAudio(patientID string) ([]pendingAudioFile, error) {
-	patientDir := filepath.Join(AudioBaseDir(), patientID)
+// audioVariant is one downloadable encoding of an audio message.
+type audioVariant struct {
+	Format    string
+	MimeType  string
+	FilePath  string
+	SizeBytes int64
+	Sha256    string
+}
 
-	entries, err := os.ReadDir(patientDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []pendingAudioFile{}, nil
+// audioVariants lists the message's encodings, primary file first.
+func (h *AudioHTTPHandler) audioVariants(ctx context.Context, message db.AudioMessage) ([]audioVariant, error) {
+	checksum := message.Sha256.String
+	if !message.Sha256.Valid {
+		// Clips queued before checksums were recorded.
+		sum, err := fileSHA256(message.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("checksum %s: %w", message.ID, err)
 		}
-		return nil, err
+		checksum = sum
 	}
 
-	var files []pendingAudioFile
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
+	variants := []audioVariant{{
+		Format:    formatForMime(message.MimeType),
+		MimeType:  message.MimeType,
+		FilePath:  message.FilePath,
+		SizeBytes: message.SizeBytes,
+		Sha256:    checksum,
+	}}
 
-		name := entry.Name()
-		if !strings.HasSuffix(strings.ToLower(name), ".wav") {
-			continue
-		}
+	rows, err := h.queries.ListAudioMessageEncodings(ctx, message.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list encodings for %s: %w", message.ID, err)
+	}
+	for _, row := range rows {
+		variants = append(variants, audioVariant{
+			Format:    formatForMime(row.MimeType),
+			MimeType:  row.MimeType,
+			FilePath:  row.FilePath,
+			SizeBytes: row.SizeBytes,
+			Sha256:    row.Sha256,
+		})
+	}
+	return variants, nil
+}
 
-		scheduleID, dueAtUTC, dueAtTime, err := parseAudioFilename(name)
-		if err != nil {
-			continue
-		}
+// selectVariant honours an explicit ?format= and otherwise negotiates on the
+// Accept header, writing a 400 or 406 response when nothing fits.
+func selectVariant(w http.ResponseWriter, r *http.Request, variants []audioVariant) (audioVariant, bool) {
+	available := make([]string, 0, len(variants))
+	for _, v := range variants {
+		available = append(available, v.MimeType)
+	}
 
-		files = append(files, pendingAudioFile{
-			Filename:     name,
-			ScheduleID:   scheduleID,
-			DueAtUTC:     dueAtUTC,
-			DueAtTime:    dueAtTime,
-			AbsolutePath: filepath.Join(patientDir, name),
-		})
+	var mime string
+	if format := r.URL.Query().Get("format"); format != "" {
+		enc, ok := encodingByName(format)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error": "unknown format " + format,
+			})
+			return audioVariant{}, false
+		}
+		mime = enc.MimeType
+	} else if negotiated, ok := negotiateAudio(r.Header.Get("Accept"), available); ok {
+		mime = negotiated
 	}
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].DueAtTime.After(files[j].DueAtTime)
+	for _, v := range variants {
+		if v.MimeType == mime {
+			return v, true
+		}
+	}
+	writeJSON(w, http.StatusNotAcceptable, map[string]any{
+		"error":     "no acceptable audio encoding",
+		"available": available,
 	})
-
-	return files, nil
+	return audioVariant{}, false
 }
 
-func parseAudioFilename(filename string) (scheduleID string, dueAtUTC string, dueAtTime time.Time, err error) {
-	if !strings.HasSuffix(strings.ToLower(filename), ".wav") {
-		return "", "", time.Time{}, errors.New("not wav")
+func formatForMime(mime string) string {
+	for _, enc := range audioEncodings {
+		if enc.MimeType == mime {
+			return enc.Name
+		}
 	}
+	return ""
+}
 
-	stem := strings.TrimSuffix(filename, ".wav")
-	idx := strings.Index(stem, "_")
-	if idx <= 0 || idx >= len(stem)-1 {
-		return "", "", time.Time{}, errors.New("invalid format")
+// wavDetails returns the clip's duration and sample rate, decoding the WAV
+// for clips queued before they were recorded.
+func wavDetails(message db.AudioMessage) (durationMs, sampleRate int64, ok bool) {
+	if message.DurationMs.Valid && message.SampleRateHz.Valid {
+		return message.DurationMs.Int64, message.SampleRateHz.Int64, true
 	}
+	raw, err := os.ReadFile(message.FilePath)
+	if err != nil {
+		return 0, 0, false
+	}
+	pcm, err := decodeWAV(raw)
+	if err != nil || pcm.sampleRate == 0 {
+		return 0, 0, false
+	}
+	return int64(len(pcm.samples)) * 1000 / int64(pcm.sampleRate), int64(pcm.sampleRate), true
+}
 
-	scheduleID = stem[:idx]
-	dueAtRaw := stem[idx+1:]
+// nextPendingMessage picks the clip to play according to AudioQueueMode.
+// Clips whose occurrence was already taken, skipped or missed are never
+// returned.
+func (h *AudioHTTPHandler) nextPendingMessage(ctx context.Context, patientID string) (db.AudioMessage, error) {
+	now := formatDBTime(time.Now())
 
-	parts := strings.SplitN(dueAtRaw, "T", 2)
-	if len(parts) != 2 {
-		return "", "", time.Time{}, errors.New("invalid due at")
+	if AudioQueueMode() == AudioQueueOldest {
+		return h.queries.GetOldestPendingAudioMessage(ctx, db.GetOldestPendingAudioMessageParams{
+			PatientID: patientID,
+			ExpiresAt: now,
+		})
 	}
 
-	datePart := parts[0]
-	timePart := strings.TrimSuffix(parts[1], "Z")
-	timeFields := strings.Split(timePart, "-")
-	if len(timeFields) != 3 {
-		return "", "", time.Time{}, errors.New("invalid due at time")
+	latest, err := h.queries.GetLatestPendingAudioMessage(ctx, db.GetLatestPendingAudioMessageParams{
+		PatientID: patientID,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		return db.AudioMessage{}, err
+	}
+	if err := h.queries.ExpireSupersededAudioMessages(ctx, db.ExpireSupersededAudioMessagesParams{
+		ExpiredAt: sql.NullString{String: now, Valid: true},
+		PatientID: patientID,
+		DueAtIso:  latest.DueAtIso,
+	}); err != nil {
+		log.Printf("audio: expire superseded clips for %s: %v", patientID, err)
 	}
+	return latest, nil
+}
 
-	dueAtUTC = datePart + "T" + timeFields[0] + ":" + timeFields[1] + ":" + timeFields[2] + "Z"
-	dueAtTime, err = time.Parse(time.RFC3339, dueAtUTC)
+// loadMessage looks up an audio message for the patient, writing a 404 or
+// 500 response when it cannot.
+func (h *AudioHTTPHandler) loadMessage(w http.ResponseWriter, r *http.Request, patientID, messageID string) (db.AudioMessage, bool) {
+	message, err := h.queries.GetAudioMessage(r.Context(), db.GetAudioMessageParams{
+		ID:        messageID,
+		PatientID: patientID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error": "audio message not found",
+		})
+		return db.AudioMessage{}, false
+	}
 	if err != nil {
-		return "", "", time.Time{}, err
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": err.Error(),
+		})
+		return db.AudioMessage{}, false
 	}
-
-	return scheduleID, dueAtUTC, dueAtTime, nil
+	return message, true
 }
 
 func splitPath(path string) []string {
@@ -0,0 +1,200 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/internal/db"
+)
+
+const (
+	audioJanitorInterval = 5 * time.Minute
+	// orphanGracePeriod leaves freshly written files alone while
+	// QueueReminderAudio is still inserting their row.
+	orphanGracePeriod = 10 * time.Minute
+)
+
+// AudioJanitor expires queued clips whose occurrence was resolved or is too
+// old, removes the files of acknowledged and expired clips, and deletes clip
+// files in the patient queue directories that no queued clip refers to.
+type AudioJanitor struct {
+	queries *db.Queries
+}
+
+func NewAudioJanitor(queries *db.Queries) *AudioJanitor {
+	return &AudioJanitor{
+		queries: queries,
+	}
+}
+
+func (j *AudioJanitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(audioJanitorInterval)
+	defer ticker.Stop()
+
+	j.runOnce(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce(ctx)
+		}
+	}
+}
+
+func (j *AudioJanitor) runOnce(ctx context.Context) {
+	now := sql.NullString{String: formatDBTime(time.Now()), Valid: true}
+
+	if n, err := j.queries.ExpireResolvedAudioMessages(ctx, now); err != nil {
+		log.Printf("audio janitor: expire resolved clips: %v", err)
+	} else if n > 0 {
+		log.Printf("audio janitor: expired %d clips for resolved doses", n)
+	}
+
+	if n, err := j.queries.ExpireStaleAudioMessages(ctx, now); err != nil {
+		log.Printf("audio janitor: expire stale clips: %v", err)
+	} else if n > 0 {
+		log.Printf("audio janitor: expired %d stale clips", n)
+	}
+
+	j.purgeInactive(ctx, now)
+	j.removeOrphans(ctx)
+}
+
+func (j *AudioJanitor) purgeInactive(ctx context.Context, now sql.NullString) {
+	messages, err := j.queries.ListPurgeableAudioMessages(ctx)
+	if err != nil {
+		log.Printf("audio janitor: list purgeable clips: %v", err)
+		return
+	}
+
+	for _, message := range messages {
+		paths := []string{message.FilePath}
+		encodings, err := j.queries.ListAudioMessageEncodings(ctx, message.ID)
+		if err != nil {
+			log.Printf("audio janitor: list encodings for %s: %v", message.ID, err)
+			continue
+		}
+		for _, enc := range encodings {
+			paths = append(paths, enc.FilePath)
+		}
+
+		removed := true
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("audio janitor: remove %s: %v", path, err)
+				removed = false
+			}
+		}
+		if !removed {
+			continue
+		}
+		if err := j.queries.MarkAudioMessagePurged(ctx, db.MarkAudioMessagePurgedParams{
+			PurgedAt: now,
+			ID:       message.ID,
+		}); err != nil {
+			log.Printf("audio janitor: mark %s purged: %v", message.ID, err)
+		}
+	}
+}
+
+// removeOrphans deletes queued clip files that no live audio message refers
+// to. Only the per-patient queue directories QueueReminderAudio writes to are
+// scanned, and only files named like its output (<uuid>.wav and the
+// transcodes beside it), so nothing else under AUDIO_BASE_DIR is touched.
+func (j *AudioJanitor) removeOrphans(ctx context.Context) {
+	paths, err := j.queries.ListLiveAudioMessagePaths(ctx)
+	if err != nil {
+		log.Printf("audio janitor: list live clips: %v", err)
+		return
+	}
+	live := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		live[absPath(path)] = true
+	}
+
+	patients, err := j.queries.ListPatients(ctx)
+	if err != nil {
+		log.Printf("audio janitor: list patients: %v", err)
+		return
+	}
+
+	// The TTS cache and caregiver recordings are tracked in their own tables
+	// and may be configured to live inside a queue directory.
+	skipDirs := map[string]bool{absPath(TTSCacheDir()): true, absPath(VoiceMessageDir()): true}
+	cutoff := time.Now().Add(-orphanGracePeriod)
+	base := AudioBaseDir()
+
+	for _, patient := range patients {
+		dir := filepath.Join(base, patient.ID)
+		if filepath.Base(dir) != patient.ID || skipDirs[absPath(dir)] {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("audio janitor: scan %s: %v", dir, err)
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !isQueuedClipName(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if live[absPath(path)] {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				log.Printf("audio janitor: remove orphan %s: %v", path, err)
+				continue
+			}
+			log.Printf("audio janitor: removed orphan %s", path)
+		}
+	}
+}
+
+// isQueuedClipName reports whether name is a clip file QueueReminderAudio
+// could have written: a UUID followed by an audio extension.
+func isQueuedClipName(name string) bool {
+	if !isAudioFile(name) {
+		return false
+	}
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	id, err := uuid.Parse(stem)
+	return err == nil && id.String() == stem
+}
+
+// absPath makes path absolute so paths stored relative to the working
+// directory compare equal to absolute ones.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func isAudioFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, enc := range audioEncodings {
+		if enc.Extension == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/internal/dbtest"
+)
+
+func TestRemoveOrphansOnlyTouchesQueuedClips(t *testing.T) {
+	ctx := context.Background()
+	_, queries := dbtest.Open(t)
+
+	// A relative AUDIO_BASE_DIR and absolute VOICE_MESSAGE_DIR must still be
+	// recognised as the same tree.
+	root := t.TempDir()
+	t.Chdir(root)
+	t.Setenv("AUDIO_BASE_DIR", "audio")
+	t.Setenv("AUDIO_EXTRA_ENCODINGS", "")
+	t.Setenv("TTS_CACHE_DIR", "")
+	t.Setenv("VOICE_MESSAGE_DIR", filepath.Join(root, "audio", "patient_demo_002"))
+
+	queued, err := QueueReminderAudio(ctx, queries, "patient_demo_001", "sched_demo_morning", time.Now(), wavWithRate(8000, 800))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	write := func(path string, modTime time.Time) string {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("audio"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	if err := os.Chtimes(queued.FilePath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	orphan := uuid.NewString()
+	removed := []string{
+		write(filepath.Join("audio", "patient_demo_001", orphan+".wav"), old),
+		write(filepath.Join("audio", "patient_demo_001", orphan+".mp3"), old),
+	}
+	kept := []string{
+		queued.FilePath,
+		write(filepath.Join("audio", "patient_demo_001", uuid.NewString()+".wav"), time.Now()),
+		write(filepath.Join("audio", "patient_demo_001", "intro.wav"), old),
+		write(filepath.Join("audio", "patient_demo_001", uuid.NewString()+".txt"), old),
+		write(filepath.Join("audio", "patient_demo_001", "nested", uuid.NewString()+".wav"), old),
+		write(filepath.Join("audio", uuid.NewString()+".wav"), old),
+		write(filepath.Join("audio", "tts-cache", uuid.NewString()+".wav"), old),
+		write(filepath.Join("audio", "unknown_patient", uuid.NewString()+".wav"), old),
+		write(filepath.Join("audio", "patient_demo_002", uuid.NewString()+".wav"), old),
+	}
+
+	NewAudioJanitor(queries).removeOrphans(ctx)
+
+	for _, path := range removed {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", path)
+		}
+	}
+	for _, path := range kept {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was removed: %v", path, err)
+		}
+	}
+}
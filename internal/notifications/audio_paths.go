@@ -1,9 +1,21 @@
 package notifications
 
 import (
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+)
+
+const (
+	// AudioQueueOldest plays every pending clip, oldest due time first.
+	AudioQueueOldest = "oldest"
+	// AudioQueueCurrent plays only the newest pending clip and expires the
+	// older ones it supersedes.
+	AudioQueueCurrent = "current"
+
+	defaultAudioMessageTTL = 6 * time.Hour
 )
 
 func AudioBaseDir() string {
@@ -12,4 +24,33 @@ func AudioBaseDir() string {
 		base = "generated_audio"
 	}
 	return filepath.Clean(base)
-}
\ No newline at end of file
+}
+
+// AudioQueueMode reads AUDIO_QUEUE_MODE, defaulting to AudioQueueOldest.
+func AudioQueueMode() string {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("AUDIO_QUEUE_MODE")))
+	switch mode {
+	case AudioQueueOldest, AudioQueueCurrent:
+		return mode
+	case "":
+		return AudioQueueOldest
+	default:
+		log.Printf("audio: unknown AUDIO_QUEUE_MODE %q, using %s", mode, AudioQueueOldest)
+		return AudioQueueOldest
+	}
+}
+
+// AudioMessageTTL reads AUDIO_MESSAGE_TTL (a Go duration such as "90m"): how
+// long after its due time a reminder may still be played.
+func AudioMessageTTL() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("AUDIO_MESSAGE_TTL"))
+	if raw == "" {
+		return defaultAudioMessageTTL
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl <= 0 {
+		log.Printf("audio: invalid AUDIO_MESSAGE_TTL %q, using %s", raw, defaultAudioMessageTTL)
+		return defaultAudioMessageTTL
+	}
+	return ttl
+}
@@ -1,29 +1,90 @@
 package notifications
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/internal/db"
 )
 
-func SaveReminderWAV(patientID, scheduleID, dueAt string, audio []byte) (string, error) {
+// QueueReminderAudio stores the WAV for a reminder occurrence and queues it
+// for the patient's device, transcoding it into any AUDIO_EXTRA_ENCODINGS.
+func QueueReminderAudio(ctx context.Context, queries *db.Queries, patientID, scheduleID string, dueAt time.Time, audio []byte) (db.AudioMessage, error) {
 	dir := filepath.Join(AudioBaseDir(), patientID)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", err
+		return db.AudioMessage{}, err
 	}
 
-	filename := fmt.Sprintf("%s_%s.wav", scheduleID, sanitizeFilePart(dueAt))
-	fullPath := filepath.Join(dir, filename)
-
+	id := uuid.NewString()
+	fullPath := filepath.Join(dir, id+".wav")
 	if err := os.WriteFile(fullPath, audio, 0o644); err != nil {
-		return "", err
+		return db.AudioMessage{}, err
+	}
+
+	params := db.CreateAudioMessageParams{
+		ID:         id,
+		PatientID:  patientID,
+		ScheduleID: scheduleID,
+		DueAtIso:   formatDBTime(dueAt),
+		FilePath:   fullPath,
+		MimeType:   MimeWAV,
+		SizeBytes:  int64(len(audio)),
+		ExpiresAt:  formatDBTime(dueAt.Add(AudioMessageTTL())),
+		Sha256:     sql.NullString{String: sha256Hex(audio), Valid: true},
+	}
+	if pcm, err := decodeWAV(audio); err == nil && pcm.sampleRate > 0 {
+		params.SampleRateHz = sql.NullInt64{Int64: int64(pcm.sampleRate), Valid: true}
+		params.DurationMs = sql.NullInt64{Int64: int64(len(pcm.samples)) * 1000 / int64(pcm.sampleRate), Valid: true}
+	}
+
+	message, err := queries.CreateAudioMessage(ctx, params)
+	if err != nil {
+		os.Remove(fullPath)
+		return db.AudioMessage{}, fmt.Errorf("create audio message: %w", err)
 	}
 
-	return fullPath, nil
+	for _, enc := range AudioExtraEncodings() {
+		if err := addAudioEncoding(ctx, queries, message, enc); err != nil {
+			// The WAV is always available, so a failed transcode is not fatal.
+			log.Printf("audio: %s encoding for %s: %v", enc.Name, message.ID, err)
+		}
+	}
+	return message, nil
 }
 
-func sanitizeFilePart(s string) string {
-	replacer := strings.NewReplacer(":", "-", "/", "-", "\\", "-", " ", "_")
-	return replacer.Replace(s)
-}
\ No newline at end of file
+func addAudioEncoding(ctx context.Context, queries *db.Queries, message db.AudioMessage, enc audioEncoding) error {
+	dst := strings.TrimSuffix(message.FilePath, filepath.Ext(message.FilePath)) + enc.Extension
+	if err := runFFmpeg(ctx, message.FilePath, dst, enc.ffmpegArgs...); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil {
+		return err
+	}
+	checksum, err := fileSHA256(dst)
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	if err := queries.CreateAudioMessageEncoding(ctx, db.CreateAudioMessageEncodingParams{
+		MessageID: message.ID,
+		MimeType:  enc.MimeType,
+		FilePath:  dst,
+		SizeBytes: info.Size(),
+		Sha256:    checksum,
+	}); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("record encoding: %w", err)
+	}
+	return nil
+}
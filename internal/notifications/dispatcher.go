@@ -0,0 +1,295 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/internal/db"
+)
+
+const (
+	DispatchSent       = "SENT"
+	DispatchQueued     = "QUEUED"
+	DispatchSuppressed = "SUPPRESSED"
+)
+
+// Dispatcher delivers caregiver SMS notifications according to the
+// recipient's notification preferences, holding back non-urgent messages in
+// the outbox during quiet hours or until the daily digest.
+type Dispatcher struct {
+	queries *db.Queries
+	sender  *TwilioSender
+}
+
+// Notification is a single message addressed to a user about a patient.
+type Notification struct {
+	UserID      string
+	PatientID   string
+	Type        string
+	Destination string
+	Message     string
+	// Timezone is the recipient's timezone, used to evaluate quiet hours.
+	Timezone string
+}
+
+// DispatchResult describes what happened to a dispatched notification.
+type DispatchResult struct {
+	Status            string
+	ProviderMessageID string
+	DeliverAfter      time.Time
+	// OutboxID identifies the outbox entry of a queued notification.
+	OutboxID string
+}
+
+// NewDispatcher creates a dispatcher. A nil sender is created from the
+// environment on the first immediate send, so callers that only defer
+// messages do not need Twilio credentials.
+func NewDispatcher(queries *db.Queries, sender *TwilioSender) *Dispatcher {
+	return &Dispatcher{
+		queries: queries,
+		sender:  sender,
+	}
+}
+
+func (d *Dispatcher) smsSender() (*TwilioSender, error) {
+	if d.sender != nil {
+		return d.sender, nil
+	}
+	sender, err := NewTwilioSenderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	d.sender = sender
+	return sender, nil
+}
+
+// Dispatch sends the notification now, queues it for later, or drops it,
+// depending on the user's preference for its type on the SMS channel.
+func (d *Dispatcher) Dispatch(ctx context.Context, n Notification) (DispatchResult, error) {
+	pref, err := LoadPreference(ctx, d.queries, n.UserID, n.Type, ChannelSMS)
+	if err != nil {
+		return DispatchResult{}, fmt.Errorf("load notification preference: %w", err)
+	}
+
+	deliverAt, enabled := pref.DeliverAt(n.Type, time.Now(), PatientLocation(n.Timezone))
+	if !enabled {
+		return DispatchResult{Status: DispatchSuppressed}, nil
+	}
+
+	if deliverAt.IsZero() {
+		sender, err := d.smsSender()
+		if err != nil {
+			return DispatchResult{}, fmt.Errorf("init sms sender: %w", err)
+		}
+		providerID, err := sender.SendSMS(ctx, n.Destination, n.Message)
+		if err != nil {
+			return DispatchResult{}, err
+		}
+		return DispatchResult{Status: DispatchSent, ProviderMessageID: providerID}, nil
+	}
+
+	outboxID, err := d.enqueue(ctx, n, deliverAt, pref.DeliveryMode == DeliveryDailyDigest)
+	if err != nil {
+		return DispatchResult{}, err
+	}
+
+	return DispatchResult{Status: DispatchQueued, DeliverAfter: deliverAt, OutboxID: outboxID}, nil
+}
+
+// Enqueue applies the user's preference like Dispatch but writes even
+// immediate notifications to the outbox instead of sending them. Callers use
+// it inside a transaction and deliver the entries with SendQueued once it
+// commits; anything left behind goes out with the next FlushOutbox.
+func (d *Dispatcher) Enqueue(ctx context.Context, n Notification) (DispatchResult, error) {
+	pref, err := LoadPreference(ctx, d.queries, n.UserID, n.Type, ChannelSMS)
+	if err != nil {
+		return DispatchResult{}, fmt.Errorf("load notification preference: %w", err)
+	}
+
+	now := time.Now()
+	deliverAt, enabled := pref.DeliverAt(n.Type, now, PatientLocation(n.Timezone))
+	if !enabled {
+		return DispatchResult{Status: DispatchSuppressed}, nil
+	}
+
+	digest := !deliverAt.IsZero() && pref.DeliveryMode == DeliveryDailyDigest
+	if deliverAt.IsZero() {
+		deliverAt = now
+	}
+	outboxID, err := d.enqueue(ctx, n, deliverAt, digest)
+	if err != nil {
+		return DispatchResult{}, err
+	}
+
+	return DispatchResult{Status: DispatchQueued, DeliverAfter: deliverAt, OutboxID: outboxID}, nil
+}
+
+// SendQueued delivers the given outbox entries that are already due and not
+// yet claimed by FlushOutbox.
+func (d *Dispatcher) SendQueued(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	sender, err := d.smsSender()
+	if err != nil {
+		return fmt.Errorf("init sms sender: %w", err)
+	}
+
+	now := formatDBTime(time.Now())
+	for _, id := range ids {
+		entry, err := d.queries.GetOutboxNotification(ctx, id)
+		if err != nil {
+			return fmt.Errorf("load outbox notification %s: %w", id, err)
+		}
+		if entry.Digest != 0 || entry.DeliverAfter > now {
+			continue
+		}
+		if claimed, err := d.claim(ctx, entry.ID); err != nil || !claimed {
+			continue
+		}
+
+		providerID, sendErr := sender.SendSMS(ctx, entry.Destination, entry.Message)
+		d.markOutbox(ctx, []db.NotificationOutbox{entry}, providerID, sendErr)
+	}
+	return nil
+}
+
+// claim marks a queued entry as being sent so that concurrent flushes do not
+// deliver it twice.
+func (d *Dispatcher) claim(ctx context.Context, id string) (bool, error) {
+	n, err := d.queries.ClaimOutboxNotification(ctx, id)
+	if err != nil {
+		log.Printf("notification outbox: claim %s: %v", id, err)
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Schedule queues a notification for delivery at the given time, e.g. a
+// snoozed reminder. The user's enabled flag is checked again when it is sent.
+func (d *Dispatcher) Schedule(ctx context.Context, n Notification, at time.Time) error {
+	_, err := d.enqueue(ctx, n, at, false)
+	return err
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, n Notification, at time.Time, digest bool) (string, error) {
+	digestFlag := int64(0)
+	if digest {
+		digestFlag = 1
+	}
+	id := uuid.NewString()
+	if _, err := d.queries.EnqueueNotification(ctx, db.EnqueueNotificationParams{
+		ID:               id,
+		UserID:           n.UserID,
+		PatientID:        n.PatientID,
+		NotificationType: n.Type,
+		Channel:          ChannelSMS,
+		Destination:      n.Destination,
+		Message:          n.Message,
+		Digest:           digestFlag,
+		DeliverAfter:     formatDBTime(at),
+	}); err != nil {
+		return "", fmt.Errorf("enqueue notification: %w", err)
+	}
+	return id, nil
+}
+
+// FlushOutbox delivers queued notifications whose delivery time has passed.
+// Digest entries for the same recipient are merged into a single message.
+func (d *Dispatcher) FlushOutbox(ctx context.Context) error {
+	due, err := d.queries.ListDueOutboxNotifications(ctx, formatDBTime(time.Now()))
+	if err != nil {
+		return fmt.Errorf("list due outbox notifications: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	sender, err := d.smsSender()
+	if err != nil {
+		return fmt.Errorf("init sms sender: %w", err)
+	}
+
+	digests := make(map[string][]db.NotificationOutbox)
+	var digestOrder []string
+
+	for _, entry := range due {
+		pref, err := LoadPreference(ctx, d.queries, entry.UserID, entry.NotificationType, entry.Channel)
+		if err != nil {
+			log.Printf("notification outbox: load preference for %s: %v", entry.ID, err)
+			continue
+		}
+		if !pref.Enabled {
+			if err := d.queries.CancelOutboxNotification(ctx, entry.ID); err != nil {
+				log.Printf("notification outbox: cancel %s: %v", entry.ID, err)
+			}
+			continue
+		}
+		if claimed, err := d.claim(ctx, entry.ID); err != nil || !claimed {
+			continue
+		}
+
+		if entry.Digest != 0 {
+			key := entry.UserID + "|" + entry.Destination
+			if _, ok := digests[key]; !ok {
+				digestOrder = append(digestOrder, key)
+			}
+			digests[key] = append(digests[key], entry)
+			continue
+		}
+
+		providerID, sendErr := sender.SendSMS(ctx, entry.Destination, entry.Message)
+		d.markOutbox(ctx, []db.NotificationOutbox{entry}, providerID, sendErr)
+	}
+
+	for _, key := range digestOrder {
+		entries := digests[key]
+
+		locale := DefaultLocale
+		if user, err := d.queries.GetUser(ctx, entries[0].UserID); err == nil {
+			locale = user.Locale
+		}
+		header, err := RenderMessage(locale, TypeDigest, entries[0].Channel, MessageData{})
+		if err != nil {
+			d.markOutbox(ctx, entries, "", fmt.Errorf("render digest header: %w", err))
+			continue
+		}
+
+		lines := make([]string, 0, len(entries)+1)
+		lines = append(lines, header)
+		for _, entry := range entries {
+			lines = append(lines, "- "+entry.Message)
+		}
+
+		providerID, sendErr := sender.SendSMS(ctx, entries[0].Destination, strings.Join(lines, "\n"))
+		d.markOutbox(ctx, entries, providerID, sendErr)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) markOutbox(ctx context.Context, entries []db.NotificationOutbox, providerID string, sendErr error) {
+	for _, entry := range entries {
+		var err error
+		if sendErr != nil {
+			log.Printf("notification outbox: send %s failed: %v", entry.ID, sendErr)
+			err = d.queries.MarkOutboxNotificationFailed(ctx, db.MarkOutboxNotificationFailedParams{
+				ErrorMessage: nullableString(sendErr.Error()),
+				ID:           entry.ID,
+			})
+		} else {
+			err = d.queries.MarkOutboxNotificationSent(ctx, db.MarkOutboxNotificationSentParams{
+				ProviderMessageID: nullableString(providerID),
+				ID:                entry.ID,
+			})
+		}
+		if err != nil {
+			log.Printf("notification outbox: update %s: %v", entry.ID, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pillbox/internal/dbtest"
+)
+
+// fakeTwilio records the messages a TwilioSender posts and accepts each one.
+type fakeTwilio struct {
+	mu   sync.Mutex
+	sent []url.Values
+}
+
+func (f *fakeTwilio) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.sent = append(f.sent, form)
+	sid := fmt.Sprintf("SM%03d", len(f.sent))
+	f.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusCreated,
+		Body:       io.NopCloser(strings.NewReader(`{"sid":"` + sid + `"}`)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Request:    req,
+	}, nil
+}
+
+func (f *fakeTwilio) sender() *TwilioSender {
+	return &TwilioSender{
+		accountSID:          "AC123",
+		authToken:           "token",
+		messagingServiceSID: "MG123",
+		httpClient:          &http.Client{Transport: f},
+	}
+}
+
+func TestFlushOutboxMergesDigests(t *testing.T) {
+	ctx := context.Background()
+	_, queries := dbtest.Open(t)
+	twilio := &fakeTwilio{}
+	d := NewDispatcher(queries, twilio.sender())
+
+	past := time.Now().Add(-time.Minute)
+	queue := func(typ, destination, message string, at time.Time, digest bool) string {
+		t.Helper()
+		id, err := d.enqueue(ctx, Notification{
+			UserID:      "user_demo_caregiver",
+			PatientID:   "patient_demo_001",
+			Type:        typ,
+			Destination: destination,
+			Message:     message,
+		}, at, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	low := queue(TypeLowStock, "+15551000", "Metformin is low", past, true)
+	refill := queue(TypeRefillForecast, "+15551000", "Refill atorvastatin by Friday", past, true)
+	other := queue(TypeLowStock, "+15559999", "Sent to another phone", past, true)
+	single := queue(TypeMissedDose, "+15551000", "Missed the 08:00 dose", past, false)
+	later := queue(TypeLowStock, "+15551000", "Not due yet", time.Now().Add(time.Hour), true)
+
+	if err := d.FlushOutbox(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	bodies := make(map[string][]string)
+	for _, form := range twilio.sent {
+		bodies[form.Get("To")] = append(bodies[form.Get("To")], form.Get("Body"))
+	}
+	if len(twilio.sent) != 3 {
+		t.Fatalf("sent %d messages, want 3: %v", len(twilio.sent), bodies)
+	}
+
+	header, err := RenderMessage(DefaultLocale, TypeDigest, ChannelSMS, MessageData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var digest string
+	for _, body := range bodies["+15551000"] {
+		if strings.HasPrefix(body, header) {
+			digest = body
+		} else if body != "Missed the 08:00 dose" {
+			t.Errorf("unexpected message %q", body)
+		}
+	}
+	lines := strings.Split(digest, "\n")
+	if len(lines) != 3 || !contains(lines, "- Metformin is low") || !contains(lines, "- Refill atorvastatin by Friday") {
+		t.Errorf("digest = %q, want the header and both queued messages", digest)
+	}
+	if got := bodies["+15559999"]; len(got) != 1 || !strings.HasSuffix(got[0], "- Sent to another phone") {
+		t.Errorf("digest to the second phone = %q", got)
+	}
+
+	for id, want := range map[string]string{low: "SENT", refill: "SENT", other: "SENT", single: "SENT", later: "QUEUED"} {
+		entry, err := queries.GetOutboxNotification(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Status != want {
+			t.Errorf("outbox %s (%s) status = %s, want %s", id, entry.Message, entry.Status, want)
+		}
+	}
+	lowEntry, _ := queries.GetOutboxNotification(ctx, low)
+	refillEntry, _ := queries.GetOutboxNotification(ctx, refill)
+	if !lowEntry.ProviderMessageID.Valid || lowEntry.ProviderMessageID != refillEntry.ProviderMessageID {
+		t.Errorf("merged entries have provider ids %v and %v, want the digest's", lowEntry.ProviderMessageID, refillEntry.ProviderMessageID)
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
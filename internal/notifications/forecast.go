@@ -0,0 +1,209 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+
+	"pillbox/internal/db"
+)
+
+const (
+	DefaultPharmacyLeadTimeDays = 3
+	MaxPharmacyLeadTimeDays     = 60
+
+	defaultRunOutAlertDays             = 7
+	defaultLotExpiryAlertDays          = 14
+	defaultPrescriptionExpiryAlertDays = 14
+	// forecastHorizon bounds how far ahead doses are simulated; stock that
+	// lasts longer has no run-out date.
+	forecastHorizon = 180 * 24 * time.Hour
+	// consumptionWindow is the period scheduled doses are averaged over.
+	consumptionWindow = 28 * 24 * time.Hour
+)
+
+// MedicationForecast projects when a medication runs out on its current
+// schedules.
+type MedicationForecast struct {
+	Medication db.Medication
+	// DailyConsumption is the average number of pills scheduled per day over
+	// the next four weeks.
+	DailyConsumption float64
+	// RunOutAt is the first scheduled dose the remaining stock cannot fully
+	// cover; nil when nothing is scheduled or stock outlasts the horizon.
+	RunOutAt *time.Time
+	// RefillBy is RunOutAt less the pharmacy lead time.
+	RefillBy *time.Time
+}
+
+// scheduledDose is one medication's share of a schedule occurrence.
+type scheduledDose struct {
+	at           time.Time
+	medicationID string
+	qty          int64
+}
+
+// ForecastMedications simulates the patient's active schedules from now on,
+// drawing each dose from the current stock.
+func ForecastMedications(ctx context.Context, queries *db.Queries, patient db.Patient, now time.Time) ([]MedicationForecast, error) {
+	medications, err := queries.ListMedicationsByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list medications: %w", err)
+	}
+	schedules, err := queries.ListSchedulesByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	return forecastMedications(ctx, queries, patient, medications, schedules, now)
+}
+
+// forecastMedications forecasts only the given medications. Schedules that
+// dose none of them are not expanded.
+func forecastMedications(ctx context.Context, queries *db.Queries, patient db.Patient, medications []db.Medication, schedules []db.Schedule, now time.Time) ([]MedicationForecast, error) {
+	loc := PatientLocation(patient.Timezone)
+	now = now.In(loc)
+	horizon := now.Add(forecastHorizon)
+
+	wanted := make(map[string]bool, len(medications))
+	for _, medication := range medications {
+		wanted[medication.ID] = true
+	}
+
+	var doses []scheduledDose
+	for _, schedule := range schedules {
+		if schedule.Status != "ACTIVE" {
+			continue
+		}
+
+		items, err := queries.ListScheduleItemsBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list schedule items for %s: %w", schedule.ID, err)
+		}
+		relevant := items[:0]
+		for _, item := range items {
+			if wanted[item.MedicationID] {
+				relevant = append(relevant, item)
+			}
+		}
+		if len(relevant) == 0 {
+			continue
+		}
+
+		occurrences, err := ScheduleOccurrences(schedule, now, horizon, loc)
+		if err != nil {
+			return nil, fmt.Errorf("expand schedule %s: %w", schedule.ID, err)
+		}
+		for _, at := range occurrences {
+			for _, item := range relevant {
+				doses = append(doses, scheduledDose{at: at, medicationID: item.MedicationID, qty: item.Qty})
+			}
+		}
+	}
+	sort.SliceStable(doses, func(i, j int) bool { return doses[i].at.Before(doses[j].at) })
+
+	leadTime := time.Duration(patient.PharmacyLeadTimeDays) * 24 * time.Hour
+	windowEnd := now.Add(consumptionWindow)
+
+	forecasts := make([]MedicationForecast, 0, len(medications))
+	for _, medication := range medications {
+		forecast := MedicationForecast{Medication: medication}
+
+		remaining := medication.StockCount
+		var windowPills int64
+		for _, dose := range doses {
+			if dose.medicationID != medication.ID {
+				continue
+			}
+			if dose.at.Before(windowEnd) {
+				windowPills += dose.qty
+			}
+			if forecast.RunOutAt == nil && remaining < dose.qty {
+				runOut := dose.at
+				refillBy := runOut.Add(-leadTime)
+				forecast.RunOutAt = &runOut
+				forecast.RefillBy = &refillBy
+			}
+			remaining -= dose.qty
+		}
+		forecast.DailyConsumption = float64(windowPills) / (consumptionWindow.Hours() / 24)
+
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts, nil
+}
+
+// RunOutAlertDays reads RUNOUT_ALERT_DAYS, how many days before a forecast
+// run-out the caregiver is warned (default 7).
+func RunOutAlertDays() int {
+	return alertDaysFromEnv("RUNOUT_ALERT_DAYS", defaultRunOutAlertDays)
+}
+
+// LotExpiryAlertDays reads LOT_EXPIRY_ALERT_DAYS, how many days before a
+// lot's expiry date the caregiver is warned (default 14).
+func LotExpiryAlertDays() int {
+	return alertDaysFromEnv("LOT_EXPIRY_ALERT_DAYS", defaultLotExpiryAlertDays)
+}
+
+// PrescriptionExpiryAlertDays reads PRESCRIPTION_EXPIRY_ALERT_DAYS, how many
+// days before a prescription expires the caregiver is warned (default 14).
+func PrescriptionExpiryAlertDays() int {
+	return alertDaysFromEnv("PRESCRIPTION_EXPIRY_ALERT_DAYS", defaultPrescriptionExpiryAlertDays)
+}
+
+func alertDaysFromEnv(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 0 {
+		return fallback
+	}
+	return days
+}
+
+// ScheduleOccurrences expands a schedule's RRULE between from and to,
+// honouring its start and end dates.
+func ScheduleOccurrences(schedule db.Schedule, from, to time.Time, loc *time.Location) ([]time.Time, error) {
+	startDate, err := parseDBTime(schedule.StartDateIso, loc)
+	if err != nil {
+		return nil, fmt.Errorf("parse start date: %w", err)
+	}
+	startDate = startDate.In(loc)
+
+	if schedule.EndDateIso.Valid && strings.TrimSpace(schedule.EndDateIso.String) != "" {
+		endDate, err := parseDBTime(schedule.EndDateIso.String, loc)
+		if err != nil {
+			return nil, fmt.Errorf("parse end date: %w", err)
+		}
+		if endDate.Before(to) {
+			to = endDate
+		}
+	}
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	cleanRule := strings.TrimSpace(schedule.Rrule)
+	if strings.HasPrefix(strings.ToUpper(cleanRule), "RRULE:") {
+		cleanRule = cleanRule[6:]
+	}
+
+	opt, err := rrule.StrToROption(cleanRule)
+	if err != nil {
+		return nil, err
+	}
+	opt.Dtstart = startDate
+
+	rule, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, err
+	}
+	return rule.Between(from, to, true), nil
+}
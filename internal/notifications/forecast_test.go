@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"pillbox/internal/db"
+	"pillbox/internal/dbtest"
+)
+
+func TestForecastMedications(t *testing.T) {
+	ctx := context.Background()
+	conn, queries := dbtest.Open(t)
+
+	// Metformin is taken once a day at the morning dose (03:00 in New York,
+	// 07:00Z in summer); atorvastatin at the morning and evening (20:00Z)
+	// doses.
+	if _, err := conn.Exec(`UPDATE medications SET stock_count = CASE id WHEN 'med_demo_metformin' THEN 60 ELSE 5 END WHERE patient_id = 'patient_demo_001'`); err != nil {
+		t.Fatal(err)
+	}
+	patient, err := queries.GetPatient(ctx, "patient_demo_001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	forecasts, err := ForecastMedications(ctx, queries, db.Patient(patient), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]MedicationForecast)
+	for _, forecast := range forecasts {
+		byID[forecast.Medication.ID] = forecast
+	}
+
+	tests := []struct {
+		medicationID string
+		daily        float64
+		runOut       time.Time
+	}{
+		// 60 doses cover June 2 to July 31.
+		{"med_demo_metformin", 1, time.Date(2026, 8, 1, 7, 0, 0, 0, time.UTC)},
+		// 5 doses cover June 1 evening to June 3 evening.
+		{"med_demo_atorvastatin", 2, time.Date(2026, 6, 4, 7, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		forecast, ok := byID[tc.medicationID]
+		if !ok {
+			t.Fatalf("no forecast for %s", tc.medicationID)
+		}
+		if forecast.DailyConsumption != tc.daily {
+			t.Errorf("%s: daily consumption = %v, want %v", tc.medicationID, forecast.DailyConsumption, tc.daily)
+		}
+		if forecast.RunOutAt == nil || !forecast.RunOutAt.Equal(tc.runOut) {
+			t.Errorf("%s: run out at %v, want %v", tc.medicationID, forecast.RunOutAt, tc.runOut)
+			continue
+		}
+		// The default pharmacy lead time is three days.
+		if want := tc.runOut.AddDate(0, 0, -3); forecast.RefillBy == nil || !forecast.RefillBy.Equal(want) {
+			t.Errorf("%s: refill by %v, want %v", tc.medicationID, forecast.RefillBy, want)
+		}
+	}
+
+	// Nothing is scheduled for lisinopril's patient after the end date.
+	if _, err := conn.Exec(`UPDATE schedules SET end_date_iso = '2026-05-01T00:00:00Z' WHERE id = 'sched_demo_central'`); err != nil {
+		t.Fatal(err)
+	}
+	central, err := queries.GetPatient(ctx, "patient_demo_002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forecasts, err = ForecastMedications(ctx, queries, db.Patient(central), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forecasts) != 1 || forecasts[0].DailyConsumption != 0 || forecasts[0].RunOutAt != nil || forecasts[0].RefillBy != nil {
+		t.Errorf("ended schedule forecast = %+v, want no consumption and no run-out", forecasts)
+	}
+}
+
+// fakeRefills counts forecast refill requests per medication and, when open
+// is set, records an open request like the pharmacy service does.
+type fakeRefills struct {
+	conn  *sql.DB
+	open  bool
+	calls map[string]int
+}
+
+func (f *fakeRefills) RequestForecastRefill(ctx context.Context, medicationID string) (bool, error) {
+	f.calls[medicationID]++
+	if !f.open {
+		return false, nil
+	}
+	_, err := f.conn.ExecContext(ctx, `INSERT INTO refill_requests (id, patient_id, medication_id, quantity, source, requested_at, updated_at)
+		SELECT 'request_' || id, patient_id, id, 30, 'FORECAST', datetime('now'), datetime('now') FROM medications WHERE id = ?`, medicationID)
+	return err == nil, err
+}
+
+func TestCheckRefillForecastThrottles(t *testing.T) {
+	ctx := context.Background()
+	conn, queries := dbtest.Open(t)
+	exec := func(query string) {
+		t.Helper()
+		if _, err := conn.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Metformin runs out within the pharmacy lead time; the caregiver was
+	// already warned, so only refill requests are left to make.
+	exec(`UPDATE patients SET auto_refill_requests = 1 WHERE id = 'patient_demo_001'`)
+	exec(`UPDATE medications SET stock_count = 2, runout_alerted_at = datetime('now') WHERE patient_id = 'patient_demo_001'`)
+
+	refills := &fakeRefills{conn: conn, calls: make(map[string]int)}
+	w := NewWorker(queries, (&fakeTwilio{}).sender(), nil, refills)
+	user, err := queries.GetUser(ctx, "user_demo_caregiver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func() {
+		t.Helper()
+		patient, err := queries.GetPatient(ctx, "patient_demo_001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.checkRefillForecast(ctx, db.Patient(patient), user, PatientLocation(patient.Timezone))
+	}
+	expire := func() {
+		run := w.forecasts["patient_demo_001"]
+		run.at = run.at.Add(-forecastInterval)
+		w.forecasts["patient_demo_001"] = run
+	}
+	want := func(step string, n int) {
+		t.Helper()
+		if got := refills.calls["med_demo_metformin"]; got != n {
+			t.Fatalf("%s: %d refill requests, want %d", step, got, n)
+		}
+	}
+
+	check()
+	want("first run", 1)
+	check()
+	want("next tick", 1)
+
+	exec(`UPDATE medications SET stock_count = 1 WHERE id = 'med_demo_metformin'`)
+	check()
+	want("after a stock change", 2)
+
+	refills.open = true
+	expire()
+	check()
+	want("an hour later", 3)
+
+	expire()
+	check()
+	want("with an open request", 3)
+}
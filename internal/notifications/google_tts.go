@@ -67,23 +67,38 @@ func newGoogleHTTPClient(ctx context.Context) (*http.Client, error) {
 	return client, nil
 }
 
-func (c *GoogleTTSClient) SynthesizeDefaultReminder(ctx context.Context, text string) (*TTSResult, error) {
+// googleDefaultVoice keeps the voice reminders used before voices became
+// configurable; other languages let Google choose from the language code.
+const googleDefaultVoice = "en-US-Chirp3-HD-Charon"
+
+func (c *GoogleTTSClient) Engine() string {
+	return "google"
+}
+
+func (c *GoogleTTSClient) Synthesize(ctx context.Context, text string, voice VoiceSettings) (*TTSResult, error) {
 	if strings.TrimSpace(text) == "" {
 		return nil, fmt.Errorf("empty tts text")
 	}
 
+	voiceParams := map[string]any{
+		"languageCode": voice.LanguageCode,
+	}
+	switch {
+	case voice.VoiceName != "":
+		voiceParams["name"] = voice.VoiceName
+	case voice.LanguageCode == "en-US":
+		voiceParams["name"] = googleDefaultVoice
+	}
+
 	body := map[string]any{
 		"input": map[string]any{
 			"text": text,
 		},
-		"voice": map[string]any{
-			"languageCode": "en-US",
-			"name":         "en-US-Chirp3-HD-Charon",
-		},
+		"voice": voiceParams,
 		"audioConfig": map[string]any{
-			"audioEncoding": "LINEAR16",
-			"speakingRate":  1.0,
-			"sampleRateHertz": 8000,
+			"audioEncoding":   "LINEAR16",
+			"speakingRate":    voice.SpeakingRate,
+			"sampleRateHertz": voice.SampleRateHz,
 		},
 	}
 
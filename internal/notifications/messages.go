@@ -0,0 +1,146 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const DefaultLocale = "en"
+
+// TypeDigest is the header template used when merging digest messages.
+const TypeDigest = "DIGEST"
+
+// Replies sent back to inbound SMS commands.
+const (
+	ReplyTaken     = "REPLY_TAKEN"
+	ReplySkipped   = "REPLY_SKIPPED"
+	ReplySnoozed   = "REPLY_SNOOZED"
+	ReplyNoPending = "REPLY_NO_PENDING"
+	ReplyHelp      = "REPLY_HELP"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// clockLayouts lists the supported locales and how each formats a time of day.
+var clockLayouts = map[string]string{
+	"en": "3:04 PM",
+	"fr": "15 h 04",
+	"es": "15:04",
+}
+
+// dateLayouts formats a calendar date in each locale.
+var dateLayouts = map[string]string{
+	"en": "Jan 2",
+	"fr": "02/01",
+	"es": "02/01",
+}
+
+var localeBundles = loadLocaleBundles()
+
+// MessageData holds the values available to message templates.
+type MessageData struct {
+	FirstName   string
+	Medications string
+	// DueAt should already be in the recipient's local time.
+	DueAt time.Time
+	// Silo is the 1-based silo number printed on the device.
+	Silo    int64
+	Stock   int64
+	Minutes int
+	// RunOutAt and RefillBy come from the medication forecast, in the
+	// recipient's local time.
+	RunOutAt time.Time
+	RefillBy time.Time
+	// LotNumber and ExpiresOn describe an expiring medication lot.
+	// ExpiresOn is also a prescription's expiry date.
+	LotNumber string
+	ExpiresOn time.Time
+	// Prescriber wrote the prescription being renewed.
+	Prescriber string
+}
+
+func loadLocaleBundles() map[string]*template.Template {
+	bundles := make(map[string]*template.Template, len(clockLayouts))
+	for locale, layout := range clockLayouts {
+		dateLayout := dateLayouts[locale]
+		funcs := template.FuncMap{
+			"clock": func(t time.Time) string { return t.Format(layout) },
+			"date":  func(t time.Time) string { return t.Format(dateLayout) },
+		}
+		bundles[locale] = template.Must(
+			template.New(locale).Funcs(funcs).ParseFS(templateFS, "templates/"+locale+".tmpl"),
+		)
+	}
+	return bundles
+}
+
+// SupportedLocales returns the locales that have a template bundle.
+func SupportedLocales() []string {
+	locales := make([]string, 0, len(clockLayouts))
+	for locale := range clockLayouts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// IsSupportedLocale reports whether locale (or its language part, as in
+// "fr-CA") has a template bundle.
+func IsSupportedLocale(locale string) bool {
+	_, ok := clockLayouts[languageOf(locale)]
+	return ok
+}
+
+// NormalizeLocale reduces a locale to a supported bundle name, falling back
+// to DefaultLocale.
+func NormalizeLocale(locale string) string {
+	lang := languageOf(locale)
+	if _, ok := clockLayouts[lang]; ok {
+		return lang
+	}
+	return DefaultLocale
+}
+
+func languageOf(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if idx := strings.IndexAny(locale, "-_"); idx >= 0 {
+		locale = locale[:idx]
+	}
+	return locale
+}
+
+// RenderMessage renders the message for notificationType in the given
+// locale. A channel-specific template such as "DOSE_REMINDER.AUDIO" takes
+// precedence over the generic one, and types missing from a locale bundle
+// fall back to English.
+func RenderMessage(locale, notificationType, channel string, data MessageData) (string, error) {
+	candidates := []string{NormalizeLocale(locale)}
+	if candidates[0] != DefaultLocale {
+		candidates = append(candidates, DefaultLocale)
+	}
+
+	for _, name := range candidates {
+		bundle := localeBundles[name]
+		tmpl := bundle.Lookup(notificationType + "." + channel)
+		if tmpl == nil {
+			tmpl = bundle.Lookup(notificationType)
+		}
+		if tmpl == nil {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("render %s message (%s): %w", notificationType, name, err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	return "", fmt.Errorf("no message template for %s", notificationType)
+}
@@ -0,0 +1,195 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	OfflineEngineEspeak = "espeak-ng"
+	OfflineEnginePiper  = "piper"
+	// OfflineEngineTone plays a chime followed by an optional prerecorded
+	// clip, for devices without any speech engine installed.
+	OfflineEngineTone = "tone"
+
+	// espeakBaseWordsPerMinute is espeak-ng's default speed, scaled by the
+	// patient's speaking rate.
+	espeakBaseWordsPerMinute = 175
+	offlineTTSTimeout        = 30 * time.Second
+)
+
+// OfflineSynthesizer produces reminder audio without a cloud service, either
+// by running a local speech engine or by falling back to a chime plus a
+// prerecorded clip.
+type OfflineSynthesizer struct {
+	engine  string
+	command string
+	// piperModel is the default Piper voice model; a patient's voice name
+	// overrides it.
+	piperModel string
+	clip       *pcmAudio
+}
+
+// NewOfflineSynthesizerFromEnv configures the offline engine from
+// TTS_OFFLINE_ENGINE (espeak-ng, piper or tone), TTS_OFFLINE_COMMAND,
+// PIPER_MODEL and TTS_OFFLINE_CLIP. Without TTS_OFFLINE_ENGINE the first
+// engine found on PATH is used, falling back to the tone.
+func NewOfflineSynthesizerFromEnv() (*OfflineSynthesizer, error) {
+	s := &OfflineSynthesizer{
+		engine:     strings.ToLower(strings.TrimSpace(os.Getenv("TTS_OFFLINE_ENGINE"))),
+		command:    strings.TrimSpace(os.Getenv("TTS_OFFLINE_COMMAND")),
+		piperModel: strings.TrimSpace(os.Getenv("PIPER_MODEL")),
+	}
+
+	if s.engine == "" {
+		s.engine = OfflineEngineTone
+		if _, err := exec.LookPath(OfflineEngineEspeak); err == nil {
+			s.engine = OfflineEngineEspeak
+		} else if _, err := exec.LookPath(OfflineEnginePiper); err == nil && s.piperModel != "" {
+			s.engine = OfflineEnginePiper
+		}
+	}
+
+	switch s.engine {
+	case OfflineEngineEspeak, OfflineEnginePiper:
+		if s.command == "" {
+			s.command = s.engine
+		}
+		path, err := exec.LookPath(s.command)
+		if err != nil {
+			return nil, fmt.Errorf("find %s: %w", s.engine, err)
+		}
+		s.command = path
+	case OfflineEngineTone:
+	default:
+		return nil, fmt.Errorf("unknown TTS_OFFLINE_ENGINE %q", s.engine)
+	}
+
+	if clipPath := strings.TrimSpace(os.Getenv("TTS_OFFLINE_CLIP")); clipPath != "" {
+		raw, err := os.ReadFile(clipPath)
+		if err != nil {
+			return nil, fmt.Errorf("read TTS_OFFLINE_CLIP: %w", err)
+		}
+		clip, err := decodeWAV(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode TTS_OFFLINE_CLIP: %w", err)
+		}
+		s.clip = &clip
+	}
+
+	return s, nil
+}
+
+// Engine names the engine in use. The default Piper model is included since
+// it changes the voice.
+func (s *OfflineSynthesizer) Engine() string {
+	if s.engine == OfflineEnginePiper && s.piperModel != "" {
+		return s.engine + ":" + s.piperModel
+	}
+	return s.engine
+}
+
+func (s *OfflineSynthesizer) Synthesize(ctx context.Context, text string, voice VoiceSettings) (*TTSResult, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty tts text")
+	}
+
+	var (
+		audio pcmAudio
+		err   error
+	)
+	switch s.engine {
+	case OfflineEngineEspeak:
+		audio, err = s.runEspeak(ctx, text, voice)
+	case OfflineEnginePiper:
+		audio, err = s.runPiper(ctx, text, voice)
+	default:
+		audio = s.chime(voice.SampleRateHz)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &TTSResult{
+		AudioBytes: encodeWAV(audio.resample(voice.SampleRateHz)),
+		MimeType:   "audio/wav",
+	}, nil
+}
+
+func (s *OfflineSynthesizer) runEspeak(ctx context.Context, text string, voice VoiceSettings) (pcmAudio, error) {
+	espeakVoice := voice.VoiceName
+	if espeakVoice == "" {
+		espeakVoice = strings.ToLower(voice.LanguageCode)
+	}
+	wordsPerMinute := int(espeakBaseWordsPerMinute * voice.SpeakingRate)
+
+	out, err := s.run(ctx, text, "--stdout", "--stdin", "-v", espeakVoice, "-s", strconv.Itoa(wordsPerMinute))
+	if err != nil {
+		return pcmAudio{}, err
+	}
+	return decodeWAV(out)
+}
+
+func (s *OfflineSynthesizer) runPiper(ctx context.Context, text string, voice VoiceSettings) (pcmAudio, error) {
+	model := voice.VoiceName
+	if model == "" {
+		model = s.piperModel
+	}
+	if model == "" {
+		return pcmAudio{}, fmt.Errorf("piper needs a voice model; set PIPER_MODEL or the patient's voice name")
+	}
+
+	outFile, err := os.CreateTemp("", "pillbox-tts-*.wav")
+	if err != nil {
+		return pcmAudio{}, fmt.Errorf("create piper output file: %w", err)
+	}
+	outPath := outFile.Name()
+	outFile.Close()
+	defer os.Remove(outPath)
+
+	// Piper's length scale is the inverse of speed.
+	lengthScale := strconv.FormatFloat(1/voice.SpeakingRate, 'f', 3, 64)
+	if _, err := s.run(ctx, text, "--model", model, "--length_scale", lengthScale, "--output_file", outPath); err != nil {
+		return pcmAudio{}, err
+	}
+
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		return pcmAudio{}, fmt.Errorf("read piper output: %w", err)
+	}
+	return decodeWAV(raw)
+}
+
+func (s *OfflineSynthesizer) run(ctx context.Context, text string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, offlineTTSTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.command, args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", s.engine, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// chime renders a two-note chime, followed by the prerecorded clip when one
+// is configured.
+func (s *OfflineSynthesizer) chime(rate int) pcmAudio {
+	samples := toneSamples(880, 180, rate)
+	samples = append(samples, toneSamples(660, 260, rate)...)
+	if s.clip != nil {
+		samples = append(samples, silenceSamples(200, rate)...)
+		samples = append(samples, s.clip.resample(rate).samples...)
+	}
+	return pcmAudio{sampleRate: rate, samples: samples}
+}
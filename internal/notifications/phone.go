@@ -0,0 +1,48 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCountryCode is assumed for phone numbers entered without one.
+const DefaultCountryCode = "1"
+
+// NormalizePhone returns phone in E.164 form (+ followed by 8 to 15 digits),
+// the form Twilio uses in the From of inbound messages. Spaces, dashes, dots
+// and parentheses are dropped; a leading 00 is read as the international
+// prefix; ten-digit numbers, and eleven-digit numbers starting with the
+// default country code, are read as national numbers.
+func NormalizePhone(phone string) (string, error) {
+	trimmed := strings.TrimSpace(phone)
+	international := strings.HasPrefix(trimmed, "+")
+
+	var b strings.Builder
+	for i, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("invalid phone number %q", phone)
+		}
+	}
+	digits := b.String()
+
+	switch {
+	case international:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case len(digits) == 10:
+		digits = DefaultCountryCode + digits
+	case len(digits) == 11 && strings.HasPrefix(digits, DefaultCountryCode):
+	default:
+		return "", fmt.Errorf("phone number %q needs a country code, e.g. +%s5551234567", phone, DefaultCountryCode)
+	}
+
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", fmt.Errorf("invalid phone number %q", phone)
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,38 @@
+package notifications
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "+15551234567", want: "+15551234567"},
+		{in: "5551234567", want: "+15551234567"},
+		{in: "15551234567", want: "+15551234567"},
+		{in: "(555) 123-4567", want: "+15551234567"},
+		{in: " +1 555.123.4567 ", want: "+15551234567"},
+		{in: "+1-555-1000", want: "+15551000"},
+		{in: "0033 1 23 45 67 89", want: "+33123456789"},
+		{in: "+44 20 7946 0958", want: "+442079460958"},
+		{in: "555-1234", wantErr: true},
+		{in: "+1234", wantErr: true},
+		{in: "+1234567890123456", wantErr: true},
+		{in: "+0123456789", wantErr: true},
+		{in: "555 123 4567 ext 2", wantErr: true},
+		{in: "1+5551234567", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := NormalizePhone(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("NormalizePhone(%q) = %q, want an error", tc.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("NormalizePhone(%q) = %q, %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+}
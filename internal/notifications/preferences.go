@@ -0,0 +1,187 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pillbox/internal/db"
+)
+
+const (
+	TypeDoseReminder = "DOSE_REMINDER"
+	TypeLowStock     = "LOW_STOCK"
+	TypeMissedDose   = "MISSED_DOSE"
+	TypeCupAbsent    = "CUP_ABSENT"
+	TypeEmptySilo    = "EMPTY_SILO"
+	// TypeRefillForecast warns ahead of a forecast run-out.
+	TypeRefillForecast = "REFILL_FORECAST"
+	// TypeLotExpiry warns that pills loaded in a silo are about to expire.
+	TypeLotExpiry = "LOT_EXPIRY"
+	// TypePrescriptionExpiring and TypeNoRefillsLeft ask the caregiver to
+	// get a prescription renewed.
+	TypePrescriptionExpiring = "PRESCRIPTION_EXPIRING"
+	TypeNoRefillsLeft        = "NO_REFILLS_LEFT"
+)
+
+const (
+	ChannelSMS   = "SMS"
+	ChannelAudio = "AUDIO"
+)
+
+const (
+	DeliveryImmediate   = "IMMEDIATE"
+	DeliveryDailyDigest = "DAILY_DIGEST"
+)
+
+// IsUrgent reports whether a notification type must go out right away.
+// Urgent types ignore quiet hours and digest delivery; the user can only
+// switch them off entirely.
+func IsUrgent(notificationType string) bool {
+	switch notificationType {
+	case TypeDoseReminder, TypeCupAbsent, TypeEmptySilo:
+		return true
+	default:
+		return false
+	}
+}
+
+// Preference is the resolved delivery preference for one user, notification
+// type and channel.
+type Preference struct {
+	Enabled         bool
+	QuietHoursStart string
+	QuietHoursEnd   string
+	DeliveryMode    string
+	DigestTime      string
+}
+
+func defaultPreference() Preference {
+	return Preference{
+		Enabled:      true,
+		DeliveryMode: DeliveryImmediate,
+		DigestTime:   "09:00",
+	}
+}
+
+// LoadPreference returns the stored preference, or the default (enabled,
+// immediate, no quiet hours) when the user has not configured one.
+func LoadPreference(ctx context.Context, queries *db.Queries, userID, notificationType, channel string) (Preference, error) {
+	row, err := queries.GetNotificationPreference(ctx, db.GetNotificationPreferenceParams{
+		UserID:           userID,
+		NotificationType: notificationType,
+		Channel:          channel,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return defaultPreference(), nil
+	}
+	if err != nil {
+		return Preference{}, err
+	}
+
+	return Preference{
+		Enabled:         row.Enabled != 0,
+		QuietHoursStart: row.QuietHoursStart.String,
+		QuietHoursEnd:   row.QuietHoursEnd.String,
+		DeliveryMode:    row.DeliveryMode,
+		DigestTime:      row.DigestTime,
+	}, nil
+}
+
+// DeliverAt decides when a notification should be delivered. It returns
+// enabled=false when the user switched it off and a zero time when it should
+// be sent immediately; otherwise the returned time is when it may go out.
+func (p Preference) DeliverAt(notificationType string, now time.Time, loc *time.Location) (deliverAt time.Time, enabled bool) {
+	if !p.Enabled {
+		return time.Time{}, false
+	}
+	if IsUrgent(notificationType) {
+		return time.Time{}, true
+	}
+
+	if p.DeliveryMode == DeliveryDailyDigest {
+		digest, err := nextClockTime(p.DigestTime, now, loc)
+		if err == nil {
+			return digest, true
+		}
+	}
+
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}, true
+	}
+	quiet, err := inQuietHours(p.QuietHoursStart, p.QuietHoursEnd, now, loc)
+	if err != nil || !quiet {
+		return time.Time{}, true
+	}
+
+	end, err := nextClockTime(p.QuietHoursEnd, now, loc)
+	if err != nil {
+		return time.Time{}, true
+	}
+	return end, true
+}
+
+// ParseClock parses a 24-hour "HH:MM" wall-clock time.
+func ParseClock(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func inQuietHours(start, end string, now time.Time, loc *time.Location) (bool, error) {
+	startHour, startMinute, err := ParseClock(start)
+	if err != nil {
+		return false, err
+	}
+	endHour, endMinute, err := ParseClock(end)
+	if err != nil {
+		return false, err
+	}
+
+	local := now.In(loc)
+	current := local.Hour()*60 + local.Minute()
+	from := startHour*60 + startMinute
+	to := endHour*60 + endMinute
+
+	switch {
+	case from == to:
+		return false, nil
+	case from < to:
+		return current >= from && current < to, nil
+	default:
+		// Window wraps past midnight, e.g. 22:00-07:00.
+		return current >= from || current < to, nil
+	}
+}
+
+func nextClockTime(clock string, now time.Time, loc *time.Location) (time.Time, error) {
+	hour, minute, err := ParseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(loc)
+	next := wallClock(local.Year(), local.Month(), local.Day(), hour, minute, loc)
+	if !next.After(local) {
+		next = wallClock(local.Year(), local.Month(), local.Day()+1, hour, minute, loc)
+	}
+	return next, nil
+}
+
+// wallClock is time.Date for a clock time that may fall in a DST gap. Go
+// resolves 02:30 on a spring-forward day to 01:30 standard time, an hour
+// early; this returns 03:30 instead, the moment 02:30 would have been.
+func wallClock(year int, month time.Month, day, hour, minute int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	if t.Hour() != hour || t.Minute() != minute {
+		_, before := t.Zone()
+		_, after := t.Add(3 * time.Hour).Zone()
+		t = t.Add(time.Duration(after-before) * time.Second)
+	}
+	return t
+}
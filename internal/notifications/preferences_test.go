@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeliverAt(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(value string) time.Time {
+		t.Helper()
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, ny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	quiet := func(start, end string) Preference {
+		p := defaultPreference()
+		p.QuietHoursStart, p.QuietHoursEnd = start, end
+		return p
+	}
+	digest := func(clock string) Preference {
+		p := defaultPreference()
+		p.DeliveryMode, p.DigestTime = DeliveryDailyDigest, clock
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		pref     Preference
+		typ      string
+		now      time.Time
+		want     time.Time
+		disabled bool
+	}{
+		{name: "no quiet hours", pref: defaultPreference(), typ: TypeLowStock, now: at("2026-06-10 23:30")},
+		{name: "disabled", pref: Preference{}, typ: TypeLowStock, now: at("2026-06-10 12:00"), disabled: true},
+		{name: "urgent ignores quiet hours", pref: quiet("22:00", "07:00"), typ: TypeDoseReminder, now: at("2026-06-10 23:30")},
+		{name: "urgent ignores digest", pref: digest("09:00"), typ: TypeCupAbsent, now: at("2026-06-10 23:30")},
+		{name: "same-day window, inside", pref: quiet("12:00", "14:00"), typ: TypeLowStock, now: at("2026-06-10 13:00"), want: at("2026-06-10 14:00")},
+		{name: "same-day window, at end", pref: quiet("12:00", "14:00"), typ: TypeLowStock, now: at("2026-06-10 14:00")},
+		{name: "wrapping window, before midnight", pref: quiet("22:00", "07:00"), typ: TypeLowStock, now: at("2026-06-10 23:30"), want: at("2026-06-11 07:00")},
+		{name: "wrapping window, after midnight", pref: quiet("22:00", "07:00"), typ: TypeLowStock, now: at("2026-06-11 03:00"), want: at("2026-06-11 07:00")},
+		{name: "wrapping window, at start", pref: quiet("22:00", "07:00"), typ: TypeLowStock, now: at("2026-06-10 22:00"), want: at("2026-06-11 07:00")},
+		{name: "wrapping window, outside", pref: quiet("22:00", "07:00"), typ: TypeLowStock, now: at("2026-06-10 12:00")},
+		{name: "empty window", pref: quiet("22:00", "22:00"), typ: TypeLowStock, now: at("2026-06-10 22:30")},
+		{name: "digest later today", pref: digest("09:00"), typ: TypeLowStock, now: at("2026-06-10 08:00"), want: at("2026-06-10 09:00")},
+		{name: "digest already past today", pref: digest("09:00"), typ: TypeLowStock, now: at("2026-06-10 09:30"), want: at("2026-06-11 09:00")},
+		{name: "digest exactly now", pref: digest("09:00"), typ: TypeLowStock, now: at("2026-06-10 09:00"), want: at("2026-06-11 09:00")},
+		// 2026-11-01 02:00 EDT falls back to 01:00 EST.
+		{name: "digest across fall back", pref: digest("09:00"), typ: TypeLowStock, now: at("2026-10-31 22:00"), want: time.Date(2026, 11, 1, 14, 0, 0, 0, time.UTC)},
+		{name: "quiet hours across fall back", pref: quiet("22:00", "07:00"), typ: TypeLowStock, now: at("2026-10-31 23:00"), want: time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)},
+		// 2026-03-08 02:00 EST springs forward to 03:00 EDT.
+		{name: "quiet hours across spring forward", pref: quiet("22:00", "07:00"), typ: TypeLowStock, now: at("2026-03-07 23:00"), want: time.Date(2026, 3, 8, 11, 0, 0, 0, time.UTC)},
+		{name: "quiet end in the skipped hour", pref: quiet("01:00", "02:30"), typ: TypeLowStock, now: at("2026-03-08 01:30"), want: time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, enabled := tc.pref.DeliverAt(tc.typ, tc.now, ny)
+			if enabled == tc.disabled {
+				t.Fatalf("enabled = %v, want %v", enabled, !tc.disabled)
+			}
+			if !got.Equal(tc.want) {
+				t.Fatalf("DeliverAt = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
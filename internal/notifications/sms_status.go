@@ -0,0 +1,131 @@
+package notifications
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pillbox/internal/db"
+)
+
+// StatusCallbackPath is where Twilio posts delivery status updates.
+const StatusCallbackPath = "/sms/status"
+
+// Carrier delivery statuses stored on notification_events.
+const (
+	DeliveryQueued      = "QUEUED"
+	DeliverySent        = "SENT"
+	DeliveryDelivered   = "DELIVERED"
+	DeliveryUndelivered = "UNDELIVERED"
+	DeliveryFailed      = "FAILED"
+)
+
+// StatusCallbackHandler records Twilio message status callbacks against the
+// notification event that sent the message.
+type StatusCallbackHandler struct {
+	queries   *db.Queries
+	validator *TwilioRequestValidator
+}
+
+func NewStatusCallbackHandler(queries *db.Queries, validator *TwilioRequestValidator) *StatusCallbackHandler {
+	return &StatusCallbackHandler{
+		queries:   queries,
+		validator: validator,
+	}
+}
+
+func (h *StatusCallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if !h.validator.Validate(r) {
+		http.Error(w, "invalid twilio signature", http.StatusForbidden)
+		return
+	}
+
+	sid := strings.TrimSpace(r.PostForm.Get("MessageSid"))
+	status, ok := MapTwilioStatus(r.PostForm.Get("MessageStatus"))
+	if sid == "" || !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	ctx := r.Context()
+	event, err := h.queries.GetNotificationEventByProviderMessageID(ctx, sql.NullString{String: sid, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Messages sent outside the reminder worker are not tracked.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		log.Printf("sms status callback: load event for %s: %v", sid, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// Callbacks can arrive out of order; never step back from a later status.
+	if deliveryRank(status) < deliveryRank(event.DeliveryStatus.String) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	errorCode := strings.TrimSpace(r.PostForm.Get("ErrorCode"))
+	errorMessage := sql.NullString{}
+	if errorCode != "" && (status == DeliveryUndelivered || status == DeliveryFailed) {
+		message := "twilio error " + errorCode
+		if detail := strings.TrimSpace(r.PostForm.Get("ErrorMessage")); detail != "" {
+			message += ": " + detail
+		}
+		errorMessage = nullableString(message)
+	}
+
+	if err := h.queries.UpdateNotificationDeliveryStatus(ctx, db.UpdateNotificationDeliveryStatusParams{
+		DeliveryStatus:    nullableString(status),
+		ErrorCode:         nullableString(errorCode),
+		ErrorMessage:      errorMessage,
+		DeliveryUpdatedAt: nullableString(formatDBTime(time.Now())),
+		ID:                event.ID,
+	}); err != nil {
+		log.Printf("sms status callback: update event %s: %v", event.ID, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MapTwilioStatus folds Twilio's MessageStatus values into the delivery
+// statuses we track.
+func MapTwilioStatus(status string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "accepted", "scheduled", "queued":
+		return DeliveryQueued, true
+	case "sending", "sent":
+		return DeliverySent, true
+	case "delivered", "read":
+		return DeliveryDelivered, true
+	case "undelivered":
+		return DeliveryUndelivered, true
+	case "failed", "canceled":
+		return DeliveryFailed, true
+	default:
+		return "", false
+	}
+}
+
+func deliveryRank(status string) int {
+	switch status {
+	case DeliveryQueued:
+		return 1
+	case DeliverySent:
+		return 2
+	case DeliveryDelivered, DeliveryUndelivered, DeliveryFailed:
+		return 3
+	default:
+		return 0
+	}
+}
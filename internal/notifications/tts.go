@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"pillbox/internal/db"
+)
+
+const (
+	DefaultSpeakingRate = 1.0
+	// DefaultSampleRateHz matches the dispenser's speaker, which plays 8 kHz
+	// 16-bit mono WAV.
+	DefaultSampleRateHz = 8000
+
+	MinSpeakingRate = 0.25
+	MaxSpeakingRate = 4.0
+)
+
+// SupportedSampleRates lists the WAV sample rates a patient may choose.
+var SupportedSampleRates = []int{8000, 16000, 22050, 24000, 44100, 48000}
+
+// defaultLanguageCodes maps a template locale to the TTS language spoken for it.
+var defaultLanguageCodes = map[string]string{
+	"en": "en-US",
+	"fr": "fr-CA",
+	"es": "es-US",
+}
+
+// Synthesizer turns reminder text into 16-bit mono WAV audio. Engine names
+// the engine and anything else about it that changes the audio, so cached
+// audio from one engine is never served for another.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string, voice VoiceSettings) (*TTSResult, error)
+	Engine() string
+}
+
+// VoiceSettings controls how a patient's reminders are spoken. An empty
+// VoiceName lets the engine pick a voice for LanguageCode.
+type VoiceSettings struct {
+	VoiceName    string
+	LanguageCode string
+	SpeakingRate float64
+	SampleRateHz int
+}
+
+// DefaultVoiceSettings returns the voice used for a locale when the patient
+// has not chosen one.
+func DefaultVoiceSettings(locale string) VoiceSettings {
+	return VoiceSettings{
+		LanguageCode: defaultLanguageCodes[NormalizeLocale(locale)],
+		SpeakingRate: DefaultSpeakingRate,
+		SampleRateHz: DefaultSampleRateHz,
+	}
+}
+
+// LoadVoiceSettings returns the patient's voice settings, filling unset
+// fields from the defaults for locale.
+func LoadVoiceSettings(ctx context.Context, queries *db.Queries, patientID, locale string) (VoiceSettings, error) {
+	settings := DefaultVoiceSettings(locale)
+
+	row, err := queries.GetPatientVoiceSettings(ctx, patientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return VoiceSettings{}, err
+	}
+
+	if row.VoiceName.Valid {
+		settings.VoiceName = row.VoiceName.String
+	}
+	if row.LanguageCode.Valid {
+		settings.LanguageCode = row.LanguageCode.String
+	}
+	settings.SpeakingRate = row.SpeakingRate
+	settings.SampleRateHz = int(row.SampleRateHz)
+	return settings, nil
+}
+
+// IsSupportedSampleRate reports whether hz is one of SupportedSampleRates.
+func IsSupportedSampleRate(hz int) bool {
+	for _, rate := range SupportedSampleRates {
+		if rate == hz {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSynthesizerFromEnv picks the TTS engine from TTS_ENGINE ("google" or
+// "offline"). When unset, Google is used if GOOGLE_CLOUD_PROJECT is set and
+// the offline engine otherwise.
+func NewSynthesizerFromEnv(ctx context.Context) (Synthesizer, error) {
+	engine := strings.ToLower(strings.TrimSpace(os.Getenv("TTS_ENGINE")))
+	if engine == "" {
+		engine = "offline"
+		if strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_PROJECT")) != "" {
+			engine = "google"
+		}
+	}
+
+	switch engine {
+	case "google":
+		return NewGoogleTTSClientFromEnv(ctx)
+	case "offline":
+		return NewOfflineSynthesizerFromEnv()
+	default:
+		return nil, fmt.Errorf("unknown TTS_ENGINE %q", engine)
+	}
+}
@@ -0,0 +1,209 @@
+package notifications
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"pillbox/internal/db"
+)
+
+const defaultTTSCacheMaxBytes = 200 << 20
+
+// CachingSynthesizer reuses previously synthesized audio for identical text
+// and voice settings, so daily reminders only hit the TTS provider once and
+// keep working while it is unavailable. Entries are evicted least recently
+// used first once the cache grows past maxBytes.
+type CachingSynthesizer struct {
+	queries  *db.Queries
+	next     Synthesizer
+	dir      string
+	maxBytes int64
+	// inflight shares one synthesis between concurrent requests for the
+	// same key; mu serializes writes and eviction, never synthesis.
+	inflight singleflight.Group
+	mu       sync.Mutex
+}
+
+func NewCachingSynthesizer(queries *db.Queries, next Synthesizer, dir string, maxBytes int64) *CachingSynthesizer {
+	return &CachingSynthesizer{
+		queries:  queries,
+		next:     next,
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+}
+
+// NewCachingSynthesizerFromEnv wraps next with a cache in TTS_CACHE_DIR
+// (default AUDIO_BASE_DIR/tts-cache) limited to TTS_CACHE_MAX_BYTES
+// (default 200 MiB).
+func NewCachingSynthesizerFromEnv(queries *db.Queries, next Synthesizer) (*CachingSynthesizer, error) {
+	dir := TTSCacheDir()
+
+	maxBytes := int64(defaultTTSCacheMaxBytes)
+	if raw := strings.TrimSpace(os.Getenv("TTS_CACHE_MAX_BYTES")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid TTS_CACHE_MAX_BYTES %q", raw)
+		}
+		maxBytes = parsed
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create tts cache dir: %w", err)
+	}
+	return NewCachingSynthesizer(queries, next, dir, maxBytes), nil
+}
+
+// TTSCacheDir returns TTS_CACHE_DIR, defaulting to AUDIO_BASE_DIR/tts-cache.
+func TTSCacheDir() string {
+	dir := strings.TrimSpace(os.Getenv("TTS_CACHE_DIR"))
+	if dir == "" {
+		dir = filepath.Join(AudioBaseDir(), "tts-cache")
+	}
+	return filepath.Clean(dir)
+}
+
+// TTSCacheKey hashes everything that affects the synthesized audio.
+func TTSCacheKey(engine, text string, voice VoiceSettings) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		engine,
+		text,
+		voice.VoiceName,
+		voice.LanguageCode,
+		strconv.FormatFloat(voice.SpeakingRate, 'f', -1, 64),
+		strconv.Itoa(voice.SampleRateHz),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *CachingSynthesizer) Engine() string {
+	return c.next.Engine()
+}
+
+func (c *CachingSynthesizer) Synthesize(ctx context.Context, text string, voice VoiceSettings) (*TTSResult, error) {
+	key := TTSCacheKey(c.next.Engine(), text, voice)
+
+	result, err, _ := c.inflight.Do(key, func() (any, error) {
+		if audio, ok := c.lookup(ctx, key); ok {
+			return &TTSResult{AudioBytes: audio, MimeType: "audio/wav"}, nil
+		}
+
+		result, err := c.next.Synthesize(ctx, text, voice)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.store(ctx, key, result.AudioBytes); err != nil {
+			// A cache failure should not cost the patient their reminder.
+			log.Printf("tts cache: store %s: %v", key, err)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*TTSResult), nil
+}
+
+func (c *CachingSynthesizer) lookup(ctx context.Context, key string) ([]byte, bool) {
+	entry, err := c.queries.GetTTSCacheEntry(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("tts cache: load %s: %v", key, err)
+		return nil, false
+	}
+
+	audio, err := os.ReadFile(entry.FilePath)
+	if err != nil {
+		// The file was removed behind our back; forget the entry.
+		log.Printf("tts cache: read %s: %v", entry.FilePath, err)
+		if err := c.queries.DeleteTTSCacheEntry(ctx, key); err != nil {
+			log.Printf("tts cache: delete %s: %v", key, err)
+		}
+		return nil, false
+	}
+
+	if err := c.queries.TouchTTSCacheEntry(ctx, db.TouchTTSCacheEntryParams{
+		LastUsedAt: formatDBTime(time.Now()),
+		CacheKey:   key,
+	}); err != nil {
+		log.Printf("tts cache: touch %s: %v", key, err)
+	}
+	return audio, true
+}
+
+func (c *CachingSynthesizer) store(ctx context.Context, key string, audio []byte) error {
+	if int64(len(audio)) > c.maxBytes {
+		return fmt.Errorf("audio larger than cache limit")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Write under a temporary name so a concurrent lookup never reads a
+	// partly written file.
+	path := filepath.Join(c.dir, key+".wav")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, audio, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := c.queries.UpsertTTSCacheEntry(ctx, db.UpsertTTSCacheEntryParams{
+		CacheKey:   key,
+		FilePath:   path,
+		SizeBytes:  int64(len(audio)),
+		LastUsedAt: formatDBTime(time.Now()),
+	}); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return c.evict(ctx)
+}
+
+// evict removes least recently used entries until the cache fits maxBytes.
+func (c *CachingSynthesizer) evict(ctx context.Context) error {
+	total, err := c.queries.GetTTSCacheSize(ctx)
+	if err != nil {
+		return fmt.Errorf("measure cache: %w", err)
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	entries, err := c.queries.ListTTSCacheEntriesByLastUsed(ctx)
+	if err != nil {
+		return fmt.Errorf("list cache entries: %w", err)
+	}
+	for _, entry := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(entry.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", entry.FilePath, err)
+		}
+		if err := c.queries.DeleteTTSCacheEntry(ctx, entry.CacheKey); err != nil {
+			return fmt.Errorf("delete cache entry %s: %w", entry.CacheKey, err)
+		}
+		total -= entry.SizeBytes
+	}
+	return nil
+}
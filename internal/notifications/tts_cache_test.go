@@ -0,0 +1,148 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"pillbox/internal/dbtest"
+)
+
+// fakeSynthesizer returns the text as audio and counts calls per text. Texts
+// in gates wait for their channel to close before returning.
+type fakeSynthesizer struct {
+	engine  string
+	gates   map[string]chan struct{}
+	started chan string
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeSynthesizer) Engine() string { return f.engine }
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text string, voice VoiceSettings) (*TTSResult, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[text]++
+	f.mu.Unlock()
+
+	if gate, ok := f.gates[text]; ok {
+		f.started <- text
+		<-gate
+	}
+	return &TTSResult{AudioBytes: []byte(f.engine + ":" + text), MimeType: "audio/wav"}, nil
+}
+
+func (f *fakeSynthesizer) callCount(text string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[text]
+}
+
+func TestTTSCacheKey(t *testing.T) {
+	voice := DefaultVoiceSettings("en")
+	base := TTSCacheKey("google", "Time for your pills", voice)
+
+	if TTSCacheKey("google", "Time for your pills", voice) != base {
+		t.Fatal("TTSCacheKey is not stable")
+	}
+	faster := voice
+	faster.SpeakingRate = 1.5
+	for name, key := range map[string]string{
+		"engine": TTSCacheKey("espeak-ng", "Time for your pills", voice),
+		"text":   TTSCacheKey("google", "Time for your medicine", voice),
+		"voice":  TTSCacheKey("google", "Time for your pills", faster),
+	} {
+		if key == base {
+			t.Errorf("changing the %s did not change the key", name)
+		}
+	}
+}
+
+func TestCachingSynthesizerReusesAudioPerEngine(t *testing.T) {
+	ctx := context.Background()
+	_, queries := dbtest.Open(t)
+	dir := t.TempDir()
+	voice := DefaultVoiceSettings("en")
+
+	google := &fakeSynthesizer{engine: "google"}
+	cache := NewCachingSynthesizer(queries, google, dir, 1<<20)
+	for i := 0; i < 2; i++ {
+		result, err := cache.Synthesize(ctx, "Time for your pills", voice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(result.AudioBytes) != "google:Time for your pills" {
+			t.Fatalf("audio = %q", result.AudioBytes)
+		}
+	}
+	if n := google.callCount("Time for your pills"); n != 1 {
+		t.Fatalf("google synthesized %d times, want 1", n)
+	}
+
+	// The same text from another engine must not be served the cached audio.
+	espeak := &fakeSynthesizer{engine: "espeak-ng"}
+	result, err := NewCachingSynthesizer(queries, espeak, dir, 1<<20).Synthesize(ctx, "Time for your pills", voice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.AudioBytes) != "espeak-ng:Time for your pills" || espeak.callCount("Time for your pills") != 1 {
+		t.Fatalf("espeak-ng got %q after %d calls", result.AudioBytes, espeak.callCount("Time for your pills"))
+	}
+}
+
+func TestCachingSynthesizerConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+	_, queries := dbtest.Open(t)
+	voice := DefaultVoiceSettings("en")
+
+	release := make(chan struct{})
+	next := &fakeSynthesizer{
+		engine:  "google",
+		gates:   map[string]chan struct{}{"slow": release},
+		started: make(chan string, 1),
+	}
+	cache := NewCachingSynthesizer(queries, next, t.TempDir(), 1<<20)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Synthesize(ctx, "slow", voice); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	<-next.started
+
+	// A different text must not wait behind the slow synthesis.
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.Synthesize(ctx, "fast", voice)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("synthesizing another text waited for the slow one")
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if n := next.callCount("slow"); n != 1 {
+		t.Fatalf("concurrent requests synthesized %d times, want 1", n)
+	}
+}
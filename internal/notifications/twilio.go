@@ -3,8 +3,10 @@ package notifications
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,7 +18,14 @@ type TwilioSender struct {
 	accountSID          string
 	authToken           string
 	messagingServiceSID string
-	httpClient          *http.Client
+	// statusCallbackURL, when set, asks Twilio to post delivery status
+	// updates for every message sent.
+	statusCallbackURL string
+	httpClient        *http.Client
+}
+
+type twilioMessageResponse struct {
+	SID string `json:"sid"`
 }
 
 func NewTwilioSenderFromEnv() (*TwilioSender, error) {
@@ -28,10 +37,18 @@ func NewTwilioSenderFromEnv() (*TwilioSender, error) {
 		return nil, fmt.Errorf("missing TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN, or TWILIO_MESSAGING_SERVICE_SID")
 	}
 
+	statusCallbackURL := strings.TrimSpace(os.Getenv("TWILIO_STATUS_CALLBACK_URL"))
+	if statusCallbackURL == "" {
+		if baseURL := strings.TrimRight(strings.TrimSpace(os.Getenv("TWILIO_WEBHOOK_BASE_URL")), "/"); baseURL != "" {
+			statusCallbackURL = baseURL + StatusCallbackPath
+		}
+	}
+
 	return &TwilioSender{
 		accountSID:          accountSID,
 		authToken:           authToken,
 		messagingServiceSID: messagingServiceSID,
+		statusCallbackURL:   statusCallbackURL,
 		httpClient: &http.Client{
 			Timeout: 15 * time.Second,
 		},
@@ -50,6 +67,9 @@ func (t *TwilioSender) SendSMS(ctx context.Context, to, body string) (string, er
 	form.Set("To", to)
 	form.Set("MessagingServiceSid", t.messagingServiceSID)
 	form.Set("Body", body)
+	if t.statusCallbackURL != "" {
+		form.Set("StatusCallback", t.statusCallbackURL)
+	}
 
 	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", t.accountSID)
 
@@ -73,5 +93,17 @@ func (t *TwilioSender) SendSMS(ctx context.Context, to, body string) (string, er
 		return "", fmt.Errorf("twilio send failed: status=%d body=%s", resp.StatusCode, string(respBody))
 	}
 
-	return "", nil
-}
\ No newline at end of file
+	// Twilio accepted the message and will deliver it, so a response we cannot
+	// read is not a failed send; it is recorded as sent without a SID, and
+	// status callbacks for it go unmatched.
+	var message twilioMessageResponse
+	if err := json.Unmarshal(respBody, &message); err != nil {
+		log.Printf("twilio: decode response for accepted message: %v", err)
+		return "", nil
+	}
+	if message.SID == "" {
+		log.Printf("twilio: accepted message has no sid")
+	}
+
+	return message.SID, nil
+}
@@ -0,0 +1,107 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TwilioRequestValidator verifies the X-Twilio-Signature header that Twilio
+// attaches to webhook requests.
+type TwilioRequestValidator struct {
+	authToken string
+	// baseURL overrides the scheme and host Twilio called, for deployments
+	// behind a proxy that rewrites them.
+	baseURL string
+}
+
+func NewTwilioRequestValidatorFromEnv() (*TwilioRequestValidator, error) {
+	authToken := strings.TrimSpace(os.Getenv("TWILIO_AUTH_TOKEN"))
+	if authToken == "" {
+		return nil, fmt.Errorf("missing TWILIO_AUTH_TOKEN")
+	}
+
+	return &TwilioRequestValidator{
+		authToken: authToken,
+		baseURL:   strings.TrimRight(strings.TrimSpace(os.Getenv("TWILIO_WEBHOOK_BASE_URL")), "/"),
+	}, nil
+}
+
+// Validate parses the request form and checks its signature.
+func (v *TwilioRequestValidator) Validate(r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		return false
+	}
+
+	signature := r.Header.Get("X-Twilio-Signature")
+	if signature == "" {
+		return false
+	}
+
+	expected := twilioSignature(v.authToken, v.requestURL(r), r.PostForm)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (v *TwilioRequestValidator) requestURL(r *http.Request) string {
+	if v.baseURL != "" {
+		return v.baseURL + r.URL.RequestURI()
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host + r.URL.RequestURI()
+}
+
+// twilioSignature computes base64(HMAC-SHA1(authToken, url + sorted params)),
+// where each POST parameter contributes its name followed by its value.
+func twilioSignature(authToken, fullURL string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(fullURL)
+	for _, key := range keys {
+		for _, value := range params[key] {
+			b.WriteString(key)
+			b.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// WriteTwiML responds to a Twilio webhook, replying with message when it is
+// not empty.
+func WriteTwiML(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write([]byte(xml.Header + "<Response>"))
+	if message != "" {
+		_, _ = w.Write([]byte("<Message>"))
+		_ = xml.EscapeText(w, []byte(message))
+		_, _ = w.Write([]byte("</Message>"))
+	}
+	_, _ = w.Write([]byte("</Response>"))
+}
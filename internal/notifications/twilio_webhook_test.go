@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Twilio's published example for validating webhook signatures:
+// https://www.twilio.com/docs/usage/security#validating-requests
+const (
+	exampleAuthToken = "12345"
+	exampleURL       = "https://mycompany.com/myapp.php?foo=1&bar=2"
+	exampleSignature = "RSOYDt4T1cUTdK1PDd93/VVr8B8="
+)
+
+func exampleParams() url.Values {
+	return url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+14158675309"},
+		"Digits":  {"1234"},
+		"From":    {"+14158675309"},
+		"To":      {"+18005551212"},
+	}
+}
+
+func TestTwilioSignature(t *testing.T) {
+	if got := twilioSignature(exampleAuthToken, exampleURL, exampleParams()); got != exampleSignature {
+		t.Fatalf("twilioSignature = %q, want %q", got, exampleSignature)
+	}
+}
+
+func TestTwilioRequestValidator(t *testing.T) {
+	request := func(target string, params url.Values, signature string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(params.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if signature != "" {
+			r.Header.Set("X-Twilio-Signature", signature)
+		}
+		return r
+	}
+	tampered := exampleParams()
+	tampered.Set("Digits", "4321")
+
+	tests := []struct {
+		name      string
+		validator TwilioRequestValidator
+		request   *http.Request
+		want      bool
+	}{
+		{
+			name:      "example request",
+			validator: TwilioRequestValidator{authToken: exampleAuthToken},
+			request:   request(exampleURL, exampleParams(), exampleSignature),
+			want:      true,
+		},
+		{
+			name:      "behind a proxy with a base URL",
+			validator: TwilioRequestValidator{authToken: exampleAuthToken, baseURL: "https://mycompany.com"},
+			request:   request("http://10.0.0.5:8080/myapp.php?foo=1&bar=2", exampleParams(), exampleSignature),
+			want:      true,
+		},
+		{
+			name:      "forwarded scheme and host",
+			validator: TwilioRequestValidator{authToken: exampleAuthToken},
+			request: func() *http.Request {
+				r := request("http://10.0.0.5:8080/myapp.php?foo=1&bar=2", exampleParams(), exampleSignature)
+				r.Header.Set("X-Forwarded-Proto", "https")
+				r.Header.Set("X-Forwarded-Host", "mycompany.com")
+				return r
+			}(),
+			want: true,
+		},
+		{
+			name:      "tampered parameter",
+			validator: TwilioRequestValidator{authToken: exampleAuthToken},
+			request:   request(exampleURL, tampered, exampleSignature),
+		},
+		{
+			name:      "wrong auth token",
+			validator: TwilioRequestValidator{authToken: "54321"},
+			request:   request(exampleURL, exampleParams(), exampleSignature),
+		},
+		{
+			name:      "different URL",
+			validator: TwilioRequestValidator{authToken: exampleAuthToken},
+			request:   request("https://mycompany.com/myapp.php?foo=1&bar=3", exampleParams(), exampleSignature),
+		},
+		{
+			name:      "missing signature",
+			validator: TwilioRequestValidator{authToken: exampleAuthToken},
+			request:   request(exampleURL, exampleParams(), ""),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.validator.Validate(tc.request); got != tc.want {
+				t.Fatalf("Validate = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/internal/db"
+)
+
+// ErrInvalidVoiceMessage marks uploads rejected because of the recording
+// itself rather than a server failure.
+var ErrInvalidVoiceMessage = errors.New("invalid voice message")
+
+const (
+	MaxVoiceMessageBytes    = 10 << 20
+	MaxVoiceMessageDuration = 2 * time.Minute
+)
+
+// VoiceMessageDir returns VOICE_MESSAGE_DIR, defaulting to
+// AUDIO_BASE_DIR/voice-messages.
+func VoiceMessageDir() string {
+	dir := strings.TrimSpace(os.Getenv("VOICE_MESSAGE_DIR"))
+	if dir == "" {
+		dir = filepath.Join(AudioBaseDir(), "voice-messages")
+	}
+	return filepath.Clean(dir)
+}
+
+// VoiceMessageUpload is a caregiver recording to attach to a schedule.
+type VoiceMessageUpload struct {
+	PatientID  string
+	ScheduleID string
+	// DueAt limits the recording to one occurrence; nil attaches it to
+	// every occurrence of the schedule.
+	DueAt *time.Time
+	Data  []byte
+}
+
+// SaveVoiceMessage validates a WAV or Ogg Opus recording, converts it to the
+// 16-bit mono WAV the patient's device plays and stores it.
+func SaveVoiceMessage(ctx context.Context, queries *db.Queries, upload VoiceMessageUpload) (db.VoiceMessage, error) {
+	if len(upload.Data) == 0 {
+		return db.VoiceMessage{}, fmt.Errorf("%w: empty recording", ErrInvalidVoiceMessage)
+	}
+	if len(upload.Data) > MaxVoiceMessageBytes {
+		return db.VoiceMessage{}, fmt.Errorf("%w: recording larger than %d MiB", ErrInvalidVoiceMessage, MaxVoiceMessageBytes>>20)
+	}
+
+	patient, err := queries.GetPatient(ctx, upload.PatientID)
+	if err != nil {
+		return db.VoiceMessage{}, fmt.Errorf("load patient %s: %w", upload.PatientID, err)
+	}
+	schedule, err := queries.GetSchedule(ctx, upload.ScheduleID)
+	if err != nil {
+		return db.VoiceMessage{}, fmt.Errorf("load schedule %s: %w", upload.ScheduleID, err)
+	}
+	if schedule.PatientID != patient.ID {
+		return db.VoiceMessage{}, fmt.Errorf("%w: schedule %s does not belong to patient %s", ErrInvalidVoiceMessage, schedule.ID, patient.ID)
+	}
+
+	voice, err := LoadVoiceSettings(ctx, queries, patient.ID, patient.Locale)
+	if err != nil {
+		return db.VoiceMessage{}, fmt.Errorf("load voice settings: %w", err)
+	}
+
+	mimeType := sniffAudio(upload.Data)
+	if mimeType == "" {
+		return db.VoiceMessage{}, fmt.Errorf("%w: unsupported format, upload WAV or Ogg Opus", ErrInvalidVoiceMessage)
+	}
+	pcm, err := decodeRecording(ctx, upload.Data, mimeType, voice.SampleRateHz)
+	if err != nil {
+		return db.VoiceMessage{}, err
+	}
+	// Check the length before resampling, which allocates for the whole
+	// recording at the new rate.
+	duration := time.Duration(len(pcm.samples)) * time.Second / time.Duration(pcm.sampleRate)
+	if duration == 0 {
+		return db.VoiceMessage{}, fmt.Errorf("%w: recording has no audio", ErrInvalidVoiceMessage)
+	}
+	if duration > MaxVoiceMessageDuration {
+		return db.VoiceMessage{}, fmt.Errorf("%w: recording longer than %s", ErrInvalidVoiceMessage, MaxVoiceMessageDuration)
+	}
+	pcm = pcm.resample(voice.SampleRateHz)
+
+	dir := filepath.Join(VoiceMessageDir(), patient.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return db.VoiceMessage{}, err
+	}
+	id := uuid.NewString()
+	fullPath := filepath.Join(dir, id+".wav")
+	encoded := encodeWAV(pcm)
+	if err := os.WriteFile(fullPath, encoded, 0o644); err != nil {
+		return db.VoiceMessage{}, err
+	}
+
+	dueAt := sql.NullString{}
+	if upload.DueAt != nil {
+		dueAt = sql.NullString{String: formatDBTime(*upload.DueAt), Valid: true}
+	}
+
+	message, err := queries.CreateVoiceMessage(ctx, db.CreateVoiceMessageParams{
+		ID:               id,
+		PatientID:        patient.ID,
+		ScheduleID:       schedule.ID,
+		DueAtIso:         dueAt,
+		FilePath:         fullPath,
+		OriginalMimeType: mimeType,
+		SizeBytes:        int64(len(encoded)),
+		Sha256:           sha256Hex(encoded),
+		DurationMs:       duration.Milliseconds(),
+		SampleRateHz:     int64(pcm.sampleRate),
+	})
+	if err != nil {
+		os.Remove(fullPath)
+		return db.VoiceMessage{}, fmt.Errorf("create voice message: %w", err)
+	}
+	return message, nil
+}
+
+// VoiceMessageAudio returns the caregiver recording for an occurrence as a
+// WAV at sampleRate, or nil when there is none.
+func VoiceMessageAudio(ctx context.Context, queries *db.Queries, patientID, scheduleID string, dueAt time.Time, sampleRate int) ([]byte, error) {
+	message, err := queries.GetVoiceMessageForOccurrence(ctx, db.GetVoiceMessageForOccurrenceParams{
+		PatientID:  patientID,
+		ScheduleID: scheduleID,
+		DueAtIso:   sql.NullString{String: formatDBTime(dueAt), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load voice message: %w", err)
+	}
+
+	raw, err := os.ReadFile(message.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("read voice message %s: %w", message.ID, err)
+	}
+	if int(message.SampleRateHz) == sampleRate {
+		return raw, nil
+	}
+
+	// The patient's sample rate changed after the upload.
+	pcm, err := decodeWAV(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode voice message %s: %w", message.ID, err)
+	}
+	return encodeWAV(pcm.resample(sampleRate)), nil
+}
+
+// DeleteVoiceMessage deactivates a recording so reminders fall back to TTS
+// and removes its file. Clips already queued from it keep playing.
+func DeleteVoiceMessage(ctx context.Context, queries *db.Queries, id string) error {
+	message, err := queries.GetVoiceMessage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("load voice message %s: %w", id, err)
+	}
+	if err := queries.DeactivateVoiceMessage(ctx, message.ID); err != nil {
+		return fmt.Errorf("deactivate voice message: %w", err)
+	}
+	if err := os.Remove(message.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("voice messages: remove %s: %v", message.FilePath, err)
+	}
+	return nil
+}
+
+func sniffAudio(data []byte) string {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return MimeWAV
+	case len(data) >= 4 && string(data[0:4]) == "OggS" && bytes.Contains(data[:min(len(data), 128)], []byte("OpusHead")):
+		return MimeOpus
+	default:
+		return ""
+	}
+}
+
+// decodeRecording decodes 16-bit PCM WAV natively and hands anything else
+// (Opus, float or 24-bit WAV) to ffmpeg.
+func decodeRecording(ctx context.Context, data []byte, mimeType string, sampleRate int) (pcmAudio, error) {
+	if mimeType == MimeWAV {
+		if pcm, err := decodeWAV(data); err == nil {
+			return pcm, nil
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pillbox-voice-*")
+	if err != nil {
+		return pcmAudio{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "upload")
+	dst := filepath.Join(tmpDir, "normalized.wav")
+	if err := os.WriteFile(src, data, 0o600); err != nil {
+		return pcmAudio{}, err
+	}
+	// Stop just past the longest allowed message so a lying header cannot
+	// expand into hours of audio; SaveVoiceMessage rejects the overlong result.
+	limit := strconv.FormatFloat((MaxVoiceMessageDuration + time.Second).Seconds(), 'f', -1, 64)
+	if err := runFFmpeg(ctx, src, dst, "-t", limit, "-ac", "1", "-ar", strconv.Itoa(sampleRate), "-c:a", "pcm_s16le"); err != nil {
+		return pcmAudio{}, fmt.Errorf("convert recording: %w", err)
+	}
+
+	out, err := os.ReadFile(dst)
+	if err != nil {
+		return pcmAudio{}, err
+	}
+	return decodeWAV(out)
+}
+
+// HandleUploadVoiceMessage stores a caregiver recording sent either as the
+// raw request body or as the "file" part of a multipart form.
+func (h *AudioHTTPHandler) HandleUploadVoiceMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	// /patients/{patientID}/voice-messages?scheduleId=...&dueAt=...
+	parts := splitPath(r.URL.Path)
+	if len(parts) != 3 || parts[0] != "patients" || parts[2] != "voice-messages" {
+		http.NotFound(w, r)
+		return
+	}
+
+	upload := VoiceMessageUpload{
+		PatientID:  parts[1],
+		ScheduleID: strings.TrimSpace(r.URL.Query().Get("scheduleId")),
+	}
+	if upload.ScheduleID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error": "scheduleId is required",
+		})
+		return
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("dueAt")); raw != "" {
+		dueAt, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error": "dueAt must be RFC3339",
+			})
+			return
+		}
+		upload.DueAt = &dueAt
+	}
+
+	body := http.MaxBytesReader(w, r.Body, MaxVoiceMessageBytes+1<<20)
+	var reader io.Reader = body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		r.Body = body
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{
+				"error": "missing file part: " + err.Error(),
+			})
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, MaxVoiceMessageBytes+1))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	upload.Data = data
+
+	message, err := SaveVoiceMessage(r.Context(), h.queries, upload)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error": err.Error(),
+		})
+		return
+	case errors.Is(err, ErrInvalidVoiceMessage):
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":                 message.ID,
+		"patient_id":         message.PatientID,
+		"schedule_id":        message.ScheduleID,
+		"due_at":             message.DueAtIso.String,
+		"original_mime_type": message.OriginalMimeType,
+		"duration_ms":        message.DurationMs,
+		"sample_rate_hz":     message.SampleRateHz,
+	})
+}
@@ -0,0 +1,157 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Sample rates decodeWAV accepts: the span of SupportedSampleRates. Anything
+// else is a broken or hostile header, and resampling from it could need more
+// memory than the server has.
+const (
+	minWAVSampleRate = 8000
+	maxWAVSampleRate = 48000
+)
+
+// pcmAudio is 16-bit mono PCM audio.
+type pcmAudio struct {
+	sampleRate int
+	samples    []int16
+}
+
+// decodeWAV reads a 16-bit PCM WAV file, mixing stereo down to mono.
+func decodeWAV(data []byte) (pcmAudio, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return pcmAudio{}, fmt.Errorf("not a wav file")
+	}
+
+	var (
+		channels   int
+		sampleRate int
+		haveFormat bool
+	)
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkID := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		// Streaming encoders such as espeak-ng --stdout write a placeholder
+		// size; treat it as "until the end of the file".
+		if size < 0 || body+size > len(data) {
+			size = len(data) - body
+		}
+
+		switch chunkID {
+		case "fmt ":
+			if size < 16 {
+				return pcmAudio{}, fmt.Errorf("short wav fmt chunk")
+			}
+			format := binary.LittleEndian.Uint16(data[body : body+2])
+			channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			bits := binary.LittleEndian.Uint16(data[body+14 : body+16])
+			if (format != 1 && format != 0xFFFE) || bits != 16 {
+				return pcmAudio{}, fmt.Errorf("unsupported wav encoding (format=%d bits=%d)", format, bits)
+			}
+			if channels != 1 && channels != 2 {
+				return pcmAudio{}, fmt.Errorf("unsupported wav channel count %d", channels)
+			}
+			if sampleRate < minWAVSampleRate || sampleRate > maxWAVSampleRate {
+				return pcmAudio{}, fmt.Errorf("unsupported wav sample rate %d", sampleRate)
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return pcmAudio{}, fmt.Errorf("wav data before fmt chunk")
+			}
+			frames := size / (2 * channels)
+			samples := make([]int16, frames)
+			for i := range samples {
+				pos := body + i*2*channels
+				left := int16(binary.LittleEndian.Uint16(data[pos : pos+2]))
+				if channels == 2 {
+					right := int16(binary.LittleEndian.Uint16(data[pos+2 : pos+4]))
+					left = int16((int(left) + int(right)) / 2)
+				}
+				samples[i] = left
+			}
+			return pcmAudio{sampleRate: sampleRate, samples: samples}, nil
+		}
+
+		offset = body + size + size%2
+	}
+
+	return pcmAudio{}, fmt.Errorf("wav file has no data chunk")
+}
+
+// encodeWAV writes the audio as a 16-bit mono PCM WAV file.
+func encodeWAV(a pcmAudio) []byte {
+	dataSize := len(a.samples) * 2
+
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1)) // mono
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(a.sampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(a.sampleRate*2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	_ = binary.Write(&buf, binary.LittleEndian, a.samples)
+	return buf.Bytes()
+}
+
+// resample converts the audio to rate using linear interpolation, which is
+// good enough for speech played on the dispenser's speaker. Audio with no
+// usable rate, or a non-positive target, is returned unchanged.
+func (a pcmAudio) resample(rate int) pcmAudio {
+	if a.sampleRate <= 0 || rate <= 0 {
+		return a
+	}
+	if a.sampleRate == rate || len(a.samples) == 0 {
+		return pcmAudio{sampleRate: rate, samples: a.samples}
+	}
+
+	ratio := float64(a.sampleRate) / float64(rate)
+	out := make([]int16, int(float64(len(a.samples))/ratio))
+	for i := range out {
+		pos := float64(i) * ratio
+		idx := int(pos)
+		frac := pos - float64(idx)
+		next := idx + 1
+		if next >= len(a.samples) {
+			next = len(a.samples) - 1
+		}
+		out[i] = int16(float64(a.samples[idx])*(1-frac) + float64(a.samples[next])*frac)
+	}
+	return pcmAudio{sampleRate: rate, samples: out}
+}
+
+// toneSamples renders a sine tone with short fades so it does not click.
+func toneSamples(freqHz float64, durationMs, rate int) []int16 {
+	count := rate * durationMs / 1000
+	fade := rate / 100
+	samples := make([]int16, count)
+	for i := range samples {
+		amp := 0.4
+		if i < fade {
+			amp *= float64(i) / float64(fade)
+		} else if count-i < fade {
+			amp *= float64(count-i) / float64(fade)
+		}
+		samples[i] = int16(amp * math.MaxInt16 * math.Sin(2*math.Pi*freqHz*float64(i)/float64(rate)))
+	}
+	return samples
+}
+
+func silenceSamples(durationMs, rate int) []int16 {
+	return make([]int16, rate*durationMs/1000)
+}
@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// wavWithRate builds a 16-bit mono PCM WAV whose fmt chunk claims rate.
+func wavWithRate(rate uint32, samples int) []byte {
+	dataSize := uint32(samples * 2)
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&buf, binary.LittleEndian, rate)
+	_ = binary.Write(&buf, binary.LittleEndian, rate*2)
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, dataSize)
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
+
+func TestDecodeWAVRejectsBadSampleRates(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rate uint32
+	}{
+		{"zero", 0},
+		{"tiny", 1},
+		{"below range", minWAVSampleRate - 1},
+		{"above range", maxWAVSampleRate + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := decodeWAV(wavWithRate(tc.rate, 1600)); err == nil {
+				t.Fatalf("decodeWAV accepted sample rate %d", tc.rate)
+			}
+		})
+	}
+}
+
+func TestDecodeWAVAcceptsSupportedSampleRates(t *testing.T) {
+	for _, rate := range SupportedSampleRates {
+		pcm, err := decodeWAV(wavWithRate(uint32(rate), 1600))
+		if err != nil {
+			t.Fatalf("decodeWAV rejected %d Hz: %v", rate, err)
+		}
+		if pcm.sampleRate != rate || len(pcm.samples) != 1600 {
+			t.Fatalf("decodeWAV(%d Hz) = %d Hz, %d samples", rate, pcm.sampleRate, len(pcm.samples))
+		}
+	}
+}
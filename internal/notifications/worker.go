@@ -3,6 +3,7 @@ package notifications
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -15,19 +16,46 @@ import (
 	"pillbox/internal/db"
 )
 
-const fallbackTimezone = "America/Toronto"
+const (
+	fallbackTimezone = "America/Toronto"
+	// forecastInterval is how often a patient's refill forecast is rerun
+	// when neither their stock nor their schedules have changed.
+	forecastInterval = time.Hour
+)
+
+// RefillRequester asks the pharmacy to refill a medication the forecast says
+// is due, reporting whether a request was actually sent.
+type RefillRequester interface {
+	RequestForecastRefill(ctx context.Context, medicationID string) (bool, error)
+}
 
 type Worker struct {
-	queries   *db.Queries
-	sender    *TwilioSender
-	ttsClient *GoogleTTSClient
+	queries    *db.Queries
+	dispatcher *Dispatcher
+	// synthesizer is optional; without it no reminder audio is generated.
+	synthesizer Synthesizer
+	// refills is optional; without it caregivers contact the pharmacy
+	// themselves.
+	refills RefillRequester
+	// forecasts records each patient's last refill forecast run. Only the
+	// worker goroutine touches it.
+	forecasts map[string]forecastRun
+}
+
+// forecastRun is when a patient's refill forecast last ran and a fingerprint
+// of the stock and schedules it saw.
+type forecastRun struct {
+	at          time.Time
+	fingerprint string
 }
 
-func NewWorker(queries *db.Queries, sender *TwilioSender, ttsClient *GoogleTTSClient) *Worker {
+func NewWorker(queries *db.Queries, sender *TwilioSender, synthesizer Synthesizer, refills RefillRequester) *Worker {
 	return &Worker{
-		queries:   queries,
-		sender:    sender,
-		ttsClient: ttsClient,
+		queries:     queries,
+		dispatcher:  NewDispatcher(queries, sender),
+		synthesizer: synthesizer,
+		refills:     refills,
+		forecasts:   make(map[string]forecastRun),
 	}
 }
 
@@ -48,6 +76,10 @@ func (w *Worker) Start(ctx context.Context) {
 }
 
 func (w *Worker) runOnce(ctx context.Context) {
+	if err := w.dispatcher.FlushOutbox(ctx); err != nil {
+		log.Printf("notification worker: flush outbox: %v", err)
+	}
+
 	patients, err := w.queries.ListPatients(ctx)
 	if err != nil {
 		log.Printf("notification worker: list patients: %v", err)
@@ -69,7 +101,7 @@ func (w *Worker) runOnce(ctx context.Context) {
 			continue
 		}
 
-		loc := patientLocation(patient.Timezone)
+		loc := PatientLocation(patient.Timezone)
 
 		schedules, err := w.queries.ListSchedulesByPatient(ctx, patient.ID)
 		if err != nil {
@@ -107,7 +139,7 @@ func (w *Worker) runOnce(ctx context.Context) {
 				continue
 			}
 
-			alreadySent, err := w.hasNotificationEvent(ctx, patient.ID, schedule.ID, *dueTime, "SMS")
+			alreadySent, err := w.hasNotificationEvent(ctx, patient.ID, schedule.ID, *dueTime, ChannelSMS)
 			if err != nil {
 				log.Printf("notification worker: check existing notification event: %v", err)
 				continue
@@ -122,23 +154,16 @@ func (w *Worker) runOnce(ctx context.Context) {
 				continue
 			}
 
-			medParts := make([]string, 0, len(items))
-			for _, item := range items {
-				label := strings.TrimSpace(item.MedicationLabel)
-				if label == "" {
-					label = "medication"
-				}
-				medParts = append(medParts, fmt.Sprintf("%d %s", item.Qty, label))
+			data := MessageData{
+				FirstName:   patient.FirstName,
+				Medications: FormatDoseList(items, patient.ShowMedicationNames != 0),
+				DueAt:       dueTime.In(loc),
+			}
+			message, err := RenderMessage(user.Locale, TypeDoseReminder, ChannelSMS, data)
+			if err != nil {
+				log.Printf("notification worker: render reminder for schedule %s: %v", schedule.ID, err)
+				continue
 			}
-
-			meds := strings.Join(medParts, ", ")
-			localDue := dueTime.In(loc).Format("3:04 PM")
-			message := fmt.Sprintf(
-				"Hi %s, this is your DoseDock reminder to take your %s at %s.",
-				patient.FirstName,
-				meds,
-				localDue,
-			)
 
 			log.Printf(
 				"notification worker: sending sms to %s for patient=%s schedule=%s due_local=%s due_utc=%s tz=%s",
@@ -150,15 +175,26 @@ func (w *Worker) runOnce(ctx context.Context) {
 				loc.String(),
 			)
 
-			providerID, sendErr := w.sender.SendSMS(ctx, user.Phone.String, message)
+			result, sendErr := w.dispatcher.Dispatch(ctx, Notification{
+				UserID:      user.ID,
+				PatientID:   patient.ID,
+				Type:        TypeDoseReminder,
+				Destination: user.Phone.String,
+				Message:     message,
+				Timezone:    user.Timezone,
+			})
 
-			status := "SENT"
+			status := result.Status
 			errorMessage := sql.NullString{}
 			if sendErr != nil {
 				status = "FAILED"
 				errorMessage = sql.NullString{String: sendErr.Error(), Valid: true}
 				log.Printf("notification worker: send sms failed: %v", sendErr)
 			}
+			deliveryStatus := sql.NullString{}
+			if status == DispatchSent {
+				deliveryStatus = nullableString(DeliveryQueued)
+			}
 
 			_, createErr := w.queries.CreateNotificationEvent(ctx, db.CreateNotificationEventParams{
 				ID:                uuid.NewString(),
@@ -166,34 +202,371 @@ func (w *Worker) runOnce(ctx context.Context) {
 				ScheduleID:        schedule.ID,
 				UserID:            sql.NullString{String: user.ID, Valid: true},
 				DueAtIso:          formatDBTime(*dueTime),
-				Channel:           "SMS",
+				Channel:           ChannelSMS,
 				Destination:       user.Phone.String,
 				Message:           message,
 				Status:            status,
-				ProviderMessageID: nullableString(providerID),
+				ProviderMessageID: nullableString(result.ProviderMessageID),
 				ErrorMessage:      errorMessage,
+				DeliveryStatus:    deliveryStatus,
 			})
 			if createErr != nil {
 				log.Printf("notification worker: create notification event failed: %v", createErr)
 			}
 
-			if w.ttsClient != nil {
-				audioResult, err := w.ttsClient.SynthesizeDefaultReminder(ctx, message)
+			audioPref, err := LoadPreference(ctx, w.queries, user.ID, TypeDoseReminder, ChannelAudio)
+			if err != nil {
+				log.Printf("notification worker: load audio preference for user %s: %v", user.ID, err)
+				continue
+			}
+			if !audioPref.Enabled {
+				continue
+			}
+
+			voice, err := LoadVoiceSettings(ctx, w.queries, patient.ID, patient.Locale)
+			if err != nil {
+				log.Printf("notification worker: load voice settings for patient %s: %v", patient.ID, err)
+				continue
+			}
+
+			// A caregiver recording for this occurrence replaces the TTS reminder.
+			audio, err := VoiceMessageAudio(ctx, w.queries, patient.ID, schedule.ID, *dueTime, voice.SampleRateHz)
+			if err != nil {
+				log.Printf("notification worker: voice message for patient=%s schedule=%s: %v", patient.ID, schedule.ID, err)
+			}
+
+			if audio == nil && w.synthesizer != nil {
+				spoken, err := RenderMessage(patient.Locale, TypeDoseReminder, ChannelAudio, data)
+				if err != nil {
+					log.Printf("notification worker: render spoken reminder for schedule %s: %v", schedule.ID, err)
+					continue
+				}
+
+				audioResult, err := w.synthesizer.Synthesize(ctx, spoken, voice)
 				if err != nil {
 					log.Printf("notification worker: tts failed for patient=%s schedule=%s: %v", patient.ID, schedule.ID, err)
-				} else {
-					audioPath, err := SaveReminderWAV(patient.ID, schedule.ID, formatDBTime(*dueTime), audioResult.AudioBytes)
-					if err != nil {
-						log.Printf("notification worker: save audio failed for patient=%s schedule=%s: %v", patient.ID, schedule.ID, err)
-					} else {
-						log.Printf("notification worker: saved reminder audio at %s", audioPath)
+					continue
+				}
+				audio = audioResult.AudioBytes
+			}
+			if audio == nil {
+				continue
+			}
+
+			queued, err := QueueReminderAudio(ctx, w.queries, patient.ID, schedule.ID, *dueTime, audio)
+			if err != nil {
+				log.Printf("notification worker: save audio failed for patient=%s schedule=%s: %v", patient.ID, schedule.ID, err)
+			} else {
+				log.Printf("notification worker: saved reminder audio at %s", queued.FilePath)
+			}
+		}
+
+		w.checkRefillForecast(ctx, patient, user, loc)
+		w.checkLotExpiry(ctx, patient, user, loc)
+		w.checkPrescriptions(ctx, patient, user, loc)
+	}
+}
+
+// checkPrescriptions asks the caregiver to get a prescription renewed once it
+// is within PrescriptionExpiryAlertDays of expiring and once it has no refills
+// left.
+func (w *Worker) checkPrescriptions(ctx context.Context, patient db.Patient, user db.GetUserRow, loc *time.Location) {
+	now := time.Now().In(loc)
+	cutoff := now.AddDate(0, 0, PrescriptionExpiryAlertDays()).Format(time.DateOnly)
+
+	rows, err := w.queries.ListPrescriptionsNeedingRenewal(ctx, db.ListPrescriptionsNeedingRenewalParams{
+		PatientID:     patient.ID,
+		ExpiresBefore: nullableString(cutoff),
+	})
+	if err != nil {
+		log.Printf("notification worker: list prescriptions for patient %s: %v", patient.ID, err)
+		return
+	}
+
+	for _, row := range rows {
+		prescription := row.Prescription
+		silo := int64(0)
+		if row.MedicationCartridgeIndex.Valid {
+			silo = row.MedicationCartridgeIndex.Int64
+		}
+		data := MessageData{
+			FirstName:  patient.FirstName,
+			Silo:       silo + 1,
+			Prescriber: prescription.PrescriberName,
+		}
+
+		if !prescription.ExpiryAlertedAt.Valid && prescription.ExpiresOn.Valid && prescription.ExpiresOn.String <= cutoff {
+			expiresOn, err := time.ParseInLocation(time.DateOnly, prescription.ExpiresOn.String, loc)
+			if err != nil {
+				log.Printf("notification worker: parse expiry of prescription %s: %v", prescription.ID, err)
+			} else {
+				data.ExpiresOn = expiresOn
+				if w.sendPrescriptionAlert(ctx, patient, user, TypePrescriptionExpiring, data, prescription.ID) {
+					if err := w.queries.MarkPrescriptionExpiryAlerted(ctx, db.MarkPrescriptionExpiryAlertedParams{
+						ExpiryAlertedAt: nullableString(formatDBTime(now)),
+						ID:              prescription.ID,
+					}); err != nil {
+						log.Printf("notification worker: mark prescription %s expiry alerted: %v", prescription.ID, err)
 					}
 				}
 			}
 		}
+
+		if !prescription.NoRefillsAlertedAt.Valid && prescription.RefillsRemaining == 0 {
+			if w.sendPrescriptionAlert(ctx, patient, user, TypeNoRefillsLeft, data, prescription.ID) {
+				if err := w.queries.MarkPrescriptionNoRefillsAlerted(ctx, db.MarkPrescriptionNoRefillsAlertedParams{
+					NoRefillsAlertedAt: nullableString(formatDBTime(now)),
+					ID:                 prescription.ID,
+				}); err != nil {
+					log.Printf("notification worker: mark prescription %s no refills alerted: %v", prescription.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// sendPrescriptionAlert reports whether the alert was handed to the
+// dispatcher.
+func (w *Worker) sendPrescriptionAlert(ctx context.Context, patient db.Patient, user db.GetUserRow, notificationType string, data MessageData, prescriptionID string) bool {
+	message, err := RenderMessage(user.Locale, notificationType, ChannelSMS, data)
+	if err != nil {
+		log.Printf("notification worker: render %s for prescription %s: %v", notificationType, prescriptionID, err)
+		return false
+	}
+
+	if _, err := w.dispatcher.Dispatch(ctx, Notification{
+		UserID:      user.ID,
+		PatientID:   patient.ID,
+		Type:        notificationType,
+		Destination: user.Phone.String,
+		Message:     message,
+		Timezone:    user.Timezone,
+	}); err != nil {
+		log.Printf("notification worker: send %s for prescription %s: %v", notificationType, prescriptionID, err)
+		return false
+	}
+	return true
+}
+
+// checkLotExpiry warns the caregiver once about each lot still in a silo
+// that expires within LotExpiryAlertDays.
+func (w *Worker) checkLotExpiry(ctx context.Context, patient db.Patient, user db.GetUserRow, loc *time.Location) {
+	now := time.Now().In(loc)
+	cutoff := now.AddDate(0, 0, LotExpiryAlertDays()).Format(time.DateOnly)
+
+	lots, err := w.queries.ListExpiringLotsByPatient(ctx, db.ListExpiringLotsByPatientParams{
+		PatientID:     patient.ID,
+		ExpiresBefore: nullableString(cutoff),
+	})
+	if err != nil {
+		log.Printf("notification worker: list expiring lots for patient %s: %v", patient.ID, err)
+		return
+	}
+
+	for _, lot := range lots {
+		expiresOn, err := time.ParseInLocation(time.DateOnly, lot.ExpiresOn.String, loc)
+		if err != nil {
+			log.Printf("notification worker: parse expiry of lot %s: %v", lot.ID, err)
+			continue
+		}
+
+		silo := int64(0)
+		if lot.Silo.Valid {
+			silo = lot.Silo.Int64
+		}
+		message, err := RenderMessage(user.Locale, TypeLotExpiry, ChannelSMS, MessageData{
+			FirstName: patient.FirstName,
+			Silo:      silo + 1,
+			Stock:     lot.QuantityRemaining,
+			LotNumber: lot.LotNumber.String,
+			ExpiresOn: expiresOn,
+		})
+		if err != nil {
+			log.Printf("notification worker: render lot expiry for lot %s: %v", lot.ID, err)
+			continue
+		}
+
+		if _, err := w.dispatcher.Dispatch(ctx, Notification{
+			UserID:      user.ID,
+			PatientID:   patient.ID,
+			Type:        TypeLotExpiry,
+			Destination: user.Phone.String,
+			Message:     message,
+			Timezone:    user.Timezone,
+		}); err != nil {
+			log.Printf("notification worker: send lot expiry for lot %s: %v", lot.ID, err)
+			continue
+		}
+
+		if err := w.queries.MarkLotExpiryAlerted(ctx, db.MarkLotExpiryAlertedParams{
+			ExpiryAlertedAt: nullableString(formatDBTime(now)),
+			ID:              lot.ID,
+		}); err != nil {
+			log.Printf("notification worker: mark lot %s expiry alerted: %v", lot.ID, err)
+		}
 	}
 }
 
+// checkRefillForecast warns the caregiver once per refill cycle when a
+// medication is forecast to run out within RunOutAlertDays, or within the
+// pharmacy lead time if that is longer.
+// checkRefillForecast warns the caregiver before a medication runs out and
+// requests refills that are due. The forecast reruns at most once per
+// forecastInterval unless the patient's stock or schedules change, and only
+// for medications still waiting on an alert or a refill request.
+func (w *Worker) checkRefillForecast(ctx context.Context, patient db.Patient, user db.GetUserRow, loc *time.Location) {
+	now := time.Now()
+
+	medications, err := w.queries.ListMedicationsByPatient(ctx, patient.ID)
+	if err != nil {
+		log.Printf("notification worker: list medications for patient %s: %v", patient.ID, err)
+		return
+	}
+	schedules, err := w.queries.ListSchedulesByPatient(ctx, patient.ID)
+	if err != nil {
+		log.Printf("notification worker: list schedules for patient %s: %v", patient.ID, err)
+		return
+	}
+
+	fingerprint := forecastFingerprint(patient, medications, schedules)
+	if last, ok := w.forecasts[patient.ID]; ok && last.fingerprint == fingerprint && now.Sub(last.at) < forecastInterval {
+		return
+	}
+
+	pending := make([]db.Medication, 0, len(medications))
+	for _, medication := range medications {
+		needed, err := w.needsForecast(ctx, patient, medication)
+		if err != nil {
+			log.Printf("notification worker: check refill state for medication %s: %v", medication.ID, err)
+			return
+		}
+		if needed {
+			pending = append(pending, medication)
+		}
+	}
+
+	forecasts, err := forecastMedications(ctx, w.queries, patient, pending, schedules, now)
+	if err != nil {
+		log.Printf("notification worker: forecast for patient %s: %v", patient.ID, err)
+		return
+	}
+	w.forecasts[patient.ID] = forecastRun{at: now, fingerprint: fingerprint}
+
+	alertDays := max(int64(RunOutAlertDays()), patient.PharmacyLeadTimeDays)
+	alertFrom := now.Add(time.Duration(alertDays) * 24 * time.Hour)
+
+	for _, forecast := range forecasts {
+		medication := forecast.Medication
+		if forecast.RefillBy != nil && !forecast.RefillBy.After(now) {
+			w.requestRefill(ctx, patient, medication)
+		}
+		if forecast.RunOutAt == nil || medication.RunoutAlertedAt.Valid || forecast.RunOutAt.After(alertFrom) {
+			continue
+		}
+
+		silo := int64(0)
+		if medication.CartridgeIndex.Valid {
+			silo = medication.CartridgeIndex.Int64
+		}
+		message, err := RenderMessage(user.Locale, TypeRefillForecast, ChannelSMS, MessageData{
+			FirstName: patient.FirstName,
+			Silo:      silo + 1,
+			Stock:     medication.StockCount,
+			RunOutAt:  forecast.RunOutAt.In(loc),
+			RefillBy:  forecast.RefillBy.In(loc),
+		})
+		if err != nil {
+			log.Printf("notification worker: render refill forecast for medication %s: %v", medication.ID, err)
+			continue
+		}
+
+		if _, err := w.dispatcher.Dispatch(ctx, Notification{
+			UserID:      user.ID,
+			PatientID:   patient.ID,
+			Type:        TypeRefillForecast,
+			Destination: user.Phone.String,
+			Message:     message,
+			Timezone:    user.Timezone,
+		}); err != nil {
+			log.Printf("notification worker: send refill forecast for medication %s: %v", medication.ID, err)
+			continue
+		}
+
+		if err := w.queries.MarkRunoutAlerted(ctx, db.MarkRunoutAlertedParams{
+			RunoutAlertedAt: nullableString(formatDBTime(now)),
+			ID:              medication.ID,
+		}); err != nil {
+			log.Printf("notification worker: mark run-out alerted for medication %s: %v", medication.ID, err)
+		}
+	}
+}
+
+// needsForecast reports whether the forecast could still do something for
+// the medication: warn about its run-out, or request a refill when there is
+// no open request.
+func (w *Worker) needsForecast(ctx context.Context, patient db.Patient, medication db.Medication) (bool, error) {
+	if !medication.RunoutAlertedAt.Valid {
+		return true, nil
+	}
+	if w.refills == nil || patient.AutoRefillRequests == 0 {
+		return false, nil
+	}
+	_, err := w.queries.GetOpenRefillRequest(ctx, medication.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	return false, err
+}
+
+// forecastFingerprint summarises everything the forecast reads, so a change
+// to stock, schedules or the patient's pharmacy settings reruns it at once.
+func forecastFingerprint(patient db.Patient, medications []db.Medication, schedules []db.Schedule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%d|%d\n", patient.Timezone, patient.UpdatedAt, patient.PharmacyLeadTimeDays, patient.AutoRefillRequests)
+	for _, m := range medications {
+		fmt.Fprintf(&b, "m|%s|%d|%s|%s\n", m.ID, m.StockCount, m.UpdatedAt, m.RunoutAlertedAt.String)
+	}
+	for _, s := range schedules {
+		fmt.Fprintf(&b, "s|%s|%s|%s|%s|%s|%s\n", s.ID, s.Status, s.UpdatedAt, s.Rrule, s.StartDateIso, s.EndDateIso.String)
+	}
+	return b.String()
+}
+
+// requestRefill sends a refill request to the pharmacy once the forecast
+// run-out is within the pharmacy lead time, for patients whose caregiver
+// opted in.
+func (w *Worker) requestRefill(ctx context.Context, patient db.Patient, medication db.Medication) {
+	if w.refills == nil || patient.AutoRefillRequests == 0 {
+		return
+	}
+	requested, err := w.refills.RequestForecastRefill(ctx, medication.ID)
+	if err != nil {
+		log.Printf("notification worker: request refill for medication %s: %v", medication.ID, err)
+		return
+	}
+	if requested {
+		log.Printf("notification worker: refill requested for medication %s", medication.ID)
+	}
+}
+
+// FormatDoseList renders schedule items as "1 Silo 1 - Green, 2 Silo 2 - Amber".
+// With showNames, medications linked to the catalog use their drug name
+// instead of the anonymized label.
+func FormatDoseList(items []db.ListScheduleItemsByScheduleRow, showNames bool) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		label := strings.TrimSpace(item.MedicationLabel)
+		if showNames && item.MedicationCatalogName.Valid && strings.TrimSpace(item.MedicationCatalogName.String) != "" {
+			label = strings.TrimSpace(item.MedicationCatalogName.String)
+		}
+		if label == "" {
+			label = "medication"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", item.Qty, label))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (w *Worker) hasNotificationEvent(ctx context.Context, patientID, scheduleID string, dueAt time.Time, channel string) (bool, error) {
 	_, err := w.queries.GetNotificationEventByOccurrence(ctx, db.GetNotificationEventByOccurrenceParams{
 		PatientID:  patientID,
@@ -217,7 +590,9 @@ func nullableString(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: true}
 }
 
-func patientLocation(tz string) *time.Location {
+// PatientLocation loads a patient's timezone, falling back to
+// America/Toronto when it is empty or unknown.
+func PatientLocation(tz string) *time.Location {
 	name := fallbackTimezone
 	if strings.TrimSpace(tz) != "" {
 		name = strings.TrimSpace(tz)
@@ -308,4 +683,4 @@ func isScheduleDueNow(
 
 	occurrence := occurrences[0].In(loc)
 	return &occurrence, nil
-}
\ No newline at end of file
+}
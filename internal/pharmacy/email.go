@@ -0,0 +1,119 @@
+package pharmacy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultSMTPPort = "587"
+
+// Mailer sends plain-text email through an SMTP relay. Fax-to-email gateways
+// are just another recipient address.
+type Mailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+// NewMailerFromEnv reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME,
+// SMTP_PASSWORD and SMTP_FROM. Username and password are optional for
+// relays that accept unauthenticated mail.
+func NewMailerFromEnv() (*Mailer, error) {
+	host := strings.TrimSpace(os.Getenv("SMTP_HOST"))
+	from := strings.TrimSpace(os.Getenv("SMTP_FROM"))
+	if host == "" || from == "" {
+		return nil, fmt.Errorf("missing SMTP_HOST or SMTP_FROM")
+	}
+	port := strings.TrimSpace(os.Getenv("SMTP_PORT"))
+	if port == "" {
+		port = defaultSMTPPort
+	}
+
+	return &Mailer{
+		host:     host,
+		port:     port,
+		username: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     from,
+		timeout:  15 * time.Second,
+	}, nil
+}
+
+// Send delivers one message, upgrading to TLS when the relay offers
+// STARTTLS.
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	if strings.TrimSpace(to) == "" {
+		return fmt.Errorf("missing recipient address")
+	}
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("recipient and subject cannot contain line breaks")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, m.port))
+	if err != nil {
+		return fmt.Errorf("connect to smtp relay: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if _, err := writer.Write([]byte(msg.String())); err != nil {
+		writer.Close()
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+	return client.Quit()
+}
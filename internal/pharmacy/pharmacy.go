@@ -0,0 +1,362 @@
+// Package pharmacy sends refill requests to a patient's pharmacy and tracks
+// them from REQUESTED through READY to PICKED_UP.
+package pharmacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pillbox/internal/db"
+)
+
+const (
+	StatusRequested = "REQUESTED"
+	StatusReady     = "READY"
+	StatusPickedUp  = "PICKED_UP"
+	StatusCancelled = "CANCELLED"
+
+	SourceManual   = "MANUAL"
+	SourceForecast = "FORECAST"
+
+	ChannelEmail   = "EMAIL"
+	ChannelFax     = "FAX"
+	ChannelWebhook = "WEBHOOK"
+
+	deliverySent   = "SENT"
+	deliveryFailed = "FAILED"
+)
+
+// ErrOpenRequest is returned when the medication already has a refill request
+// that hasn't been picked up or cancelled.
+var ErrOpenRequest = errors.New("medication already has an open refill request")
+
+// transitions lists the statuses each status can move to. Pickup may skip
+// READY when the pharmacy never confirmed.
+var transitions = map[string][]string{
+	StatusRequested: {StatusReady, StatusPickedUp, StatusCancelled},
+	StatusReady:     {StatusPickedUp, StatusCancelled},
+}
+
+// Service creates refill requests and delivers them through every channel
+// the pharmacy has configured.
+type Service struct {
+	queries *db.Queries
+	// mailer is optional; without it email and fax deliveries fail and the
+	// caregiver has to call the pharmacy.
+	mailer  *Mailer
+	webhook *WebhookClient
+}
+
+func NewService(queries *db.Queries, mailer *Mailer, webhook *WebhookClient) *Service {
+	return &Service{
+		queries: queries,
+		mailer:  mailer,
+		webhook: webhook,
+	}
+}
+
+// Request describes a refill to ask for. Empty fields are filled from the
+// medication's refillable prescription.
+type Request struct {
+	MedicationID   string
+	PrescriptionID string
+	PharmacyID     string
+	Quantity       int64
+	Source         string
+}
+
+// Order is what the pharmacy receives, as the webhook's JSON body and as the
+// text of the email.
+type Order struct {
+	RequestID   string    `json:"requestId"`
+	RequestedAt time.Time `json:"requestedAt"`
+	Pharmacy    string    `json:"pharmacy"`
+	Patient     struct {
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"patient"`
+	Medication struct {
+		Name       string `json:"name"`
+		Ingredient string `json:"ingredient,omitempty"`
+		Strength   string `json:"strength,omitempty"`
+		DosageForm string `json:"dosageForm,omitempty"`
+	} `json:"medication"`
+	RxNumber   string `json:"rxNumber,omitempty"`
+	Prescriber string `json:"prescriber,omitempty"`
+	Quantity   int64  `json:"quantity"`
+}
+
+// Request records a refill request and sends it to the pharmacy. Delivery
+// failures are recorded against the request rather than returned, so the
+// caregiver can see which channels need a phone call instead.
+func (s *Service) Request(ctx context.Context, req Request) (db.RefillRequest, error) {
+	medication, err := s.queries.GetMedication(ctx, req.MedicationID)
+	if err != nil {
+		return db.RefillRequest{}, fmt.Errorf("load medication %s: %w", req.MedicationID, err)
+	}
+	if _, err := s.queries.GetOpenRefillRequest(ctx, medication.ID); err == nil {
+		return db.RefillRequest{}, ErrOpenRequest
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return db.RefillRequest{}, fmt.Errorf("check open refill requests: %w", err)
+	}
+
+	prescription, err := s.prescriptionFor(ctx, medication, req.PrescriptionID)
+	if err != nil {
+		return db.RefillRequest{}, err
+	}
+
+	pharmacyID := strings.TrimSpace(req.PharmacyID)
+	if pharmacyID == "" && prescription.PharmacyID.Valid {
+		pharmacyID = prescription.PharmacyID.String
+	}
+	if pharmacyID == "" {
+		return db.RefillRequest{}, fmt.Errorf("prescription %s has no pharmacy", prescription.ID)
+	}
+	pharmacy, err := s.queries.GetPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return db.RefillRequest{}, fmt.Errorf("load pharmacy %s: %w", pharmacyID, err)
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = prescription.QuantityPerFill
+	}
+	if quantity < 1 {
+		return db.RefillRequest{}, fmt.Errorf("quantity must be positive")
+	}
+
+	patient, err := s.queries.GetPatient(ctx, medication.PatientID)
+	if err != nil {
+		return db.RefillRequest{}, fmt.Errorf("load patient %s: %w", medication.PatientID, err)
+	}
+
+	source := req.Source
+	if source == "" {
+		source = SourceManual
+	}
+	now := time.Now().UTC()
+	record, err := s.queries.CreateRefillRequest(ctx, db.CreateRefillRequestParams{
+		ID:             uuid.NewString(),
+		PatientID:      patient.ID,
+		MedicationID:   medication.ID,
+		PrescriptionID: sql.NullString{String: prescription.ID, Valid: true},
+		PharmacyID:     sql.NullString{String: pharmacy.ID, Valid: true},
+		RxNumber:       prescription.RxNumber,
+		Quantity:       quantity,
+		Source:         source,
+		RequestedAt:    formatDBTime(now),
+		UpdatedAt:      formatDBTime(now),
+	})
+	if err != nil {
+		return db.RefillRequest{}, fmt.Errorf("create refill request: %w", err)
+	}
+
+	order := Order{
+		RequestID:   record.ID,
+		RequestedAt: now,
+		Pharmacy:    pharmacy.Name,
+		RxNumber:    prescription.RxNumber.String,
+		Prescriber:  prescription.PrescriberName,
+		Quantity:    quantity,
+	}
+	order.Patient.FirstName = patient.FirstName
+	order.Patient.LastName = patient.LastName
+	order.Medication.Name = medication.Label
+	if medication.CatalogID.Valid {
+		entry, err := s.queries.GetCatalogEntry(ctx, medication.CatalogID.String)
+		if err != nil {
+			return db.RefillRequest{}, fmt.Errorf("load catalog entry %s: %w", medication.CatalogID.String, err)
+		}
+		order.Medication.Name = entry.Name
+		order.Medication.Ingredient = entry.Ingredient
+		order.Medication.Strength = strings.TrimSpace(entry.Strength.String + " " + entry.Unit.String)
+		order.Medication.DosageForm = entry.DosageForm.String
+	}
+
+	s.deliver(ctx, pharmacy, order)
+	return record, nil
+}
+
+// RequestForecastRefill is Request for the notification worker. Medications
+// with an open request, or without a refillable prescription at a pharmacy,
+// are skipped rather than reported so they aren't retried as errors every
+// tick; it returns whether a request was made.
+func (s *Service) RequestForecastRefill(ctx context.Context, medicationID string) (bool, error) {
+	if _, err := s.queries.GetOpenRefillRequest(ctx, medicationID); err == nil {
+		return false, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("check open refill requests: %w", err)
+	}
+
+	prescription, err := s.queries.GetRefillablePrescription(ctx, db.GetRefillablePrescriptionParams{
+		MedicationID: medicationID,
+		Today:        sql.NullString{String: time.Now().UTC().Format(time.DateOnly), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("find refillable prescription: %w", err)
+	}
+	if !prescription.PharmacyID.Valid {
+		return false, nil
+	}
+
+	if _, err := s.Request(ctx, Request{
+		MedicationID:   medicationID,
+		PrescriptionID: prescription.ID,
+		Source:         SourceForecast,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// prescriptionFor returns prescriptionID, or when it is empty the
+// medication's refillable prescription that expires first.
+func (s *Service) prescriptionFor(ctx context.Context, medication db.Medication, prescriptionID string) (db.Prescription, error) {
+	today := time.Now().UTC().Format(time.DateOnly)
+
+	if prescriptionID = strings.TrimSpace(prescriptionID); prescriptionID == "" {
+		prescription, err := s.queries.GetRefillablePrescription(ctx, db.GetRefillablePrescriptionParams{
+			MedicationID: medication.ID,
+			Today:        sql.NullString{String: today, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Prescription{}, fmt.Errorf("medication %s has no active prescription with refills left", medication.ID)
+		}
+		if err != nil {
+			return db.Prescription{}, fmt.Errorf("find refillable prescription: %w", err)
+		}
+		return prescription, nil
+	}
+
+	prescription, err := s.queries.GetPrescription(ctx, prescriptionID)
+	if err != nil {
+		return db.Prescription{}, fmt.Errorf("load prescription %s: %w", prescriptionID, err)
+	}
+	switch {
+	case prescription.MedicationID != medication.ID:
+		return db.Prescription{}, fmt.Errorf("prescription %s is not for medication %s", prescription.ID, medication.ID)
+	case prescription.Active == 0:
+		return db.Prescription{}, fmt.Errorf("prescription %s is not active", prescription.ID)
+	case prescription.ExpiresOn.Valid && prescription.ExpiresOn.String < today:
+		return db.Prescription{}, fmt.Errorf("prescription %s expired on %s", prescription.ID, prescription.ExpiresOn.String)
+	case prescription.RefillsRemaining == 0:
+		return db.Prescription{}, fmt.Errorf("prescription %s has no refills left", prescription.ID)
+	}
+	return prescription, nil
+}
+
+// deliver sends the order through each configured channel and records the
+// outcome of every attempt.
+func (s *Service) deliver(ctx context.Context, pharmacy db.Pharmacy, order Order) {
+	subject := fmt.Sprintf("Refill request: %s for %s %s", order.Medication.Name, order.Patient.FirstName, order.Patient.LastName)
+	body := formatOrder(order)
+
+	sendMail := func(to string) error {
+		if s.mailer == nil {
+			return fmt.Errorf("email is not configured")
+		}
+		return s.mailer.Send(ctx, to, subject, body)
+	}
+
+	if pharmacy.Email.Valid {
+		s.recordDelivery(ctx, order.RequestID, ChannelEmail, pharmacy.Email.String, sendMail(pharmacy.Email.String))
+	}
+	if pharmacy.FaxEmail.Valid {
+		s.recordDelivery(ctx, order.RequestID, ChannelFax, pharmacy.FaxEmail.String, sendMail(pharmacy.FaxEmail.String))
+	}
+	if pharmacy.WebhookUrl.Valid {
+		var err error
+		if s.webhook == nil {
+			err = fmt.Errorf("webhooks are not configured")
+		} else {
+			err = s.webhook.Post(ctx, pharmacy.WebhookUrl.String, order)
+		}
+		s.recordDelivery(ctx, order.RequestID, ChannelWebhook, pharmacy.WebhookUrl.String, err)
+	}
+}
+
+func (s *Service) recordDelivery(ctx context.Context, requestID, channel, destination string, sendErr error) {
+	status := deliverySent
+	errText := sql.NullString{}
+	if sendErr != nil {
+		status = deliveryFailed
+		errText = sql.NullString{String: sendErr.Error(), Valid: true}
+		log.Printf("refill request %s: %s delivery to %s failed: %v", requestID, strings.ToLower(channel), destination, sendErr)
+	}
+
+	if _, err := s.queries.CreateRefillRequestDelivery(ctx, db.CreateRefillRequestDeliveryParams{
+		ID:              uuid.NewString(),
+		RefillRequestID: requestID,
+		Channel:         channel,
+		Destination:     destination,
+		Status:          status,
+		Error:           errText,
+		AttemptedAt:     formatDBTime(time.Now()),
+	}); err != nil {
+		log.Printf("refill request %s: record %s delivery: %v", requestID, strings.ToLower(channel), err)
+	}
+}
+
+func formatOrder(order Order) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Refill request %s\n", order.RequestID)
+	fmt.Fprintf(&b, "Requested: %s\n\n", order.RequestedAt.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "Patient: %s %s\n", order.Patient.FirstName, order.Patient.LastName)
+	fmt.Fprintf(&b, "Medication: %s\n", order.Medication.Name)
+	if order.Medication.Strength != "" {
+		fmt.Fprintf(&b, "Strength: %s\n", order.Medication.Strength)
+	}
+	if order.Medication.DosageForm != "" {
+		fmt.Fprintf(&b, "Form: %s\n", order.Medication.DosageForm)
+	}
+	if order.RxNumber != "" {
+		fmt.Fprintf(&b, "Rx number: %s\n", order.RxNumber)
+	}
+	if order.Prescriber != "" {
+		fmt.Fprintf(&b, "Prescriber: %s\n", order.Prescriber)
+	}
+	fmt.Fprintf(&b, "Quantity: %d\n", order.Quantity)
+	return b.String()
+}
+
+// SetStatus moves a request along REQUESTED -> READY -> PICKED_UP, or to
+// CANCELLED while it is still open. Setting the current status again is a
+// no-op.
+func SetStatus(ctx context.Context, q *db.Queries, id, status string) (db.RefillRequest, error) {
+	record, err := q.GetRefillRequest(ctx, id)
+	if err != nil {
+		return db.RefillRequest{}, fmt.Errorf("load refill request %s: %w", id, err)
+	}
+	if record.Status == status {
+		return record, nil
+	}
+
+	if !slices.Contains(transitions[record.Status], status) {
+		return db.RefillRequest{}, fmt.Errorf("refill request %s cannot go from %s to %s", id, record.Status, status)
+	}
+
+	updated, err := q.UpdateRefillRequestStatus(ctx, db.UpdateRefillRequestStatusParams{
+		Status:    status,
+		UpdatedAt: formatDBTime(time.Now()),
+		ID:        id,
+	})
+	if err != nil {
+		return db.RefillRequest{}, fmt.Errorf("update refill request status: %w", err)
+	}
+	return updated, nil
+}
+
+func formatDBTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,68 @@
+package pharmacy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed by
+// PHARMACY_WEBHOOK_SECRET, so the pharmacy system can check the sender.
+const SignatureHeader = "X-Pillbox-Signature"
+
+// WebhookClient posts refill requests as JSON to a pharmacy system.
+type WebhookClient struct {
+	secret     string
+	httpClient *http.Client
+}
+
+// NewWebhookClientFromEnv reads PHARMACY_WEBHOOK_SECRET; without it requests
+// are sent unsigned.
+func NewWebhookClientFromEnv() *WebhookClient {
+	return &WebhookClient{
+		secret: strings.TrimSpace(os.Getenv("PHARMACY_WEBHOOK_SECRET")),
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// Post sends order to url and treats any non-2xx response as a failure.
+func (c *WebhookClient) Post(ctx context.Context, url string, order Order) error {
+	body, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("encode refill request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
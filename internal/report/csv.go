@@ -0,0 +1,111 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSV writes the report as titled sections separated by blank lines,
+// each with its own header row, so it opens cleanly in a spreadsheet.
+func WriteCSV(w io.Writer, a Adherence) error {
+	cw := csv.NewWriter(w)
+	write := func(record ...string) {
+		_ = cw.Write(record)
+	}
+	section := func(title string, header ...string) {
+		if title != "Patient" {
+			write()
+		}
+		write(title)
+		if len(header) > 0 {
+			write(header...)
+		}
+	}
+
+	section("Patient")
+	write("Name", a.PatientName)
+	write("Timezone", a.Timezone)
+	write("From", a.Start.Format(time.DateOnly))
+	write("To", a.lastDay().Format(time.DateOnly))
+	write("Generated", a.GeneratedAt.Format(dateTimeLayout))
+	write("Scheduled", strconv.Itoa(a.Overall.Scheduled))
+	write("Taken", strconv.Itoa(a.Overall.Taken))
+	write("Missed", strconv.Itoa(a.Overall.Missed))
+	write("Skipped", strconv.Itoa(a.Overall.Skipped))
+	write("Adherence", formatRate(a.Overall.AdherenceRate))
+	write("On time", formatRate(a.Overall.OnTimeRate))
+	write("Mean delay", formatDelay(a.Overall.MeanDelayMinutes))
+	write("Longest streak", strconv.Itoa(a.Overall.LongestStreak))
+
+	section("Regimen", "Schedule", "Status", "Rule", "Since", "Medications", "Scheduled", "Taken", "Missed", "Skipped", "Adherence")
+	for _, entry := range a.Regimen {
+		write(
+			entry.Title,
+			entry.Status,
+			entry.Rule,
+			entry.Since.Format(time.DateOnly),
+			strings.Join(entry.Medications, "; "),
+			strconv.Itoa(entry.Stats.Scheduled),
+			strconv.Itoa(entry.Stats.Taken),
+			strconv.Itoa(entry.Stats.Missed),
+			strconv.Itoa(entry.Stats.Skipped),
+			formatRate(entry.Stats.AdherenceRate),
+		)
+	}
+
+	header := []string{"Date"}
+	for _, entry := range a.Regimen {
+		header = append(header, entry.Title)
+	}
+	section("Daily doses ("+gridLegend+")", header...)
+	days, cells := a.grid()
+	for i, day := range days {
+		record := []string{day.Format(time.DateOnly)}
+		for _, doses := range cells[i] {
+			marks := make([]string, 0, len(doses))
+			for _, dose := range doses {
+				mark := statusCode(dose.Status) + " " + dose.DueAt.Format(timeLayout)
+				if dose.ActedAt != nil {
+					mark += fmt.Sprintf(" (%s)", dose.ActedAt.Format(timeLayout))
+				}
+				marks = append(marks, mark)
+			}
+			record = append(record, strings.Join(marks, "; "))
+		}
+		write(record...)
+	}
+
+	section("Missed doses", "Due", "Schedule", "Medications", "Reason")
+	for _, dose := range a.Doses {
+		if dose.Status != StatusMissed {
+			continue
+		}
+		entry := a.Regimen[dose.Schedule]
+		write(dose.DueAt.Format(dateTimeLayout), entry.Title, strings.Join(entry.Medications, "; "), dose.Reason)
+	}
+
+	section("Device faults", "Due", "Reported", "Schedule", "Fault", "Source")
+	for _, fault := range a.Faults {
+		write(fault.DueAt.Format(dateTimeLayout), formatOptionalTime(fault.ActedAt, dateTimeLayout), fault.Schedule, fault.Kind, fault.Source)
+	}
+
+	section("Stock refills", "Date", "Medication", "Quantity", "Balance after", "Lot", "Expires", "By")
+	for _, refill := range a.Refills {
+		write(
+			refill.At.Format(dateTimeLayout),
+			refill.Medication,
+			strconv.FormatInt(refill.Quantity, 10),
+			strconv.FormatInt(refill.BalanceAfter, 10),
+			refill.LotNumber,
+			refill.ExpiresOn,
+			refill.Actor,
+		)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,231 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfMargin    = 15.0
+	pdfRowHeight = 6.0
+)
+
+// pdfWriter lays out tables on A4 pages, repeating a table's header after a
+// page break.
+type pdfWriter struct {
+	pdf *fpdf.Fpdf
+	// tr converts UTF-8 to the core fonts' cp1252 encoding
+	tr func(string) string
+}
+
+// WritePDF renders the report with the PDF core fonts, so no font files are
+// needed.
+func WritePDF(w io.Writer, a Adherence) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+	pdf.SetTitle("Adherence report", true)
+	p := &pdfWriter{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 4, p.tr(fmt.Sprintf("%s - generated %s - page %d/{nb}", a.PatientName, a.GeneratedAt.Format(dateTimeLayout), pdf.PageNo())), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, p.tr("Adherence report"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	p.keyValue("Patient", a.PatientName)
+	p.keyValue("Period", fmt.Sprintf("%s to %s (%s)", a.Start.Format(time.DateOnly), a.lastDay().Format(time.DateOnly), a.Timezone))
+	p.keyValue("Doses", fmt.Sprintf("%d scheduled, %d taken, %d missed, %d skipped, %d pending",
+		a.Overall.Scheduled, a.Overall.Taken, a.Overall.Missed, a.Overall.Skipped, a.Overall.Pending))
+	p.keyValue("Adherence", joinNonEmpty(", ",
+		formatRate(a.Overall.AdherenceRate),
+		labelled("on time ", formatRate(a.Overall.OnTimeRate)),
+		labelled("mean delay ", formatDelay(a.Overall.MeanDelayMinutes)),
+		fmt.Sprintf("longest streak %d", a.Overall.LongestStreak),
+	))
+
+	p.heading("Regimen")
+	regimen := make([][]string, 0, len(a.Regimen))
+	for _, entry := range a.Regimen {
+		regimen = append(regimen, []string{
+			entry.Title,
+			strings.Join(entry.Medications, ", "),
+			entry.Rule,
+			entry.Status,
+			fmt.Sprintf("%d/%d", entry.Stats.Taken, entry.Stats.Scheduled),
+			formatRate(entry.Stats.AdherenceRate),
+		})
+	}
+	p.table([]string{"Schedule", "Medications", "Rule", "Status", "Taken", "Rate"}, []float64{35, 55, 35, 20, 20, 15}, regimen)
+
+	p.heading("Daily doses")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(0, 5, p.tr(gridLegend), "", 1, "L", false, 0, "")
+	if len(a.Regimen) > 0 {
+		header := []string{"Date"}
+		widths := []float64{28}
+		column := (p.contentWidth() - widths[0]) / float64(len(a.Regimen))
+		for _, entry := range a.Regimen {
+			header = append(header, entry.Title)
+			widths = append(widths, column)
+		}
+		days, cells := a.grid()
+		rows := make([][]string, 0, len(days))
+		for i, day := range days {
+			row := []string{day.Format("Mon 2006-01-02")}
+			for _, doses := range cells[i] {
+				marks := make([]string, 0, len(doses))
+				for _, dose := range doses {
+					marks = append(marks, statusCode(dose.Status))
+				}
+				row = append(row, strings.Join(marks, " "))
+			}
+			rows = append(rows, row)
+		}
+		p.table(header, widths, rows)
+	}
+
+	p.heading("Missed doses")
+	var missed [][]string
+	for _, dose := range a.Doses {
+		if dose.Status != StatusMissed {
+			continue
+		}
+		entry := a.Regimen[dose.Schedule]
+		missed = append(missed, []string{dose.DueAt.Format(dateTimeLayout), entry.Title, strings.Join(entry.Medications, ", "), dose.Reason})
+	}
+	p.table([]string{"Due", "Schedule", "Medications", "Reason"}, []float64{32, 40, 73, 35}, missed)
+
+	p.heading("Device faults")
+	faults := make([][]string, 0, len(a.Faults))
+	for _, fault := range a.Faults {
+		faults = append(faults, []string{fault.DueAt.Format(dateTimeLayout), formatOptionalTime(fault.ActedAt, dateTimeLayout), fault.Schedule, fault.Kind, fault.Source})
+	}
+	p.table([]string{"Due", "Reported", "Schedule", "Fault", "Source"}, []float64{32, 32, 51, 35, 30}, faults)
+
+	p.heading("Stock refills")
+	refills := make([][]string, 0, len(a.Refills))
+	for _, refill := range a.Refills {
+		refills = append(refills, []string{
+			refill.At.Format(dateTimeLayout),
+			refill.Medication,
+			"+" + strconv.FormatInt(refill.Quantity, 10),
+			strconv.FormatInt(refill.BalanceAfter, 10),
+			joinNonEmpty(" ", refill.LotNumber, labelled("exp ", refill.ExpiresOn)),
+			refill.Actor,
+		})
+	}
+	p.table([]string{"Date", "Medication", "Added", "Balance", "Lot", "By"}, []float64{32, 58, 18, 18, 34, 20}, refills)
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("render pdf: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func (p *pdfWriter) contentWidth() float64 {
+	width, _ := p.pdf.GetPageSize()
+	return width - 2*pdfMargin
+}
+
+// ensureSpace starts a new page unless height fits above the bottom margin.
+func (p *pdfWriter) ensureSpace(height float64) bool {
+	_, pageHeight := p.pdf.GetPageSize()
+	if p.pdf.GetY()+height <= pageHeight-pdfMargin {
+		return false
+	}
+	p.pdf.AddPage()
+	return true
+}
+
+func (p *pdfWriter) keyValue(key, value string) {
+	p.pdf.SetFont("Helvetica", "B", 10)
+	p.pdf.CellFormat(25, 6, p.tr(key), "", 0, "L", false, 0, "")
+	p.pdf.SetFont("Helvetica", "", 10)
+	p.pdf.CellFormat(0, 6, p.tr(value), "", 1, "L", false, 0, "")
+}
+
+func (p *pdfWriter) heading(title string) {
+	// Keep a heading with at least its table header and first row.
+	p.ensureSpace(10 + 3*pdfRowHeight)
+	p.pdf.Ln(4)
+	p.pdf.SetFont("Helvetica", "B", 12)
+	p.pdf.CellFormat(0, 7, p.tr(title), "B", 1, "L", false, 0, "")
+	p.pdf.Ln(1)
+}
+
+func (p *pdfWriter) table(header []string, widths []float64, rows [][]string) {
+	if len(rows) == 0 {
+		p.pdf.SetFont("Helvetica", "I", 9)
+		p.pdf.CellFormat(0, pdfRowHeight, p.tr("None"), "", 1, "L", false, 0, "")
+		return
+	}
+
+	drawHeader := func() {
+		p.pdf.SetFont("Helvetica", "B", 8)
+		p.pdf.SetFillColor(230, 230, 230)
+		for i, title := range header {
+			p.pdf.CellFormat(widths[i], pdfRowHeight, p.fit(title, widths[i]), "1", 0, "L", true, 0, "")
+		}
+		p.pdf.Ln(-1)
+		p.pdf.SetFont("Helvetica", "", 8)
+	}
+
+	drawHeader()
+	for _, row := range rows {
+		if p.ensureSpace(pdfRowHeight) {
+			drawHeader()
+		}
+		for i, value := range row {
+			p.pdf.CellFormat(widths[i], pdfRowHeight, p.fit(value, widths[i]), "1", 0, "L", false, 0, "")
+		}
+		p.pdf.Ln(-1)
+	}
+}
+
+// fit encodes value and shortens it with an ellipsis to fit a cell.
+func (p *pdfWriter) fit(value string, width float64) string {
+	text := p.tr(value)
+	limit := width - 2*p.pdf.GetCellMargin()
+	if p.pdf.GetStringWidth(text) <= limit {
+		return text
+	}
+	runes := []rune(value)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		text = p.tr(string(runes) + "...")
+		if p.pdf.GetStringWidth(text) <= limit {
+			return text
+		}
+	}
+	return ""
+}
+
+func labelled(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return label + value
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,225 @@
+// Package report renders an adherence report for a date range as CSV or PDF
+// for caregivers to bring to appointments.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format is an output format, as accepted by Write.
+type Format string
+
+const (
+	FormatCSV Format = "CSV"
+	FormatPDF Format = "PDF"
+)
+
+// ParseFormat accepts a format name in any case, e.g. "pdf".
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToUpper(strings.TrimSpace(value))) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatPDF:
+		return FormatPDF, nil
+	}
+	return "", fmt.Errorf("unsupported report format %q (want csv or pdf)", value)
+}
+
+// ContentType is the MIME type of the format.
+func (f Format) ContentType() string {
+	if f == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension is the file extension of the format, without the dot.
+func (f Format) Extension() string {
+	return strings.ToLower(string(f))
+}
+
+// Dose statuses; they match the GraphQL DispenseStatus values used by the
+// adherence report.
+const (
+	StatusTaken   = "TAKEN"
+	StatusSkipped = "SKIPPED"
+	StatusMissed  = "MISSED"
+	StatusPending = "PENDING"
+)
+
+// Adherence is everything a report shows. Times are in the patient's
+// timezone; the range is [Start, End).
+type Adherence struct {
+	PatientName string
+	Timezone    string
+	Start       time.Time
+	End         time.Time
+	GeneratedAt time.Time
+	Overall     Stats
+	Regimen     []RegimenEntry
+	// Doses in due order; Schedule indexes Regimen
+	Doses   []Dose
+	Faults  []Fault
+	Refills []Refill
+}
+
+// Stats mirrors the GraphQL AdherenceStats; nil rates had nothing to divide.
+type Stats struct {
+	Scheduled        int
+	Taken            int
+	Missed           int
+	Skipped          int
+	Pending          int
+	AdherenceRate    *float64
+	OnTimeRate       *float64
+	MeanDelayMinutes *float64
+	LongestStreak    int
+}
+
+// RegimenEntry is one schedule with the medications it dispenses.
+type RegimenEntry struct {
+	Title string
+	// Status is the schedule's current status (ACTIVE, PAUSED, ARCHIVED)
+	Status string
+	Rule   string
+	Since  time.Time
+	// Medications reads like "1 x Metformin 500 mg"
+	Medications []string
+	Stats       Stats
+}
+
+type Dose struct {
+	Schedule int
+	DueAt    time.Time
+	Status   string
+	ActedAt  *time.Time
+	// Reason explains a missed dose: the device fault or "No response"
+	Reason string
+}
+
+// Fault is a dispense the device could not complete.
+type Fault struct {
+	DueAt    time.Time
+	ActedAt  *time.Time
+	Schedule string
+	Kind     string
+	Source   string
+}
+
+type Refill struct {
+	At           time.Time
+	Medication   string
+	Quantity     int64
+	BalanceAfter int64
+	LotNumber    string
+	ExpiresOn    string
+	Actor        string
+}
+
+// Filename is a download name such as
+// "adherence-stone-ava-2026-10-01-to-2026-10-31.pdf".
+func (a Adherence) Filename(format Format) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, a.PatientName)
+	name = strings.Trim(strings.Join(strings.FieldsFunc(name, func(r rune) bool { return r == '-' }), "-"), "-")
+	if name == "" {
+		name = "patient"
+	}
+	return fmt.Sprintf("adherence-%s-%s-to-%s.%s", name, a.Start.Format(time.DateOnly), a.lastDay().Format(time.DateOnly), format.Extension())
+}
+
+// Write renders the report in format.
+func Write(w io.Writer, format Format, a Adherence) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, a)
+	case FormatPDF:
+		return WritePDF(w, a)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+// lastDay is the last calendar day the range touches.
+func (a Adherence) lastDay() time.Time {
+	return a.End.Add(-time.Nanosecond)
+}
+
+// days lists the calendar days in the range.
+func (a Adherence) days() []time.Time {
+	var days []time.Time
+	day := time.Date(a.Start.Year(), a.Start.Month(), a.Start.Day(), 0, 0, 0, 0, a.Start.Location())
+	for ; day.Before(a.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// grid returns, per day and schedule, the doses due that day.
+func (a Adherence) grid() (days []time.Time, cells [][][]Dose) {
+	days = a.days()
+	cells = make([][][]Dose, len(days))
+	for i := range cells {
+		cells[i] = make([][]Dose, len(a.Regimen))
+	}
+	for _, dose := range a.Doses {
+		dueDay := time.Date(dose.DueAt.Year(), dose.DueAt.Month(), dose.DueAt.Day(), 0, 0, 0, 0, dose.DueAt.Location())
+		for i, day := range days {
+			if day.Equal(dueDay) {
+				cells[i][dose.Schedule] = append(cells[i][dose.Schedule], dose)
+				break
+			}
+		}
+	}
+	return days, cells
+}
+
+// statusCode is a dose's mark in the daily grid.
+func statusCode(status string) string {
+	switch status {
+	case StatusTaken:
+		return "T"
+	case StatusSkipped:
+		return "S"
+	case StatusPending:
+		return "P"
+	}
+	return "M"
+}
+
+const gridLegend = "T taken, M missed, S skipped, P pending"
+
+func formatRate(rate *float64) string {
+	if rate == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%%", *rate*100)
+}
+
+func formatDelay(minutes *float64) string {
+	if minutes == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.0f min", *minutes)
+}
+
+func formatOptionalTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+const (
+	dateTimeLayout = "2006-01-02 15:04"
+	timeLayout     = "15:04"
+)
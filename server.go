@@ -20,8 +20,11 @@ import (
 	"github.com/rs/cors"
 
 	"pillbox/graph"
+	"pillbox/internal/catalog"
 	"pillbox/internal/db"
+	"pillbox/internal/fhir"
 	"pillbox/internal/notifications"
+	"pillbox/internal/pharmacy"
 )
 
 //go:embed db/migrations/*.sql
@@ -62,9 +65,27 @@ func main() {
 		log.Fatalf("ping database: %v", err)
 	}
 
+	if path := catalog.PathFromEnv(); path != "" {
+		count, err := catalog.LoadFile(context.Background(), conn, path)
+		if err != nil {
+			log.Fatalf("load medication catalog: %v", err)
+		}
+		log.Printf("medication catalog loaded: %d entries from %s", count, path)
+	}
+
+	queries := db.New(conn)
+
+	// Refill requests still get recorded without SMTP; deliveries to email
+	// and fax addresses are marked failed so the caregiver knows to call.
+	mailer, err := pharmacy.NewMailerFromEnv()
+	if err != nil {
+		log.Printf("pharmacy email disabled: %v", err)
+	}
+
 	resolver := &graph.Resolver{
-		DB:      conn,
-		Queries: db.New(conn),
+		DB:       conn,
+		Queries:  queries,
+		Pharmacy: pharmacy.NewService(queries, mailer, pharmacy.NewWebhookClientFromEnv()),
 	}
 
 	notificationsEnabled := envOrDefault("NOTIFICATIONS_ENABLED", "true")
@@ -74,23 +95,33 @@ func main() {
 			log.Fatalf("init twilio sender: %v", err)
 		}
 
-		ttsClient, err := notifications.NewGoogleTTSClientFromEnv(context.Background())
-		if err != nil {
-			log.Fatalf("init google tts client: %v", err)
+		// Reminder audio is optional: without a usable TTS engine the worker
+		// still sends SMS reminders.
+		var synthesizer notifications.Synthesizer
+		if tts, err := notifications.NewSynthesizerFromEnv(context.Background()); err != nil {
+			log.Printf("reminder audio disabled: %v", err)
+		} else if cached, err := notifications.NewCachingSynthesizerFromEnv(resolver.Queries, tts); err != nil {
+			log.Printf("tts cache disabled: %v", err)
+			synthesizer = tts
+		} else {
+			synthesizer = cached
 		}
 
-		worker := notifications.NewWorker(resolver.Queries, sender, ttsClient)
+		worker := notifications.NewWorker(resolver.Queries, sender, synthesizer, resolver.Pharmacy)
 		go worker.Start(context.Background())
 		log.Printf("notification worker started")
 	}
 
+	go notifications.NewAudioJanitor(resolver.Queries).Start(context.Background())
+
 	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
 
 	mux := http.NewServeMux()
 	mux.Handle("/", playground.Handler("GraphQL Playground", "/query"))
 	mux.Handle("/query", srv)
 
-	audioHandler := notifications.NewAudioHTTPHandler()
+	audioHandler := notifications.NewAudioHTTPHandler(resolver.Queries)
+	adherenceReportHandler := graph.NewAdherenceReportHandler(resolver)
 
 	mux.HandleFunc("/patients/", func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasSuffix(r.URL.Path, "/next-audio") {
@@ -101,10 +132,27 @@ func main() {
 			audioHandler.HandleAckAudio(w, r)
 			return
 		}
+		if strings.HasSuffix(r.URL.Path, "/voice-messages") {
+			audioHandler.HandleUploadVoiceMessage(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/adherence-report") {
+			adherenceReportHandler.ServeHTTP(w, r)
+			return
+		}
 		http.NotFound(w, r)
 	})
 
 	mux.HandleFunc("/audio/", audioHandler.HandleServeAudio)
+	mux.Handle("/fhir/", fhir.NewHandler(resolver.Queries, fhir.MedicationCodeSystemFromEnv()))
+
+	smsValidator, err := notifications.NewTwilioRequestValidatorFromEnv()
+	if err != nil {
+		log.Printf("twilio webhooks disabled: %v", err)
+	} else {
+		mux.Handle("/sms/inbound", graph.NewInboundSMSHandler(resolver, smsValidator))
+		mux.Handle(notifications.StatusCallbackPath, notifications.NewStatusCallbackHandler(resolver.Queries, smsValidator))
+	}
 
 	handlerWithCors := cors.AllowAll().Handler(mux)
 